@@ -85,7 +85,7 @@ func (server *Server) deleteAccountRequest(ctx *gin.Context) {
 		return
 	}
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if account.Owner != authPayload.Username {
+	if !canAccess(authPayload, account.Owner) {
 		err := errors.New("account does not belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
@@ -120,7 +120,7 @@ func (server *Server) getAccount(ctx *gin.Context) {
 		return
 	}
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if account.Owner != authPayload.Username {
+	if !canAccess(authPayload, account.Owner) {
 		err = errors.New("account does not belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
@@ -129,7 +129,7 @@ func (server *Server) getAccount(ctx *gin.Context) {
 }
 
 type listAccountRequest struct {
-	PageID   int64 `form:"page_id" binding:"required,min=1"`
+	pageQuery
 	PageSize int64 `form:"page_size" binding:"required,min=5,max=10"`
 }
 
@@ -140,11 +140,20 @@ func (server *Server) listAccount(ctx *gin.Context) {
 		return
 	}
 
+	pageToken, err := req.pageToken("", "")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	arg := db.ListAccountsParams{
 		Owner:  authPayload.Username,
 		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+		Offset: req.offset(req.PageSize),
+	}
+	if pageToken != nil {
+		arg.StartAfter(*pageToken)
 	}
 
 	accounts, err := server.store.ListAccounts(ctx, arg)
@@ -153,5 +162,6 @@ func (server *Server) listAccount(ctx *gin.Context) {
 		return
 	}
 
+	ctx.Header(nextPageTokenHeader, arg.NextPageToken(accounts))
 	ctx.JSON(http.StatusOK, accounts)
 }
@@ -27,32 +27,32 @@ func (server *Server) newEntry(ctx *gin.Context) {
 		return
 	}
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if account.Owner != authPayload.Username {
+	if !canAccess(authPayload, account.Owner) {
 		err := errors.New("account does not belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
 
-	arg := db.AddAccountBalanceParams{
-		ID:     req.AccountID,
-		Amount: req.Amount,
-	}
-	_, err := server.store.AddAccountBalance(ctx, arg)
+	idempotency, err := server.idempotencyParams(ctx, authPayload.Username)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
-
-	entryArg := db.NewEntryParams{
-		AccountID: req.AccountID,
-		Amount:    req.Amount,
+	arg := db.DepositTxParams{
+		AccountID:   req.AccountID,
+		Amount:      req.Amount,
+		Idempotency: idempotency,
 	}
-	entry, err := server.store.NewEntry(ctx, entryArg)
+	result, err := server.store.DepositTx(ctx, arg)
 	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyConflict) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
-	ctx.JSON(http.StatusOK, entry)
+	ctx.JSON(http.StatusOK, result.Entry)
 }
 
 type GetEntryRequest struct {
@@ -80,7 +80,7 @@ func (server *Server) getEntry(ctx *gin.Context) {
 		return
 	}
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if account.Owner != authPayload.Username {
+	if !canAccess(authPayload, account.Owner) {
 		err := errors.New("account does not belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
@@ -90,8 +90,9 @@ func (server *Server) getEntry(ctx *gin.Context) {
 
 type listEntriesRequest struct {
 	AccountID int64 `form:"account_id" binding:"required,min=1"`
-	PageID    int64 `form:"page_id" binding:"required,min=1"`
-	PageSize  int64 `form:"page_size" binding:"required,min=5,max=10"`
+	PageSize  int64 `form:"page_size" binding:"required,min=5,max=100"`
+	pageQuery
+	historyQuery
 }
 
 func (server *Server) listEntries(ctx *gin.Context) {
@@ -100,6 +101,15 @@ func (server *Server) listEntries(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
+	if err := req.validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	pageToken, err := req.pageToken(req.SortBy, req.SortOrder)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
 
 	// Check if the accounts exist
 	account, valid := accountValidator(server, ctx, req.AccountID, "", false)
@@ -108,16 +118,27 @@ func (server *Server) listEntries(ctx *gin.Context) {
 		return
 	}
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if account.Owner != authPayload.Username {
+	if !canAccess(authPayload, account.Owner) {
 		err := errors.New("account does not belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
 
 	arg := db.ListEntriesParams{
-		AccountID: req.AccountID,
-		Limit:     req.PageSize,
-		Offset:    (req.PageID - 1) * req.PageSize,
+		AccountID:             req.AccountID,
+		StartTime:             req.startTime(),
+		EndTime:               req.endTime(),
+		MinAmount:             req.minAmount(),
+		MaxAmount:             req.maxAmount(),
+		Direction:             req.direction(),
+		CounterpartyAccountID: req.counterpartyAccountID(),
+		SortBy:                req.SortBy,
+		SortOrder:             req.SortOrder,
+		Limit:                 req.PageSize,
+		Offset:                req.offset(req.PageSize),
+	}
+	if pageToken != nil {
+		arg.StartAfter(*pageToken)
 	}
 
 	entries, err := server.store.ListEntries(ctx, arg)
@@ -125,5 +146,6 @@ func (server *Server) listEntries(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
+	ctx.Header(nextPageTokenHeader, arg.NextPageToken(entries))
 	ctx.JSON(http.StatusOK, entries)
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	mockdb "github.com/the-eduardo/Go-Bank/db/mock"
@@ -35,7 +36,7 @@ func TestGetEntryAPI(t *testing.T) {
 			name:    "OK",
 			entryID: entry.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				account := db.Account{ID: entry.AccountID, Owner: user.Username}
@@ -53,7 +54,7 @@ func TestGetEntryAPI(t *testing.T) {
 			name:    "NotFound",
 			entryID: entry.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetEntry(gomock.Any(), entry.ID).
@@ -68,7 +69,7 @@ func TestGetEntryAPI(t *testing.T) {
 			name:    "InternalError",
 			entryID: entry.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetEntry(gomock.Any(), entry.ID).
@@ -83,7 +84,7 @@ func TestGetEntryAPI(t *testing.T) {
 			name:    "InvalidID",
 			entryID: 0,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				// No call is expected because the request should fail validation.
@@ -96,7 +97,7 @@ func TestGetEntryAPI(t *testing.T) {
 			name:    "UnauthorizedUser",
 			entryID: entry.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, "unauthorized_user", user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, "unauthorized_user", user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -167,20 +168,16 @@ func TestCreateNewEntryAPI(t *testing.T) {
 			accountID: entry.AccountID,
 			Amount:    entry.Amount,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				account := db.Account{ID: entry.AccountID, Owner: user.Username}
 				store.EXPECT().GetAccount(gomock.Any(), entry.AccountID).
 					Times(1).Return(account, nil) // Mock GetAccount to return the account and nil error to pass validation
-				store.EXPECT().AddAccountBalance(gomock.Any(), gomock.Eq(db.AddAccountBalanceParams{
-					ID:     entry.AccountID,
-					Amount: entry.Amount, // I'm not sure if I should do that
-				})).Times(1).Return(db.Account{}, nil)
-				store.EXPECT().NewEntry(gomock.Any(), gomock.Eq(db.NewEntryParams{
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Eq(db.DepositTxParams{
 					AccountID: account.ID,
-					Amount:    entry.Amount, // I'm not sure if I should do that
-				})).Times(1).Return(entry, nil)
+					Amount:    entry.Amount,
+				})).Times(1).Return(db.DepositTxResult{Account: account, Entry: entry}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				// check the response
@@ -193,16 +190,16 @@ func TestCreateNewEntryAPI(t *testing.T) {
 			accountID: entry.AccountID,
 			Amount:    entry.Amount,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				account := db.Account{ID: entry.AccountID, Owner: user.Username}
 				store.EXPECT().GetAccount(gomock.Any(), entry.AccountID).
 					Times(1).Return(account, nil) // Mock GetAccount to return the account and nil error to pass validation
-				store.EXPECT().AddAccountBalance(gomock.Any(), gomock.Eq(db.AddAccountBalanceParams{
-					ID:     entry.AccountID,
-					Amount: entry.Amount,
-				})).Times(1).Return(db.Account{}, sql.ErrConnDone)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Eq(db.DepositTxParams{
+					AccountID: entry.AccountID,
+					Amount:    entry.Amount,
+				})).Times(1).Return(db.DepositTxResult{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -213,7 +210,7 @@ func TestCreateNewEntryAPI(t *testing.T) {
 			accountID: 0,
 			Amount:    0,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				// No call is expected because the request should fail validation.
@@ -227,7 +224,7 @@ func TestCreateNewEntryAPI(t *testing.T) {
 			accountID: entry.AccountID,
 			Amount:    entry.Amount,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, "unauthorized_user", user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, "unauthorized_user", user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -291,6 +288,7 @@ func TestListAccountEntriesAPI(t *testing.T) {
 		pageID    int
 		pageSize  int
 		AccountID int64
+		filters   map[string]string
 	}
 	testCases := []struct {
 		name          string
@@ -308,7 +306,7 @@ func TestListAccountEntriesAPI(t *testing.T) {
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				// Return a valid account with an owner
@@ -323,6 +321,152 @@ func TestListAccountEntriesAPI(t *testing.T) {
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				// check the response
 				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Empty(t, recorder.Header().Get(nextPageTokenHeader))
+			},
+		},
+		{
+			name: "Filtered",
+			query: Query{
+				pageID:    2,
+				pageSize:  50,
+				AccountID: entry.AccountID,
+				filters: map[string]string{
+					"start_time":              "2026-09-01T00:00:00Z",
+					"end_time":                "2026-10-01T00:00:00Z",
+					"min_amount":              "10",
+					"max_amount":              "100",
+					"direction":               util.DirectionOut,
+					"counterparty_account_id": "7",
+					"sort_by":                 util.SortByAmount,
+					"sort_order":              util.SortOrderDesc,
+				},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				account := db.Account{ID: entry.AccountID, Owner: user.Username}
+				store.EXPECT().GetAccount(gomock.Any(), entry.AccountID).
+					Times(1).Return(account, nil)
+
+				start := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+				arg := db.ListEntriesParams{
+					AccountID:             entry.AccountID,
+					StartTime:             pgtype.Timestamptz{Time: start, Valid: true},
+					EndTime:               pgtype.Timestamptz{Time: start.AddDate(0, 1, 0), Valid: true},
+					MinAmount:             pgtype.Int8{Int64: 10, Valid: true},
+					MaxAmount:             pgtype.Int8{Int64: 100, Valid: true},
+					Direction:             pgtype.Text{String: util.DirectionOut, Valid: true},
+					CounterpartyAccountID: pgtype.Int8{Int64: 7, Valid: true},
+					SortBy:                util.SortByAmount,
+					SortOrder:             util.SortOrderDesc,
+					Limit:                 50,
+					Offset:                50,
+				}
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.Entry{entry}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "PageToken",
+			query: Query{
+				pageSize:  5,
+				AccountID: entry.AccountID,
+				filters: map[string]string{
+					"sort_order": util.SortOrderDesc,
+					"page_token": util.PageToken{SortOrder: util.SortOrderDesc, CreatedAt: time.Unix(1700000000, 0).UTC(), Amount: 10, ID: entry.ID}.Encode(),
+				},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				account := db.Account{ID: entry.AccountID, Owner: user.Username}
+				store.EXPECT().GetAccount(gomock.Any(), entry.AccountID).
+					Times(1).Return(account, nil)
+
+				arg := db.ListEntriesParams{
+					AccountID:       entry.AccountID,
+					CursorID:        pgtype.Int8{Int64: entry.ID, Valid: true},
+					SortOrder:       util.SortOrderDesc,
+					CursorAmount:    pgtype.Int8{Int64: 10, Valid: true},
+					CursorCreatedAt: pgtype.Timestamptz{Time: time.Unix(1700000000, 0).UTC(), Valid: true},
+					Limit:           5,
+				}
+				page := make([]db.Entry, 5)
+				for i := range page {
+					page[i] = randomEntry(entry.AccountID)
+				}
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(page, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var entries []db.Entry
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &entries))
+				token, err := util.DecodePageToken(recorder.Header().Get(nextPageTokenHeader), "", util.SortOrderDesc)
+				require.NoError(t, err)
+				require.Equal(t, entries[4].ID, token.ID)
+			},
+		},
+		{
+			name: "PageTokenWithPageID",
+			query: Query{
+				pageID:    2,
+				pageSize:  5,
+				AccountID: entry.AccountID,
+				filters:   map[string]string{"page_token": util.PageToken{ID: entry.ID}.Encode()},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidDirection",
+			query: Query{
+				pageID:    1,
+				pageSize:  5,
+				AccountID: entry.AccountID,
+				filters:   map[string]string{"direction": "sideways"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MaxAmountBelowMinAmount",
+			query: Query{
+				pageID:    1,
+				pageSize:  5,
+				AccountID: entry.AccountID,
+				filters:   map[string]string{"min_amount": "100", "max_amount": "10"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
@@ -333,7 +477,7 @@ func TestListAccountEntriesAPI(t *testing.T) {
 				AccountID: entry.AccountID,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				// Return a valid account with an owner
@@ -356,7 +500,7 @@ func TestListAccountEntriesAPI(t *testing.T) {
 				AccountID: entry.AccountID,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), entry.AccountID).
@@ -375,7 +519,7 @@ func TestListAccountEntriesAPI(t *testing.T) {
 				AccountID: 0,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				// No call is expected because the request should fail validation.
@@ -392,7 +536,7 @@ func TestListAccountEntriesAPI(t *testing.T) {
 				AccountID: entry.AccountID,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, "unauthorized_user", user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, "unauthorized_user", user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -443,8 +587,13 @@ func TestListAccountEntriesAPI(t *testing.T) {
 
 			q := request.URL.Query()
 			q.Add("account_id", fmt.Sprintf("%d", tc.query.AccountID))
-			q.Add("page_id", fmt.Sprintf("%d", tc.query.pageID))
+			if tc.query.pageID != 0 {
+				q.Add("page_id", fmt.Sprintf("%d", tc.query.pageID))
+			}
 			q.Add("page_size", fmt.Sprintf("%d", tc.query.pageSize))
+			for key, value := range tc.query.filters {
+				q.Add(key, value)
+			}
 			request.URL.RawQuery = q.Encode()
 			//url := fmt.Sprintf("/entries/?account_id=%d&page_id=%d&page_size=%d", tc.AccountID, tc.PageID, tc.PageSize)
 
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"errors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"time"
+)
+
+// historyQuery holds the filters and the order of a transaction history request, the filters left empty are skipped
+type historyQuery struct {
+	StartTime             time.Time `form:"start_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTime               time.Time `form:"end_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	MinAmount             int64     `form:"min_amount" binding:"omitempty,min=1"`
+	MaxAmount             int64     `form:"max_amount" binding:"omitempty,min=1"`
+	Direction             string    `form:"direction" binding:"omitempty,oneof=in out"`
+	CounterpartyAccountID int64     `form:"counterparty_account_id" binding:"omitempty,min=1"`
+	SortBy                string    `form:"sort_by" binding:"omitempty,oneof=created_at amount"`
+	SortOrder             string    `form:"sort_order" binding:"omitempty,oneof=asc desc"`
+}
+
+// validate checks the ranges, which the binding tags cannot compare
+func (query historyQuery) validate() error {
+	if !query.StartTime.IsZero() && !query.EndTime.IsZero() && !query.EndTime.After(query.StartTime) {
+		return errors.New("end_time must be after start_time")
+	}
+	if query.MaxAmount != 0 && query.MaxAmount < query.MinAmount {
+		return errors.New("max_amount must not be less than min_amount")
+	}
+	return nil
+}
+
+func (query historyQuery) startTime() pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: query.StartTime, Valid: !query.StartTime.IsZero()}
+}
+
+func (query historyQuery) endTime() pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: query.EndTime, Valid: !query.EndTime.IsZero()}
+}
+
+func (query historyQuery) minAmount() pgtype.Int8 {
+	return pgtype.Int8{Int64: query.MinAmount, Valid: query.MinAmount != 0}
+}
+
+func (query historyQuery) maxAmount() pgtype.Int8 {
+	return pgtype.Int8{Int64: query.MaxAmount, Valid: query.MaxAmount != 0}
+}
+
+func (query historyQuery) direction() pgtype.Text {
+	return pgtype.Text{String: query.Direction, Valid: query.Direction != ""}
+}
+
+func (query historyQuery) counterpartyAccountID() pgtype.Int8 {
+	return pgtype.Int8{Int64: query.CounterpartyAccountID, Valid: query.CounterpartyAccountID != 0}
+}
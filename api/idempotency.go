@@ -0,0 +1,25 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/val"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// idempotencyParams returns nil when the client did not send an Idempotency-Key header
+func (server *Server) idempotencyParams(ctx *gin.Context, username string) (*db.IdempotencyParams, error) {
+	key := ctx.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		return nil, nil
+	}
+	if err := val.ValidateIdempotencyKey(key); err != nil {
+		return nil, err
+	}
+	return &db.IdempotencyParams{
+		Key:      key,
+		Username: username,
+		Window:   server.config.IdempotencyKeyTTL,
+	}, nil
+}
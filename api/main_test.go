@@ -10,10 +10,15 @@ import (
 	"time"
 )
 
+// testDailyTransferLimits are the kyc tier limits of the test server
+var testDailyTransferLimits = []int64{0, 100000, 1000000}
+
 func newTestServer(t *testing.T, store db.Store) *Server {
 	config := util.Config{
-		TokenSymmetricKey:   util.RandomString(32),
-		AccessTokenDuration: time.Minute,
+		TokenSymmetricKey:      util.RandomString(32),
+		AccessTokenDuration:    time.Minute,
+		RequireVerifiedEmail:   true,
+		KYCDailyTransferLimits: testDailyTransferLimits,
 	}
 	server, err := NewServer(config, store)
 	require.NoError(t, err)
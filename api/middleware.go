@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/the-eduardo/Go-Bank/token"
+	"github.com/the-eduardo/Go-Bank/util"
 	"net/http"
+	"slices"
 	"strings"
 )
 
@@ -15,7 +17,8 @@ const (
 	authorizationPayloadKey = "authorization_payload"
 )
 
-func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+// authMiddleware authenticates the request and only lets through the accessible roles
+func authMiddleware(tokenMaker token.Maker, accessibleRoles []string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		// Get the token from the header
 		authorizationHeaderKey := ctx.GetHeader(authorizationHeaderKey)
@@ -39,12 +42,23 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 		}
 		// If the token is valid, get the user from the token and add it to the context
 		accessToken := fields[1]
-		payload, err := tokenMaker.VerifyToken(accessToken)
+		payload, err := tokenMaker.VerifyToken(accessToken, token.TokenTypeAccessToken)
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
 			return
 		}
+		if !slices.Contains(accessibleRoles, payload.Role) {
+			err := fmt.Errorf("role %q cannot access this resource", payload.Role)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
 		ctx.Set(authorizationPayloadKey, payload)
 		ctx.Next()
 	}
 }
+
+// canAccess checks if the authenticated user may act on the resources of owner.
+// Staff roles may act on the resources of any user.
+func canAccess(payload *token.Payload, owner string) bool {
+	return payload.Username == owner || util.IsStaffRole(payload.Role)
+}
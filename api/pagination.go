@@ -0,0 +1,39 @@
+package api
+
+import (
+	"errors"
+	"github.com/the-eduardo/Go-Bank/util"
+)
+
+// nextPageTokenHeader carries the token of the next page, the body of a list stays a plain array
+const nextPageTokenHeader = "X-Next-Page-Token"
+
+// pageQuery asks for a page either by page_id or by the page_token of the previous page.
+// Leaving both empty asks for the first page.
+type pageQuery struct {
+	PageID    int64  `form:"page_id" binding:"omitempty,min=1"`
+	PageToken string `form:"page_token"`
+}
+
+// offset returns the number of rows before a page asked by page_id
+func (query pageQuery) offset(pageSize int64) int64 {
+	if query.PageID < 1 {
+		return 0
+	}
+	return (query.PageID - 1) * pageSize
+}
+
+// pageToken decodes the page token, it returns nil when the page is asked by page_id
+func (query pageQuery) pageToken(sortBy string, sortOrder string) (*util.PageToken, error) {
+	if query.PageToken == "" {
+		return nil, nil
+	}
+	if query.PageID != 0 {
+		return nil, errors.New("page_id must be empty when page_token is set")
+	}
+	token, err := util.DecodePageToken(query.PageToken, sortBy, sortOrder)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
@@ -6,28 +6,51 @@ import (
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/fees"
+	"github.com/the-eduardo/Go-Bank/fx"
+	"github.com/the-eduardo/Go-Bank/policy"
 	"github.com/the-eduardo/Go-Bank/token"
 	"github.com/the-eduardo/Go-Bank/util"
 )
 
 // Server provides the HTTP rest API
 type Server struct {
-	config     util.Config
-	tokenMaker token.Maker
-	store      db.Store
-	router     *gin.Engine
+	config       util.Config
+	tokenMaker   token.Maker
+	store        db.Store
+	rateProvider fx.RateProvider
+	feeSchedule  *fees.Schedule
+	policy       policy.Policy
+	router       *gin.Engine
 }
 
 // NewServer creates a new HTTP server and set up routing
 func NewServer(config util.Config, store db.Store) (*Server, error) {
-	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	tokenMaker, err := token.NewMaker(token.MakerConfig{
+		Kind:           config.TokenMaker,
+		SymmetricKey:   config.TokenSymmetricKey,
+		PrivateKeyFile: config.TokenPrivateKeyFile,
+		KeyID:          config.TokenKeyID,
+		KeyringFile:    config.TokenKeyringFile,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
+	rateProvider, err := fx.NewRateProvider(config.FXRateProvider, config.FXRatesSource)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create rate provider: %w", err)
+	}
+	feeSchedule, err := fees.LoadSchedule(config.FeeScheduleFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load fee schedule: %w", err)
+	}
 	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
+		config:       config,
+		store:        store,
+		tokenMaker:   tokenMaker,
+		rateProvider: rateProvider,
+		feeSchedule:  feeSchedule,
+		policy:       policy.New(config),
 	}
 	server.setupRouter()
 
@@ -47,7 +70,7 @@ func (server *Server) setupRouter() {
 	router.POST("/users/login", server.loginUser)
 	router.POST("/tokens/renew_access", server.renewAccessToken)
 
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, []string{util.DepositorRole, util.BankerRole, util.AdminRole}))
 
 	// Add routes for accounts
 	authRoutes.POST("/accounts", server.createAccount)
@@ -57,14 +80,23 @@ func (server *Server) setupRouter() {
 
 	// Add routes for transfers
 	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.GET("/transfers/fee", server.previewTransferFee)
 	authRoutes.GET("/transfers/:id", server.getTransfer)
 	authRoutes.GET("/transfers/", server.listTransfers)
 
+	// Add routes for exchange rate quotes
+	authRoutes.POST("/fx/quotes", server.createFXQuote)
+
 	// Add routes for entries
 	authRoutes.POST("/entries", server.newEntry)
 	authRoutes.GET("/entries/:id", server.getEntry)
 	authRoutes.GET("/entries/", server.listEntries)
 
+	// Add admin only routes
+	adminRoutes := router.Group("/admin").Use(authMiddleware(server.tokenMaker, []string{util.AdminRole}))
+	adminRoutes.GET("/users/", server.listUsers)
+	adminRoutes.GET("/accounts/", server.listAllAccounts)
+
 	server.router = router
 }
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
+	"github.com/the-eduardo/Go-Bank/token"
 	"net/http"
 	"time"
 )
@@ -24,7 +25,7 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
-	refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken)
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken, token.TokenTypeRefreshToken)
 	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
@@ -47,6 +48,11 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
+	if session.RotatedAt.Valid {
+		err = fmt.Errorf("refresh token was already rotated")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
 	if session.Username != refreshPayload.Username {
 		err = fmt.Errorf("incorrect session user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
@@ -64,9 +70,23 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		return
 
 	}
+	// The role may have changed since the login, the new token carries the current one
+	user, err := server.store.GetUser(ctx, session.Username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
 	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
-		refreshPayload.Username,
-		server.config.AccessTokenDuration)
+		user.Username,
+		user.Role,
+		refreshPayload.ID,
+		server.config.AccessTokenDuration,
+		token.TokenTypeAccessToken,
+	)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -3,8 +3,11 @@ package api
 import (
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/fx"
+	"github.com/the-eduardo/Go-Bank/policy"
 	"github.com/the-eduardo/Go-Bank/token"
 	"net/http"
 )
@@ -14,6 +17,8 @@ type CreateTransferRequest struct {
 	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
 	Amount        int64  `json:"amount" binding:"required,gt=0"`
 	Currency      string `json:"currency" binding:"required,currency"`
+	// QuoteID is required when the destination account uses a different currency
+	QuoteID string `json:"quote_id" binding:"omitempty,uuid"`
 }
 
 func (server *Server) createTransfer(ctx *gin.Context) {
@@ -29,30 +34,136 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if fromAccount.Owner != authPayload.Username {
+	if !canAccess(authPayload, fromAccount.Owner) {
 		err := errors.New("from account does not belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
+	// The policy applies to the owner of the money, also when staff transfers on their behalf
+	owner, err := server.store.GetUser(ctx, fromAccount.Owner)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	limits, err := server.policy.TransferLimits(owner)
+	if err != nil {
+		if errors.Is(err, policy.ErrEmailNotVerified) || errors.Is(err, policy.ErrKYCRequired) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
 
-	_, valid = accountValidator(server, ctx, req.ToAccountID, req.Currency, true)
+	// The destination currency may differ from the source currency only for quoted transfers
+	toAccount, valid := accountValidator(server, ctx, req.ToAccountID, req.Currency, req.QuoteID == "")
 	if !valid {
 		return
 	}
+	// system accounts belong to the ledger and never receive transfers
+	if toAccount.IsSystem {
+		ctx.JSON(http.StatusNotFound, errorResponse(errors.New("account not found")))
+		return
+	}
 
-	arg := db.TransferTxParams{
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
-		Amount:        req.Amount,
+	idempotency, err := server.idempotencyParams(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var transfer db.TransferTxResult
+	if req.QuoteID == "" {
+		arg := db.TransferTxParams{
+			FromAccountID: req.FromAccountID,
+			ToAccountID:   req.ToAccountID,
+			Amount:        req.Amount,
+			Idempotency:   idempotency,
+			Limits:        limits,
+			Fees:          server.feeSchedule,
+		}
+		transfer, err = server.store.TransferTx(ctx, arg)
+	} else {
+		quoteID, _ := ConvertGoogleUUIDToPGTypeUUID(uuid.MustParse(req.QuoteID))
+		arg := db.FXTransferTxParams{
+			FromAccountID: req.FromAccountID,
+			ToAccountID:   req.ToAccountID,
+			Amount:        req.Amount,
+			QuoteID:       quoteID,
+			FromCurrency:  fromAccount.Currency,
+			ToCurrency:    toAccount.Currency,
+			Username:      authPayload.Username,
+			Idempotency:   idempotency,
+			Limits:        limits,
+			Fees:          server.feeSchedule,
+		}
+		transfer, err = server.store.FXTransferTx(ctx, arg)
 	}
-	transfer, err := server.store.TransferTx(ctx, arg)
 	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyConflict) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		if errors.Is(err, fx.ErrInvalidAmount) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrInvalidQuote) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		var limitErr *db.TransferLimitError
+		if errors.As(err, &limitErr) {
+			ctx.JSON(http.StatusUnprocessableEntity, transferLimitResponse(limitErr))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 	ctx.JSON(http.StatusOK, transfer)
 }
 
+// transferLimitResponse tells the client which limit was reached and how much of it is left
+func transferLimitResponse(err *db.TransferLimitError) gin.H {
+	return gin.H{
+		"error":     err.Error(),
+		"scope":     err.Scope,
+		"period":    err.Period,
+		"limit":     err.Limit,
+		"remaining": err.Remaining,
+	}
+}
+
+type previewTransferFeeRequest struct {
+	Amount   int64  `form:"amount" binding:"required,gt=0"`
+	Currency string `form:"currency" binding:"required,currency"`
+}
+
+// previewTransferFeeResponse is the fee a transfer would be charged, Total is debited from the source account
+type previewTransferFeeResponse struct {
+	Amount   int64  `json:"amount"`
+	Fee      int64  `json:"fee"`
+	Total    int64  `json:"total"`
+	Currency string `json:"currency"`
+}
+
+// previewTransferFee calculates the fee of a transfer without moving money
+func (server *Server) previewTransferFee(ctx *gin.Context) {
+	var req previewTransferFeeRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fee := server.feeSchedule.Calculate(req.Currency, req.Amount)
+	ctx.JSON(http.StatusOK, previewTransferFeeResponse{
+		Amount:   req.Amount,
+		Fee:      fee,
+		Total:    req.Amount + fee,
+		Currency: req.Currency,
+	})
+}
+
 type getTransferRequest struct {
 	ID int64 `uri:"id" binding:"required,min=1"`
 }
@@ -77,8 +188,9 @@ func (server *Server) getTransfer(ctx *gin.Context) {
 
 type ListTransferRequest struct {
 	FromAccountID int64 `form:"from_account_id" binding:"required,min=1"`
-	PageID        int64 `form:"page_id" binding:"required,min=1"`
-	PageSize      int64 `form:"page_size" binding:"required,min=5,max=10"`
+	PageSize      int64 `form:"page_size" binding:"required,min=5,max=100"`
+	pageQuery
+	historyQuery
 }
 
 func (server *Server) listTransfers(ctx *gin.Context) {
@@ -87,6 +199,15 @@ func (server *Server) listTransfers(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
+	if err := req.validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	pageToken, err := req.pageToken(req.SortBy, req.SortOrder)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
 
 	// Check if the accounts exist
 	account, valid := accountValidator(server, ctx, req.FromAccountID, "", false)
@@ -95,16 +216,27 @@ func (server *Server) listTransfers(ctx *gin.Context) {
 		return
 	}
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if account.Owner != authPayload.Username {
+	if !canAccess(authPayload, account.Owner) {
 		err := errors.New("account does not belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
 
 	arg := db.ListTransfersByAccountIdParams{
-		FromAccountID: req.FromAccountID,
-		Limit:         req.PageSize,
-		Offset:        (req.PageID - 1) * req.PageSize,
+		AccountID:             req.FromAccountID,
+		StartTime:             req.startTime(),
+		EndTime:               req.endTime(),
+		MinAmount:             req.minAmount(),
+		MaxAmount:             req.maxAmount(),
+		Direction:             req.direction(),
+		CounterpartyAccountID: req.counterpartyAccountID(),
+		SortBy:                req.SortBy,
+		SortOrder:             req.SortOrder,
+		Limit:                 req.PageSize,
+		Offset:                req.offset(req.PageSize),
+	}
+	if pageToken != nil {
+		arg.StartAfter(*pageToken)
 	}
 
 	transfer, err := server.store.ListTransfersByAccountId(ctx, arg)
@@ -112,5 +244,6 @@ func (server *Server) listTransfers(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
+	ctx.Header(nextPageTokenHeader, arg.NextPageToken(transfer))
 	ctx.JSON(http.StatusOK, transfer)
 }
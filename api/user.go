@@ -9,8 +9,8 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/token"
 	"github.com/the-eduardo/Go-Bank/util"
-	"golang.org/x/crypto/bcrypt"
 	"net/http"
 	"time"
 )
@@ -25,6 +25,7 @@ type userResponse struct {
 	Username          string             `json:"username"`
 	FullName          string             `json:"full_name"`
 	Email             string             `json:"email"`
+	Role              string             `json:"role"`
 	PasswordChangedAt pgtype.Timestamptz `json:"password_changed_at"`
 	CreatedAt         pgtype.Timestamptz `json:"created_at"`
 }
@@ -34,6 +35,7 @@ func newUserResponse(user db.User) userResponse {
 		Username:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
+		Role:              user.Role,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 	}
@@ -90,6 +92,26 @@ type loginUserResponse struct {
 	User                  userResponse       `json:"user"`
 }
 
+// errInvalidCredentials is the same for an unknown username and a wrong password
+var errInvalidCredentials = errors.New("invalid username or password")
+
+// loginFailed counts a failed attempt for the username and the client ip, then answers the request.
+// Unlike the gRPC API this server has no task distributor to send the lockout email.
+func (server *Server) loginFailed(ctx *gin.Context, username string) {
+	_, err := server.store.LoginFailureTx(ctx, db.LoginFailureTxParams{
+		Username:            username,
+		ClientIP:            ctx.ClientIP(),
+		MaxUserAttempts:     server.config.LoginMaxAttempts,
+		MaxClientIPAttempts: server.config.LoginMaxAttemptsPerIP,
+		LockoutDuration:     server.config.LoginLockoutDuration,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidCredentials))
+}
+
 func (server *Server) loginUser(ctx *gin.Context) {
 	var req loginUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -97,35 +119,81 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	lockedUntil, err := server.store.GetLoginLockout(ctx, db.GetLoginLockoutParams{
+		Username: req.Username,
+		ClientIp: ctx.ClientIP(),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if lockedUntil.Valid && lockedUntil.Time.After(time.Now()) {
+		err := fmt.Errorf("too many failed login attempts, try again after %s", lockedUntil.Time.UTC().Format(time.RFC3339))
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(err))
+		return
+	}
+
 	user, err := server.store.GetUser(ctx, req.Username)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			util.CheckPasswordOfMissingUser(req.Password)
+			server.loginFailed(ctx, req.Username)
 			return
 		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
+	if user.HashedPassword == "" {
+		// System users have no password and never log in, they fail like a missing user
+		util.CheckPasswordOfMissingUser(req.Password)
+		server.loginFailed(ctx, user.Username)
+		return
+	}
 	err = util.CheckPassword(user.HashedPassword, req.Password)
 	if err != nil {
-		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
-			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-			return
-		}
+		server.loginFailed(ctx, user.Username)
+		return
+	}
+
+	// This API has no second login step, users with two-factor authentication log in through gRPC
+	factor, err := server.store.GetTOTPFactor(ctx, user.Username)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
-	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
-		user.Username,
-		server.config.AccessTokenDuration)
+	if err == nil && factor.IsConfirmed {
+		err := errors.New("two-factor authentication is enabled, log in with /v1/login_user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	err = server.store.ResetLoginAttempts(ctx, db.ResetLoginAttemptsParams{
+		Scope: db.LoginScopeUsername,
+		Key:   user.Username,
+	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
+
 	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
 		user.Username,
+		user.Role,
+		uuid.Nil,
 		server.config.RefreshTokenDuration,
+		token.TokenTypeRefreshToken,
+	)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
+		user.Username,
+		user.Role,
+		refreshPayload.ID,
+		server.config.AccessTokenDuration,
+		token.TokenTypeAccessToken,
 	)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
@@ -145,6 +213,8 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		ClientIp:     ctx.ClientIP(),
 		IsBlocked:    false,
 		ExpiresAt:    fixedRefreshPayloadExpiresAt,
+		// A login starts a new family of rotated refresh tokens
+		FamilyID: fixedRefreshPayload,
 	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
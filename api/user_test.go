@@ -2,16 +2,19 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
@@ -214,10 +217,25 @@ func TestLoginUserAPI(t *testing.T) {
 				"password": password,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockout(gomock.Any(), gomock.Eq(db.GetLoginLockoutParams{Username: user.Username, ClientIp: "192.0.2.1"})).
+					Times(1).
+					Return(pgtype.Timestamptz{}, nil)
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
 					Return(user, nil)
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.TotpFactor{}, pgx.ErrNoRows)
+				store.EXPECT().
+					ResetLoginAttempts(gomock.Any(), gomock.Eq(db.ResetLoginAttemptsParams{
+						Scope: db.LoginScopeUsername,
+						Key:   user.Username,
+					})).
+					Times(1).
+					Return(nil)
 				store.EXPECT().
 					CreateSession(gomock.Any(), gomock.Any()).
 					Times(1)
@@ -226,6 +244,33 @@ func TestLoginUserAPI(t *testing.T) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name: "MFAEnabled",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockout(gomock.Any(), gomock.Eq(db.GetLoginLockoutParams{Username: user.Username, ClientIp: "192.0.2.1"})).
+					Times(1).
+					Return(pgtype.Timestamptz{}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.TotpFactor{Username: user.Username, IsConfirmed: true}, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "UserNotFound",
 			body: gin.H{
@@ -233,13 +278,27 @@ func TestLoginUserAPI(t *testing.T) {
 				"password": password,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockout(gomock.Any(), gomock.Eq(db.GetLoginLockoutParams{Username: "NotFound", ClientIp: "192.0.2.1"})).
+					Times(1).
+					Return(pgtype.Timestamptz{}, nil)
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, pgx.ErrNoRows)
+				store.EXPECT().
+					LoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.LoginFailureTxParams) (db.LoginFailureTxResult, error) {
+						require.Equal(t, "NotFound", arg.Username)
+						require.Equal(t, "192.0.2.1", arg.ClientIP)
+						return db.LoginFailureTxResult{}, nil
+					})
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+				// An unknown username looks exactly like a wrong password
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), errInvalidCredentials.Error())
 			},
 		},
 		{
@@ -249,13 +308,74 @@ func TestLoginUserAPI(t *testing.T) {
 				"password": "incorrect",
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockout(gomock.Any(), gomock.Eq(db.GetLoginLockoutParams{Username: user.Username, ClientIp: "192.0.2.1"})).
+					Times(1).
+					Return(pgtype.Timestamptz{}, nil)
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
 					Return(user, nil)
+				store.EXPECT().
+					LoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginFailureTxResult{}, nil)
+				store.EXPECT().
+					ResetLoginAttempts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), errInvalidCredentials.Error())
+			},
+		},
+		{
+			name: "UserWithoutPassword",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				systemUser := user
+				systemUser.HashedPassword = ""
+				store.EXPECT().
+					GetLoginLockout(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(pgtype.Timestamptz{}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(systemUser, nil)
+				store.EXPECT().
+					LoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginFailureTxResult{}, nil)
+				store.EXPECT().
+					ResetLoginAttempts(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), errInvalidCredentials.Error())
+			},
+		},
+		{
+			name: "LockedOut",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockout(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
 			},
 		},
 		{
@@ -265,6 +385,10 @@ func TestLoginUserAPI(t *testing.T) {
 				"password": password,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginLockout(gomock.Any(), gomock.Eq(db.GetLoginLockoutParams{Username: user.Username, ClientIp: "192.0.2.1"})).
+					Times(1).
+					Return(pgtype.Timestamptz{}, nil)
 				store.EXPECT().
 					GetUser(gomock.Any(), gomock.Any()).
 					Times(1).
@@ -311,6 +435,7 @@ func TestLoginUserAPI(t *testing.T) {
 			url := "/users/login"
 			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
 			require.NoError(t, err)
+			request.RemoteAddr = "192.0.2.1:4321"
 
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(recorder)
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/util"
+	"os"
+)
+
+// reconcile checks the ledger and exits with a non-zero status when it finds any inconsistency
+func main() {
+	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		log.Fatal().Msgf("cannot load config: %v", err)
+	}
+	if config.Environment == "development" {
+		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	}
+
+	conn, err := pgxpool.New(context.Background(), config.DBSource)
+	if err != nil {
+		log.Fatal().Msgf("cannot connect to db: %v", err)
+	}
+	defer conn.Close()
+
+	store := db.NewStore(conn)
+	result, err := store.Reconcile(context.Background())
+	if err != nil {
+		log.Fatal().Msgf("cannot reconcile the ledger: %v", err)
+	}
+
+	for _, account := range result.UnreconciledAccounts {
+		log.Error().
+			Int64("account_id", account.ID).
+			Int64("balance", account.Balance).
+			Int64("entries_total", account.EntriesTotal).
+			Msg("balance does not match entries")
+	}
+	for _, journal := range result.UnbalancedJournals {
+		log.Error().
+			Int64("journal_id", journal.JournalID.Int64).
+			Str("currency", journal.Currency).
+			Int64("total", journal.Total).
+			Msg("journal is unbalanced")
+	}
+	if !result.OK() {
+		conn.Close()
+		os.Exit(1)
+	}
+	log.Info().Msg("ledger is reconciled")
+}
@@ -0,0 +1,13 @@
+CREATE TABLE "idempotency_keys" (
+                                    "username" varchar NOT NULL,
+                                    "key" varchar NOT NULL,
+                                    "request_hash" varchar NOT NULL,
+                                    "response_body" jsonb NOT NULL,
+                                    "created_at" timestamptz NOT NULL DEFAULT (now()),
+                                    "expires_at" timestamptz NOT NULL,
+                                    PRIMARY KEY ("username", "key")
+);
+
+CREATE INDEX ON "idempotency_keys" ("expires_at");
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,8 @@
+ALTER TABLE "accounts" ADD COLUMN "overdraft_limit" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "overdraft_limit_positive" CHECK ("overdraft_limit" >= 0);
+
+-- NOT VALID keeps legacy negative balances but enforces the limit on every new write
+ALTER TABLE "accounts" ADD CONSTRAINT "balance_within_overdraft" CHECK ("balance" >= -"overdraft_limit") NOT VALID;
+
+COMMENT ON COLUMN "accounts"."overdraft_limit" IS 'how far below zero the balance may go';
@@ -0,0 +1,9 @@
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "quote_id";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "exchange_rate";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "to_amount";
+
+COMMENT ON COLUMN "transfers"."amount" IS 'must be positive';
+
+DROP TABLE IF EXISTS "fx_quotes";
@@ -0,0 +1,28 @@
+CREATE TABLE "fx_quotes" (
+                             "id" uuid PRIMARY KEY,
+                             "username" varchar NOT NULL,
+                             "from_currency" varchar NOT NULL,
+                             "to_currency" varchar NOT NULL,
+                             "rate" double precision NOT NULL,
+                             "is_used" boolean NOT NULL DEFAULT false,
+                             "expires_at" timestamptz NOT NULL,
+                             "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "fx_quotes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfers" ADD COLUMN "to_amount" bigint;
+
+UPDATE "transfers" SET "to_amount" = "amount";
+
+ALTER TABLE "transfers" ALTER COLUMN "to_amount" SET NOT NULL;
+
+ALTER TABLE "transfers" ADD COLUMN "exchange_rate" double precision NOT NULL DEFAULT 1;
+
+ALTER TABLE "transfers" ADD COLUMN "quote_id" uuid;
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("quote_id") REFERENCES "fx_quotes" ("id");
+
+COMMENT ON COLUMN "transfers"."amount" IS 'must be positive, in the source account currency';
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'credited amount, in the destination account currency';
@@ -0,0 +1,17 @@
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "is_system");
+
+DELETE FROM "entries" WHERE "journal_id" IN (SELECT "id" FROM "journals" WHERE "kind" = 'opening_balance');
+
+DELETE FROM "accounts" WHERE "is_system";
+
+DELETE FROM "users" WHERE "username" IN ('system_cash_in', 'system_cash_out', 'system_fees', 'system_fx');
+
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "balance_within_overdraft";
+
+ALTER TABLE "accounts" ADD CONSTRAINT "balance_within_overdraft" CHECK ("balance" >= -"overdraft_limit") NOT VALID;
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "is_system";
+
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "journal_id";
+
+DROP TABLE IF EXISTS "journals";
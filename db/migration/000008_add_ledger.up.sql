@@ -0,0 +1,58 @@
+CREATE TABLE "journals" (
+                            "id" bigserial PRIMARY KEY,
+                            "kind" varchar NOT NULL,
+                            "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "entries" ADD COLUMN "journal_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
+CREATE INDEX ON "entries" ("journal_id");
+
+COMMENT ON COLUMN "entries"."journal_id" IS 'null only for entries recorded before the ledger';
+
+ALTER TABLE "accounts" ADD COLUMN "is_system" boolean NOT NULL DEFAULT false;
+
+-- system accounts are the counterparty of money entering or leaving the bank, so they may go below zero
+ALTER TABLE "accounts" DROP CONSTRAINT "balance_within_overdraft";
+
+ALTER TABLE "accounts" ADD CONSTRAINT "balance_within_overdraft" CHECK ("is_system" OR "balance" >= -"overdraft_limit") NOT VALID;
+
+-- the bank holds one system account per purpose and currency. System users have no password and cannot log in
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email", "is_email_verified") VALUES
+    ('system_cash_in', '', 'System Cash In', 'cash-in@system.gobank.invalid', true),
+    ('system_cash_out', '', 'System Cash Out', 'cash-out@system.gobank.invalid', true),
+    ('system_fees', '', 'System Fees', 'fees@system.gobank.invalid', true),
+    ('system_fx', '', 'System FX', 'fx@system.gobank.invalid', true);
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "is_system")
+SELECT "username", 0, "currency"
+FROM "users", (VALUES ('USD'), ('EUR'), ('BRL')) AS currencies ("currency")
+WHERE "username" IN ('system_cash_in', 'system_cash_out', 'system_fees', 'system_fx');
+
+-- legacy balances were changed without entries, book the difference as an opening balance against cash-in
+WITH "diffs" AS (
+    SELECT a."id", a."currency", a."balance" - COALESCE(SUM(e."amount"), 0) AS "amount"
+    FROM "accounts" a
+    LEFT JOIN "entries" e ON e."account_id" = a."id"
+    WHERE NOT a."is_system"
+    GROUP BY a."id"
+    HAVING a."balance" <> COALESCE(SUM(e."amount"), 0)
+), "journal" AS (
+    INSERT INTO "journals" ("kind")
+    SELECT 'opening_balance' WHERE EXISTS (SELECT 1 FROM "diffs")
+    RETURNING "id"
+)
+INSERT INTO "entries" ("account_id", "amount", "journal_id")
+SELECT d."id", d."amount", j."id" FROM "diffs" d, "journal" j
+UNION ALL
+SELECT s."id", -SUM(d."amount"), j."id"
+FROM "diffs" d
+JOIN "accounts" s ON s."owner" = 'system_cash_in' AND s."currency" = d."currency"
+CROSS JOIN "journal" j
+GROUP BY s."id", j."id";
+
+UPDATE "accounts" a
+SET "balance" = (SELECT COALESCE(SUM(e."amount"), 0) FROM "entries" e WHERE e."account_id" = a."id")
+WHERE a."is_system";
@@ -0,0 +1,37 @@
+CREATE TABLE "totp_factors" (
+                             "username" varchar PRIMARY KEY,
+                             "secret" varchar NOT NULL,
+                             "is_confirmed" boolean NOT NULL DEFAULT false,
+                             "last_used_step" bigint NOT NULL DEFAULT 0,
+                             "confirmed_at" timestamptz,
+                             "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "recovery_codes" (
+                               "id" bigserial PRIMARY KEY,
+                               "username" varchar NOT NULL,
+                               "hashed_code" varchar NOT NULL,
+                               "used_at" timestamptz,
+                               "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "mfa_challenges" (
+                               "id" uuid PRIMARY KEY,
+                               "username" varchar NOT NULL,
+                               "attempts" int NOT NULL DEFAULT 0,
+                               "is_used" boolean NOT NULL DEFAULT false,
+                               "expires_at" timestamptz NOT NULL,
+                               "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "recovery_codes" ("username", "hashed_code");
+
+ALTER TABLE "totp_factors" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "recovery_codes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "mfa_challenges" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+COMMENT ON COLUMN "totp_factors"."last_used_step" IS 'time step of the last accepted code, older or equal steps are replays';
+
+COMMENT ON COLUMN "recovery_codes"."hashed_code" IS 'sha256 of the code, the codes are random enough not to need a slow hash';
@@ -0,0 +1,12 @@
+CREATE TABLE "login_attempts" (
+                               "scope" varchar NOT NULL,
+                               "key" varchar NOT NULL,
+                               "failed_count" int NOT NULL DEFAULT 0,
+                               "last_failed_at" timestamptz NOT NULL DEFAULT (now()),
+                               "locked_until" timestamptz,
+                               PRIMARY KEY ("scope", "key")
+);
+
+COMMENT ON COLUMN "login_attempts"."scope" IS 'username or client_ip';
+
+COMMENT ON COLUMN "login_attempts"."key" IS 'not a foreign key, failures for unknown usernames are counted too';
@@ -0,0 +1,14 @@
+CREATE TABLE "password_resets" (
+                                "id" bigserial PRIMARY KEY,
+                                "username" varchar NOT NULL,
+                                "hashed_code" varchar UNIQUE NOT NULL,
+                                "is_used" boolean NOT NULL DEFAULT false,
+                                "created_at" timestamptz NOT NULL DEFAULT (now()),
+                                "expires_at" timestamptz NOT NULL
+);
+
+CREATE INDEX ON "password_resets" ("username");
+
+ALTER TABLE "password_resets" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+COMMENT ON COLUMN "password_resets"."hashed_code" IS 'sha256 of the code sent by email';
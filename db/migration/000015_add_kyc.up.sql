@@ -0,0 +1,34 @@
+ALTER TABLE "users" ADD COLUMN "kyc_tier" int NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "users"."kyc_tier" IS '0 until a kyc submission is approved, it sets the daily transfer limit';
+
+CREATE TABLE "kyc_submissions" (
+                                "id" bigserial PRIMARY KEY,
+                                "username" varchar NOT NULL,
+                                "tier" int NOT NULL,
+                                "document_type" varchar NOT NULL,
+                                "document_number" varchar NOT NULL,
+                                "status" varchar NOT NULL DEFAULT 'pending',
+                                "reviewed_by" varchar,
+                                "review_note" varchar NOT NULL DEFAULT '',
+                                "reviewed_at" timestamptz,
+                                "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "kyc_submissions" ("username");
+
+CREATE INDEX ON "kyc_submissions" ("status", "created_at");
+
+CREATE UNIQUE INDEX "kyc_submissions_one_pending" ON "kyc_submissions" ("username") WHERE "status" = 'pending';
+
+ALTER TABLE "kyc_submissions" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "kyc_submissions" ADD FOREIGN KEY ("reviewed_by") REFERENCES "users" ("username");
+
+ALTER TABLE "kyc_submissions" ADD CONSTRAINT "valid_kyc_status" CHECK ("status" IN ('pending', 'approved', 'rejected'));
+
+ALTER TABLE "kyc_submissions" ADD CONSTRAINT "valid_kyc_tier" CHECK ("tier" > 0);
+
+COMMENT ON COLUMN "kyc_submissions"."tier" IS 'kyc tier the user asks for';
+
+COMMENT ON COLUMN "kyc_submissions"."status" IS 'pending, approved or rejected';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "account_transfer_limits";
+DROP INDEX IF EXISTS "transfers_from_account_id_created_at_idx";
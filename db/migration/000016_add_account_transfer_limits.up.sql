@@ -0,0 +1,18 @@
+CREATE TABLE "account_transfer_limits" (
+                                       "account_id" bigint PRIMARY KEY,
+                                       "per_transfer" bigint,
+                                       "daily" bigint,
+                                       "weekly" bigint,
+                                       "hourly_count" bigint,
+                                       "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
+
+ALTER TABLE "account_transfer_limits" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "account_transfer_limits" ADD CONSTRAINT "valid_transfer_limits" CHECK (
+    "per_transfer" >= 0 AND "daily" >= 0 AND "weekly" >= 0 AND "hourly_count" >= 0
+);
+
+COMMENT ON TABLE "account_transfer_limits" IS 'a null limit uses the default from the config, 0 removes the limit';
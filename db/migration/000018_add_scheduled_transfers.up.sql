@@ -0,0 +1,56 @@
+CREATE TABLE "scheduled_transfers" (
+                                    "id" bigserial PRIMARY KEY,
+                                    "owner" varchar NOT NULL,
+                                    "from_account_id" bigint NOT NULL,
+                                    "to_account_id" bigint NOT NULL,
+                                    "amount" bigint NOT NULL,
+                                    "cron_expression" varchar NOT NULL DEFAULT '',
+                                    "interval_seconds" bigint NOT NULL DEFAULT 0,
+                                    "next_run_at" timestamptz NOT NULL,
+                                    "is_active" boolean NOT NULL DEFAULT true,
+                                    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "scheduled_transfer_runs" (
+                                        "id" bigserial PRIMARY KEY,
+                                        "scheduled_transfer_id" bigint NOT NULL,
+                                        "scheduled_for" timestamptz NOT NULL,
+                                        "status" varchar NOT NULL,
+                                        "transfer_id" bigint,
+                                        "error" varchar NOT NULL DEFAULT '',
+                                        "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+
+CREATE INDEX "scheduled_transfers_due" ON "scheduled_transfers" ("next_run_at") WHERE "is_active";
+
+CREATE UNIQUE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id", "scheduled_for");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD CONSTRAINT "valid_scheduled_transfer_amount" CHECK ("amount" > 0);
+
+ALTER TABLE "scheduled_transfers" ADD CONSTRAINT "valid_scheduled_transfer_recurrence" CHECK (
+    ("cron_expression" = '') <> ("interval_seconds" = 0) AND "interval_seconds" >= 0
+);
+
+ALTER TABLE "scheduled_transfer_runs" ADD CONSTRAINT "valid_scheduled_transfer_run_status" CHECK ("status" IN ('succeeded', 'failed'));
+
+COMMENT ON COLUMN "scheduled_transfers"."owner" IS 'owner of the source account, who gets the failure emails';
+
+COMMENT ON COLUMN "scheduled_transfers"."cron_expression" IS 'standard cron expression in UTC, empty when interval_seconds is set';
+
+COMMENT ON COLUMN "scheduled_transfers"."next_run_at" IS 'advanced when the run is enqueued, missed runs are skipped';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."scheduled_for" IS 'one run per occurrence, so a retried run never transfers twice';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."status" IS 'succeeded or failed';
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "transfers_pending_holds";
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "hold_expires_at";
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "authorized_amount";
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "status";
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "balance_within_overdraft";
+ALTER TABLE "accounts" ADD CONSTRAINT "balance_within_overdraft" CHECK ("is_system" OR "balance" >= -"overdraft_limit") NOT VALID;
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "available_balance";
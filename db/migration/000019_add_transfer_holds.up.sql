@@ -0,0 +1,26 @@
+ALTER TABLE "accounts" ADD COLUMN "available_balance" bigint NOT NULL DEFAULT 0;
+
+UPDATE "accounts" SET "available_balance" = "balance";
+
+ALTER TABLE "accounts" ALTER COLUMN "available_balance" DROP DEFAULT;
+
+-- the holds only lower available_balance, so checking it keeps balance within the overdraft limit too
+ALTER TABLE "accounts" DROP CONSTRAINT "balance_within_overdraft";
+
+ALTER TABLE "accounts" ADD CONSTRAINT "balance_within_overdraft" CHECK ("is_system" OR "available_balance" >= -"overdraft_limit") NOT VALID;
+
+COMMENT ON COLUMN "accounts"."available_balance" IS 'balance minus the holds of pending transfers';
+
+ALTER TABLE "transfers" ADD COLUMN "status" varchar NOT NULL DEFAULT 'posted';
+
+ALTER TABLE "transfers" ADD COLUMN "authorized_amount" bigint;
+
+ALTER TABLE "transfers" ADD COLUMN "hold_expires_at" timestamptz;
+
+ALTER TABLE "transfers" ADD CONSTRAINT "valid_transfer_status" CHECK ("status" IN ('pending', 'posted', 'voided', 'expired'));
+
+CREATE INDEX "transfers_pending_holds" ON "transfers" ("hold_expires_at") WHERE "status" = 'pending';
+
+COMMENT ON COLUMN "transfers"."status" IS 'pending while the amount and fee are held, posted once they reach the ledger, voided or expired when the hold is released';
+
+COMMENT ON COLUMN "transfers"."authorized_amount" IS 'amount held by the authorization, null for transfers posted at once';
@@ -0,0 +1,3 @@
+ALTER TABLE "transfers" DROP CONSTRAINT IF EXISTS "reversed_within_amount";
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "reversed_amount";
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "reversal_of";
@@ -0,0 +1,14 @@
+ALTER TABLE "transfers" ADD COLUMN "reversal_of" bigint;
+
+ALTER TABLE "transfers" ADD COLUMN "reversed_amount" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reversal_of") REFERENCES "transfers" ("id");
+
+-- the refunds of a transfer can never give back more than it moved
+ALTER TABLE "transfers" ADD CONSTRAINT "reversed_within_amount" CHECK ("reversed_amount" >= 0 AND "reversed_amount" <= "amount");
+
+CREATE INDEX ON "transfers" ("reversal_of");
+
+COMMENT ON COLUMN "transfers"."reversal_of" IS 'transfer refunded by this one, null for other transfers';
+
+COMMENT ON COLUMN "transfers"."reversed_amount" IS 'sum of the reversals of this transfer';
@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS "transfers_from_account_id_created_at_idx" ON "transfers" ("from_account_id", "created_at");
+DROP INDEX IF EXISTS "transfers_to_account_keyset";
+DROP INDEX IF EXISTS "transfers_from_account_keyset";
+DROP INDEX IF EXISTS "entries_account_keyset";
+DROP INDEX IF EXISTS "accounts_owner_keyset";
@@ -0,0 +1,10 @@
+CREATE INDEX "accounts_owner_keyset" ON "accounts" ("owner", "created_at", "id");
+
+CREATE INDEX "entries_account_keyset" ON "entries" ("account_id", "created_at", "id");
+
+CREATE INDEX "transfers_from_account_keyset" ON "transfers" ("from_account_id", "created_at", "id");
+
+CREATE INDEX "transfers_to_account_keyset" ON "transfers" ("to_account_id", "created_at", "id");
+
+-- the keyset index also serves the transfer limits, which sum the transfers sent since a time
+DROP INDEX IF EXISTS "transfers_from_account_id_created_at_idx";
@@ -0,0 +1,62 @@
+CREATE TABLE "webhook_subscriptions" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "url" varchar NOT NULL,
+  "secret" varchar NOT NULL,
+  "event_types" varchar[] NOT NULL,
+  "is_global" boolean NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_events" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "dispatched_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_deliveries" (
+  "id" bigserial PRIMARY KEY,
+  "event_id" bigint NOT NULL,
+  "subscription_id" bigint NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" int NOT NULL DEFAULT 0,
+  "response_status" int,
+  "error" varchar NOT NULL DEFAULT '',
+  "last_attempt_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "webhook_subscriptions" ("owner");
+
+CREATE INDEX "webhook_events_undispatched" ON "webhook_events" ("id") WHERE "dispatched_at" IS NULL;
+
+CREATE UNIQUE INDEX ON "webhook_deliveries" ("event_id", "subscription_id");
+
+CREATE INDEX ON "webhook_deliveries" ("subscription_id", "id");
+
+ALTER TABLE "webhook_subscriptions" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "webhook_events" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("event_id") REFERENCES "webhook_events" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("subscription_id") REFERENCES "webhook_subscriptions" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "webhook_subscriptions" ADD CONSTRAINT "valid_webhook_event_types" CHECK (cardinality("event_types") > 0);
+
+ALTER TABLE "webhook_deliveries" ADD CONSTRAINT "valid_webhook_delivery_status" CHECK ("status" IN ('pending', 'succeeded', 'failed'));
+
+COMMENT ON COLUMN "webhook_subscriptions"."secret" IS 'signs the payloads with HMAC-SHA256, only shown when the subscription is created';
+
+COMMENT ON COLUMN "webhook_subscriptions"."is_global" IS 'only for admins, receives the events of every user instead of those of its owner';
+
+COMMENT ON COLUMN "webhook_events"."owner" IS 'user the event is about, only the subscriptions of this user and the global ones receive it';
+
+COMMENT ON COLUMN "webhook_events"."dispatched_at" IS 'set once a delivery is created for every matching subscription';
+
+COMMENT ON COLUMN "webhook_deliveries"."status" IS 'pending until an attempt succeeds or the retries run out';
+
+COMMENT ON COLUMN "webhook_deliveries"."response_status" IS 'http status of the last attempt, null when the receiver could not be reached';
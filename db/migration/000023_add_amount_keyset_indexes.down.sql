@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "transfers_to_account_amount_keyset";
+DROP INDEX IF EXISTS "transfers_from_account_amount_keyset";
+DROP INDEX IF EXISTS "entries_account_amount_keyset";
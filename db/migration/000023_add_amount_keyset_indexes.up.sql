@@ -0,0 +1,7 @@
+-- the entries are sorted by their amount without sign
+CREATE INDEX "entries_account_amount_keyset" ON "entries" ("account_id", abs("amount"), "id");
+
+CREATE INDEX "transfers_from_account_amount_keyset" ON "transfers" ("from_account_id", "amount", "id");
+
+-- a received transfer is sorted by the amount credited to the account
+CREATE INDEX "transfers_to_account_amount_keyset" ON "transfers" ("to_account_id", "to_amount", "id");
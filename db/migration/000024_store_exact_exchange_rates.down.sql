@@ -0,0 +1,3 @@
+ALTER TABLE "transfers" ALTER COLUMN "exchange_rate" TYPE double precision;
+
+ALTER TABLE "fx_quotes" ALTER COLUMN "rate" TYPE double precision;
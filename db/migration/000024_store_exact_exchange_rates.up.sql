@@ -0,0 +1,4 @@
+-- rates keep 8 decimal places and are converted with exact arithmetic, never through a float
+ALTER TABLE "fx_quotes" ALTER COLUMN "rate" TYPE numeric(20,8) USING round("rate"::numeric, 8);
+
+ALTER TABLE "transfers" ALTER COLUMN "exchange_rate" TYPE numeric(20,8) USING round("exchange_rate"::numeric, 8);
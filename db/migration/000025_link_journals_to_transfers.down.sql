@@ -0,0 +1 @@
+ALTER TABLE "journals" DROP COLUMN IF EXISTS "transfer_id";
@@ -0,0 +1,22 @@
+ALTER TABLE "journals" ADD COLUMN "transfer_id" bigint;
+
+ALTER TABLE "journals" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "journals" ("transfer_id");
+
+COMMENT ON COLUMN "journals"."transfer_id" IS 'transfer posted by the journal, null for deposits and other journals';
+
+-- transfers posted at once were written in the same transaction as their journal, so they share its creation time
+UPDATE "journals" j
+SET "transfer_id" = t."id"
+FROM "transfers" t
+WHERE j."kind" IN ('transfer', 'fx_transfer', 'reversal')
+  AND j."created_at" = t."created_at"
+  AND EXISTS (
+    SELECT 1 FROM "entries" e
+    WHERE e."journal_id" = j."id" AND e."account_id" = t."from_account_id" AND e."amount" = -t."amount"
+  )
+  AND EXISTS (
+    SELECT 1 FROM "entries" e
+    WHERE e."journal_id" = j."id" AND e."account_id" = t."to_account_id" AND e."amount" = t."to_amount"
+  );
@@ -0,0 +1,3 @@
+ALTER TABLE "accounts" ADD COLUMN "balance_version" bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "accounts"."balance_version" IS 'incremented by every change of balance or available_balance, orders the account events';
@@ -3,9 +3,10 @@
 INSERT INTO accounts (
    owner,
    balance,
+   available_balance,
    currency
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $2, $3
 ) RETURNING *;
 
 -- name: GetAccount :one
@@ -17,26 +18,71 @@ SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE;
 
--- name: ListAccounts :many
+-- GetAccountSnapshot returns the balances of an account with their version and the id of its last entry, 0 when it has none.
+-- They are read by one statement, so the balances include exactly the entries up to last_entry_id.
+-- name: GetAccountSnapshot :one
+SELECT a.id, a.balance, a.available_balance, a.balance_version,
+  COALESCE((SELECT max(e.id) FROM entries e WHERE e.account_id = a.id), 0)::bigint AS last_entry_id
+FROM accounts a
+WHERE a.id = $1;
+
+-- name: GetSystemAccount :one
+SELECT * FROM accounts
+WHERE owner = $1 AND currency = $2 AND is_system
+LIMIT 1;
+
+-- ListAccountsOldestFirst returns the accounts of an owner created after the cursor, oldest first
+-- name: ListAccountsOldestFirst :many
+SELECT * FROM accounts
+WHERE owner = sqlc.arg(owner)
+  AND (created_at, id) > (sqlc.arg(cursor_created_at)::timestamptz, sqlc.arg(cursor_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit');
+
+-- ListAllAccounts returns the accounts of every user
+-- name: ListAllAccounts :many
 SELECT * FROM accounts
-WHERE owner = $1
 ORDER BY id
-LIMIT $2
-OFFSET $3;
+LIMIT $1
+OFFSET $2;
 
--- name: UpdateAccount :one
+-- Use AddAccountBalance to add the amount of money as a new entry.
+-- name: AddAccountBalance :one
 UPDATE accounts
-SET balance = $2
-WHERE id = $1
+SET balance = balance + sqlc.arg(amount),
+    available_balance = available_balance + sqlc.arg(amount),
+    balance_version = balance_version + 1
+WHERE id = sqlc.arg(id)
 RETURNING *;
 
--- Use AddAccountBalance to add the amount of money as a new entry.
--- name: AddAccountBalance :one
+-- AddAccountHold holds an amount of the available balance, a negative amount releases it
+-- name: AddAccountHold :one
 UPDATE accounts
-SET balance = balance + sqlc.arg(amount)
+SET available_balance = available_balance - sqlc.arg(amount),
+    balance_version = balance_version + 1
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
 -- name: DeleteAccount :exec
 DELETE FROM accounts
 WHERE id = $1;
+
+-- name: UpdateAccountOverdraftLimit :one
+UPDATE accounts
+SET overdraft_limit = sqlc.arg(overdraft_limit)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- ListUnreconciledAccounts returns the accounts whose balance differs from the sum of their entries
+-- name: ListUnreconciledAccounts :many
+SELECT a.id, a.balance, COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- NotifyAccountEvent notifies the listeners of the account_events channel, the payload is an AccountEvent in JSON.
+-- Postgres delivers the notification when the transaction commits.
+-- name: NotifyAccountEvent :exec
+SELECT pg_notify('account_events', sqlc.arg(payload)::text);
@@ -3,9 +3,10 @@
 -- name: NewEntry :one
 INSERT INTO entries (
 account_id,
-amount
+amount,
+journal_id
 ) VALUES (
- $1, $2
+ $1, $2, $3
 ) RETURNING *;
 
 -- GetEntry returns the entry with an entry ID
@@ -13,10 +14,96 @@ amount
 SELECT * FROM entries
 WHERE id = $1;
 
--- ListEntries returns a list of entries for the given account ID
--- name: ListEntries :many
-SELECT * FROM entries
-WHERE account_id = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3;
\ No newline at end of file
+-- ListEntriesNewestFirst returns the entries of an account created before the cursor, newest first.
+-- The filters left null are skipped, direction is 'in' for credits and 'out' for debits,
+-- the amounts are compared without their sign and the counterparty is an account with an entry in the same journal.
+-- The cursor is the created_at and id of the last entry of the previous page, the ListEntries wrapper picks the query of the sort order.
+-- name: ListEntriesNewestFirst :many
+SELECT * FROM entries e
+WHERE e.account_id = sqlc.arg(account_id)
+  AND (e.created_at, e.id) < (sqlc.arg(cursor_created_at)::timestamptz, sqlc.arg(cursor_id)::bigint)
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR e.created_at >= sqlc.narg(start_time))
+  AND (sqlc.narg(end_time)::timestamptz IS NULL OR e.created_at < sqlc.narg(end_time))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR abs(e.amount) >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR abs(e.amount) <= sqlc.narg(max_amount))
+  AND (sqlc.narg(direction)::text IS NULL
+    OR (sqlc.narg(direction) = 'in' AND e.amount > 0)
+    OR (sqlc.narg(direction) = 'out' AND e.amount < 0))
+  AND (sqlc.narg(counterparty_account_id)::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries c
+    WHERE c.journal_id = e.journal_id AND c.account_id = sqlc.narg(counterparty_account_id)))
+ORDER BY e.created_at DESC, e.id DESC
+LIMIT sqlc.arg('limit');
+
+-- ListEntriesOldestFirst returns the entries of an account created after the cursor, oldest first, with the filters of ListEntriesNewestFirst
+-- name: ListEntriesOldestFirst :many
+SELECT * FROM entries e
+WHERE e.account_id = sqlc.arg(account_id)
+  AND (e.created_at, e.id) > (sqlc.arg(cursor_created_at)::timestamptz, sqlc.arg(cursor_id)::bigint)
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR e.created_at >= sqlc.narg(start_time))
+  AND (sqlc.narg(end_time)::timestamptz IS NULL OR e.created_at < sqlc.narg(end_time))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR abs(e.amount) >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR abs(e.amount) <= sqlc.narg(max_amount))
+  AND (sqlc.narg(direction)::text IS NULL
+    OR (sqlc.narg(direction) = 'in' AND e.amount > 0)
+    OR (sqlc.narg(direction) = 'out' AND e.amount < 0))
+  AND (sqlc.narg(counterparty_account_id)::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries c
+    WHERE c.journal_id = e.journal_id AND c.account_id = sqlc.narg(counterparty_account_id)))
+ORDER BY e.created_at ASC, e.id ASC
+LIMIT sqlc.arg('limit');
+
+-- ListEntriesLargestFirst returns the entries of an account below the cursor by amount without sign then id, largest first,
+-- with the filters of ListEntriesNewestFirst
+-- name: ListEntriesLargestFirst :many
+SELECT * FROM entries e
+WHERE e.account_id = sqlc.arg(account_id)
+  AND (abs(e.amount), e.id) < (sqlc.arg(cursor_amount)::bigint, sqlc.arg(cursor_id)::bigint)
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR e.created_at >= sqlc.narg(start_time))
+  AND (sqlc.narg(end_time)::timestamptz IS NULL OR e.created_at < sqlc.narg(end_time))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR abs(e.amount) >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR abs(e.amount) <= sqlc.narg(max_amount))
+  AND (sqlc.narg(direction)::text IS NULL
+    OR (sqlc.narg(direction) = 'in' AND e.amount > 0)
+    OR (sqlc.narg(direction) = 'out' AND e.amount < 0))
+  AND (sqlc.narg(counterparty_account_id)::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries c
+    WHERE c.journal_id = e.journal_id AND c.account_id = sqlc.narg(counterparty_account_id)))
+ORDER BY abs(e.amount) DESC, e.id DESC
+LIMIT sqlc.arg('limit');
+
+-- ListEntriesSmallestFirst returns the entries of an account above the cursor by amount without sign then id, smallest first,
+-- with the filters of ListEntriesNewestFirst
+-- name: ListEntriesSmallestFirst :many
+SELECT * FROM entries e
+WHERE e.account_id = sqlc.arg(account_id)
+  AND (abs(e.amount), e.id) > (sqlc.arg(cursor_amount)::bigint, sqlc.arg(cursor_id)::bigint)
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR e.created_at >= sqlc.narg(start_time))
+  AND (sqlc.narg(end_time)::timestamptz IS NULL OR e.created_at < sqlc.narg(end_time))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR abs(e.amount) >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR abs(e.amount) <= sqlc.narg(max_amount))
+  AND (sqlc.narg(direction)::text IS NULL
+    OR (sqlc.narg(direction) = 'in' AND e.amount > 0)
+    OR (sqlc.narg(direction) = 'out' AND e.amount < 0))
+  AND (sqlc.narg(counterparty_account_id)::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries c
+    WHERE c.journal_id = e.journal_id AND c.account_id = sqlc.narg(counterparty_account_id)))
+ORDER BY abs(e.amount) ASC, e.id ASC
+LIMIT sqlc.arg('limit');
+
+-- ListEntriesAfter returns the entries of an account with an id above after_id, in id order,
+-- with the balance of the account right after each of them, worked back from the current balance.
+-- name: ListEntriesAfter :many
+SELECT e.id, e.account_id, e.amount, e.created_at, e.journal_id,
+  (a.balance - COALESCE(SUM(e.amount) OVER (ORDER BY e.id DESC ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING), 0))::bigint AS balance
+FROM entries e
+JOIN accounts a ON a.id = e.account_id
+WHERE e.account_id = sqlc.arg(account_id) AND e.id > sqlc.arg(after_id)
+ORDER BY e.id
+LIMIT sqlc.arg('limit');
+
+-- SumEntriesBefore returns the sum of the entries of an account created before a time, its balance at that time
+-- name: SumEntriesBefore :one
+SELECT COALESCE(sum(amount), 0)::bigint AS total
+FROM entries
+WHERE account_id = sqlc.arg(account_id) AND created_at < sqlc.arg(before)::timestamptz;
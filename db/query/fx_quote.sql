@@ -0,0 +1,25 @@
+-- name: CreateFXQuote :one
+INSERT INTO fx_quotes (
+    id,
+    username,
+    from_currency,
+    to_currency,
+    rate,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetFXQuote :one
+SELECT * FROM fx_quotes
+WHERE id = $1 LIMIT 1;
+
+-- UseFXQuote marks a quote as used, only if it is still valid for the user
+-- name: UseFXQuote :one
+UPDATE fx_quotes
+SET is_used = true
+WHERE id = @id
+    AND username = @username
+    AND is_used = false
+    AND expires_at > now()
+RETURNING *;
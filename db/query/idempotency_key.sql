@@ -0,0 +1,24 @@
+-- name: GetIdempotencyKeyForUpdate :one
+SELECT * FROM idempotency_keys
+WHERE username = $1 AND key = $2 AND expires_at > now()
+LIMIT 1
+FOR UPDATE;
+
+-- CreateIdempotencyKey overwrites a key only once its replay window is over
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+    username,
+    key,
+    request_hash,
+    response_body,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+) ON CONFLICT (username, key) DO UPDATE
+SET
+    request_hash = EXCLUDED.request_hash,
+    response_body = EXCLUDED.response_body,
+    created_at = now(),
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= now()
+RETURNING *;
@@ -0,0 +1,23 @@
+-- name: CreateJournal :one
+INSERT INTO journals (
+    kind,
+    transfer_id
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- ListJournalTransfers returns the transfers posted by the given journals, with the journal of each
+-- name: ListJournalTransfers :many
+SELECT sqlc.embed(t), j.id AS journal_id FROM journals j
+JOIN transfers t ON t.id = j.transfer_id
+WHERE j.id = ANY(sqlc.arg(journal_ids)::bigint[]);
+
+-- ListUnbalancedJournals returns the journals whose entries do not sum to zero in some currency
+-- name: ListUnbalancedJournals :many
+SELECT e.journal_id, a.currency, SUM(e.amount)::bigint AS total
+FROM entries e
+JOIN accounts a ON a.id = e.account_id
+WHERE e.journal_id IS NOT NULL
+GROUP BY e.journal_id, a.currency
+HAVING SUM(e.amount) <> 0
+ORDER BY e.journal_id;
@@ -0,0 +1,30 @@
+-- name: CreateKYCSubmission :one
+INSERT INTO kyc_submissions (
+    username,
+    tier,
+    document_type,
+    document_number
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetKYCSubmission :one
+SELECT * FROM kyc_submissions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListKYCSubmissions :many
+SELECT * FROM kyc_submissions
+WHERE status = $1
+ORDER BY created_at
+LIMIT $2
+OFFSET $3;
+
+-- ReviewKYCSubmission approves or rejects a submission, only while it is pending
+-- name: ReviewKYCSubmission :one
+UPDATE kyc_submissions
+SET status = $2,
+    reviewed_by = $3,
+    review_note = $4,
+    reviewed_at = now()
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
@@ -0,0 +1,32 @@
+-- GetLoginLockout returns the latest lockout of the username or the client ip, null when neither is locked
+-- name: GetLoginLockout :one
+SELECT max(locked_until)::timestamptz AS locked_until
+FROM login_attempts
+WHERE (scope = 'username' AND key = sqlc.arg(username))
+   OR (scope = 'client_ip' AND key = sqlc.arg(client_ip));
+
+-- RecordLoginFailure counts a failure, failures older than reset_before are forgotten first
+-- name: RecordLoginFailure :one
+INSERT INTO login_attempts (
+    scope,
+    key,
+    failed_count
+) VALUES (
+    sqlc.arg(scope), sqlc.arg(key), 1
+) ON CONFLICT (scope, key) DO UPDATE
+SET failed_count = CASE
+        WHEN login_attempts.last_failed_at < sqlc.arg(reset_before) THEN 1
+        ELSE login_attempts.failed_count + 1
+    END,
+    last_failed_at = now()
+RETURNING *;
+
+-- name: LockLogin :one
+UPDATE login_attempts
+SET locked_until = $3
+WHERE scope = $1 AND key = $2
+RETURNING *;
+
+-- name: ResetLoginAttempts :exec
+DELETE FROM login_attempts
+WHERE scope = $1 AND key = $2;
@@ -0,0 +1,73 @@
+-- UpsertTOTPFactor starts an enrollment, it never replaces a confirmed factor
+-- name: UpsertTOTPFactor :one
+INSERT INTO totp_factors (
+    username,
+    secret
+) VALUES (
+    $1, $2
+) ON CONFLICT (username) DO UPDATE
+SET secret = EXCLUDED.secret,
+    last_used_step = 0,
+    created_at = now()
+WHERE totp_factors.is_confirmed = false
+RETURNING *;
+
+-- name: GetTOTPFactor :one
+SELECT * FROM totp_factors
+WHERE username = $1 LIMIT 1;
+
+-- name: ConfirmTOTPFactor :one
+UPDATE totp_factors
+SET is_confirmed = true,
+    confirmed_at = now()
+WHERE username = $1 AND is_confirmed = false
+RETURNING *;
+
+-- UseTOTPStep records the time step of an accepted code, no row is affected when the code is replayed
+-- name: UseTOTPStep :execrows
+UPDATE totp_factors
+SET last_used_step = $2
+WHERE username = $1 AND last_used_step < $2;
+
+-- name: CreateRecoveryCode :one
+INSERT INTO recovery_codes (
+    username,
+    hashed_code
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE username = $1;
+
+-- name: UseRecoveryCode :one
+UPDATE recovery_codes
+SET used_at = now()
+WHERE username = $1 AND hashed_code = $2 AND used_at IS NULL
+RETURNING *;
+
+-- name: CreateMFAChallenge :one
+INSERT INTO mfa_challenges (
+    id,
+    username,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- AttemptMFAChallenge counts a verification attempt, only while the challenge can still be answered
+-- name: AttemptMFAChallenge :one
+UPDATE mfa_challenges
+SET attempts = attempts + 1
+WHERE id = $1
+  AND is_used = false
+  AND expires_at > now()
+  AND attempts < sqlc.arg(max_attempts)
+RETURNING *;
+
+-- name: UseMFAChallenge :one
+UPDATE mfa_challenges
+SET is_used = true
+WHERE id = $1 AND is_used = false
+RETURNING *;
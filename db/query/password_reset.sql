@@ -0,0 +1,23 @@
+-- name: CreatePasswordReset :one
+INSERT INTO password_resets (
+    username,
+    hashed_code,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- UsePasswordReset consumes a reset code, only once and before it expires
+-- name: UsePasswordReset :one
+UPDATE password_resets
+SET is_used = true
+WHERE hashed_code = $1
+  AND is_used = false
+  AND expires_at > now()
+RETURNING *;
+
+-- InvalidatePasswordResets burns the other codes of the user once one of them is used
+-- name: InvalidatePasswordResets :execrows
+UPDATE password_resets
+SET is_used = true
+WHERE username = $1 AND is_used = false;
@@ -0,0 +1,65 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+    owner,
+    from_account_id,
+    to_account_id,
+    amount,
+    cron_expression,
+    interval_seconds,
+    next_run_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET amount = COALESCE(sqlc.narg(amount), amount),
+    cron_expression = COALESCE(sqlc.narg(cron_expression), cron_expression),
+    interval_seconds = COALESCE(sqlc.narg(interval_seconds), interval_seconds),
+    next_run_at = COALESCE(sqlc.narg(next_run_at), next_run_at),
+    is_active = COALESCE(sqlc.narg(is_active), is_active)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteScheduledTransfer :exec
+DELETE FROM scheduled_transfers
+WHERE id = $1;
+
+-- ListDueScheduledTransfersForUpdate skips the rows another scheduler is already claiming
+-- name: ListDueScheduledTransfersForUpdate :many
+SELECT * FROM scheduled_transfers
+WHERE is_active AND next_run_at <= $1
+ORDER BY next_run_at
+LIMIT $2
+FOR UPDATE SKIP LOCKED;
+
+-- CreateScheduledTransferRun returns no rows when the occurrence already has a run
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs (
+    scheduled_transfer_id,
+    scheduled_for,
+    status,
+    transfer_id,
+    error
+) VALUES (
+    $1, $2, $3, $4, $5
+) ON CONFLICT (scheduled_transfer_id, scheduled_for) DO NOTHING
+RETURNING *;
+
+-- name: ListScheduledTransferRuns :many
+SELECT * FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY scheduled_for DESC
+LIMIT $2
+OFFSET $3;
@@ -0,0 +1,21 @@
+-- name: GetAccountTransferLimits :one
+SELECT * FROM account_transfer_limits
+WHERE account_id = $1 LIMIT 1;
+
+-- UpsertAccountTransferLimits replaces every override of the account
+-- name: UpsertAccountTransferLimits :one
+INSERT INTO account_transfer_limits (
+    account_id,
+    per_transfer,
+    daily,
+    weekly,
+    hourly_count
+) VALUES (
+    $1, $2, $3, $4, $5
+) ON CONFLICT (account_id) DO UPDATE
+SET per_transfer = EXCLUDED.per_transfer,
+    daily = EXCLUDED.daily,
+    weekly = EXCLUDED.weekly,
+    hourly_count = EXCLUDED.hourly_count,
+    updated_at = now()
+RETURNING *;
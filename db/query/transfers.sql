@@ -3,9 +3,25 @@
 INSERT INTO transfers (
     from_account_id,
     to_account_id,
-    amount
+    amount,
+    to_amount,
+    fee
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $3, $4
+) RETURNING *;
+
+-- CreateFXTransfer records a transfer between accounts with different currencies
+-- name: CreateFXTransfer :one
+INSERT INTO transfers (
+    from_account_id,
+    to_account_id,
+    amount,
+    to_amount,
+    exchange_rate,
+    quote_id,
+    fee
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
 ) RETURNING *;
 
 -- GetTransferById returns a single transfer by ID
@@ -13,11 +29,197 @@ INSERT INTO transfers (
 SELECT * FROM transfers
 WHERE id = $1;
 
--- ListTransfersByAccountId returns a list of transfers for a given account ID
--- name: ListTransfersByAccountId :many
+-- ListTransfersNewestFirst returns the transfers sent and received by an account created before the cursor, newest first.
+-- The filters left null are skipped, direction is 'in' for the transfers received and 'out' for the ones sent,
+-- and the amounts are the ones seen by the account, so a received FX transfer is compared by its converted amount.
+-- Each side is read from its own keyset index and returns up to limit rows, the ListTransfersByAccountId wrapper merges them.
+-- name: ListTransfersNewestFirst :many
+(SELECT * FROM transfers
+WHERE from_account_id = sqlc.arg(account_id)
+  AND (created_at, id) < (sqlc.arg(cursor_created_at)::timestamptz, sqlc.arg(cursor_id)::bigint)
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR created_at >= sqlc.narg(start_time))
+  AND (sqlc.narg(end_time)::timestamptz IS NULL OR created_at < sqlc.narg(end_time))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(direction)::text IS NULL OR sqlc.narg(direction) = 'out')
+  AND (sqlc.narg(counterparty_account_id)::bigint IS NULL OR to_account_id = sqlc.narg(counterparty_account_id))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit'))
+UNION ALL
+(SELECT * FROM transfers
+WHERE to_account_id = sqlc.arg(account_id) AND from_account_id <> sqlc.arg(account_id)
+  AND (created_at, id) < (sqlc.arg(cursor_created_at)::timestamptz, sqlc.arg(cursor_id)::bigint)
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR created_at >= sqlc.narg(start_time))
+  AND (sqlc.narg(end_time)::timestamptz IS NULL OR created_at < sqlc.narg(end_time))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR to_amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR to_amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(direction)::text IS NULL OR sqlc.narg(direction) = 'in')
+  AND (sqlc.narg(counterparty_account_id)::bigint IS NULL OR from_account_id = sqlc.narg(counterparty_account_id))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit'));
+
+-- ListTransfersOldestFirst returns the transfers of an account created after the cursor, oldest first, with the filters of ListTransfersNewestFirst
+-- name: ListTransfersOldestFirst :many
+(SELECT * FROM transfers
+WHERE from_account_id = sqlc.arg(account_id)
+  AND (created_at, id) > (sqlc.arg(cursor_created_at)::timestamptz, sqlc.arg(cursor_id)::bigint)
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR created_at >= sqlc.narg(start_time))
+  AND (sqlc.narg(end_time)::timestamptz IS NULL OR created_at < sqlc.narg(end_time))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(direction)::text IS NULL OR sqlc.narg(direction) = 'out')
+  AND (sqlc.narg(counterparty_account_id)::bigint IS NULL OR to_account_id = sqlc.narg(counterparty_account_id))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit'))
+UNION ALL
+(SELECT * FROM transfers
+WHERE to_account_id = sqlc.arg(account_id) AND from_account_id <> sqlc.arg(account_id)
+  AND (created_at, id) > (sqlc.arg(cursor_created_at)::timestamptz, sqlc.arg(cursor_id)::bigint)
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR created_at >= sqlc.narg(start_time))
+  AND (sqlc.narg(end_time)::timestamptz IS NULL OR created_at < sqlc.narg(end_time))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR to_amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR to_amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(direction)::text IS NULL OR sqlc.narg(direction) = 'in')
+  AND (sqlc.narg(counterparty_account_id)::bigint IS NULL OR from_account_id = sqlc.narg(counterparty_account_id))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit'));
+
+-- ListTransfersLargestFirst returns the transfers of an account below the cursor by the amount seen by the account then id,
+-- largest first, with the filters of ListTransfersNewestFirst
+-- name: ListTransfersLargestFirst :many
+(SELECT * FROM transfers
+WHERE from_account_id = sqlc.arg(account_id)
+  AND (amount, id) < (sqlc.arg(cursor_amount)::bigint, sqlc.arg(cursor_id)::bigint)
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR created_at >= sqlc.narg(start_time))
+  AND (sqlc.narg(end_time)::timestamptz IS NULL OR created_at < sqlc.narg(end_time))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(direction)::text IS NULL OR sqlc.narg(direction) = 'out')
+  AND (sqlc.narg(counterparty_account_id)::bigint IS NULL OR to_account_id = sqlc.narg(counterparty_account_id))
+ORDER BY amount DESC, id DESC
+LIMIT sqlc.arg('limit'))
+UNION ALL
+(SELECT * FROM transfers
+WHERE to_account_id = sqlc.arg(account_id) AND from_account_id <> sqlc.arg(account_id)
+  AND (to_amount, id) < (sqlc.arg(cursor_amount)::bigint, sqlc.arg(cursor_id)::bigint)
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR created_at >= sqlc.narg(start_time))
+  AND (sqlc.narg(end_time)::timestamptz IS NULL OR created_at < sqlc.narg(end_time))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR to_amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR to_amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(direction)::text IS NULL OR sqlc.narg(direction) = 'in')
+  AND (sqlc.narg(counterparty_account_id)::bigint IS NULL OR from_account_id = sqlc.narg(counterparty_account_id))
+ORDER BY to_amount DESC, id DESC
+LIMIT sqlc.arg('limit'));
+
+-- ListTransfersSmallestFirst returns the transfers of an account above the cursor by the amount seen by the account then id,
+-- smallest first, with the filters of ListTransfersNewestFirst
+-- name: ListTransfersSmallestFirst :many
+(SELECT * FROM transfers
+WHERE from_account_id = sqlc.arg(account_id)
+  AND (amount, id) > (sqlc.arg(cursor_amount)::bigint, sqlc.arg(cursor_id)::bigint)
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR created_at >= sqlc.narg(start_time))
+  AND (sqlc.narg(end_time)::timestamptz IS NULL OR created_at < sqlc.narg(end_time))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(direction)::text IS NULL OR sqlc.narg(direction) = 'out')
+  AND (sqlc.narg(counterparty_account_id)::bigint IS NULL OR to_account_id = sqlc.narg(counterparty_account_id))
+ORDER BY amount ASC, id ASC
+LIMIT sqlc.arg('limit'))
+UNION ALL
+(SELECT * FROM transfers
+WHERE to_account_id = sqlc.arg(account_id) AND from_account_id <> sqlc.arg(account_id)
+  AND (to_amount, id) > (sqlc.arg(cursor_amount)::bigint, sqlc.arg(cursor_id)::bigint)
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR created_at >= sqlc.narg(start_time))
+  AND (sqlc.narg(end_time)::timestamptz IS NULL OR created_at < sqlc.narg(end_time))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR to_amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR to_amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(direction)::text IS NULL OR sqlc.narg(direction) = 'in')
+  AND (sqlc.narg(counterparty_account_id)::bigint IS NULL OR from_account_id = sqlc.narg(counterparty_account_id))
+ORDER BY to_amount ASC, id ASC
+LIMIT sqlc.arg('limit'));
+
+-- SumTransfersSince returns the total and the number of the transfers sent by an account since a time,
+-- pending transfers count until their hold is released, refunds do not count
+-- name: SumTransfersSince :one
+SELECT COALESCE(sum(amount), 0)::bigint AS total, count(*) AS count
+FROM transfers
+WHERE from_account_id = $1 AND created_at >= sqlc.arg(since) AND status IN ('pending', 'posted')
+  AND reversal_of IS NULL;
+
+-- SumUserTransfersSince returns the total sent by the accounts of a user in a currency since a time,
+-- and the number of the transfers sent by all of their accounts, refunds do not count
+-- name: SumUserTransfersSince :one
+SELECT COALESCE(sum(t.amount) FILTER (WHERE a.currency = sqlc.arg(currency)), 0)::bigint AS total, count(*) AS count
+FROM transfers t
+JOIN accounts a ON a.id = t.from_account_id
+WHERE a.owner = sqlc.arg(owner) AND t.created_at >= sqlc.arg(since) AND t.status IN ('pending', 'posted')
+  AND t.reversal_of IS NULL;
+
+-- CreatePendingTransfer records an authorized transfer, its amount and fee are held until it is captured
+-- name: CreatePendingTransfer :one
+INSERT INTO transfers (
+    from_account_id,
+    to_account_id,
+    amount,
+    to_amount,
+    fee,
+    status,
+    authorized_amount,
+    hold_expires_at
+) VALUES (
+    $1, $2, $3, $3, $4, 'pending', $3, $5
+) RETURNING *;
+
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- CaptureTransfer posts a pending transfer with the captured amount
+-- name: CaptureTransfer :one
+UPDATE transfers
+SET amount = sqlc.arg(amount),
+    to_amount = sqlc.arg(amount),
+    fee = sqlc.arg(fee),
+    status = 'posted'
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
+
+-- ReleaseTransfer closes a pending transfer without posting it
+-- name: ReleaseTransfer :one
+UPDATE transfers
+SET status = sqlc.arg(status)
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
+
+-- ListExpiredTransfersForUpdate skips the holds another worker is already releasing
+-- name: ListExpiredTransfersForUpdate :many
 SELECT * FROM transfers
-WHERE from_account_id = $1 OR to_account_id = $1
-ORDER BY created_at DESC
+WHERE status = 'pending' AND hold_expires_at <= $1
+ORDER BY hold_expires_at
 LIMIT $2
-OFFSET $3;
+FOR UPDATE SKIP LOCKED;
 
+-- CreateReversalTransfer records a refund of a transfer, from its destination back to its source
+-- name: CreateReversalTransfer :one
+INSERT INTO transfers (
+    from_account_id,
+    to_account_id,
+    amount,
+    to_amount,
+    reversal_of
+) VALUES (
+    $1, $2, $3, $3, $4
+) RETURNING *;
+
+-- AddTransferReversedAmount counts a refund against the transfer it gives back
+-- name: AddTransferReversedAmount :one
+UPDATE transfers
+SET reversed_amount = reversed_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListTransferReversals :many
+SELECT * FROM transfers
+WHERE reversal_of = $1
+ORDER BY id;
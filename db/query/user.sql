@@ -14,6 +14,16 @@ INSERT INTO users (
 SELECT * FROM users
 WHERE username = $1 LIMIT 1;
 
+-- GetUserForUpdate locks the user, the transfers checked against user limits are serialized on it
+-- name: GetUserForUpdate :one
+SELECT * FROM users
+WHERE username = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users
+WHERE email = $1 LIMIT 1;
+
 -- name: UpdateUser :one
 UPDATE users
 SET
@@ -24,4 +34,31 @@ SET
     is_email_verified = COALESCE(sqlc.narg(is_email_verified), is_email_verified)
 WHERE
     username = sqlc.arg(username)
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: SetPendingEmail :one
+UPDATE users
+SET pending_email = $2
+WHERE username = $1
+RETURNING *;
+
+-- SwitchToPendingEmail makes the pending address the email of the user once it is verified
+-- name: SwitchToPendingEmail :one
+UPDATE users
+SET email = pending_email,
+    pending_email = NULL,
+    is_email_verified = true
+WHERE username = $1 AND pending_email = $2
+RETURNING *;
+
+-- name: ListUsers :many
+SELECT * FROM users
+ORDER BY username
+LIMIT $1
+OFFSET $2;
+
+-- name: SetUserKYCTier :one
+UPDATE users
+SET kyc_tier = $2
+WHERE username = $1
+RETURNING *;
@@ -0,0 +1,95 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (
+    owner,
+    url,
+    secret,
+    event_types,
+    is_global
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: DeleteWebhookSubscription :exec
+DELETE FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: CreateWebhookEvent :one
+INSERT INTO webhook_events (
+    owner,
+    event_type,
+    payload
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetWebhookEvent :one
+SELECT * FROM webhook_events
+WHERE id = $1 LIMIT 1;
+
+-- ListUndispatchedWebhookEventsForUpdate skips the events another dispatcher is already claiming
+-- name: ListUndispatchedWebhookEventsForUpdate :many
+SELECT * FROM webhook_events
+WHERE dispatched_at IS NULL
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkWebhookEventDispatched :exec
+UPDATE webhook_events
+SET dispatched_at = now()
+WHERE id = $1;
+
+-- CreateWebhookDeliveries creates a delivery of the event for every subscription that takes its type,
+-- from its owner or a global one. A global subscription stops receiving events if its owner loses the global role.
+-- name: CreateWebhookDeliveries :many
+INSERT INTO webhook_deliveries (
+    event_id,
+    subscription_id
+)
+SELECT sqlc.arg(event_id)::bigint, s.id
+FROM webhook_subscriptions s
+JOIN users u ON u.username = s.owner
+WHERE sqlc.arg(event_type)::varchar = ANY(s.event_types)
+  AND (s.owner = sqlc.arg(owner) OR (s.is_global AND u.role = sqlc.arg(global_role)::varchar))
+RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookDeliveries :many
+SELECT sqlc.embed(d), e.event_type FROM webhook_deliveries d
+JOIN webhook_events e ON e.id = d.event_id
+WHERE d.subscription_id = $1
+ORDER BY d.id DESC
+LIMIT $2
+OFFSET $3;
+
+-- RecordWebhookDeliveryAttempt counts an attempt and keeps the response of the receiver
+-- name: RecordWebhookDeliveryAttempt :one
+UPDATE webhook_deliveries
+SET status = $2,
+    attempts = attempts + 1,
+    response_status = $3,
+    error = $4,
+    last_attempt_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- ResetWebhookDelivery returns no rows while the delivery is still pending
+-- name: ResetWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status = 'pending'
+WHERE id = $1 AND status <> 'pending'
+RETURNING *;
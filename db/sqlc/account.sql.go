@@ -7,13 +7,17 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
-SET balance = balance + $1
+SET balance = balance + $1,
+    available_balance = available_balance + $1,
+    balance_version = balance_version + 1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, is_system, available_balance, balance_version
 `
 
 type AddAccountBalanceParams struct {
@@ -31,6 +35,41 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.IsSystem,
+		&i.AvailableBalance,
+		&i.BalanceVersion,
+	)
+	return i, err
+}
+
+const addAccountHold = `-- name: AddAccountHold :one
+UPDATE accounts
+SET available_balance = available_balance - $1,
+    balance_version = balance_version + 1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, is_system, available_balance, balance_version
+`
+
+type AddAccountHoldParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+// AddAccountHold holds an amount of the available balance, a negative amount releases it
+func (q *Queries) AddAccountHold(ctx context.Context, arg AddAccountHoldParams) (Account, error) {
+	row := q.db.QueryRow(ctx, addAccountHold, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.IsSystem,
+		&i.AvailableBalance,
+		&i.BalanceVersion,
 	)
 	return i, err
 }
@@ -39,10 +78,11 @@ const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
    owner,
    balance,
+   available_balance,
    currency
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, owner, balance, currency, created_at
+  $1, $2, $2, $3
+) RETURNING id, owner, balance, currency, created_at, overdraft_limit, is_system, available_balance, balance_version
 `
 
 type CreateAccountParams struct {
@@ -61,6 +101,10 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.IsSystem,
+		&i.AvailableBalance,
+		&i.BalanceVersion,
 	)
 	return i, err
 }
@@ -76,7 +120,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, overdraft_limit, is_system, available_balance, balance_version FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -89,12 +133,16 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.IsSystem,
+		&i.AvailableBalance,
+		&i.BalanceVersion,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, overdraft_limit, is_system, available_balance, balance_version FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -108,26 +156,138 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.IsSystem,
+		&i.AvailableBalance,
+		&i.BalanceVersion,
+	)
+	return i, err
+}
+
+const getAccountSnapshot = `-- name: GetAccountSnapshot :one
+SELECT a.id, a.balance, a.available_balance, a.balance_version,
+  COALESCE((SELECT max(e.id) FROM entries e WHERE e.account_id = a.id), 0)::bigint AS last_entry_id
+FROM accounts a
+WHERE a.id = $1
+`
+
+type GetAccountSnapshotRow struct {
+	ID               int64 `json:"id"`
+	Balance          int64 `json:"balance"`
+	AvailableBalance int64 `json:"available_balance"`
+	BalanceVersion   int64 `json:"balance_version"`
+	LastEntryID      int64 `json:"last_entry_id"`
+}
+
+// GetAccountSnapshot returns the balances of an account with their version and the id of its last entry, 0 when it has none.
+// They are read by one statement, so the balances include exactly the entries up to last_entry_id.
+func (q *Queries) GetAccountSnapshot(ctx context.Context, id int64) (GetAccountSnapshotRow, error) {
+	row := q.db.QueryRow(ctx, getAccountSnapshot, id)
+	var i GetAccountSnapshotRow
+	err := row.Scan(
+		&i.ID,
+		&i.Balance,
+		&i.AvailableBalance,
+		&i.BalanceVersion,
+		&i.LastEntryID,
+	)
+	return i, err
+}
+
+const getSystemAccount = `-- name: GetSystemAccount :one
+SELECT id, owner, balance, currency, created_at, overdraft_limit, is_system, available_balance, balance_version FROM accounts
+WHERE owner = $1 AND currency = $2 AND is_system
+LIMIT 1
+`
+
+type GetSystemAccountParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) GetSystemAccount(ctx context.Context, arg GetSystemAccountParams) (Account, error) {
+	row := q.db.QueryRow(ctx, getSystemAccount, arg.Owner, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.IsSystem,
+		&i.AvailableBalance,
+		&i.BalanceVersion,
 	)
 	return i, err
 }
 
-const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
+const listAccountsOldestFirst = `-- name: ListAccountsOldestFirst :many
+SELECT id, owner, balance, currency, created_at, overdraft_limit, is_system, available_balance, balance_version FROM accounts
 WHERE owner = $1
+  AND (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type ListAccountsOldestFirstParams struct {
+	Owner           string             `json:"owner"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID        int64              `json:"cursor_id"`
+	Limit           int64              `json:"limit"`
+}
+
+// ListAccountsOldestFirst returns the accounts of an owner created after the cursor, oldest first
+func (q *Queries) ListAccountsOldestFirst(ctx context.Context, arg ListAccountsOldestFirstParams) ([]Account, error) {
+	rows, err := q.db.Query(ctx, listAccountsOldestFirst,
+		arg.Owner,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.IsSystem,
+			&i.AvailableBalance,
+			&i.BalanceVersion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllAccounts = `-- name: ListAllAccounts :many
+SELECT id, owner, balance, currency, created_at, overdraft_limit, is_system, available_balance, balance_version FROM accounts
 ORDER BY id
-LIMIT $2
-OFFSET $3
+LIMIT $1
+OFFSET $2
 `
 
-type ListAccountsParams struct {
-	Owner  string `json:"owner"`
-	Limit  int64  `json:"limit"`
-	Offset int64  `json:"offset"`
+type ListAllAccountsParams struct {
+	Limit  int64 `json:"limit"`
+	Offset int64 `json:"offset"`
 }
 
-func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.Query(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
+// ListAllAccounts returns the accounts of every user
+func (q *Queries) ListAllAccounts(ctx context.Context, arg ListAllAccountsParams) ([]Account, error) {
+	rows, err := q.db.Query(ctx, listAllAccounts, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -141,6 +301,10 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.IsSystem,
+			&i.AvailableBalance,
+			&i.BalanceVersion,
 		); err != nil {
 			return nil, err
 		}
@@ -152,20 +316,67 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
-const updateAccount = `-- name: UpdateAccount :one
+const listUnreconciledAccounts = `-- name: ListUnreconciledAccounts :many
+SELECT a.id, a.balance, COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id
+`
+
+type ListUnreconciledAccountsRow struct {
+	ID           int64 `json:"id"`
+	Balance      int64 `json:"balance"`
+	EntriesTotal int64 `json:"entries_total"`
+}
+
+// ListUnreconciledAccounts returns the accounts whose balance differs from the sum of their entries
+func (q *Queries) ListUnreconciledAccounts(ctx context.Context) ([]ListUnreconciledAccountsRow, error) {
+	rows, err := q.db.Query(ctx, listUnreconciledAccounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUnreconciledAccountsRow{}
+	for rows.Next() {
+		var i ListUnreconciledAccountsRow
+		if err := rows.Scan(&i.ID, &i.Balance, &i.EntriesTotal); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const notifyAccountEvent = `-- name: NotifyAccountEvent :exec
+SELECT pg_notify('account_events', $1::text)
+`
+
+// NotifyAccountEvent notifies the listeners of the account_events channel, the payload is an AccountEvent in JSON.
+// Postgres delivers the notification when the transaction commits.
+func (q *Queries) NotifyAccountEvent(ctx context.Context, payload string) error {
+	_, err := q.db.Exec(ctx, notifyAccountEvent, payload)
+	return err
+}
+
+const updateAccountOverdraftLimit = `-- name: UpdateAccountOverdraftLimit :one
 UPDATE accounts
-SET balance = $2
-WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+SET overdraft_limit = $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, is_system, available_balance, balance_version
 `
 
-type UpdateAccountParams struct {
-	ID      int64 `json:"id"`
-	Balance int64 `json:"balance"`
+type UpdateAccountOverdraftLimitParams struct {
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	ID             int64 `json:"id"`
 }
 
-func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
-	row := q.db.QueryRow(ctx, updateAccount, arg.ID, arg.Balance)
+func (q *Queries) UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error) {
+	row := q.db.QueryRow(ctx, updateAccountOverdraftLimit, arg.OverdraftLimit, arg.ID)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -173,6 +384,10 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.IsSystem,
+		&i.AvailableBalance,
+		&i.BalanceVersion,
 	)
 	return i, err
 }
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// AccountEventsChannel is the Postgres channel the NotifyAccountEvent query notifies on
+const AccountEventsChannel = "account_events"
+
+// Account event kinds
+const (
+	// AccountEventEntry is notified when an entry is posted to the account
+	AccountEventEntry = "entry"
+	// AccountEventHold is notified when a hold changes the available balance of the account
+	AccountEventHold = "hold"
+)
+
+// AccountEvent is notified when a transaction changes the balances of a customer account.
+// The notifications of a transaction are delivered when it commits, so the listeners never see a rolled back change.
+type AccountEvent struct {
+	Kind             string `json:"kind"`
+	AccountID        int64  `json:"account_id"`
+	Balance          int64  `json:"balance"`
+	AvailableBalance int64  `json:"available_balance"`
+	// BalanceVersion is the version of the balances, a newer event always has a greater one
+	BalanceVersion int64 `json:"balance_version"`
+	// Entry is nil when only a hold changed
+	Entry *Entry `json:"entry,omitempty"`
+}
+
+// publishAccountEvent notifies the balances of the account after a change, the system accounts are left out
+func publishAccountEvent(ctx context.Context, q *Queries, kind string, account Account, entry *Entry) error {
+	if account.IsSystem {
+		return nil
+	}
+	payload, err := json.Marshal(AccountEvent{
+		Kind:             kind,
+		AccountID:        account.ID,
+		Balance:          account.Balance,
+		AvailableBalance: account.AvailableBalance,
+		BalanceVersion:   account.BalanceVersion,
+		Entry:            entry,
+	})
+	if err != nil {
+		return err
+	}
+	return q.NotifyAccountEvent(ctx, string(payload))
+}
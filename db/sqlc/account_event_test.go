@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/util"
+	"testing"
+	"time"
+)
+
+// listenAccountEvents returns a function that waits for the next account event of one of the accounts
+func listenAccountEvents(t *testing.T, accountIDs ...int64) func() AccountEvent {
+	conn, err := testDB.Acquire(context.Background())
+	require.NoError(t, err)
+	listener := conn.Hijack()
+	t.Cleanup(func() {
+		listener.Close(context.Background())
+	})
+	_, err = listener.Exec(context.Background(), "LISTEN "+pgx.Identifier{AccountEventsChannel}.Sanitize())
+	require.NoError(t, err)
+
+	return func() AccountEvent {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		for {
+			notification, err := listener.WaitForNotification(ctx)
+			require.NoError(t, err)
+			var event AccountEvent
+			require.NoError(t, json.Unmarshal([]byte(notification.Payload), &event))
+			for _, id := range accountIDs {
+				if event.AccountID == id {
+					return event
+				}
+			}
+		}
+	}
+}
+
+func TestAccountEvents(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createEmptyAccount(t, util.USD)
+	account2 := createEmptyAccount(t, util.USD)
+	nextEvent := listenAccountEvents(t, account1.ID, account2.ID)
+
+	deposit, err := store.DepositTx(context.Background(), DepositTxParams{AccountID: account1.ID, Amount: 100})
+	require.NoError(t, err)
+
+	event := nextEvent()
+	require.Equal(t, AccountEventEntry, event.Kind)
+	require.Equal(t, account1.ID, event.AccountID)
+	require.Equal(t, int64(100), event.Balance)
+	require.Equal(t, int64(100), event.AvailableBalance)
+	require.NotNil(t, event.Entry)
+	require.Equal(t, deposit.Entry.ID, event.Entry.ID)
+	require.Equal(t, deposit.Account.BalanceVersion, event.BalanceVersion)
+
+	// a transfer that is rolled back notifies nothing
+	_, err = store.TransferTx(context.Background(), TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 1000})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 30})
+	require.NoError(t, err)
+
+	// the postings are made in account id order
+	events := map[int64]AccountEvent{}
+	for i := 0; i < 2; i++ {
+		event = nextEvent()
+		events[event.AccountID] = event
+	}
+	require.Equal(t, transfer.FromEntry.ID, events[account1.ID].Entry.ID)
+	require.Equal(t, int64(70), events[account1.ID].Balance)
+	require.Equal(t, transfer.ToEntry.ID, events[account2.ID].Entry.ID)
+	require.Equal(t, int64(30), events[account2.ID].Balance)
+
+	authorized, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        20,
+		HoldExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	event = nextEvent()
+	require.Equal(t, AccountEventHold, event.Kind)
+	require.Nil(t, event.Entry)
+	require.Equal(t, int64(70), event.Balance)
+	require.Equal(t, int64(50), event.AvailableBalance)
+	// a hold changes the version of the balances like an entry
+	require.Greater(t, event.BalanceVersion, events[account1.ID].BalanceVersion)
+	holdVersion := event.BalanceVersion
+
+	_, err = store.VoidTransferTx(context.Background(), VoidTransferTxParams{TransferID: authorized.Transfer.ID})
+	require.NoError(t, err)
+
+	event = nextEvent()
+	require.Equal(t, AccountEventHold, event.Kind)
+	require.Equal(t, int64(70), event.AvailableBalance)
+	require.Greater(t, event.BalanceVersion, holdVersion)
+
+	snapshot, err := testQueries.GetAccountSnapshot(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, event.BalanceVersion, snapshot.BalanceVersion)
+}
+
+func TestListEntriesAfter(t *testing.T) {
+	store := NewStore(testDB)
+	account := createEmptyAccount(t, util.USD)
+
+	var entries []Entry
+	for _, amount := range []int64{10, 20, 30, 40} {
+		deposit, err := store.DepositTx(context.Background(), DepositTxParams{AccountID: account.ID, Amount: amount})
+		require.NoError(t, err)
+		entries = append(entries, deposit.Entry)
+	}
+
+	rows, err := testQueries.ListEntriesAfter(context.Background(), ListEntriesAfterParams{
+		AccountID: account.ID,
+		AfterID:   entries[0].ID,
+		Limit:     2,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	require.Equal(t, entries[1].ID, rows[0].ID)
+	require.Equal(t, int64(30), rows[0].Balance)
+	require.Equal(t, entries[2].ID, rows[1].ID)
+	require.Equal(t, int64(60), rows[1].Balance)
+
+	rows, err = testQueries.ListEntriesAfter(context.Background(), ListEntriesAfterParams{
+		AccountID: account.ID,
+		AfterID:   rows[1].ID,
+		Limit:     2,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, entries[3].ID, rows[0].ID)
+	require.Equal(t, int64(100), rows[0].Balance)
+}
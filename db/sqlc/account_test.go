@@ -24,6 +24,7 @@ func createRandomAccount(t *testing.T) Account {
 
 	require.Equal(t, arg.Owner, account.Owner)
 	require.Equal(t, arg.Balance, account.Balance)
+	require.Equal(t, arg.Balance, account.AvailableBalance)
 	require.Equal(t, arg.Currency, account.Currency)
 
 	require.NotZero(t, account.ID)
@@ -53,23 +54,23 @@ func TestGetAccount(t *testing.T) {
 	require.WithinDuration(t, account1.CreatedAt.Time, account2.CreatedAt.Time, time.Second)
 }
 
-func TestUpdateAccount(t *testing.T) {
-	account1 := createRandomAccount(t)
-	require.NotEmpty(t, account1)
+func TestGetAccountSnapshot(t *testing.T) {
+	account := createRandomAccount(t)
 
-	arg := UpdateAccountParams{
-		ID:      account1.ID,
-		Balance: util.RandomMoney(),
-	}
-	account2, err := testQueries.UpdateAccount(context.Background(), arg)
+	snapshot, err := testQueries.GetAccountSnapshot(context.Background(), account.ID)
 	require.NoError(t, err)
-	require.NotEmpty(t, account2)
+	require.Equal(t, account.ID, snapshot.ID)
+	require.Equal(t, account.Balance, snapshot.Balance)
+	require.Equal(t, account.AvailableBalance, snapshot.AvailableBalance)
+	require.Zero(t, snapshot.LastEntryID)
 
-	require.Equal(t, account1.ID, account2.ID)
-	require.Equal(t, account1.Owner, account2.Owner)
-	require.Equal(t, account1.Currency, account2.Currency)
-	require.Equal(t, arg.Balance, account2.Balance)
-	require.WithinDuration(t, account1.CreatedAt.Time, account2.CreatedAt.Time, time.Second)
+	createNewEntry(account, t)
+	entry := createNewEntry(account, t)
+	createNewEntry(createRandomAccount(t), t)
+
+	snapshot, err = testQueries.GetAccountSnapshot(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, entry.ID, snapshot.LastEntryID)
 }
 
 func TestDeleteAccount(t *testing.T) {
@@ -7,10 +7,12 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, journal_id FROM entries
 WHERE id = $1
 `
 
@@ -23,27 +25,322 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.JournalID,
 	)
 	return i, err
 }
 
-const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
-WHERE account_id = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3
+const listEntriesAfter = `-- name: ListEntriesAfter :many
+SELECT e.id, e.account_id, e.amount, e.created_at, e.journal_id,
+  (a.balance - COALESCE(SUM(e.amount) OVER (ORDER BY e.id DESC ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING), 0))::bigint AS balance
+FROM entries e
+JOIN accounts a ON a.id = e.account_id
+WHERE e.account_id = $1 AND e.id > $2
+ORDER BY e.id
+LIMIT $3
 `
 
-type ListEntriesParams struct {
+type ListEntriesAfterParams struct {
 	AccountID int64 `json:"account_id"`
+	AfterID   int64 `json:"after_id"`
 	Limit     int64 `json:"limit"`
-	Offset    int64 `json:"offset"`
 }
 
-// ListEntries returns a list of entries for the given account ID
-func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
-	rows, err := q.db.Query(ctx, listEntries, arg.AccountID, arg.Limit, arg.Offset)
+type ListEntriesAfterRow struct {
+	ID        int64              `json:"id"`
+	AccountID int64              `json:"account_id"`
+	Amount    int64              `json:"amount"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	JournalID pgtype.Int8        `json:"journal_id"`
+	Balance   int64              `json:"balance"`
+}
+
+// ListEntriesAfter returns the entries of an account with an id above after_id, in id order,
+// with the balance of the account right after each of them, worked back from the current balance.
+func (q *Queries) ListEntriesAfter(ctx context.Context, arg ListEntriesAfterParams) ([]ListEntriesAfterRow, error) {
+	rows, err := q.db.Query(ctx, listEntriesAfter, arg.AccountID, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListEntriesAfterRow{}
+	for rows.Next() {
+		var i ListEntriesAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.JournalID,
+			&i.Balance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesLargestFirst = `-- name: ListEntriesLargestFirst :many
+SELECT id, account_id, amount, created_at, journal_id FROM entries e
+WHERE e.account_id = $1
+  AND (abs(e.amount), e.id) < ($2::bigint, $3::bigint)
+  AND ($4::timestamptz IS NULL OR e.created_at >= $4)
+  AND ($5::timestamptz IS NULL OR e.created_at < $5)
+  AND ($6::bigint IS NULL OR abs(e.amount) >= $6)
+  AND ($7::bigint IS NULL OR abs(e.amount) <= $7)
+  AND ($8::text IS NULL
+    OR ($8 = 'in' AND e.amount > 0)
+    OR ($8 = 'out' AND e.amount < 0))
+  AND ($9::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries c
+    WHERE c.journal_id = e.journal_id AND c.account_id = $9))
+ORDER BY abs(e.amount) DESC, e.id DESC
+LIMIT $10
+`
+
+type ListEntriesLargestFirstParams struct {
+	AccountID             int64              `json:"account_id"`
+	CursorAmount          int64              `json:"cursor_amount"`
+	CursorID              int64              `json:"cursor_id"`
+	StartTime             pgtype.Timestamptz `json:"start_time"`
+	EndTime               pgtype.Timestamptz `json:"end_time"`
+	MinAmount             pgtype.Int8        `json:"min_amount"`
+	MaxAmount             pgtype.Int8        `json:"max_amount"`
+	Direction             pgtype.Text        `json:"direction"`
+	CounterpartyAccountID pgtype.Int8        `json:"counterparty_account_id"`
+	Limit                 int64              `json:"limit"`
+}
+
+// ListEntriesLargestFirst returns the entries of an account below the cursor by amount without sign then id, largest first,
+// with the filters of ListEntriesNewestFirst
+func (q *Queries) ListEntriesLargestFirst(ctx context.Context, arg ListEntriesLargestFirstParams) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listEntriesLargestFirst,
+		arg.AccountID,
+		arg.CursorAmount,
+		arg.CursorID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Direction,
+		arg.CounterpartyAccountID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.JournalID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesNewestFirst = `-- name: ListEntriesNewestFirst :many
+SELECT id, account_id, amount, created_at, journal_id FROM entries e
+WHERE e.account_id = $1
+  AND (e.created_at, e.id) < ($2::timestamptz, $3::bigint)
+  AND ($4::timestamptz IS NULL OR e.created_at >= $4)
+  AND ($5::timestamptz IS NULL OR e.created_at < $5)
+  AND ($6::bigint IS NULL OR abs(e.amount) >= $6)
+  AND ($7::bigint IS NULL OR abs(e.amount) <= $7)
+  AND ($8::text IS NULL
+    OR ($8 = 'in' AND e.amount > 0)
+    OR ($8 = 'out' AND e.amount < 0))
+  AND ($9::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries c
+    WHERE c.journal_id = e.journal_id AND c.account_id = $9))
+ORDER BY e.created_at DESC, e.id DESC
+LIMIT $10
+`
+
+type ListEntriesNewestFirstParams struct {
+	AccountID             int64              `json:"account_id"`
+	CursorCreatedAt       pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID              int64              `json:"cursor_id"`
+	StartTime             pgtype.Timestamptz `json:"start_time"`
+	EndTime               pgtype.Timestamptz `json:"end_time"`
+	MinAmount             pgtype.Int8        `json:"min_amount"`
+	MaxAmount             pgtype.Int8        `json:"max_amount"`
+	Direction             pgtype.Text        `json:"direction"`
+	CounterpartyAccountID pgtype.Int8        `json:"counterparty_account_id"`
+	Limit                 int64              `json:"limit"`
+}
+
+// ListEntriesNewestFirst returns the entries of an account created before the cursor, newest first.
+// The filters left null are skipped, direction is 'in' for credits and 'out' for debits,
+// the amounts are compared without their sign and the counterparty is an account with an entry in the same journal.
+// The cursor is the created_at and id of the last entry of the previous page, the ListEntries wrapper picks the query of the sort order.
+func (q *Queries) ListEntriesNewestFirst(ctx context.Context, arg ListEntriesNewestFirstParams) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listEntriesNewestFirst,
+		arg.AccountID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Direction,
+		arg.CounterpartyAccountID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.JournalID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesOldestFirst = `-- name: ListEntriesOldestFirst :many
+SELECT id, account_id, amount, created_at, journal_id FROM entries e
+WHERE e.account_id = $1
+  AND (e.created_at, e.id) > ($2::timestamptz, $3::bigint)
+  AND ($4::timestamptz IS NULL OR e.created_at >= $4)
+  AND ($5::timestamptz IS NULL OR e.created_at < $5)
+  AND ($6::bigint IS NULL OR abs(e.amount) >= $6)
+  AND ($7::bigint IS NULL OR abs(e.amount) <= $7)
+  AND ($8::text IS NULL
+    OR ($8 = 'in' AND e.amount > 0)
+    OR ($8 = 'out' AND e.amount < 0))
+  AND ($9::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries c
+    WHERE c.journal_id = e.journal_id AND c.account_id = $9))
+ORDER BY e.created_at ASC, e.id ASC
+LIMIT $10
+`
+
+type ListEntriesOldestFirstParams struct {
+	AccountID             int64              `json:"account_id"`
+	CursorCreatedAt       pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID              int64              `json:"cursor_id"`
+	StartTime             pgtype.Timestamptz `json:"start_time"`
+	EndTime               pgtype.Timestamptz `json:"end_time"`
+	MinAmount             pgtype.Int8        `json:"min_amount"`
+	MaxAmount             pgtype.Int8        `json:"max_amount"`
+	Direction             pgtype.Text        `json:"direction"`
+	CounterpartyAccountID pgtype.Int8        `json:"counterparty_account_id"`
+	Limit                 int64              `json:"limit"`
+}
+
+// ListEntriesOldestFirst returns the entries of an account created after the cursor, oldest first, with the filters of ListEntriesNewestFirst
+func (q *Queries) ListEntriesOldestFirst(ctx context.Context, arg ListEntriesOldestFirstParams) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listEntriesOldestFirst,
+		arg.AccountID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Direction,
+		arg.CounterpartyAccountID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.JournalID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesSmallestFirst = `-- name: ListEntriesSmallestFirst :many
+SELECT id, account_id, amount, created_at, journal_id FROM entries e
+WHERE e.account_id = $1
+  AND (abs(e.amount), e.id) > ($2::bigint, $3::bigint)
+  AND ($4::timestamptz IS NULL OR e.created_at >= $4)
+  AND ($5::timestamptz IS NULL OR e.created_at < $5)
+  AND ($6::bigint IS NULL OR abs(e.amount) >= $6)
+  AND ($7::bigint IS NULL OR abs(e.amount) <= $7)
+  AND ($8::text IS NULL
+    OR ($8 = 'in' AND e.amount > 0)
+    OR ($8 = 'out' AND e.amount < 0))
+  AND ($9::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries c
+    WHERE c.journal_id = e.journal_id AND c.account_id = $9))
+ORDER BY abs(e.amount) ASC, e.id ASC
+LIMIT $10
+`
+
+type ListEntriesSmallestFirstParams struct {
+	AccountID             int64              `json:"account_id"`
+	CursorAmount          int64              `json:"cursor_amount"`
+	CursorID              int64              `json:"cursor_id"`
+	StartTime             pgtype.Timestamptz `json:"start_time"`
+	EndTime               pgtype.Timestamptz `json:"end_time"`
+	MinAmount             pgtype.Int8        `json:"min_amount"`
+	MaxAmount             pgtype.Int8        `json:"max_amount"`
+	Direction             pgtype.Text        `json:"direction"`
+	CounterpartyAccountID pgtype.Int8        `json:"counterparty_account_id"`
+	Limit                 int64              `json:"limit"`
+}
+
+// ListEntriesSmallestFirst returns the entries of an account above the cursor by amount without sign then id, smallest first,
+// with the filters of ListEntriesNewestFirst
+func (q *Queries) ListEntriesSmallestFirst(ctx context.Context, arg ListEntriesSmallestFirstParams) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listEntriesSmallestFirst,
+		arg.AccountID,
+		arg.CursorAmount,
+		arg.CursorID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Direction,
+		arg.CounterpartyAccountID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -56,6 +353,7 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.JournalID,
 		); err != nil {
 			return nil, err
 		}
@@ -70,27 +368,49 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 const newEntry = `-- name: NewEntry :one
 INSERT INTO entries (
 account_id,
-amount
+amount,
+journal_id
 ) VALUES (
- $1, $2
-) RETURNING id, account_id, amount, created_at
+ $1, $2, $3
+) RETURNING id, account_id, amount, created_at, journal_id
 `
 
 type NewEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID int64       `json:"account_id"`
+	Amount    int64       `json:"amount"`
+	JournalID pgtype.Int8 `json:"journal_id"`
 }
 
 // noinspection SqlResolveForFile
 // NewEntry Does not add the amount of money. Use AddAccountBalance instead
 func (q *Queries) NewEntry(ctx context.Context, arg NewEntryParams) (Entry, error) {
-	row := q.db.QueryRow(ctx, newEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, newEntry, arg.AccountID, arg.Amount, arg.JournalID)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.JournalID,
 	)
 	return i, err
 }
+
+const sumEntriesBefore = `-- name: SumEntriesBefore :one
+SELECT COALESCE(sum(amount), 0)::bigint AS total
+FROM entries
+WHERE account_id = $1 AND created_at < $2::timestamptz
+`
+
+type SumEntriesBeforeParams struct {
+	AccountID int64              `json:"account_id"`
+	Before    pgtype.Timestamptz `json:"before"`
+}
+
+// SumEntriesBefore returns the sum of the entries of an account created before a time, its balance at that time
+func (q *Queries) SumEntriesBefore(ctx context.Context, arg SumEntriesBeforeParams) (int64, error) {
+	row := q.db.QueryRow(ctx, sumEntriesBefore, arg.AccountID, arg.Before)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 	"github.com/the-eduardo/Go-Bank/util"
 	"testing"
@@ -61,4 +62,61 @@ func TestListEntries(t *testing.T) {
 	for _, entry := range entries {
 		require.NotEmpty(t, entry)
 	}
+
+	// the offset page is the page after the first one
+	firstPage, err := testQueries.ListEntries(context.Background(), ListEntriesParams{AccountID: account.ID, Limit: 5})
+	require.NoError(t, err)
+	arg.StartAfter(util.PageToken{CreatedAt: firstPage[4].CreatedAt.Time, ID: firstPage[4].ID})
+	require.Zero(t, arg.Offset)
+	nextPage, err := testQueries.ListEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, nextPage, entries)
+}
+
+func TestListEntriesFilters(t *testing.T) {
+	account := createRandomAccount(t)
+
+	var entries []Entry
+	for _, amount := range []int64{-30, 10, -20} {
+		entry, err := testQueries.NewEntry(context.Background(), NewEntryParams{
+			AccountID: account.ID,
+			Amount:    amount,
+		})
+		require.NoError(t, err)
+		entries = append(entries, entry)
+	}
+
+	listEntries := func(arg ListEntriesParams) []int64 {
+		arg.AccountID = account.ID
+		arg.Limit = 10
+		result, err := testQueries.ListEntries(context.Background(), arg)
+		require.NoError(t, err)
+		ids := make([]int64, len(result))
+		for i, entry := range result {
+			ids[i] = entry.ID
+		}
+		return ids
+	}
+
+	// newest first by default
+	require.Equal(t, []int64{entries[2].ID, entries[1].ID, entries[0].ID}, listEntries(ListEntriesParams{}))
+	require.Equal(t, []int64{entries[0].ID, entries[1].ID, entries[2].ID}, listEntries(ListEntriesParams{SortOrder: util.SortOrderAsc}))
+
+	// amounts are compared and sorted without their sign
+	require.Equal(t, []int64{entries[0].ID, entries[2].ID, entries[1].ID}, listEntries(ListEntriesParams{SortBy: util.SortByAmount}))
+	require.Equal(t, []int64{entries[1].ID, entries[2].ID, entries[0].ID}, listEntries(ListEntriesParams{
+		SortBy:    util.SortByAmount,
+		SortOrder: util.SortOrderAsc,
+	}))
+	require.Equal(t, []int64{entries[2].ID}, listEntries(ListEntriesParams{
+		MinAmount: pgtype.Int8{Int64: 15, Valid: true},
+		MaxAmount: pgtype.Int8{Int64: 25, Valid: true},
+	}))
+
+	require.Equal(t, []int64{entries[1].ID}, listEntries(ListEntriesParams{Direction: pgtype.Text{String: util.DirectionIn, Valid: true}}))
+	require.Equal(t, []int64{entries[2].ID, entries[0].ID}, listEntries(ListEntriesParams{Direction: pgtype.Text{String: util.DirectionOut, Valid: true}}))
+
+	// entries without a journal have no counterparty
+	require.Empty(t, listEntries(ListEntriesParams{CounterpartyAccountID: pgtype.Int8{Int64: account.ID + 1, Valid: true}}))
+	require.Empty(t, listEntries(ListEntriesParams{EndTime: pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true}}))
 }
@@ -0,0 +1,274 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/the-eduardo/Go-Bank/util"
+	"math"
+	"sort"
+)
+
+// ListAccountsParams are the owner and the cursor of an accounts page, oldest first.
+// The page starts after the account the cursor points to, or at the start of the list when CursorID is null.
+// Offset skips rows after the cursor for the deprecated page_id.
+type ListAccountsParams struct {
+	Owner           string             `json:"owner"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID        pgtype.Int8        `json:"cursor_id"`
+	Limit           int64              `json:"limit"`
+	Offset          int64              `json:"offset"`
+}
+
+// ListEntriesParams are the filters, sort and cursor of an entries page.
+// The entries are sorted by created_at, or by amount without sign when SortBy is util.SortByAmount, then by id,
+// newest or largest first unless SortOrder is util.SortOrderAsc.
+// The page starts after the entry the cursor points to, or at the start of the list when CursorID is null.
+// Offset skips rows after the cursor for the deprecated page_id.
+type ListEntriesParams struct {
+	AccountID             int64              `json:"account_id"`
+	StartTime             pgtype.Timestamptz `json:"start_time"`
+	EndTime               pgtype.Timestamptz `json:"end_time"`
+	MinAmount             pgtype.Int8        `json:"min_amount"`
+	MaxAmount             pgtype.Int8        `json:"max_amount"`
+	Direction             pgtype.Text        `json:"direction"`
+	CounterpartyAccountID pgtype.Int8        `json:"counterparty_account_id"`
+	SortBy                string             `json:"sort_by"`
+	SortOrder             string             `json:"sort_order"`
+	CursorID              pgtype.Int8        `json:"cursor_id"`
+	CursorAmount          pgtype.Int8        `json:"cursor_amount"`
+	CursorCreatedAt       pgtype.Timestamptz `json:"cursor_created_at"`
+	Limit                 int64              `json:"limit"`
+	Offset                int64              `json:"offset"`
+}
+
+// ListTransfersByAccountIdParams are the filters, sort and cursor of a page of the transfers sent or received by an account.
+// The amounts are the ones seen by the account, so a received FX transfer is compared and sorted by its converted amount.
+// The order, the cursor and the offset work as in ListEntriesParams.
+type ListTransfersByAccountIdParams struct {
+	AccountID             int64              `json:"account_id"`
+	StartTime             pgtype.Timestamptz `json:"start_time"`
+	EndTime               pgtype.Timestamptz `json:"end_time"`
+	MinAmount             pgtype.Int8        `json:"min_amount"`
+	MaxAmount             pgtype.Int8        `json:"max_amount"`
+	Direction             pgtype.Text        `json:"direction"`
+	CounterpartyAccountID pgtype.Int8        `json:"counterparty_account_id"`
+	SortBy                string             `json:"sort_by"`
+	SortOrder             string             `json:"sort_order"`
+	CursorID              pgtype.Int8        `json:"cursor_id"`
+	CursorAmount          pgtype.Int8        `json:"cursor_amount"`
+	CursorCreatedAt       pgtype.Timestamptz `json:"cursor_created_at"`
+	Limit                 int64              `json:"limit"`
+	Offset                int64              `json:"offset"`
+}
+
+// historyCursor is the keyset the page starts after, the first page starts after a key outside of every row
+type historyCursor struct {
+	createdAt pgtype.Timestamptz
+	amount    int64
+	id        int64
+}
+
+func newHistoryCursor(ascending bool, id, amount pgtype.Int8, createdAt pgtype.Timestamptz) historyCursor {
+	if id.Valid {
+		return historyCursor{createdAt: createdAt, amount: amount.Int64, id: id.Int64}
+	}
+	if ascending {
+		return historyCursor{createdAt: pgtype.Timestamptz{InfinityModifier: pgtype.NegativeInfinity, Valid: true}}
+	}
+	return historyCursor{
+		createdAt: pgtype.Timestamptz{InfinityModifier: pgtype.Infinity, Valid: true},
+		amount:    math.MaxInt64,
+		id:        math.MaxInt64,
+	}
+}
+
+// ListAccounts returns a page of the accounts of an owner, read from the keyset index of the owner
+func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
+	cursor := newHistoryCursor(true, arg.CursorID, pgtype.Int8{}, arg.CursorCreatedAt)
+	accounts, err := q.ListAccountsOldestFirst(ctx, ListAccountsOldestFirstParams{
+		Owner:           arg.Owner,
+		CursorCreatedAt: cursor.createdAt,
+		CursorID:        cursor.id,
+		Limit:           arg.Limit + arg.Offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return skipRows(accounts, arg.Offset), nil
+}
+
+// ListEntries returns a page of the entries of an account with the query of its sort order,
+// so the page is read from the keyset indexes of the account
+func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
+	ascending := arg.SortOrder == util.SortOrderAsc
+	cursor := newHistoryCursor(ascending, arg.CursorID, arg.CursorAmount, arg.CursorCreatedAt)
+	limit := arg.Limit + arg.Offset
+
+	var entries []Entry
+	var err error
+	switch {
+	case arg.SortBy == util.SortByAmount && ascending:
+		entries, err = q.ListEntriesSmallestFirst(ctx, ListEntriesSmallestFirstParams{
+			AccountID:             arg.AccountID,
+			CursorAmount:          cursor.amount,
+			CursorID:              cursor.id,
+			StartTime:             arg.StartTime,
+			EndTime:               arg.EndTime,
+			MinAmount:             arg.MinAmount,
+			MaxAmount:             arg.MaxAmount,
+			Direction:             arg.Direction,
+			CounterpartyAccountID: arg.CounterpartyAccountID,
+			Limit:                 limit,
+		})
+	case arg.SortBy == util.SortByAmount:
+		entries, err = q.ListEntriesLargestFirst(ctx, ListEntriesLargestFirstParams{
+			AccountID:             arg.AccountID,
+			CursorAmount:          cursor.amount,
+			CursorID:              cursor.id,
+			StartTime:             arg.StartTime,
+			EndTime:               arg.EndTime,
+			MinAmount:             arg.MinAmount,
+			MaxAmount:             arg.MaxAmount,
+			Direction:             arg.Direction,
+			CounterpartyAccountID: arg.CounterpartyAccountID,
+			Limit:                 limit,
+		})
+	case ascending:
+		entries, err = q.ListEntriesOldestFirst(ctx, ListEntriesOldestFirstParams{
+			AccountID:             arg.AccountID,
+			CursorCreatedAt:       cursor.createdAt,
+			CursorID:              cursor.id,
+			StartTime:             arg.StartTime,
+			EndTime:               arg.EndTime,
+			MinAmount:             arg.MinAmount,
+			MaxAmount:             arg.MaxAmount,
+			Direction:             arg.Direction,
+			CounterpartyAccountID: arg.CounterpartyAccountID,
+			Limit:                 limit,
+		})
+	default:
+		entries, err = q.ListEntriesNewestFirst(ctx, ListEntriesNewestFirstParams{
+			AccountID:             arg.AccountID,
+			CursorCreatedAt:       cursor.createdAt,
+			CursorID:              cursor.id,
+			StartTime:             arg.StartTime,
+			EndTime:               arg.EndTime,
+			MinAmount:             arg.MinAmount,
+			MaxAmount:             arg.MaxAmount,
+			Direction:             arg.Direction,
+			CounterpartyAccountID: arg.CounterpartyAccountID,
+			Limit:                 limit,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return skipRows(entries, arg.Offset), nil
+}
+
+// ListTransfersByAccountId returns a page of the transfers sent or received by an account with the query of its sort order.
+// The query reads up to Offset+Limit transfers of each side, the page is the next Limit transfers of both in the sort order.
+func (q *Queries) ListTransfersByAccountId(ctx context.Context, arg ListTransfersByAccountIdParams) ([]Transfer, error) {
+	ascending := arg.SortOrder == util.SortOrderAsc
+	byAmount := arg.SortBy == util.SortByAmount
+	cursor := newHistoryCursor(ascending, arg.CursorID, arg.CursorAmount, arg.CursorCreatedAt)
+	limit := arg.Limit + arg.Offset
+
+	var transfers []Transfer
+	var err error
+	switch {
+	case byAmount && ascending:
+		transfers, err = q.ListTransfersSmallestFirst(ctx, ListTransfersSmallestFirstParams{
+			AccountID:             arg.AccountID,
+			CursorAmount:          cursor.amount,
+			CursorID:              cursor.id,
+			StartTime:             arg.StartTime,
+			EndTime:               arg.EndTime,
+			MinAmount:             arg.MinAmount,
+			MaxAmount:             arg.MaxAmount,
+			Direction:             arg.Direction,
+			CounterpartyAccountID: arg.CounterpartyAccountID,
+			Limit:                 limit,
+		})
+	case byAmount:
+		transfers, err = q.ListTransfersLargestFirst(ctx, ListTransfersLargestFirstParams{
+			AccountID:             arg.AccountID,
+			CursorAmount:          cursor.amount,
+			CursorID:              cursor.id,
+			StartTime:             arg.StartTime,
+			EndTime:               arg.EndTime,
+			MinAmount:             arg.MinAmount,
+			MaxAmount:             arg.MaxAmount,
+			Direction:             arg.Direction,
+			CounterpartyAccountID: arg.CounterpartyAccountID,
+			Limit:                 limit,
+		})
+	case ascending:
+		transfers, err = q.ListTransfersOldestFirst(ctx, ListTransfersOldestFirstParams{
+			AccountID:             arg.AccountID,
+			CursorCreatedAt:       cursor.createdAt,
+			CursorID:              cursor.id,
+			StartTime:             arg.StartTime,
+			EndTime:               arg.EndTime,
+			MinAmount:             arg.MinAmount,
+			MaxAmount:             arg.MaxAmount,
+			Direction:             arg.Direction,
+			CounterpartyAccountID: arg.CounterpartyAccountID,
+			Limit:                 limit,
+		})
+	default:
+		transfers, err = q.ListTransfersNewestFirst(ctx, ListTransfersNewestFirstParams{
+			AccountID:             arg.AccountID,
+			CursorCreatedAt:       cursor.createdAt,
+			CursorID:              cursor.id,
+			StartTime:             arg.StartTime,
+			EndTime:               arg.EndTime,
+			MinAmount:             arg.MinAmount,
+			MaxAmount:             arg.MaxAmount,
+			Direction:             arg.Direction,
+			CounterpartyAccountID: arg.CounterpartyAccountID,
+			Limit:                 limit,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// both sides come sorted, merge them and keep the first page
+	sort.SliceStable(transfers, func(i, j int) bool {
+		a, b := transfers[i], transfers[j]
+		if ascending {
+			a, b = b, a
+		}
+		if byAmount {
+			amountA, amountB := arg.accountAmount(a), arg.accountAmount(b)
+			if amountA != amountB {
+				return amountA > amountB
+			}
+		} else if !a.CreatedAt.Time.Equal(b.CreatedAt.Time) {
+			return a.CreatedAt.Time.After(b.CreatedAt.Time)
+		}
+		return a.ID > b.ID
+	})
+	transfers = skipRows(transfers, arg.Offset)
+	if int64(len(transfers)) > arg.Limit {
+		transfers = transfers[:arg.Limit]
+	}
+	return transfers, nil
+}
+
+// skipRows drops the rows before the offset, the queries read them with the page since their keyset has no offset
+func skipRows[T any](rows []T, offset int64) []T {
+	if int64(len(rows)) <= offset {
+		return rows[:0]
+	}
+	return rows[offset:]
+}
+
+// accountAmount is the amount of a transfer seen by the account of the list
+func (arg ListTransfersByAccountIdParams) accountAmount(transfer Transfer) int64 {
+	if transfer.FromAccountID == arg.AccountID {
+		return transfer.Amount
+	}
+	return transfer.ToAmount
+}
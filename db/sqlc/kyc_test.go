@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/util"
+	"testing"
+)
+
+func createRandomKYCSubmission(t *testing.T, username string, tier int32) KycSubmission {
+	arg := CreateKYCSubmissionParams{
+		Username:       username,
+		Tier:           tier,
+		DocumentType:   "passport",
+		DocumentNumber: util.RandomString(9),
+	}
+	submission, err := testQueries.CreateKYCSubmission(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, submission.Username)
+	require.Equal(t, arg.Tier, submission.Tier)
+	require.Equal(t, KYCStatusPending, submission.Status)
+	require.False(t, submission.ReviewedBy.Valid)
+	require.False(t, submission.ReviewedAt.Valid)
+	return submission
+}
+
+func TestCreateKYCSubmission(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomKYCSubmission(t, user.Username, 1)
+
+	// a user has at most one pending submission
+	_, err := testQueries.CreateKYCSubmission(context.Background(), CreateKYCSubmissionParams{
+		Username:       user.Username,
+		Tier:           2,
+		DocumentType:   "passport",
+		DocumentNumber: util.RandomString(9),
+	})
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "23505", pgErr.Code)
+}
+
+func TestListKYCSubmissions(t *testing.T) {
+	submission := createRandomKYCSubmission(t, createRandomUser(t).Username, 1)
+
+	submissions, err := testQueries.ListKYCSubmissions(context.Background(), ListKYCSubmissionsParams{
+		Status: KYCStatusPending,
+		Limit:  1000,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Contains(t, submissions, submission)
+	for _, s := range submissions {
+		require.Equal(t, KYCStatusPending, s.Status)
+	}
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/util"
+	"testing"
+	"time"
+)
+
+func TestRecordLoginFailure(t *testing.T) {
+	arg := RecordLoginFailureParams{
+		Scope:       LoginScopeUsername,
+		Key:         util.RandomOwner(),
+		ResetBefore: pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true},
+	}
+	for i := 1; i <= 3; i++ {
+		attempt, err := testQueries.RecordLoginFailure(context.Background(), arg)
+		require.NoError(t, err)
+		require.Equal(t, int32(i), attempt.FailedCount)
+		require.False(t, attempt.LockedUntil.Valid)
+	}
+
+	// Failures before reset_before are forgotten
+	arg.ResetBefore = pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true}
+	attempt, err := testQueries.RecordLoginFailure(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), attempt.FailedCount)
+}
+
+func TestGetLoginLockout(t *testing.T) {
+	username := util.RandomOwner()
+	clientIP := "198.51.100." + util.RandomString(3)
+
+	lockedUntil, err := testQueries.GetLoginLockout(context.Background(), GetLoginLockoutParams{
+		Username: username,
+		ClientIp: clientIP,
+	})
+	require.NoError(t, err)
+	require.False(t, lockedUntil.Valid)
+
+	lockout := time.Now().Add(time.Minute)
+	_, err = testQueries.RecordLoginFailure(context.Background(), RecordLoginFailureParams{
+		Scope:       LoginScopeClientIP,
+		Key:         clientIP,
+		ResetBefore: pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+	_, err = testQueries.LockLogin(context.Background(), LockLoginParams{
+		Scope:       LoginScopeClientIP,
+		Key:         clientIP,
+		LockedUntil: pgtype.Timestamptz{Time: lockout, Valid: true},
+	})
+	require.NoError(t, err)
+
+	// A lockout of the client ip applies to every username
+	lockedUntil, err = testQueries.GetLoginLockout(context.Background(), GetLoginLockoutParams{
+		Username: util.RandomOwner(),
+		ClientIp: clientIP,
+	})
+	require.NoError(t, err)
+	require.WithinDuration(t, lockout, lockedUntil.Time, time.Second)
+
+	err = testQueries.ResetLoginAttempts(context.Background(), ResetLoginAttemptsParams{
+		Scope: LoginScopeClientIP,
+		Key:   clientIP,
+	})
+	require.NoError(t, err)
+	lockedUntil, err = testQueries.GetLoginLockout(context.Background(), GetLoginLockoutParams{
+		Username: username,
+		ClientIp: clientIP,
+	})
+	require.NoError(t, err)
+	require.False(t, lockedUntil.Valid)
+}
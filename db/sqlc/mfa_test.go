@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/util"
+	"testing"
+	"time"
+)
+
+func createRandomTOTPFactor(t *testing.T, username string) TotpFactor {
+	arg := UpsertTOTPFactorParams{
+		Username: username,
+		Secret:   util.RandomString(32),
+	}
+	factor, err := testQueries.UpsertTOTPFactor(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, factor.Username)
+	require.Equal(t, arg.Secret, factor.Secret)
+	require.False(t, factor.IsConfirmed)
+	require.Zero(t, factor.LastUsedStep)
+	return factor
+}
+
+func TestUpsertTOTPFactor(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomTOTPFactor(t, user.Username)
+
+	// Enrolling again replaces the unconfirmed secret
+	factor := createRandomTOTPFactor(t, user.Username)
+
+	_, err := testQueries.ConfirmTOTPFactor(context.Background(), user.Username)
+	require.NoError(t, err)
+
+	_, err = testQueries.UpsertTOTPFactor(context.Background(), UpsertTOTPFactorParams{
+		Username: user.Username,
+		Secret:   util.RandomString(32),
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	confirmed, err := testQueries.GetTOTPFactor(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, factor.Secret, confirmed.Secret)
+	require.True(t, confirmed.IsConfirmed)
+	require.True(t, confirmed.ConfirmedAt.Valid)
+}
+
+func TestUseTOTPStep(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomTOTPFactor(t, user.Username)
+
+	arg := UseTOTPStepParams{
+		Username:     user.Username,
+		LastUsedStep: time.Now().Unix() / 30,
+	}
+	rows, err := testQueries.UseTOTPStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	// Replaying the same step or an older one is rejected
+	rows, err = testQueries.UseTOTPStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	arg.LastUsedStep--
+	rows, err = testQueries.UseTOTPStep(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+}
+
+func TestUseRecoveryCode(t *testing.T) {
+	user := createRandomUser(t)
+	hashedCode := util.HashRecoveryCode(util.RandomString(10))
+	_, err := testQueries.CreateRecoveryCode(context.Background(), CreateRecoveryCodeParams{
+		Username:   user.Username,
+		HashedCode: hashedCode,
+	})
+	require.NoError(t, err)
+
+	arg := UseRecoveryCodeParams{
+		Username:   user.Username,
+		HashedCode: hashedCode,
+	}
+	code, err := testQueries.UseRecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, code.UsedAt.Valid)
+
+	_, err = testQueries.UseRecoveryCode(context.Background(), arg)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestAttemptMFAChallenge(t *testing.T) {
+	user := createRandomUser(t)
+	challenge, err := testQueries.CreateMFAChallenge(context.Background(), CreateMFAChallengeParams{
+		ID:        pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Username:  user.Username,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+	require.Zero(t, challenge.Attempts)
+
+	arg := AttemptMFAChallengeParams{
+		ID:          challenge.ID,
+		MaxAttempts: 2,
+	}
+	for i := 1; i <= 2; i++ {
+		attempted, err := testQueries.AttemptMFAChallenge(context.Background(), arg)
+		require.NoError(t, err)
+		require.Equal(t, int32(i), attempted.Attempts)
+	}
+	_, err = testQueries.AttemptMFAChallenge(context.Background(), arg)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	used, err := testQueries.UseMFAChallenge(context.Background(), challenge.ID)
+	require.NoError(t, err)
+	require.True(t, used.IsUsed)
+
+	_, err = testQueries.UseMFAChallenge(context.Background(), challenge.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
@@ -6,6 +6,7 @@ package db
 
 import (
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/the-eduardo/Go-Bank/fx"
 )
 
 type Account struct {
@@ -14,6 +15,23 @@ type Account struct {
 	Balance   int64              `json:"balance"`
 	Currency  string             `json:"currency"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	// how far below zero the balance may go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	IsSystem       bool  `json:"is_system"`
+	// balance minus the holds of pending transfers
+	AvailableBalance int64 `json:"available_balance"`
+	// incremented by every change of balance or available_balance, orders the account events
+	BalanceVersion int64 `json:"balance_version"`
+}
+
+// a null limit uses the default from the config, 0 removes the limit
+type AccountTransferLimit struct {
+	AccountID   int64              `json:"account_id"`
+	PerTransfer pgtype.Int8        `json:"per_transfer"`
+	Daily       pgtype.Int8        `json:"daily"`
+	Weekly      pgtype.Int8        `json:"weekly"`
+	HourlyCount pgtype.Int8        `json:"hourly_count"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 }
 
 type Entry struct {
@@ -22,6 +40,117 @@ type Entry struct {
 	// can be negative or positive
 	Amount    int64              `json:"amount"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	// null only for entries recorded before the ledger
+	JournalID pgtype.Int8 `json:"journal_id"`
+}
+
+type FxQuote struct {
+	ID           pgtype.UUID        `json:"id"`
+	Username     string             `json:"username"`
+	FromCurrency string             `json:"from_currency"`
+	ToCurrency   string             `json:"to_currency"`
+	Rate         fx.ExchangeRate    `json:"rate"`
+	IsUsed       bool               `json:"is_used"`
+	ExpiresAt    pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	Username     string             `json:"username"`
+	Key          string             `json:"key"`
+	RequestHash  string             `json:"request_hash"`
+	ResponseBody []byte             `json:"response_body"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	ExpiresAt    pgtype.Timestamptz `json:"expires_at"`
+}
+
+type Journal struct {
+	ID        int64              `json:"id"`
+	Kind      string             `json:"kind"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	// transfer posted by the journal, null for deposits and other journals
+	TransferID pgtype.Int8 `json:"transfer_id"`
+}
+
+type KycSubmission struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// kyc tier the user asks for
+	Tier           int32  `json:"tier"`
+	DocumentType   string `json:"document_type"`
+	DocumentNumber string `json:"document_number"`
+	// pending, approved or rejected
+	Status     string             `json:"status"`
+	ReviewedBy pgtype.Text        `json:"reviewed_by"`
+	ReviewNote string             `json:"review_note"`
+	ReviewedAt pgtype.Timestamptz `json:"reviewed_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type LoginAttempt struct {
+	// username or client_ip
+	Scope string `json:"scope"`
+	// not a foreign key, failures for unknown usernames are counted too
+	Key          string             `json:"key"`
+	FailedCount  int32              `json:"failed_count"`
+	LastFailedAt pgtype.Timestamptz `json:"last_failed_at"`
+	LockedUntil  pgtype.Timestamptz `json:"locked_until"`
+}
+
+type MfaChallenge struct {
+	ID        pgtype.UUID        `json:"id"`
+	Username  string             `json:"username"`
+	Attempts  int32              `json:"attempts"`
+	IsUsed    bool               `json:"is_used"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type PasswordReset struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// sha256 of the code sent by email
+	HashedCode string             `json:"hashed_code"`
+	IsUsed     bool               `json:"is_used"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+}
+
+type RecoveryCode struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// sha256 of the code, the codes are random enough not to need a slow hash
+	HashedCode string             `json:"hashed_code"`
+	UsedAt     pgtype.Timestamptz `json:"used_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type ScheduledTransfer struct {
+	ID int64 `json:"id"`
+	// owner of the source account, who gets the failure emails
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	// standard cron expression in UTC, empty when interval_seconds is set
+	CronExpression  string `json:"cron_expression"`
+	IntervalSeconds int64  `json:"interval_seconds"`
+	// advanced when the run is enqueued, missed runs are skipped
+	NextRunAt pgtype.Timestamptz `json:"next_run_at"`
+	IsActive  bool               `json:"is_active"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64 `json:"id"`
+	ScheduledTransferID int64 `json:"scheduled_transfer_id"`
+	// one run per occurrence, so a retried run never transfers twice
+	ScheduledFor pgtype.Timestamptz `json:"scheduled_for"`
+	// succeeded or failed
+	Status     string             `json:"status"`
+	TransferID pgtype.Int8        `json:"transfer_id"`
+	Error      string             `json:"error"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 }
 
 type Session struct {
@@ -33,15 +162,44 @@ type Session struct {
 	IsBlocked    bool               `json:"is_blocked"`
 	ExpiresAt    pgtype.Timestamptz `json:"expires_at"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	// id of the login session this refresh token was rotated from
+	FamilyID pgtype.UUID `json:"family_id"`
+	// set once the refresh token is exchanged for a new one
+	RotatedAt pgtype.Timestamptz `json:"rotated_at"`
+}
+
+type TotpFactor struct {
+	Username    string `json:"username"`
+	Secret      string `json:"secret"`
+	IsConfirmed bool   `json:"is_confirmed"`
+	// time step of the last accepted code, older or equal steps are replays
+	LastUsedStep int64              `json:"last_used_step"`
+	ConfirmedAt  pgtype.Timestamptz `json:"confirmed_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 }
 
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
-	// must be positive
+	// must be positive, in the source account currency
 	Amount    int64              `json:"amount"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	// credited amount, in the destination account currency
+	ToAmount     int64           `json:"to_amount"`
+	ExchangeRate fx.ExchangeRate `json:"exchange_rate"`
+	QuoteID      pgtype.UUID     `json:"quote_id"`
+	// charged to the source account on top of amount, in its currency
+	Fee int64 `json:"fee"`
+	// pending while the amount and fee are held, posted once they reach the ledger, voided or expired when the hold is released
+	Status string `json:"status"`
+	// amount held by the authorization, null for transfers posted at once
+	AuthorizedAmount pgtype.Int8        `json:"authorized_amount"`
+	HoldExpiresAt    pgtype.Timestamptz `json:"hold_expires_at"`
+	// transfer refunded by this one, null for other transfers
+	ReversalOf pgtype.Int8 `json:"reversal_of"`
+	// sum of the reversals of this transfer
+	ReversedAmount int64 `json:"reversed_amount"`
 }
 
 type User struct {
@@ -52,6 +210,12 @@ type User struct {
 	PasswordChangedAt pgtype.Timestamptz `json:"password_changed_at"`
 	CreatedAt         pgtype.Timestamptz `json:"created_at"`
 	IsEmailVerified   bool               `json:"is_email_verified"`
+	// depositor, banker or admin
+	Role string `json:"role"`
+	// new address waiting for verification, email keeps the current one until then
+	PendingEmail pgtype.Text `json:"pending_email"`
+	// 0 until a kyc submission is approved, it sets the daily transfer limit
+	KycTier int32 `json:"kyc_tier"`
 }
 
 type VerifyEmail struct {
@@ -63,3 +227,40 @@ type VerifyEmail struct {
 	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
 }
+
+type WebhookDelivery struct {
+	ID             int64 `json:"id"`
+	EventID        int64 `json:"event_id"`
+	SubscriptionID int64 `json:"subscription_id"`
+	// pending until an attempt succeeds or the retries run out
+	Status   string `json:"status"`
+	Attempts int32  `json:"attempts"`
+	// http status of the last attempt, null when the receiver could not be reached
+	ResponseStatus pgtype.Int4        `json:"response_status"`
+	Error          string             `json:"error"`
+	LastAttemptAt  pgtype.Timestamptz `json:"last_attempt_at"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+type WebhookEvent struct {
+	ID int64 `json:"id"`
+	// user the event is about, only the subscriptions of this user and the global ones receive it
+	Owner     string `json:"owner"`
+	EventType string `json:"event_type"`
+	Payload   []byte `json:"payload"`
+	// set once a delivery is created for every matching subscription
+	DispatchedAt pgtype.Timestamptz `json:"dispatched_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type WebhookSubscription struct {
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	Url   string `json:"url"`
+	// signs the payloads with HMAC-SHA256, only shown when the subscription is created
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types"`
+	// only for admins, receives the events of every user instead of those of its owner
+	IsGlobal  bool               `json:"is_global"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
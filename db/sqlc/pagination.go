@@ -0,0 +1,75 @@
+package db
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/the-eduardo/Go-Bank/util"
+)
+
+// StartAfter makes the list start after the account the page token points to
+func (arg *ListAccountsParams) StartAfter(token util.PageToken) {
+	arg.CursorCreatedAt = pgtype.Timestamptz{Time: token.CreatedAt, Valid: true}
+	arg.CursorID = pgtype.Int8{Int64: token.ID, Valid: true}
+	arg.Offset = 0
+}
+
+// NextPageToken returns the token of the page after the accounts, or an empty string when they fill less than a page
+func (arg ListAccountsParams) NextPageToken(accounts []Account) string {
+	if len(accounts) == 0 || int64(len(accounts)) < arg.Limit {
+		return ""
+	}
+	last := accounts[len(accounts)-1]
+	return util.PageToken{
+		CreatedAt: last.CreatedAt.Time,
+		ID:        last.ID,
+	}.Encode()
+}
+
+// StartAfter makes the list start after the entry the page token points to
+func (arg *ListEntriesParams) StartAfter(token util.PageToken) {
+	arg.CursorCreatedAt = pgtype.Timestamptz{Time: token.CreatedAt, Valid: true}
+	arg.CursorAmount = pgtype.Int8{Int64: token.Amount, Valid: true}
+	arg.CursorID = pgtype.Int8{Int64: token.ID, Valid: true}
+	arg.Offset = 0
+}
+
+// NextPageToken returns the token of the page after the entries, or an empty string when they fill less than a page
+func (arg ListEntriesParams) NextPageToken(entries []Entry) string {
+	if len(entries) == 0 || int64(len(entries)) < arg.Limit {
+		return ""
+	}
+	last := entries[len(entries)-1]
+	amount := last.Amount
+	if amount < 0 {
+		amount = -amount
+	}
+	return util.PageToken{
+		SortBy:    arg.SortBy,
+		SortOrder: arg.SortOrder,
+		CreatedAt: last.CreatedAt.Time,
+		Amount:    amount,
+		ID:        last.ID,
+	}.Encode()
+}
+
+// StartAfter makes the list start after the transfer the page token points to
+func (arg *ListTransfersByAccountIdParams) StartAfter(token util.PageToken) {
+	arg.CursorCreatedAt = pgtype.Timestamptz{Time: token.CreatedAt, Valid: true}
+	arg.CursorAmount = pgtype.Int8{Int64: token.Amount, Valid: true}
+	arg.CursorID = pgtype.Int8{Int64: token.ID, Valid: true}
+	arg.Offset = 0
+}
+
+// NextPageToken returns the token of the page after the transfers, or an empty string when they fill less than a page
+func (arg ListTransfersByAccountIdParams) NextPageToken(transfers []Transfer) string {
+	if len(transfers) == 0 || int64(len(transfers)) < arg.Limit {
+		return ""
+	}
+	last := transfers[len(transfers)-1]
+	return util.PageToken{
+		SortBy:    arg.SortBy,
+		SortOrder: arg.SortOrder,
+		CreatedAt: last.CreatedAt.Time,
+		Amount:    arg.accountAmount(last),
+		ID:        last.ID,
+	}.Encode()
+}
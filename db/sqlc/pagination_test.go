@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/util"
+	"testing"
+)
+
+func TestListAccountsKeyset(t *testing.T) {
+	user := createRandomUser(t)
+	var created []int64
+	for _, currency := range []string{util.USD, util.EUR, util.BRL} {
+		account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:    user.Username,
+			Currency: currency,
+		})
+		require.NoError(t, err)
+		created = append(created, account.ID)
+	}
+
+	arg := ListAccountsParams{Owner: user.Username, Limit: 2}
+	var listed []int64
+	for {
+		accounts, err := testQueries.ListAccounts(context.Background(), arg)
+		require.NoError(t, err)
+		for _, account := range accounts {
+			listed = append(listed, account.ID)
+		}
+		next := arg.NextPageToken(accounts)
+		if next == "" {
+			break
+		}
+		token, err := util.DecodePageToken(next, "", "")
+		require.NoError(t, err)
+		arg.StartAfter(token)
+	}
+	require.Equal(t, created, listed)
+}
+
+func TestListTransfersByAccountIdKeyset(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// two transfers of every amount, so the pages have to break ties by id,
+	// sent and received in turn, so the pages have to merge both sides
+	for i, amount := range []int64{10, 20, 10, 30, 20, 30} {
+		arg := CreateNewTransferParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+		}
+		if i%2 == 1 {
+			arg.FromAccountID, arg.ToAccountID = account2.ID, account1.ID
+		}
+		_, err := testQueries.CreateNewTransfer(context.Background(), arg)
+		require.NoError(t, err)
+	}
+
+	for _, sort := range []struct{ by, order string }{
+		{"", ""},
+		{util.SortByCreatedAt, util.SortOrderAsc},
+		{util.SortByAmount, util.SortOrderAsc},
+		{util.SortByAmount, util.SortOrderDesc},
+	} {
+		arg := ListTransfersByAccountIdParams{
+			AccountID: account2.ID,
+			SortBy:    sort.by,
+			SortOrder: sort.order,
+			Limit:     6,
+		}
+		all, err := testQueries.ListTransfersByAccountId(context.Background(), arg)
+		require.NoError(t, err)
+		require.Len(t, all, 6)
+
+		arg.Limit = 4
+		var paged []Transfer
+		for {
+			transfers, err := testQueries.ListTransfersByAccountId(context.Background(), arg)
+			require.NoError(t, err)
+			paged = append(paged, transfers...)
+			next := arg.NextPageToken(transfers)
+			if next == "" {
+				break
+			}
+			token, err := util.DecodePageToken(next, sort.by, sort.order)
+			require.NoError(t, err)
+			arg.StartAfter(token)
+		}
+		require.Equal(t, all, paged)
+	}
+}
+
+func TestListEntriesKeyset(t *testing.T) {
+	account := createRandomAccount(t)
+	for _, amount := range []int64{-10, 20, 10, -20, 30} {
+		_, err := testQueries.NewEntry(context.Background(), NewEntryParams{
+			AccountID: account.ID,
+			Amount:    amount,
+		})
+		require.NoError(t, err)
+	}
+
+	for _, sort := range []struct{ by, order string }{
+		{"", ""},
+		{util.SortByCreatedAt, util.SortOrderAsc},
+		{util.SortByAmount, ""},
+		{util.SortByAmount, util.SortOrderAsc},
+	} {
+		arg := ListEntriesParams{
+			AccountID: account.ID,
+			SortBy:    sort.by,
+			SortOrder: sort.order,
+			Limit:     5,
+		}
+		all, err := testQueries.ListEntries(context.Background(), arg)
+		require.NoError(t, err)
+		require.Len(t, all, 5)
+
+		arg.Limit = 2
+		var paged []Entry
+		for {
+			entries, err := testQueries.ListEntries(context.Background(), arg)
+			require.NoError(t, err)
+			paged = append(paged, entries...)
+			next := arg.NextPageToken(entries)
+			if next == "" {
+				break
+			}
+			token, err := util.DecodePageToken(next, sort.by, sort.order)
+			require.NoError(t, err)
+			arg.StartAfter(token)
+		}
+		require.Equal(t, all, paged)
+	}
+}
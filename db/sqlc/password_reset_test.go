@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/util"
+	"testing"
+	"time"
+)
+
+func createRandomPasswordReset(t *testing.T, username string, duration time.Duration) (PasswordReset, string) {
+	code := util.RandomString(32)
+	arg := CreatePasswordResetParams{
+		Username:   username,
+		HashedCode: util.HashSecretCode(code),
+		ExpiresAt:  pgtype.Timestamptz{Time: time.Now().Add(duration), Valid: true},
+	}
+	reset, err := testQueries.CreatePasswordReset(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, reset.Username)
+	require.Equal(t, arg.HashedCode, reset.HashedCode)
+	require.False(t, reset.IsUsed)
+	return reset, code
+}
+
+func TestUsePasswordReset(t *testing.T) {
+	user := createRandomUser(t)
+	reset, code := createRandomPasswordReset(t, user.Username, time.Minute)
+
+	used, err := testQueries.UsePasswordReset(context.Background(), util.HashSecretCode(code))
+	require.NoError(t, err)
+	require.Equal(t, reset.ID, used.ID)
+	require.True(t, used.IsUsed)
+
+	_, err = testQueries.UsePasswordReset(context.Background(), util.HashSecretCode(code))
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	_, expiredCode := createRandomPasswordReset(t, user.Username, -time.Minute)
+	_, err = testQueries.UsePasswordReset(context.Background(), util.HashSecretCode(expiredCode))
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
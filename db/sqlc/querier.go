@@ -13,35 +13,186 @@ import (
 type Querier interface {
 	// Use AddAccountBalance to add the amount of money as a new entry.
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	// AddAccountHold holds an amount of the available balance, a negative amount releases it
+	AddAccountHold(ctx context.Context, arg AddAccountHoldParams) (Account, error)
+	// AddTransferReversedAmount counts a refund against the transfer it gives back
+	AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error)
+	// AttemptMFAChallenge counts a verification attempt, only while the challenge can still be answered
+	AttemptMFAChallenge(ctx context.Context, arg AttemptMFAChallengeParams) (MfaChallenge, error)
+	// CaptureTransfer posts a pending transfer with the captured amount
+	CaptureTransfer(ctx context.Context, arg CaptureTransferParams) (Transfer, error)
+	ConfirmTOTPFactor(ctx context.Context, username string) (TotpFactor, error)
 	// noinspection SqlResolveForFile
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateFXQuote(ctx context.Context, arg CreateFXQuoteParams) (FxQuote, error)
+	// CreateFXTransfer records a transfer between accounts with different currencies
+	CreateFXTransfer(ctx context.Context, arg CreateFXTransferParams) (Transfer, error)
+	// CreateIdempotencyKey overwrites a key only once its replay window is over
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateJournal(ctx context.Context, arg CreateJournalParams) (Journal, error)
+	CreateKYCSubmission(ctx context.Context, arg CreateKYCSubmissionParams) (KycSubmission, error)
+	CreateMFAChallenge(ctx context.Context, arg CreateMFAChallengeParams) (MfaChallenge, error)
 	// noinspection SqlResolveForFile
 	CreateNewTransfer(ctx context.Context, arg CreateNewTransferParams) (Transfer, error)
+	// CreatePendingTransfer records an authorized transfer, its amount and fee are held until it is captured
+	CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (Transfer, error)
+	// CreateReversalTransfer records a refund of a transfer, from its destination back to its source
+	CreateReversalTransfer(ctx context.Context, arg CreateReversalTransferParams) (Transfer, error)
+	CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) (PasswordReset, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	// CreateScheduledTransferRun returns no rows when the occurrence already has a run
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
 	// noinspection SqlResolveForFile
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	// noinspection SqlResolveForFile
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailParams) (VerifyEmail, error)
+	// CreateWebhookDeliveries creates a delivery of the event for every subscription that takes its type,
+	// from its owner or a global one. A global subscription stops receiving events if its owner loses the global role.
+	CreateWebhookDeliveries(ctx context.Context, arg CreateWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	CreateWebhookEvent(ctx context.Context, arg CreateWebhookEventParams) (WebhookEvent, error)
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteExpiredSessions(ctx context.Context) (int64, error)
+	DeleteRecoveryCodes(ctx context.Context, username string) error
+	DeleteScheduledTransfer(ctx context.Context, id int64) error
+	DeleteWebhookSubscription(ctx context.Context, id int64) error
 	GetAccount(ctx context.Context, id int64) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	// GetAccountSnapshot returns the balances of an account with their version and the id of its last entry, 0 when it has none.
+	// They are read by one statement, so the balances include exactly the entries up to last_entry_id.
+	GetAccountSnapshot(ctx context.Context, id int64) (GetAccountSnapshotRow, error)
+	GetAccountTransferLimits(ctx context.Context, accountID int64) (AccountTransferLimit, error)
 	// GetEntry returns the entry with an entry ID
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetFXQuote(ctx context.Context, id pgtype.UUID) (FxQuote, error)
+	GetIdempotencyKeyForUpdate(ctx context.Context, arg GetIdempotencyKeyForUpdateParams) (IdempotencyKey, error)
+	GetKYCSubmission(ctx context.Context, id int64) (KycSubmission, error)
+	// GetLoginLockout returns the latest lockout of the username or the client ip, null when neither is locked
+	GetLoginLockout(ctx context.Context, arg GetLoginLockoutParams) (pgtype.Timestamptz, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
 	GetSession(ctx context.Context, id pgtype.UUID) (Session, error)
+	GetSessionForUpdate(ctx context.Context, id pgtype.UUID) (Session, error)
+	GetSystemAccount(ctx context.Context, arg GetSystemAccountParams) (Account, error)
+	GetTOTPFactor(ctx context.Context, username string) (TotpFactor, error)
 	// GetTransferById returns a single transfer by ID
 	GetTransferById(ctx context.Context, id int64) (Transfer, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
 	GetUser(ctx context.Context, username string) (User, error)
-	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
-	// ListEntries returns a list of entries for the given account ID
-	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
-	// ListTransfersByAccountId returns a list of transfers for a given account ID
-	ListTransfersByAccountId(ctx context.Context, arg ListTransfersByAccountIdParams) ([]Transfer, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	// GetUserForUpdate locks the user, the transfers checked against user limits are serialized on it
+	GetUserForUpdate(ctx context.Context, username string) (User, error)
+	GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhookEvent(ctx context.Context, id int64) (WebhookEvent, error)
+	GetWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error)
+	// InvalidatePasswordResets burns the other codes of the user once one of them is used
+	InvalidatePasswordResets(ctx context.Context, username string) (int64, error)
+	// ListAccountsOldestFirst returns the accounts of an owner created after the cursor, oldest first
+	ListAccountsOldestFirst(ctx context.Context, arg ListAccountsOldestFirstParams) ([]Account, error)
+	// ListAllAccounts returns the accounts of every user
+	ListAllAccounts(ctx context.Context, arg ListAllAccountsParams) ([]Account, error)
+	// ListDueScheduledTransfersForUpdate skips the rows another scheduler is already claiming
+	ListDueScheduledTransfersForUpdate(ctx context.Context, arg ListDueScheduledTransfersForUpdateParams) ([]ScheduledTransfer, error)
+	// ListExpiredTransfersForUpdate skips the holds another worker is already releasing
+	ListExpiredTransfersForUpdate(ctx context.Context, arg ListExpiredTransfersForUpdateParams) ([]Transfer, error)
+	// ListEntriesAfter returns the entries of an account with an id above after_id, in id order,
+	// with the balance of the account right after each of them, worked back from the current balance.
+	ListEntriesAfter(ctx context.Context, arg ListEntriesAfterParams) ([]ListEntriesAfterRow, error)
+	// ListEntriesLargestFirst returns the entries of an account below the cursor by amount without sign then id, largest first,
+	// with the filters of ListEntriesNewestFirst
+	ListEntriesLargestFirst(ctx context.Context, arg ListEntriesLargestFirstParams) ([]Entry, error)
+	// ListEntriesNewestFirst returns the entries of an account created before the cursor, newest first.
+	// The filters left null are skipped, direction is 'in' for credits and 'out' for debits,
+	// the amounts are compared without their sign and the counterparty is an account with an entry in the same journal.
+	// The cursor is the created_at and id of the last entry of the previous page, the ListEntries wrapper picks the query of the sort order.
+	ListEntriesNewestFirst(ctx context.Context, arg ListEntriesNewestFirstParams) ([]Entry, error)
+	// ListEntriesOldestFirst returns the entries of an account created after the cursor, oldest first, with the filters of ListEntriesNewestFirst
+	ListEntriesOldestFirst(ctx context.Context, arg ListEntriesOldestFirstParams) ([]Entry, error)
+	// ListEntriesSmallestFirst returns the entries of an account above the cursor by amount without sign then id, smallest first,
+	// with the filters of ListEntriesNewestFirst
+	ListEntriesSmallestFirst(ctx context.Context, arg ListEntriesSmallestFirstParams) ([]Entry, error)
+	// ListJournalTransfers returns the transfers posted by the given journals, with the journal of each
+	ListJournalTransfers(ctx context.Context, journalIds []int64) ([]ListJournalTransfersRow, error)
+	ListKYCSubmissions(ctx context.Context, arg ListKYCSubmissionsParams) ([]KycSubmission, error)
+	ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListSessions(ctx context.Context, arg ListSessionsParams) ([]Session, error)
+	ListTransferReversals(ctx context.Context, reversalOf pgtype.Int8) ([]Transfer, error)
+	// ListTransfersLargestFirst returns the transfers of an account below the cursor by the amount seen by the account then id,
+	// largest first, with the filters of ListTransfersNewestFirst
+	ListTransfersLargestFirst(ctx context.Context, arg ListTransfersLargestFirstParams) ([]Transfer, error)
+	// ListTransfersNewestFirst returns the transfers sent and received by an account created before the cursor, newest first.
+	// The filters left null are skipped, direction is 'in' for the transfers received and 'out' for the ones sent,
+	// and the amounts are the ones seen by the account, so a received FX transfer is compared by its converted amount.
+	// Each side is read from its own keyset index and returns up to limit rows, the ListTransfersByAccountId wrapper merges them.
+	ListTransfersNewestFirst(ctx context.Context, arg ListTransfersNewestFirstParams) ([]Transfer, error)
+	// ListTransfersOldestFirst returns the transfers of an account created after the cursor, oldest first, with the filters of ListTransfersNewestFirst
+	ListTransfersOldestFirst(ctx context.Context, arg ListTransfersOldestFirstParams) ([]Transfer, error)
+	// ListTransfersSmallestFirst returns the transfers of an account above the cursor by the amount seen by the account then id,
+	// smallest first, with the filters of ListTransfersNewestFirst
+	ListTransfersSmallestFirst(ctx context.Context, arg ListTransfersSmallestFirstParams) ([]Transfer, error)
+	// ListUnbalancedJournals returns the journals whose entries do not sum to zero in some currency
+	ListUnbalancedJournals(ctx context.Context) ([]ListUnbalancedJournalsRow, error)
+	// ListUndispatchedWebhookEventsForUpdate skips the events another dispatcher is already claiming
+	ListUndispatchedWebhookEventsForUpdate(ctx context.Context, limit int64) ([]WebhookEvent, error)
+	// ListUnreconciledAccounts returns the accounts whose balance differs from the sum of their entries
+	ListUnreconciledAccounts(ctx context.Context) ([]ListUnreconciledAccountsRow, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]ListWebhookDeliveriesRow, error)
+	ListWebhookSubscriptions(ctx context.Context, arg ListWebhookSubscriptionsParams) ([]WebhookSubscription, error)
+	LockLogin(ctx context.Context, arg LockLoginParams) (LoginAttempt, error)
+	MarkWebhookEventDispatched(ctx context.Context, id int64) error
 	// noinspection SqlResolveForFile
 	// NewEntry Does not add the amount of money. Use AddAccountBalance instead
 	NewEntry(ctx context.Context, arg NewEntryParams) (Entry, error)
-	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	// NotifyAccountEvent notifies the listeners of the account_events channel, the payload is an AccountEvent in JSON.
+	// Postgres delivers the notification when the transaction commits.
+	NotifyAccountEvent(ctx context.Context, payload string) error
+	// RecordLoginFailure counts a failure, failures older than reset_before are forgotten first
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginAttempt, error)
+	// RecordWebhookDeliveryAttempt counts an attempt and keeps the response of the receiver
+	RecordWebhookDeliveryAttempt(ctx context.Context, arg RecordWebhookDeliveryAttemptParams) (WebhookDelivery, error)
+	// ReleaseTransfer closes a pending transfer without posting it
+	ReleaseTransfer(ctx context.Context, arg ReleaseTransferParams) (Transfer, error)
+	ResetLoginAttempts(ctx context.Context, arg ResetLoginAttemptsParams) error
+	// ResetWebhookDelivery returns no rows while the delivery is still pending
+	ResetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	// ReviewKYCSubmission approves or rejects a submission, only while it is pending
+	ReviewKYCSubmission(ctx context.Context, arg ReviewKYCSubmissionParams) (KycSubmission, error)
+	RevokeAllSessions(ctx context.Context, username string) (int64, error)
+	RevokeSession(ctx context.Context, arg RevokeSessionParams) (Session, error)
+	// RevokeSessionFamily blocks every session rotated from the same login
+	RevokeSessionFamily(ctx context.Context, familyID pgtype.UUID) (int64, error)
+	RotateSession(ctx context.Context, id pgtype.UUID) (Session, error)
+	SetPendingEmail(ctx context.Context, arg SetPendingEmailParams) (User, error)
+	SetUserKYCTier(ctx context.Context, arg SetUserKYCTierParams) (User, error)
+	// SumEntriesBefore returns the sum of the entries of an account created before a time, its balance at that time
+	SumEntriesBefore(ctx context.Context, arg SumEntriesBeforeParams) (int64, error)
+	// SumTransfersSince returns the total and the number of the transfers sent by an account since a time,
+	// pending transfers count until their hold is released, refunds do not count
+	SumTransfersSince(ctx context.Context, arg SumTransfersSinceParams) (SumTransfersSinceRow, error)
+	// SumUserTransfersSince returns the total sent by the accounts of a user in a currency since a time,
+	// and the number of the transfers sent by all of their accounts, refunds do not count
+	SumUserTransfersSince(ctx context.Context, arg SumUserTransfersSinceParams) (SumUserTransfersSinceRow, error)
+	// SwitchToPendingEmail makes the pending address the email of the user once it is verified
+	SwitchToPendingEmail(ctx context.Context, arg SwitchToPendingEmailParams) (User, error)
+	UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error)
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	UpdateVerifyEmail(ctx context.Context, arg UpdateVerifyEmailParams) (VerifyEmail, error)
+	// UpsertAccountTransferLimits replaces every override of the account
+	UpsertAccountTransferLimits(ctx context.Context, arg UpsertAccountTransferLimitsParams) (AccountTransferLimit, error)
+	// UpsertTOTPFactor starts an enrollment, it never replaces a confirmed factor
+	UpsertTOTPFactor(ctx context.Context, arg UpsertTOTPFactorParams) (TotpFactor, error)
+	// UseFXQuote marks a quote as used, only if it is still valid for the user
+	UseFXQuote(ctx context.Context, arg UseFXQuoteParams) (FxQuote, error)
+	UseMFAChallenge(ctx context.Context, id pgtype.UUID) (MfaChallenge, error)
+	// UsePasswordReset consumes a reset code, only once and before it expires
+	UsePasswordReset(ctx context.Context, hashedCode string) (PasswordReset, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (RecoveryCode, error)
+	// UseTOTPStep records the time step of an accepted code, no row is affected when the code is replayed
+	UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
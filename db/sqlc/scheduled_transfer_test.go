@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func createRandomScheduledTransfer(t *testing.T, from Account, to Account, nextRunAt time.Time) ScheduledTransfer {
+	arg := CreateScheduledTransferParams{
+		Owner:           from.Owner,
+		FromAccountID:   from.ID,
+		ToAccountID:     to.ID,
+		Amount:          10,
+		IntervalSeconds: 86400,
+		NextRunAt:       pgtype.Timestamptz{Time: nextRunAt, Valid: true},
+	}
+	scheduledTransfer, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, scheduledTransfer.ID)
+	require.Equal(t, arg.Owner, scheduledTransfer.Owner)
+	require.Equal(t, arg.Amount, scheduledTransfer.Amount)
+	require.Empty(t, scheduledTransfer.CronExpression)
+	require.Equal(t, arg.IntervalSeconds, scheduledTransfer.IntervalSeconds)
+	require.WithinDuration(t, nextRunAt, scheduledTransfer.NextRunAt.Time, time.Second)
+	require.True(t, scheduledTransfer.IsActive)
+	return scheduledTransfer
+}
+
+func TestCreateScheduledTransfer(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	createRandomScheduledTransfer(t, account1, account2, time.Now().Add(time.Hour))
+
+	// a scheduled transfer repeats on a cron expression or an interval, not both
+	_, err := testQueries.CreateScheduledTransfer(context.Background(), CreateScheduledTransferParams{
+		Owner:           account1.Owner,
+		FromAccountID:   account1.ID,
+		ToAccountID:     account2.ID,
+		Amount:          10,
+		CronExpression:  "@daily",
+		IntervalSeconds: 86400,
+		NextRunAt:       pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	})
+	require.Error(t, err)
+}
+
+func TestListScheduledTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	for i := 0; i < 3; i++ {
+		createRandomScheduledTransfer(t, account1, account2, time.Now().Add(time.Hour))
+	}
+
+	scheduledTransfers, err := testQueries.ListScheduledTransfers(context.Background(), ListScheduledTransfersParams{
+		Owner:  account1.Owner,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, scheduledTransfers, 3)
+	for _, scheduledTransfer := range scheduledTransfers {
+		require.Equal(t, account1.Owner, scheduledTransfer.Owner)
+	}
+}
+
+func TestUpdateScheduledTransfer(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	scheduledTransfer := createRandomScheduledTransfer(t, account1, account2, time.Now().Add(time.Hour))
+
+	updated, err := testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:              scheduledTransfer.ID,
+		CronExpression:  pgtype.Text{String: "@weekly", Valid: true},
+		IntervalSeconds: pgtype.Int8{Int64: 0, Valid: true},
+		IsActive:        pgtype.Bool{Bool: false, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "@weekly", updated.CronExpression)
+	require.Zero(t, updated.IntervalSeconds)
+	require.False(t, updated.IsActive)
+	require.Equal(t, scheduledTransfer.Amount, updated.Amount)
+	require.Equal(t, scheduledTransfer.NextRunAt, updated.NextRunAt)
+}
+
+func TestCreateScheduledTransferRun(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	scheduledTransfer := createRandomScheduledTransfer(t, account1, account2, time.Now())
+
+	arg := CreateScheduledTransferRunParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		ScheduledFor:        scheduledTransfer.NextRunAt,
+		Status:              ScheduledTransferRunFailed,
+		Error:               "the source account has insufficient funds",
+	}
+	run, err := testQueries.CreateScheduledTransferRun(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferRunFailed, run.Status)
+	require.False(t, run.TransferID.Valid)
+
+	// an occurrence has a single run
+	_, err = testQueries.CreateScheduledTransferRun(context.Background(), arg)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	runs, err := testQueries.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Limit:               5,
+		Offset:              0,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, run.ID, runs[0].ID)
+
+	// the runs are deleted with their scheduled transfer
+	err = testQueries.DeleteScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	_, err = testQueries.GetScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/util"
+	"testing"
+	"time"
+)
+
+func createRandomSession(t *testing.T, username string, duration time.Duration) Session {
+	id := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	arg := CreateSessionParams{
+		ID:           id,
+		Username:     username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    util.RandomString(10),
+		ClientIp:     "127.0.0.1",
+		ExpiresAt:    pgtype.Timestamptz{Time: time.Now().Add(duration), Valid: true},
+		FamilyID:     id,
+	}
+	session, err := testQueries.CreateSession(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ID, session.ID)
+	require.Equal(t, arg.Username, session.Username)
+	require.Equal(t, arg.FamilyID, session.FamilyID)
+	require.False(t, session.IsBlocked)
+	require.False(t, session.RotatedAt.Valid)
+	return session
+}
+
+func TestListSessions(t *testing.T) {
+	user := createRandomUser(t)
+	active := createRandomSession(t, user.Username, time.Hour)
+	createRandomSession(t, user.Username, -time.Minute)
+	revoked := createRandomSession(t, user.Username, time.Hour)
+	_, err := testQueries.RevokeSession(context.Background(), RevokeSessionParams{
+		ID:       revoked.ID,
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+
+	sessions, err := testQueries.ListSessions(context.Background(), ListSessionsParams{
+		Username: user.Username,
+		Limit:    5,
+		Offset:   0,
+	})
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, active.ID, sessions[0].ID)
+}
+
+func TestRevokeSession(t *testing.T) {
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+	session := createRandomSession(t, user.Username, time.Hour)
+
+	_, err := testQueries.RevokeSession(context.Background(), RevokeSessionParams{
+		ID:       session.ID,
+		Username: other.Username,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	revoked, err := testQueries.RevokeSession(context.Background(), RevokeSessionParams{
+		ID:       session.ID,
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.True(t, revoked.IsBlocked)
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+	for i := 0; i < 3; i++ {
+		createRandomSession(t, user.Username, time.Hour)
+	}
+	otherSession := createRandomSession(t, other.Username, time.Hour)
+
+	n, err := testQueries.RevokeAllSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), n)
+
+	n, err = testQueries.RevokeAllSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	session, err := testQueries.GetSession(context.Background(), otherSession.ID)
+	require.NoError(t, err)
+	require.False(t, session.IsBlocked)
+}
+
+func TestDeleteExpiredSessions(t *testing.T) {
+	user := createRandomUser(t)
+	expired := createRandomSession(t, user.Username, -time.Minute)
+	active := createRandomSession(t, user.Username, time.Hour)
+
+	n, err := testQueries.DeleteExpiredSessions(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, n, int64(1))
+
+	_, err = testQueries.GetSession(context.Background(), expired.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+	_, err = testQueries.GetSession(context.Background(), active.ID)
+	require.NoError(t, err)
+}
+
+func TestRotateSession(t *testing.T) {
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username, time.Hour)
+
+	rotated, err := testQueries.RotateSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, rotated.RotatedAt.Valid)
+	require.WithinDuration(t, time.Now(), rotated.RotatedAt.Time, time.Second)
+
+	// A rotated session is no longer listed
+	sessions, err := testQueries.ListSessions(context.Background(), ListSessionsParams{
+		Username: user.Username,
+		Limit:    5,
+		Offset:   0,
+	})
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
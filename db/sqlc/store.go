@@ -2,17 +2,51 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/the-eduardo/Go-Bank/fees"
+	"sort"
 )
 
+// ErrInsufficientFunds is returned when a debit would take an account below its overdraft limit
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// balanceWithinOverdraftConstraint is the CHECK constraint that keeps available_balance >= -overdraft_limit
+const balanceWithinOverdraftConstraint = "balance_within_overdraft"
+
 // Store provides all functions to execute queries and transactions
 type Store interface {
 	Querier
+	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListTransfersByAccountId(ctx context.Context, arg ListTransfersByAccountIdParams) ([]Transfer, error)
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	FXTransferTx(ctx context.Context, arg FXTransferTxParams) (TransferTxResult, error)
 	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error)
 	VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResult, error)
+	UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (UpdateUserTxResult, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResult, error)
+	RenewSessionTx(ctx context.Context, arg RenewSessionTxParams) (RenewSessionTxResult, error)
+	ConfirmTOTPTx(ctx context.Context, arg ConfirmTOTPTxParams) (ConfirmTOTPTxResult, error)
+	ReviewKYCTx(ctx context.Context, arg ReviewKYCTxParams) (ReviewKYCTxResult, error)
+	LoginFailureTx(ctx context.Context, arg LoginFailureTxParams) (LoginFailureTxResult, error)
+	DepositTx(ctx context.Context, arg DepositTxParams) (DepositTxResult, error)
+	LedgerTx(ctx context.Context, arg LedgerTxParams) (LedgerTxResult, error)
+	Reconcile(ctx context.Context) (ReconcileResult, error)
+	StatementTx(ctx context.Context, arg StatementTxParams) (StatementTxResult, error)
+	ClaimDueScheduledTransfersTx(ctx context.Context, arg ClaimDueScheduledTransfersTxParams) (ClaimDueScheduledTransfersTxResult, error)
+	ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResult, error)
+	AuthorizeTransferTx(ctx context.Context, arg AuthorizeTransferTxParams) (AuthorizeTransferTxResult, error)
+	CaptureTransferTx(ctx context.Context, arg CaptureTransferTxParams) (TransferTxResult, error)
+	VoidTransferTx(ctx context.Context, arg VoidTransferTxParams) (VoidTransferTxResult, error)
+	ExpireTransfersTx(ctx context.Context, arg ExpireTransfersTxParams) (ExpireTransfersTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	DispatchWebhookEventsTx(ctx context.Context, arg DispatchWebhookEventsTxParams) (DispatchWebhookEventsTxResult, error)
+	RedeliverWebhookTx(ctx context.Context, arg RedeliverWebhookTxParams) (RedeliverWebhookTxResult, error)
 }
 
 // SQLStore provides all functions to execute SQL queries and transactions
@@ -30,7 +64,12 @@ func NewStore(db *pgxpool.Pool) Store {
 
 // execTx executes a function within a database transaction
 func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, pgx.TxOptions{})
+	return store.execTxWithOptions(ctx, pgx.TxOptions{}, fn)
+}
+
+// execTxWithOptions executes a function within a database transaction started with the given options
+func (store *SQLStore) execTxWithOptions(ctx context.Context, txOptions pgx.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, txOptions)
 	if err != nil {
 		return err
 	}
@@ -50,6 +89,12 @@ type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// Idempotency makes retries of the same transfer return the first result
+	Idempotency *IdempotencyParams `json:"-"`
+	// Limits are checked against the transfers already sent by the source account and its owner
+	Limits *TransferLimits `json:"-"`
+	// Fees charges the source account on top of the amount, nil transfers for free
+	Fees *fees.Schedule `json:"-"`
 }
 
 // TransferTxResult is the result of the transfer transaction
@@ -59,6 +104,16 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// Fee is nil when the transfer is free
+	Fee *TransferFee `json:"fee,omitempty"`
+}
+
+// TransferFee is the line item of the fee charged to the source account
+type TransferFee struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	// Entry debits the source account, the fee account is credited in the same journal
+	Entry Entry `json:"entry"`
 }
 
 // TransferTx performs a money transfer from one account to the other
@@ -66,61 +121,208 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 	var err error
 	var result TransferTxResult
 	err = store.execTx(ctx, func(q *Queries) error {
-		result.Transfer, err = q.CreateNewTransfer(ctx, CreateNewTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
+		return execIdempotent(ctx, q, arg.Idempotency, "transfer", arg, &result, func() error {
+			return transfer(ctx, q, arg, &result)
 		})
-		if err != nil {
-			return err
-		}
+	})
+	return result, err
+}
 
-		result.FromEntry, err = q.NewEntry(ctx, NewEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
+func transfer(ctx context.Context, q *Queries, arg TransferTxParams, result *TransferTxResult) error {
+	var fee int64
+	var currency string
+	if arg.Fees != nil {
+		fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
 		if err != nil {
 			return err
 		}
+		currency = fromAccount.Currency
+		fee = arg.Fees.Calculate(currency, arg.Amount)
+	}
 
-		result.ToEntry, err = q.NewEntry(ctx, NewEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		})
+	var err error
+	result.Transfer, err = q.CreateNewTransfer(ctx, CreateNewTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Fee:           fee,
+	})
+	if err != nil {
+		return err
+	}
+	err = postTransfer(ctx, q, result, JournalTransfer, currency, []Posting{
+		{AccountID: arg.FromAccountID, Amount: -arg.Amount},
+		{AccountID: arg.ToAccountID, Amount: arg.Amount},
+	})
+	if err != nil {
+		return err
+	}
+	return checkTransferLimits(ctx, q, result, arg.Limits)
+}
+
+// postTransfer records the postings of result.Transfer in the ledger.
+// The first posting must debit the source account and the last one credit the destination account.
+// The fee of the transfer, in the currency of the source account, is posted to the fee account in the same journal.
+// The owners of the accounts get a transfer.posted webhook event through the outbox.
+func postTransfer(ctx context.Context, q *Queries, result *TransferTxResult, kind string, currency string, postings []Posting) error {
+	last := len(postings) - 1
+	fee := result.Transfer.Fee
+	if fee > 0 {
+		feeAccount, err := q.GetSystemAccount(ctx, GetSystemAccountParams{Owner: SystemFees, Currency: currency})
 		if err != nil {
 			return err
 		}
+		postings = append(postings,
+			Posting{AccountID: result.Transfer.FromAccountID, Amount: -fee},
+			Posting{AccountID: feeAccount.ID, Amount: fee},
+		)
+	}
 
-		// get account and update balance
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
-		}
+	ledger, err := postJournal(ctx, q, LedgerTxParams{
+		Kind:       kind,
+		TransferID: pgtype.Int8{Int64: result.Transfer.ID, Valid: true},
+		Postings:   postings,
+	})
+	if err != nil {
+		return err
+	}
+	result.FromEntry, result.FromAccount = ledger.Entries[0], ledger.Accounts[0]
+	result.ToEntry, result.ToAccount = ledger.Entries[last], ledger.Accounts[last]
+	if fee > 0 {
+		// the fee is debited after the amount, so its account holds the final balance
+		result.FromAccount = ledger.Accounts[last+1]
+		result.Fee = &TransferFee{Amount: fee, Currency: currency, Entry: ledger.Entries[last+1]}
+	}
+	return writeTransferPostedEvents(ctx, q, result)
+}
+
+// balanceError converts a violation of the overdraft CHECK constraint into ErrInsufficientFunds.
+// The constraint is evaluated by the balance UPDATE, so the check holds under concurrent transfers.
+func balanceError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23514" && pgErr.ConstraintName == balanceWithinOverdraftConstraint {
+		return ErrInsufficientFunds
+	}
+	return err
+}
+
+// Journal kinds
+const (
+	JournalDeposit    = "deposit"
+	JournalTransfer   = "transfer"
+	JournalFXTransfer = "fx_transfer"
+	JournalReversal   = "reversal"
+)
+
+// Owners of the system accounts. The bank holds one system account per owner and currency.
+const (
+	SystemCashIn  = "system_cash_in"
+	SystemCashOut = "system_cash_out"
+	SystemFees    = "system_fees"
+	SystemFX      = "system_fx"
+)
+
+// ErrUnbalancedPostings is returned when the postings of a journal do not sum to zero in every currency
+var ErrUnbalancedPostings = errors.New("ledger postings are unbalanced")
+
+// Posting adds Amount to an account balance. Debits are negative and credits are positive.
+type Posting struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+}
+
+// LedgerTxParams contains the input parameters for the ledger transaction
+type LedgerTxParams struct {
+	Kind string `json:"kind"`
+	// TransferID links the journal to the transfer it posts, statements join the entries to their transfer through it
+	TransferID pgtype.Int8 `json:"transfer_id"`
+	Postings   []Posting   `json:"postings"`
+}
+
+// LedgerTxResult is the result of the ledger transaction.
+// Entries and Accounts follow the order of the postings.
+type LedgerTxResult struct {
+	Journal  Journal   `json:"journal"`
+	Entries  []Entry   `json:"entries"`
+	Accounts []Account `json:"accounts"`
+}
+
+// LedgerTx records balanced postings as a journal and applies them to the account balances
+func (store *SQLStore) LedgerTx(ctx context.Context, arg LedgerTxParams) (LedgerTxResult, error) {
+	var result LedgerTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = postJournal(ctx, q, arg)
 		return err
 	})
 	return result, err
 }
 
-func addMoney(
-	ctx context.Context,
-	q *Queries,
-	accountID1, amount1, accountID2, amount2 int64,
-) (account1, account2 Account, err error) {
+// postJournal writes a journal with one entry per posting and updates the balances.
+// It must run inside a transaction, which is rolled back when the postings are unbalanced.
+// Every entry posted to a customer account notifies an AccountEvent.
+func postJournal(ctx context.Context, q *Queries, arg LedgerTxParams) (LedgerTxResult, error) {
+	var result LedgerTxResult
+	if len(arg.Postings) < 2 {
+		return result, fmt.Errorf("%w: a journal needs at least two postings", ErrUnbalancedPostings)
+	}
+	for _, posting := range arg.Postings {
+		if posting.Amount == 0 {
+			return result, fmt.Errorf("%w: posting to account %d has no amount", ErrUnbalancedPostings, posting.AccountID)
+		}
+	}
 
-	account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID1,
-		Amount: amount1,
+	var err error
+	result.Journal, err = q.CreateJournal(ctx, CreateJournalParams{
+		Kind:       arg.Kind,
+		TransferID: arg.TransferID,
 	})
 	if err != nil {
-		return
+		return result, err
+	}
+	journalID := pgtype.Int8{Int64: result.Journal.ID, Valid: true}
+
+	// update the balances in account id order, so concurrent journals never deadlock
+	order := make([]int, len(arg.Postings))
+	for i := range order {
+		order[i] = i
 	}
-	account2, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID2,
-		Amount: amount2,
+	sort.SliceStable(order, func(i, j int) bool {
+		return arg.Postings[order[i]].AccountID < arg.Postings[order[j]].AccountID
 	})
-	if err != nil {
-		return
+
+	result.Entries = make([]Entry, len(arg.Postings))
+	result.Accounts = make([]Account, len(arg.Postings))
+	totals := make(map[string]int64)
+	for _, i := range order {
+		posting := arg.Postings[i]
+		result.Accounts[i], err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     posting.AccountID,
+			Amount: posting.Amount,
+		})
+		if err != nil {
+			return result, balanceError(err)
+		}
+		totals[result.Accounts[i].Currency] += posting.Amount
+
+		result.Entries[i], err = q.NewEntry(ctx, NewEntryParams{
+			AccountID: posting.AccountID,
+			Amount:    posting.Amount,
+			JournalID: journalID,
+		})
+		if err != nil {
+			return result, err
+		}
+		err = publishAccountEvent(ctx, q, AccountEventEntry, result.Accounts[i], &result.Entries[i])
+		if err != nil {
+			return result, err
+		}
+	}
+
+	for currency, total := range totals {
+		if total != 0 {
+			return result, fmt.Errorf("%w: %s postings sum to %d", ErrUnbalancedPostings, currency, total)
+		}
 	}
-	return
+	return result, nil
 }
@@ -2,20 +2,39 @@ package db
 
 import (
 	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/fees"
+	"github.com/the-eduardo/Go-Bank/fx"
+	"github.com/the-eduardo/Go-Bank/util"
 	"testing"
+	"time"
 )
 
+// fundAccount deposits the money for the transfers of a test through the ledger
+func fundAccount(t *testing.T, account Account, amount int64) Account {
+	result, err := NewStore(testDB).DepositTx(context.Background(), DepositTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+	})
+	require.NoError(t, err)
+	return result.Account
+}
+
 func TestStore_TransferTx(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1 := createRandomAccount(t)
+	n := 5
+	amount := int64(10)
+
+	account1 := fundAccount(t, createRandomAccount(t), int64(n)*amount)
 	account2 := createRandomAccount(t)
 
 	// Print the initial balances
 	t.Logf(">>before: account1: %d, account2: %d\n", account1.Balance, account2.Balance)
-	n := 5
-	amount := int64(10)
 
 	errs := make(chan error)
 	results := make(chan TransferTxResult)
@@ -110,13 +129,14 @@ func TestStore_TransferTx(t *testing.T) {
 func TestStore_TransferTxDeadLock(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	n := 10
+	amount := int64(10)
+
+	account1 := fundAccount(t, createRandomAccount(t), int64(n)*amount)
+	account2 := fundAccount(t, createRandomAccount(t), int64(n)*amount)
 
 	// Print the initial balances
 	t.Logf(">>before: account1: %d, account2: %d\n", account1.Balance, account2.Balance)
-	n := 10
-	amount := int64(10)
 	errs := make(chan error)
 
 	for i := 0; i < n; i++ {
@@ -155,3 +175,1159 @@ func TestStore_TransferTxDeadLock(t *testing.T) {
 	require.Equal(t, account2.Balance, updateAccount2.Balance)
 
 }
+
+func TestStore_TransferTxIdempotency(t *testing.T) {
+	store := NewStore(testDB)
+
+	amount := int64(10)
+	account1 := fundAccount(t, createRandomAccount(t), amount)
+	account2 := createRandomAccount(t)
+
+	idempotency := &IdempotencyParams{
+		Key:      util.RandomString(16),
+		Username: account1.Owner,
+		Window:   time.Minute,
+	}
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		Idempotency:   idempotency,
+	}
+
+	result1, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// replaying the same request returns the stored result without moving money again
+	result2, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, result1.Transfer.ID, result2.Transfer.ID)
+	require.Equal(t, result1.FromAccount.Balance, result2.FromAccount.Balance)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-amount, updatedAccount1.Balance)
+
+	// reusing the key for a different payload is rejected
+	arg.Amount = amount + 1
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyConflict)
+}
+
+func TestStore_TransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// the failed transfer must not leave any money movement behind
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	// an overdraft limit lets the balance go below zero up to the limit
+	_, err = testQueries.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 1,
+	})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-1), result.FromAccount.Balance)
+}
+
+func TestStore_TransferTxDailyLimit(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	limits := &TransferLimits{Account: TransferLimit{Daily: 10}}
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        6,
+		Limits:        limits,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        5,
+		Limits:        limits,
+	})
+	var limitErr *TransferLimitError
+	require.ErrorAs(t, err, &limitErr)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+	require.Equal(t, TransferLimitScopeAccount, limitErr.Scope)
+	require.Equal(t, TransferLimitDaily, limitErr.Period)
+	require.Equal(t, int64(4), limitErr.Remaining)
+
+	// the rejected transfer is rolled back, so what remains can still be sent
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        4,
+		Limits:        limits,
+	})
+	require.NoError(t, err)
+}
+
+func TestStore_TransferTxAccountLimitOverrides(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := fundAccount(t, createRandomAccount(t), 1000)
+	account2 := createRandomAccount(t)
+	limits := &TransferLimits{Account: TransferLimit{PerTransfer: 5, Weekly: 100}}
+
+	// the override raises the per transfer limit and caps the number of transfers, the weekly default is kept
+	_, err := testQueries.UpsertAccountTransferLimits(context.Background(), UpsertAccountTransferLimitsParams{
+		AccountID:   account1.ID,
+		PerTransfer: pgtype.Int8{Int64: 50, Valid: true},
+		HourlyCount: pgtype.Int8{Int64: 2, Valid: true},
+	})
+	require.NoError(t, err)
+
+	amounts := []int64{40, 40}
+	for _, amount := range amounts {
+		_, err = store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+			Limits:        limits,
+		})
+		require.NoError(t, err)
+	}
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+		Limits:        limits,
+	})
+	var limitErr *TransferLimitError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, TransferLimitHourly, limitErr.Period)
+	require.Equal(t, int64(2), limitErr.Limit)
+	require.Zero(t, limitErr.Remaining)
+
+	_, err = testQueries.UpsertAccountTransferLimits(context.Background(), UpsertAccountTransferLimitsParams{
+		AccountID:   account1.ID,
+		PerTransfer: pgtype.Int8{Int64: 50, Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        30,
+		Limits:        limits,
+	})
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, TransferLimitWeekly, limitErr.Period)
+	require.Equal(t, int64(20), limitErr.Remaining)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        51,
+		Limits:        &TransferLimits{},
+	})
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, TransferLimitPerTransfer, limitErr.Period)
+	require.Equal(t, int64(50), limitErr.Remaining)
+}
+
+func TestStore_TransferTxUserLimits(t *testing.T) {
+	store := NewStore(testDB)
+
+	usdAccount := fundAccount(t, createEmptyAccount(t, util.USD), 100)
+	eurAccount, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    usdAccount.Owner,
+		Balance:  100,
+		Currency: util.EUR,
+	})
+	require.NoError(t, err)
+	usdTo := createEmptyAccount(t, util.USD)
+	eurTo := createEmptyAccount(t, util.EUR)
+	limits := &TransferLimits{User: TransferLimit{HourlyCount: 2}}
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: usdAccount.ID,
+		ToAccountID:   usdTo.ID,
+		Amount:        10,
+		Limits:        limits,
+	})
+	require.NoError(t, err)
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: eurAccount.ID,
+		ToAccountID:   eurTo.ID,
+		Amount:        10,
+		Limits:        limits,
+	})
+	require.NoError(t, err)
+
+	// the count of the user sums all of their accounts
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: usdAccount.ID,
+		ToAccountID:   usdTo.ID,
+		Amount:        10,
+		Limits:        limits,
+	})
+	var limitErr *TransferLimitError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, TransferLimitScopeUser, limitErr.Scope)
+	require.Equal(t, TransferLimitHourly, limitErr.Period)
+
+	// while the amounts only sum the accounts in the same currency
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: usdAccount.ID,
+		ToAccountID:   usdTo.ID,
+		Amount:        10,
+		Limits:        &TransferLimits{User: TransferLimit{Daily: 20}},
+	})
+	require.NoError(t, err)
+}
+
+func TestStore_TransferTxLimitsSkipRefunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := fundAccount(t, createEmptyAccount(t, util.USD), 100)
+	account2 := fundAccount(t, createEmptyAccount(t, util.USD), 100)
+
+	transferred, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        50,
+	})
+	require.NoError(t, err)
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: transferred.Transfer.ID})
+	require.NoError(t, err)
+
+	// the refund is sent by the recipient, but it does not use up their limits
+	sum, err := testQueries.SumTransfersSince(context.Background(), SumTransfersSinceParams{
+		FromAccountID: account2.ID,
+		Since:         pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+	require.Zero(t, sum.Total)
+	require.Zero(t, sum.Count)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        40,
+		Limits: &TransferLimits{
+			Account: TransferLimit{Daily: 40, HourlyCount: 1},
+			User:    TransferLimit{Daily: 40, HourlyCount: 1},
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestStore_TransferTxFee(t *testing.T) {
+	store := NewStore(testDB)
+
+	amount := int64(1000)
+	account1 := fundAccount(t, createEmptyAccount(t, util.USD), amount)
+	account2 := createEmptyAccount(t, util.USD)
+	schedule := &fees.Schedule{Currencies: map[string]fees.Rule{util.USD: {Flat: 10, PercentBps: 100}}}
+	feeAccount, err := testQueries.GetSystemAccount(context.Background(), GetSystemAccountParams{
+		Owner:    SystemFees,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		Fees:          schedule,
+	})
+	require.NoError(t, err)
+
+	fee := int64(11)
+	require.Equal(t, fee, result.Transfer.Fee)
+	require.NotNil(t, result.Fee)
+	require.Equal(t, fee, result.Fee.Amount)
+	require.Equal(t, util.USD, result.Fee.Currency)
+	require.Equal(t, -fee, result.Fee.Entry.Amount)
+	require.Equal(t, account1.ID, result.Fee.Entry.AccountID)
+	require.Equal(t, result.FromEntry.JournalID, result.Fee.Entry.JournalID)
+
+	// the source account pays the fee on top of the amount, the destination receives the amount
+	require.Equal(t, int64(-100), result.FromEntry.Amount)
+	require.Equal(t, amount-100-fee, result.FromAccount.Balance)
+	require.Equal(t, int64(100), result.ToAccount.Balance)
+
+	updatedFeeAccount, err := testQueries.GetAccount(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fee, updatedFeeAccount.Balance-feeAccount.Balance)
+
+	// the fee is part of the funds the account must hold
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        result.FromAccount.Balance,
+		Fees:          schedule,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// transfers are free without a schedule
+	result, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.Transfer.Fee)
+	require.Nil(t, result.Fee)
+}
+
+func TestStore_FXTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	amount := int64(100)
+	account1 := fundAccount(t, createRandomAccount(t), amount)
+	account2 := createRandomAccount(t)
+
+	// 1.005 has no exact float64, the rate has to come back from the numeric column unchanged
+	rate, err := fx.ParseExchangeRate("1.005")
+	require.NoError(t, err)
+	quote, err := testQueries.CreateFXQuote(context.Background(), CreateFXQuoteParams{
+		ID:           pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Username:     account1.Owner,
+		FromCurrency: account1.Currency,
+		ToCurrency:   account2.Currency,
+		Rate:         rate,
+		ExpiresAt:    pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, rate, quote.Rate)
+	require.False(t, quote.IsUsed)
+
+	arg := FXTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		QuoteID:       quote.ID,
+		FromCurrency:  account1.Currency,
+		ToCurrency:    account2.Currency,
+		Username:      account1.Owner,
+	}
+	result, err := store.FXTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	toAmount, err := fx.Convert(amount, quote.Rate)
+	require.NoError(t, err)
+	require.Equal(t, int64(101), toAmount)
+	require.Equal(t, amount, result.Transfer.Amount)
+	require.Equal(t, toAmount, result.Transfer.ToAmount)
+	require.Equal(t, quote.Rate, result.Transfer.ExchangeRate)
+	require.Equal(t, quote.ID, result.Transfer.QuoteID)
+	require.Equal(t, -amount, result.FromEntry.Amount)
+	require.Equal(t, toAmount, result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-amount, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+toAmount, result.ToAccount.Balance)
+
+	// a quote can only be used once
+	_, err = store.FXTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInvalidQuote)
+
+	// an amount that converts to nothing is rejected and the quote stays usable
+	quote, err = testQueries.CreateFXQuote(context.Background(), CreateFXQuoteParams{
+		ID:           pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Username:     account1.Owner,
+		FromCurrency: account1.Currency,
+		ToCurrency:   account2.Currency,
+		Rate:         fx.RateScale / 4,
+		ExpiresAt:    pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+	arg.QuoteID = quote.ID
+	arg.Amount = 1
+	_, err = store.FXTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, fx.ErrInvalidAmount)
+
+	quote, err = testQueries.GetFXQuote(context.Background(), quote.ID)
+	require.NoError(t, err)
+	require.False(t, quote.IsUsed)
+}
+
+// createEmptyAccount creates an account without an opening balance, so it reconciles with its entries
+func createEmptyAccount(t *testing.T, currency string) Account {
+	user := createRandomUser(t)
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  0,
+		Currency: currency,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func TestStore_LedgerTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	amount := util.RandomMoney()
+	account := createEmptyAccount(t, util.USD)
+	cashIn, err := testQueries.GetSystemAccount(context.Background(), GetSystemAccountParams{
+		Owner:    SystemCashIn,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+
+	result, err := store.LedgerTx(context.Background(), LedgerTxParams{
+		Kind: JournalDeposit,
+		Postings: []Posting{
+			{AccountID: cashIn.ID, Amount: -amount},
+			{AccountID: account.ID, Amount: amount},
+		},
+	})
+	require.NoError(t, err)
+	require.NotZero(t, result.Journal.ID)
+	require.Equal(t, JournalDeposit, result.Journal.Kind)
+	require.Len(t, result.Entries, 2)
+	require.Equal(t, -amount, result.Entries[0].Amount)
+	require.Equal(t, amount, result.Entries[1].Amount)
+	require.Equal(t, result.Journal.ID, result.Entries[1].JournalID.Int64)
+	require.Equal(t, cashIn.Balance-amount, result.Accounts[0].Balance)
+	require.Equal(t, amount, result.Accounts[1].Balance)
+
+	// postings that create money out of nothing are rejected and leave no trace
+	_, err = store.LedgerTx(context.Background(), LedgerTxParams{
+		Kind: JournalDeposit,
+		Postings: []Posting{
+			{AccountID: cashIn.ID, Amount: -amount},
+			{AccountID: account.ID, Amount: amount + 1},
+		},
+	})
+	require.ErrorIs(t, err, ErrUnbalancedPostings)
+
+	updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, amount, updatedAccount.Balance)
+
+	_, err = store.LedgerTx(context.Background(), LedgerTxParams{
+		Kind:     JournalDeposit,
+		Postings: []Posting{{AccountID: account.ID, Amount: amount}},
+	})
+	require.ErrorIs(t, err, ErrUnbalancedPostings)
+}
+
+func TestStore_StatementTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createEmptyAccount(t, util.USD)
+	account2 := createEmptyAccount(t, util.USD)
+	deposit := func(amount int64) {
+		_, err := store.DepositTx(context.Background(), DepositTxParams{AccountID: account1.ID, Amount: amount})
+		require.NoError(t, err)
+	}
+
+	deposit(100)
+	authorized, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		HoldExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	start := time.Now()
+	deposit(50)
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        30,
+	})
+	require.NoError(t, err)
+	// the hold was authorized before the range, its entries are posted in it
+	captured, err := store.CaptureTransferTx(context.Background(), CaptureTransferTxParams{
+		TransferID: authorized.Transfer.ID,
+		Amount:     10,
+	})
+	require.NoError(t, err)
+	end := time.Now()
+	deposit(20)
+
+	result, err := store.StatementTx(context.Background(), StatementTxParams{
+		AccountID: account1.ID,
+		Start:     start,
+		End:       end,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account1.ID, result.Account.ID)
+	require.Equal(t, int64(130), result.Account.Balance)
+
+	// the balances come from the entries, not from the balance after the range
+	require.Equal(t, int64(100), result.OpeningBalance)
+	require.Len(t, result.Entries, 3)
+	require.Equal(t, int64(50), result.Entries[0].Amount)
+	require.Equal(t, transfer.FromEntry.ID, result.Entries[1].ID)
+	require.Equal(t, captured.FromEntry.ID, result.Entries[2].ID)
+
+	// the transfers are found through the journals of the entries, whatever their creation time
+	require.Len(t, result.Transfers, 2)
+	require.Equal(t, transfer.Transfer.ID, result.Transfers[result.Entries[1].JournalID.Int64].ID)
+	require.Equal(t, authorized.Transfer.ID, result.Transfers[result.Entries[2].JournalID.Int64].ID)
+	require.True(t, result.Transfers[result.Entries[2].JournalID.Int64].CreatedAt.Time.Before(start))
+}
+
+func TestStore_Reconcile(t *testing.T) {
+	store := NewStore(testDB)
+
+	amount := int64(100)
+	account1 := createEmptyAccount(t, util.USD)
+	account2 := createEmptyAccount(t, util.USD)
+
+	deposit, err := store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account1.ID,
+		Amount:    amount,
+	})
+	require.NoError(t, err)
+	require.Equal(t, amount, deposit.Account.Balance)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount / 2,
+	})
+	require.NoError(t, err)
+
+	result, err := store.Reconcile(context.Background())
+	require.NoError(t, err)
+	for _, unreconciled := range result.UnreconciledAccounts {
+		require.NotEqual(t, account1.ID, unreconciled.ID)
+		require.NotEqual(t, account2.ID, unreconciled.ID)
+	}
+	require.Empty(t, result.UnbalancedJournals)
+
+	// a balance changed outside the ledger is reported
+	_, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account2.ID,
+		Amount: amount / 2,
+	})
+	require.NoError(t, err)
+	result, err = store.Reconcile(context.Background())
+	require.NoError(t, err)
+	require.False(t, result.OK())
+	require.Contains(t, result.UnreconciledAccounts, ListUnreconciledAccountsRow{
+		ID:           account2.ID,
+		Balance:      amount,
+		EntriesTotal: amount / 2,
+	})
+}
+
+func TestStore_UpdateUserTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username, time.Hour)
+
+	// Changing only the full name keeps the sessions
+	result, err := store.UpdateUserTx(context.Background(), UpdateUserTxParams{
+		UpdateUserParams: UpdateUserParams{
+			Username: user.Username,
+			FullName: pgtype.Text{String: util.RandomOwner(), Valid: true},
+		},
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.RevokedSessions)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(10))
+	require.NoError(t, err)
+	result, err = store.UpdateUserTx(context.Background(), UpdateUserTxParams{
+		UpdateUserParams: UpdateUserParams{
+			Username:          user.Username,
+			HashedPassword:    pgtype.Text{String: hashedPassword, Valid: true},
+			PasswordChangedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, hashedPassword, result.User.HashedPassword)
+	require.Equal(t, int64(1), result.RevokedSessions)
+
+	session, err = store.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, session.IsBlocked)
+}
+
+func TestStore_RenewSessionTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	login := createRandomSession(t, user.Username, time.Hour)
+
+	newSessionParams := func() CreateSessionParams {
+		return CreateSessionParams{
+			ID:           pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Username:     user.Username,
+			RefreshToken: util.RandomString(32),
+			UserAgent:    login.UserAgent,
+			ClientIp:     login.ClientIp,
+			ExpiresAt:    pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+		}
+	}
+
+	// The refresh token must match the session
+	_, err := store.RenewSessionTx(context.Background(), RenewSessionTxParams{
+		SessionID:    login.ID,
+		Username:     user.Username,
+		RefreshToken: util.RandomString(32),
+		NewSession:   newSessionParams(),
+	})
+	require.ErrorIs(t, err, ErrSessionMismatch)
+
+	result, err := store.RenewSessionTx(context.Background(), RenewSessionTxParams{
+		SessionID:    login.ID,
+		Username:     user.Username,
+		RefreshToken: login.RefreshToken,
+		NewSession:   newSessionParams(),
+	})
+	require.NoError(t, err)
+	require.True(t, result.OldSession.RotatedAt.Valid)
+	require.Equal(t, login.FamilyID, result.NewSession.FamilyID)
+	require.False(t, result.NewSession.RotatedAt.Valid)
+	rotated := result.NewSession
+
+	// Replaying the first refresh token revokes the whole family
+	_, err = store.RenewSessionTx(context.Background(), RenewSessionTxParams{
+		SessionID:    login.ID,
+		Username:     user.Username,
+		RefreshToken: login.RefreshToken,
+		NewSession:   newSessionParams(),
+	})
+	require.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	rotated, err = store.GetSession(context.Background(), rotated.ID)
+	require.NoError(t, err)
+	require.True(t, rotated.IsBlocked)
+
+	_, err = store.RenewSessionTx(context.Background(), RenewSessionTxParams{
+		SessionID:    rotated.ID,
+		Username:     user.Username,
+		RefreshToken: rotated.RefreshToken,
+		NewSession:   newSessionParams(),
+	})
+	require.ErrorIs(t, err, ErrSessionBlocked)
+}
+
+func TestStore_ConfirmTOTPTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	createRandomTOTPFactor(t, user.Username)
+
+	hashedCodes := []string{
+		util.HashRecoveryCode(util.RandomString(10)),
+		util.HashRecoveryCode(util.RandomString(10)),
+	}
+	result, err := store.ConfirmTOTPTx(context.Background(), ConfirmTOTPTxParams{
+		Username:            user.Username,
+		HashedRecoveryCodes: hashedCodes,
+	})
+	require.NoError(t, err)
+	require.True(t, result.Factor.IsConfirmed)
+	require.Len(t, result.RecoveryCodes, len(hashedCodes))
+	for i, code := range result.RecoveryCodes {
+		require.Equal(t, user.Username, code.Username)
+		require.Equal(t, hashedCodes[i], code.HashedCode)
+		require.False(t, code.UsedAt.Valid)
+	}
+
+	// A confirmed factor cannot be confirmed again
+	_, err = store.ConfirmTOTPTx(context.Background(), ConfirmTOTPTxParams{
+		Username:            user.Username,
+		HashedRecoveryCodes: hashedCodes,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestStore_LoginFailureTx(t *testing.T) {
+	store := NewStore(testDB)
+	arg := LoginFailureTxParams{
+		Username:            util.RandomOwner(),
+		ClientIP:            "203.0.113." + util.RandomString(3),
+		MaxUserAttempts:     3,
+		MaxClientIPAttempts: 10,
+		LockoutDuration:     time.Minute,
+	}
+
+	result, err := store.LoginFailureTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), result.UserAttempt.FailedCount)
+	require.Equal(t, int32(1), result.ClientIPAttempt.FailedCount)
+	require.False(t, result.UserAttempt.LockedUntil.Valid)
+	require.False(t, result.UserLockedOut)
+
+	// The second failure starts the backoff
+	result, err = store.LoginFailureTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, result.UserAttempt.LockedUntil.Valid)
+	require.WithinDuration(t, time.Now().Add(time.Second), result.UserAttempt.LockedUntil.Time, time.Second)
+	require.False(t, result.UserLockedOut)
+
+	result, err = store.LoginFailureTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(3), result.UserAttempt.FailedCount)
+	require.WithinDuration(t, time.Now().Add(arg.LockoutDuration), result.UserAttempt.LockedUntil.Time, time.Second)
+	require.True(t, result.UserLockedOut)
+
+	// Only the failure reaching the limit reports the lockout
+	result, err = store.LoginFailureTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, result.UserLockedOut)
+	require.Equal(t, int32(4), result.ClientIPAttempt.FailedCount)
+}
+
+func TestStore_ResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username, time.Hour)
+	_, code := createRandomPasswordReset(t, user.Username, time.Minute)
+	_, otherCode := createRandomPasswordReset(t, user.Username, time.Minute)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(10))
+	require.NoError(t, err)
+	result, err := store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		HashedCode:     util.HashSecretCode(code),
+		HashedPassword: hashedPassword,
+	})
+	require.NoError(t, err)
+	require.Equal(t, hashedPassword, result.User.HashedPassword)
+	require.True(t, result.User.PasswordChangedAt.Time.After(user.PasswordChangedAt.Time))
+	require.Equal(t, int64(1), result.RevokedSessions)
+
+	session, err = store.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, session.IsBlocked)
+
+	// Using a code burns the other codes of the user
+	_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		HashedCode:     util.HashSecretCode(otherCode),
+		HashedPassword: hashedPassword,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestStore_VerifyEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	oldEmail := createVerifyEmailFor(t, user.Username, user.Email)
+
+	// A new email is only stored as pending, the current one stays in use
+	newEmail := util.RandomEmail()
+	updated, err := store.UpdateUserTx(context.Background(), UpdateUserTxParams{
+		UpdateUserParams: UpdateUserParams{Username: user.Username},
+		PendingEmail:     pgtype.Text{String: newEmail, Valid: true},
+		AfterUpdate: func(user User) error {
+			require.Equal(t, newEmail, user.PendingEmail.String)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Email, updated.User.Email)
+	require.False(t, updated.User.IsEmailVerified)
+
+	// A link sent to the old address before the change no longer verifies anything
+	_, err = store.VerifyEmailTx(context.Background(), VerifyEmailTxParams{
+		EmailID:    oldEmail.ID,
+		SecretCode: oldEmail.SecretCode,
+	})
+	require.ErrorIs(t, err, ErrEmailChanged)
+
+	verifyEmail := createVerifyEmailFor(t, user.Username, newEmail)
+	result, err := store.VerifyEmailTx(context.Background(), VerifyEmailTxParams{
+		EmailID:    verifyEmail.ID,
+		SecretCode: verifyEmail.SecretCode,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Email, result.PreviousEmail)
+	require.Equal(t, newEmail, result.User.Email)
+	require.False(t, result.User.PendingEmail.Valid)
+	require.True(t, result.User.IsEmailVerified)
+}
+
+func createVerifyEmailFor(t *testing.T, username string, email string) VerifyEmail {
+	verifyEmail, err := testQueries.CreateVerifyEmail(context.Background(), CreateVerifyEmailParams{
+		Username:   username,
+		Email:      email,
+		SecretCode: util.RandomString(32),
+	})
+	require.NoError(t, err)
+	return verifyEmail
+}
+
+func TestStore_ReviewKYCTx(t *testing.T) {
+	store := NewStore(testDB)
+	admin := createRandomUser(t)
+
+	submission := createRandomKYCSubmission(t, createRandomUser(t).Username, 2)
+	result, err := store.ReviewKYCTx(context.Background(), ReviewKYCTxParams{
+		ID:         submission.ID,
+		Approved:   true,
+		ReviewedBy: admin.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, KYCStatusApproved, result.Submission.Status)
+	require.Equal(t, admin.Username, result.Submission.ReviewedBy.String)
+	require.True(t, result.Submission.ReviewedAt.Valid)
+	require.Equal(t, submission.Tier, result.User.KycTier)
+
+	// a reviewed submission cannot be reviewed again
+	_, err = store.ReviewKYCTx(context.Background(), ReviewKYCTxParams{
+		ID:         submission.ID,
+		Approved:   false,
+		ReviewedBy: admin.Username,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	rejected := createRandomKYCSubmission(t, createRandomUser(t).Username, 1)
+	result, err = store.ReviewKYCTx(context.Background(), ReviewKYCTxParams{
+		ID:         rejected.ID,
+		Approved:   false,
+		ReviewedBy: admin.Username,
+		ReviewNote: "document expired",
+	})
+	require.NoError(t, err)
+	require.Equal(t, KYCStatusRejected, result.Submission.Status)
+	require.Zero(t, result.User.KycTier)
+}
+
+func TestStore_ClaimDueScheduledTransfersTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	now := time.Now().UTC()
+	// a daily transfer that missed its last two runs
+	missed := now.Add(-50 * time.Hour).Truncate(time.Second)
+	due := createRandomScheduledTransfer(t, account1, account2, missed)
+	notDue := createRandomScheduledTransfer(t, account1, account2, now.Add(time.Hour))
+
+	// a failed enqueue releases the claims
+	errEnqueue := errors.New("cannot enqueue task")
+	_, err := store.ClaimDueScheduledTransfersTx(context.Background(), ClaimDueScheduledTransfersTxParams{
+		Now:   now,
+		Limit: 1000,
+		AfterClaim: func(scheduledTransfer ScheduledTransfer) error {
+			return errEnqueue
+		},
+	})
+	require.ErrorIs(t, err, errEnqueue)
+	unchanged, err := testQueries.GetScheduledTransfer(context.Background(), due.ID)
+	require.NoError(t, err)
+	require.Equal(t, due.NextRunAt, unchanged.NextRunAt)
+
+	claimed := make(map[int64]ScheduledTransfer)
+	result, err := store.ClaimDueScheduledTransfersTx(context.Background(), ClaimDueScheduledTransfersTxParams{
+		Now:   now,
+		Limit: 1000,
+		AfterClaim: func(scheduledTransfer ScheduledTransfer) error {
+			claimed[scheduledTransfer.ID] = scheduledTransfer
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Claimed, len(claimed))
+	require.Contains(t, claimed, due.ID)
+	require.NotContains(t, claimed, notDue.ID)
+	require.WithinDuration(t, missed, claimed[due.ID].NextRunAt.Time, time.Second)
+
+	// the next run skips the missed runs and keeps the time of day
+	advanced, err := testQueries.GetScheduledTransfer(context.Background(), due.ID)
+	require.NoError(t, err)
+	require.WithinDuration(t, missed.Add(72*time.Hour), advanced.NextRunAt.Time, time.Second)
+}
+
+func TestStore_ExecuteScheduledTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := fundAccount(t, createRandomAccount(t), 1000)
+	account2 := createRandomAccount(t)
+	scheduledTransfer := createRandomScheduledTransfer(t, account1, account2, time.Now())
+	scheduledFor := scheduledTransfer.NextRunAt.Time
+
+	result, err := store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{
+		ScheduledTransfer: scheduledTransfer,
+		ScheduledFor:      scheduledFor,
+	})
+	require.NoError(t, err)
+	require.Equal(t, scheduledTransfer.Amount, result.Transfer.Amount)
+	require.Equal(t, account1.Balance-scheduledTransfer.Amount, result.FromAccount.Balance)
+	require.Equal(t, ScheduledTransferRunSucceeded, result.Run.Status)
+	require.Equal(t, pgtype.Int8{Int64: result.Transfer.ID, Valid: true}, result.Run.TransferID)
+
+	// a retried occurrence does not transfer again
+	_, err = store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{
+		ScheduledTransfer: scheduledTransfer,
+		ScheduledFor:      scheduledFor,
+	})
+	require.ErrorIs(t, err, ErrScheduledTransferAlreadyRun)
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.FromAccount.Balance, updatedAccount1.Balance)
+
+	// a failed transfer leaves the occurrence without a run
+	scheduledTransfer.Amount = updatedAccount1.Balance + 1
+	nextOccurrence := scheduledFor.Add(24 * time.Hour)
+	_, err = store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{
+		ScheduledTransfer: scheduledTransfer,
+		ScheduledFor:      nextOccurrence,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	runs, err := testQueries.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Limit:               5,
+		Offset:              0,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+}
+
+func TestStore_AuthorizeCaptureTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := fundAccount(t, createEmptyAccount(t, util.USD), 1000)
+	account2 := createEmptyAccount(t, util.USD)
+	schedule := &fees.Schedule{Currencies: map[string]fees.Rule{util.USD: {Flat: 10}}}
+
+	authorized, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        500,
+		HoldExpiresAt: time.Now().Add(time.Hour),
+		Fees:          schedule,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusPending, authorized.Transfer.Status)
+	require.Equal(t, pgtype.Int8{Int64: 500, Valid: true}, authorized.Transfer.AuthorizedAmount)
+	require.Equal(t, int64(10), authorized.Transfer.Fee)
+
+	// the hold lowers the available balance, the ledger balance does not move
+	require.Equal(t, int64(1000), authorized.FromAccount.Balance)
+	require.Equal(t, int64(490), authorized.FromAccount.AvailableBalance)
+
+	// the held funds cannot be spent twice
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        491,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.CaptureTransferTx(context.Background(), CaptureTransferTxParams{
+		TransferID: authorized.Transfer.ID,
+		Amount:     501,
+		Fees:       schedule,
+	})
+	require.ErrorIs(t, err, ErrCaptureExceedsAuthorization)
+
+	// a partial capture posts the captured amount and releases the rest of the hold
+	result, err := store.CaptureTransferTx(context.Background(), CaptureTransferTxParams{
+		TransferID: authorized.Transfer.ID,
+		Amount:     300,
+		Fees:       schedule,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusPosted, result.Transfer.Status)
+	require.Equal(t, int64(300), result.Transfer.Amount)
+	require.Equal(t, int64(-300), result.FromEntry.Amount)
+	require.Equal(t, int64(300), result.ToEntry.Amount)
+	require.NotNil(t, result.Fee)
+	require.Equal(t, int64(690), result.FromAccount.Balance)
+	require.Equal(t, int64(690), result.FromAccount.AvailableBalance)
+	require.Equal(t, int64(300), result.ToAccount.Balance)
+	require.Equal(t, int64(300), result.ToAccount.AvailableBalance)
+
+	_, err = store.CaptureTransferTx(context.Background(), CaptureTransferTxParams{
+		TransferID: authorized.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrTransferNotPending)
+}
+
+func TestStore_VoidTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := fundAccount(t, createEmptyAccount(t, util.USD), 1000)
+	account2 := createEmptyAccount(t, util.USD)
+
+	authorized, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1000,
+		HoldExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Zero(t, authorized.FromAccount.AvailableBalance)
+
+	result, err := store.VoidTransferTx(context.Background(), VoidTransferTxParams{TransferID: authorized.Transfer.ID})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusVoided, result.Transfer.Status)
+	require.Equal(t, int64(1000), result.FromAccount.Balance)
+	require.Equal(t, int64(1000), result.FromAccount.AvailableBalance)
+
+	_, err = store.VoidTransferTx(context.Background(), VoidTransferTxParams{TransferID: authorized.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferNotPending)
+
+	// voided transfers do not count against the limits
+	sent, err := testQueries.SumTransfersSince(context.Background(), SumTransfersSinceParams{
+		FromAccountID: account1.ID,
+		Since:         pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+	require.Zero(t, sent.Total)
+	require.Zero(t, sent.Count)
+}
+
+func TestStore_ExpireTransfersTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := fundAccount(t, createEmptyAccount(t, util.USD), 1000)
+	account2 := createEmptyAccount(t, util.USD)
+
+	expiring, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        300,
+		HoldExpiresAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	active, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        200,
+		HoldExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(500), active.FromAccount.AvailableBalance)
+
+	_, err = store.CaptureTransferTx(context.Background(), CaptureTransferTxParams{TransferID: expiring.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferHoldExpired)
+
+	for {
+		result, err := store.ExpireTransfersTx(context.Background(), ExpireTransfersTxParams{
+			Now:   time.Now(),
+			Limit: 100,
+		})
+		require.NoError(t, err)
+		if len(result.Expired) < 100 {
+			break
+		}
+	}
+
+	transfer, err := testQueries.GetTransferById(context.Background(), expiring.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusExpired, transfer.Status)
+	transfer, err = testQueries.GetTransferById(context.Background(), active.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusPending, transfer.Status)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), updatedAccount1.Balance)
+	require.Equal(t, int64(800), updatedAccount1.AvailableBalance)
+}
+
+func TestStore_ReverseTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := fundAccount(t, createEmptyAccount(t, util.USD), 1000)
+	account2 := createEmptyAccount(t, util.USD)
+
+	transferred, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	// a partial refund moves the money back and links the reversal to the original transfer
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transferred.Transfer.ID,
+		Amount:     40,
+	})
+	require.NoError(t, err)
+	require.Equal(t, pgtype.Int8{Int64: transferred.Transfer.ID, Valid: true}, result.Transfer.ReversalOf)
+	require.Equal(t, account2.ID, result.Transfer.FromAccountID)
+	require.Equal(t, account1.ID, result.Transfer.ToAccountID)
+	require.Equal(t, int64(40), result.Original.ReversedAmount)
+	require.Equal(t, int64(-40), result.FromEntry.Amount)
+	require.Equal(t, int64(40), result.ToEntry.Amount)
+	require.Equal(t, result.FromEntry.JournalID, result.ToEntry.JournalID)
+	require.Equal(t, int64(60), result.FromAccount.Balance)
+	require.Equal(t, int64(940), result.ToAccount.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transferred.Transfer.ID,
+		Amount:     61,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsAmount)
+
+	// reversals cannot be reversed
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: result.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferNotReversible)
+
+	// without an amount the rest of the transfer is reversed, once
+	result, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: transferred.Transfer.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(60), result.Transfer.Amount)
+	require.Equal(t, int64(100), result.Original.ReversedAmount)
+	require.Zero(t, result.FromAccount.Balance)
+	require.Equal(t, int64(1000), result.ToAccount.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: transferred.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferAlreadyReversed)
+
+	reversals, err := testQueries.ListTransferReversals(context.Background(), pgtype.Int8{Int64: transferred.Transfer.ID, Valid: true})
+	require.NoError(t, err)
+	require.Len(t, reversals, 2)
+}
+
+func TestStore_ReverseTransferTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := fundAccount(t, createEmptyAccount(t, util.USD), 1000)
+	account2 := createEmptyAccount(t, util.USD)
+
+	transferred, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	n := 5
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: transferred.Transfer.ID})
+			errs <- err
+		}()
+	}
+
+	reversed := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			reversed++
+			continue
+		}
+		require.ErrorIs(t, err, ErrTransferAlreadyReversed)
+	}
+	require.Equal(t, 1, reversed)
+
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Zero(t, updatedAccount2.Balance)
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestUpsertAccountTransferLimits(t *testing.T) {
+	account := createRandomAccount(t)
+
+	_, err := testQueries.GetAccountTransferLimits(context.Background(), account.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	arg := UpsertAccountTransferLimitsParams{
+		AccountID: account.ID,
+		Daily:     pgtype.Int8{Int64: 1000, Valid: true},
+	}
+	limits, err := testQueries.UpsertAccountTransferLimits(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, account.ID, limits.AccountID)
+	require.Equal(t, arg.Daily, limits.Daily)
+	require.False(t, limits.PerTransfer.Valid)
+
+	// the overrides are replaced as a whole
+	arg = UpsertAccountTransferLimitsParams{
+		AccountID:   account.ID,
+		HourlyCount: pgtype.Int8{Int64: 3, Valid: true},
+	}
+	_, err = testQueries.UpsertAccountTransferLimits(context.Background(), arg)
+	require.NoError(t, err)
+
+	limits, err = testQueries.GetAccountTransferLimits(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.False(t, limits.Daily.Valid)
+	require.Equal(t, arg.HourlyCount, limits.HourlyCount)
+
+	_, err = testQueries.UpsertAccountTransferLimits(context.Background(), UpsertAccountTransferLimitsParams{
+		AccountID: account.ID,
+		Weekly:    pgtype.Int8{Int64: -1, Valid: true},
+	})
+	require.Error(t, err)
+}
@@ -7,27 +7,167 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/the-eduardo/Go-Bank/fx"
 )
 
+const addTransferReversedAmount = `-- name: AddTransferReversedAmount :one
+UPDATE transfers
+SET reversed_amount = reversed_amount + $1
+WHERE id = $2
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount
+`
+
+type AddTransferReversedAmountParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+// AddTransferReversedAmount counts a refund against the transfer it gives back
+func (q *Queries) AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error) {
+	row := q.db.QueryRow(ctx, addTransferReversedAmount, arg.Amount, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.QuoteID,
+		&i.Fee,
+		&i.Status,
+		&i.AuthorizedAmount,
+		&i.HoldExpiresAt,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+	)
+	return i, err
+}
+
+const captureTransfer = `-- name: CaptureTransfer :one
+UPDATE transfers
+SET amount = $1,
+    to_amount = $1,
+    fee = $2,
+    status = 'posted'
+WHERE id = $3 AND status = 'pending'
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount
+`
+
+type CaptureTransferParams struct {
+	Amount int64 `json:"amount"`
+	Fee    int64 `json:"fee"`
+	ID     int64 `json:"id"`
+}
+
+// CaptureTransfer posts a pending transfer with the captured amount
+func (q *Queries) CaptureTransfer(ctx context.Context, arg CaptureTransferParams) (Transfer, error) {
+	row := q.db.QueryRow(ctx, captureTransfer, arg.Amount, arg.Fee, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.QuoteID,
+		&i.Fee,
+		&i.Status,
+		&i.AuthorizedAmount,
+		&i.HoldExpiresAt,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+	)
+	return i, err
+}
+
+const createFXTransfer = `-- name: CreateFXTransfer :one
+INSERT INTO transfers (
+    from_account_id,
+    to_account_id,
+    amount,
+    to_amount,
+    exchange_rate,
+    quote_id,
+    fee
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount
+`
+
+type CreateFXTransferParams struct {
+	FromAccountID int64           `json:"from_account_id"`
+	ToAccountID   int64           `json:"to_account_id"`
+	Amount        int64           `json:"amount"`
+	ToAmount      int64           `json:"to_amount"`
+	ExchangeRate  fx.ExchangeRate `json:"exchange_rate"`
+	QuoteID       pgtype.UUID     `json:"quote_id"`
+	Fee           int64           `json:"fee"`
+}
+
+// CreateFXTransfer records a transfer between accounts with different currencies
+func (q *Queries) CreateFXTransfer(ctx context.Context, arg CreateFXTransferParams) (Transfer, error) {
+	row := q.db.QueryRow(ctx, createFXTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.ExchangeRate,
+		arg.QuoteID,
+		arg.Fee,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.QuoteID,
+		&i.Fee,
+		&i.Status,
+		&i.AuthorizedAmount,
+		&i.HoldExpiresAt,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+	)
+	return i, err
+}
+
 const createNewTransfer = `-- name: CreateNewTransfer :one
 INSERT INTO transfers (
     from_account_id,
     to_account_id,
-    amount
+    amount,
+    to_amount,
+    fee
 ) VALUES (
-    $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+    $1, $2, $3, $3, $4
+) RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount
 `
 
 type CreateNewTransferParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	Fee           int64 `json:"fee"`
 }
 
 // noinspection SqlResolveForFile
 func (q *Queries) CreateNewTransfer(ctx context.Context, arg CreateNewTransferParams) (Transfer, error) {
-	row := q.db.QueryRow(ctx, createNewTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createNewTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Fee,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -35,12 +175,120 @@ func (q *Queries) CreateNewTransfer(ctx context.Context, arg CreateNewTransferPa
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.QuoteID,
+		&i.Fee,
+		&i.Status,
+		&i.AuthorizedAmount,
+		&i.HoldExpiresAt,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+	)
+	return i, err
+}
+
+const createPendingTransfer = `-- name: CreatePendingTransfer :one
+INSERT INTO transfers (
+    from_account_id,
+    to_account_id,
+    amount,
+    to_amount,
+    fee,
+    status,
+    authorized_amount,
+    hold_expires_at
+) VALUES (
+    $1, $2, $3, $3, $4, 'pending', $3, $5
+) RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount
+`
+
+type CreatePendingTransferParams struct {
+	FromAccountID int64              `json:"from_account_id"`
+	ToAccountID   int64              `json:"to_account_id"`
+	Amount        int64              `json:"amount"`
+	Fee           int64              `json:"fee"`
+	HoldExpiresAt pgtype.Timestamptz `json:"hold_expires_at"`
+}
+
+// CreatePendingTransfer records an authorized transfer, its amount and fee are held until it is captured
+func (q *Queries) CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (Transfer, error) {
+	row := q.db.QueryRow(ctx, createPendingTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Fee,
+		arg.HoldExpiresAt,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.QuoteID,
+		&i.Fee,
+		&i.Status,
+		&i.AuthorizedAmount,
+		&i.HoldExpiresAt,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+	)
+	return i, err
+}
+
+const createReversalTransfer = `-- name: CreateReversalTransfer :one
+INSERT INTO transfers (
+    from_account_id,
+    to_account_id,
+    amount,
+    to_amount,
+    reversal_of
+) VALUES (
+    $1, $2, $3, $3, $4
+) RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount
+`
+
+type CreateReversalTransferParams struct {
+	FromAccountID int64       `json:"from_account_id"`
+	ToAccountID   int64       `json:"to_account_id"`
+	Amount        int64       `json:"amount"`
+	ReversalOf    pgtype.Int8 `json:"reversal_of"`
+}
+
+// CreateReversalTransfer records a refund of a transfer, from its destination back to its source
+func (q *Queries) CreateReversalTransfer(ctx context.Context, arg CreateReversalTransferParams) (Transfer, error) {
+	row := q.db.QueryRow(ctx, createReversalTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ReversalOf,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.QuoteID,
+		&i.Fee,
+		&i.Status,
+		&i.AuthorizedAmount,
+		&i.HoldExpiresAt,
+		&i.ReversalOf,
+		&i.ReversedAmount,
 	)
 	return i, err
 }
 
 const getTransferById = `-- name: GetTransferById :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount FROM transfers
 WHERE id = $1
 `
 
@@ -54,27 +302,63 @@ func (q *Queries) GetTransferById(ctx context.Context, id int64) (Transfer, erro
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.QuoteID,
+		&i.Fee,
+		&i.Status,
+		&i.AuthorizedAmount,
+		&i.HoldExpiresAt,
+		&i.ReversalOf,
+		&i.ReversedAmount,
 	)
 	return i, err
 }
 
-const listTransfersByAccountId = `-- name: ListTransfersByAccountId :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
-WHERE from_account_id = $1 OR to_account_id = $1
-ORDER BY created_at DESC
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRow(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.QuoteID,
+		&i.Fee,
+		&i.Status,
+		&i.AuthorizedAmount,
+		&i.HoldExpiresAt,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+	)
+	return i, err
+}
+
+const listExpiredTransfersForUpdate = `-- name: ListExpiredTransfersForUpdate :many
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount FROM transfers
+WHERE status = 'pending' AND hold_expires_at <= $1
+ORDER BY hold_expires_at
 LIMIT $2
-OFFSET $3
+FOR UPDATE SKIP LOCKED
 `
 
-type ListTransfersByAccountIdParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	Limit         int64 `json:"limit"`
-	Offset        int64 `json:"offset"`
+type ListExpiredTransfersForUpdateParams struct {
+	HoldExpiresAt pgtype.Timestamptz `json:"hold_expires_at"`
+	Limit         int64              `json:"limit"`
 }
 
-// ListTransfersByAccountId returns a list of transfers for a given account ID
-func (q *Queries) ListTransfersByAccountId(ctx context.Context, arg ListTransfersByAccountIdParams) ([]Transfer, error) {
-	rows, err := q.db.Query(ctx, listTransfersByAccountId, arg.FromAccountID, arg.Limit, arg.Offset)
+// ListExpiredTransfersForUpdate skips the holds another worker is already releasing
+func (q *Queries) ListExpiredTransfersForUpdate(ctx context.Context, arg ListExpiredTransfersForUpdateParams) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, listExpiredTransfersForUpdate, arg.HoldExpiresAt, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -88,6 +372,15 @@ func (q *Queries) ListTransfersByAccountId(ctx context.Context, arg ListTransfer
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.QuoteID,
+			&i.Fee,
+			&i.Status,
+			&i.AuthorizedAmount,
+			&i.HoldExpiresAt,
+			&i.ReversalOf,
+			&i.ReversedAmount,
 		); err != nil {
 			return nil, err
 		}
@@ -98,3 +391,482 @@ func (q *Queries) ListTransfersByAccountId(ctx context.Context, arg ListTransfer
 	}
 	return items, nil
 }
+
+const listTransferReversals = `-- name: ListTransferReversals :many
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount FROM transfers
+WHERE reversal_of = $1
+ORDER BY id
+`
+
+func (q *Queries) ListTransferReversals(ctx context.Context, reversalOf pgtype.Int8) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, listTransferReversals, reversalOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.QuoteID,
+			&i.Fee,
+			&i.Status,
+			&i.AuthorizedAmount,
+			&i.HoldExpiresAt,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersLargestFirst = `-- name: ListTransfersLargestFirst :many
+(SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount FROM transfers
+WHERE from_account_id = $1
+  AND (amount, id) < ($2::bigint, $3::bigint)
+  AND ($4::timestamptz IS NULL OR created_at >= $4)
+  AND ($5::timestamptz IS NULL OR created_at < $5)
+  AND ($6::bigint IS NULL OR amount >= $6)
+  AND ($7::bigint IS NULL OR amount <= $7)
+  AND ($8::text IS NULL OR $8 = 'out')
+  AND ($9::bigint IS NULL OR to_account_id = $9)
+ORDER BY amount DESC, id DESC
+LIMIT $10)
+UNION ALL
+(SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount FROM transfers
+WHERE to_account_id = $1 AND from_account_id <> $1
+  AND (to_amount, id) < ($2::bigint, $3::bigint)
+  AND ($4::timestamptz IS NULL OR created_at >= $4)
+  AND ($5::timestamptz IS NULL OR created_at < $5)
+  AND ($6::bigint IS NULL OR to_amount >= $6)
+  AND ($7::bigint IS NULL OR to_amount <= $7)
+  AND ($8::text IS NULL OR $8 = 'in')
+  AND ($9::bigint IS NULL OR from_account_id = $9)
+ORDER BY to_amount DESC, id DESC
+LIMIT $10)
+`
+
+type ListTransfersLargestFirstParams struct {
+	AccountID             int64              `json:"account_id"`
+	CursorAmount          int64              `json:"cursor_amount"`
+	CursorID              int64              `json:"cursor_id"`
+	StartTime             pgtype.Timestamptz `json:"start_time"`
+	EndTime               pgtype.Timestamptz `json:"end_time"`
+	MinAmount             pgtype.Int8        `json:"min_amount"`
+	MaxAmount             pgtype.Int8        `json:"max_amount"`
+	Direction             pgtype.Text        `json:"direction"`
+	CounterpartyAccountID pgtype.Int8        `json:"counterparty_account_id"`
+	Limit                 int64              `json:"limit"`
+}
+
+// ListTransfersLargestFirst returns the transfers of an account below the cursor by the amount seen by the account then id,
+// largest first, with the filters of ListTransfersNewestFirst
+func (q *Queries) ListTransfersLargestFirst(ctx context.Context, arg ListTransfersLargestFirstParams) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, listTransfersLargestFirst,
+		arg.AccountID,
+		arg.CursorAmount,
+		arg.CursorID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Direction,
+		arg.CounterpartyAccountID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.QuoteID,
+			&i.Fee,
+			&i.Status,
+			&i.AuthorizedAmount,
+			&i.HoldExpiresAt,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersNewestFirst = `-- name: ListTransfersNewestFirst :many
+(SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount FROM transfers
+WHERE from_account_id = $1
+  AND (created_at, id) < ($2::timestamptz, $3::bigint)
+  AND ($4::timestamptz IS NULL OR created_at >= $4)
+  AND ($5::timestamptz IS NULL OR created_at < $5)
+  AND ($6::bigint IS NULL OR amount >= $6)
+  AND ($7::bigint IS NULL OR amount <= $7)
+  AND ($8::text IS NULL OR $8 = 'out')
+  AND ($9::bigint IS NULL OR to_account_id = $9)
+ORDER BY created_at DESC, id DESC
+LIMIT $10)
+UNION ALL
+(SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount FROM transfers
+WHERE to_account_id = $1 AND from_account_id <> $1
+  AND (created_at, id) < ($2::timestamptz, $3::bigint)
+  AND ($4::timestamptz IS NULL OR created_at >= $4)
+  AND ($5::timestamptz IS NULL OR created_at < $5)
+  AND ($6::bigint IS NULL OR to_amount >= $6)
+  AND ($7::bigint IS NULL OR to_amount <= $7)
+  AND ($8::text IS NULL OR $8 = 'in')
+  AND ($9::bigint IS NULL OR from_account_id = $9)
+ORDER BY created_at DESC, id DESC
+LIMIT $10)
+`
+
+type ListTransfersNewestFirstParams struct {
+	AccountID             int64              `json:"account_id"`
+	CursorCreatedAt       pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID              int64              `json:"cursor_id"`
+	StartTime             pgtype.Timestamptz `json:"start_time"`
+	EndTime               pgtype.Timestamptz `json:"end_time"`
+	MinAmount             pgtype.Int8        `json:"min_amount"`
+	MaxAmount             pgtype.Int8        `json:"max_amount"`
+	Direction             pgtype.Text        `json:"direction"`
+	CounterpartyAccountID pgtype.Int8        `json:"counterparty_account_id"`
+	Limit                 int64              `json:"limit"`
+}
+
+// ListTransfersNewestFirst returns the transfers sent and received by an account created before the cursor, newest first.
+// The filters left null are skipped, direction is 'in' for the transfers received and 'out' for the ones sent,
+// and the amounts are the ones seen by the account, so a received FX transfer is compared by its converted amount.
+// Each side is read from its own keyset index and returns up to limit rows, the ListTransfersByAccountId wrapper merges them.
+func (q *Queries) ListTransfersNewestFirst(ctx context.Context, arg ListTransfersNewestFirstParams) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, listTransfersNewestFirst,
+		arg.AccountID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Direction,
+		arg.CounterpartyAccountID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.QuoteID,
+			&i.Fee,
+			&i.Status,
+			&i.AuthorizedAmount,
+			&i.HoldExpiresAt,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersOldestFirst = `-- name: ListTransfersOldestFirst :many
+(SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount FROM transfers
+WHERE from_account_id = $1
+  AND (created_at, id) > ($2::timestamptz, $3::bigint)
+  AND ($4::timestamptz IS NULL OR created_at >= $4)
+  AND ($5::timestamptz IS NULL OR created_at < $5)
+  AND ($6::bigint IS NULL OR amount >= $6)
+  AND ($7::bigint IS NULL OR amount <= $7)
+  AND ($8::text IS NULL OR $8 = 'out')
+  AND ($9::bigint IS NULL OR to_account_id = $9)
+ORDER BY created_at ASC, id ASC
+LIMIT $10)
+UNION ALL
+(SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount FROM transfers
+WHERE to_account_id = $1 AND from_account_id <> $1
+  AND (created_at, id) > ($2::timestamptz, $3::bigint)
+  AND ($4::timestamptz IS NULL OR created_at >= $4)
+  AND ($5::timestamptz IS NULL OR created_at < $5)
+  AND ($6::bigint IS NULL OR to_amount >= $6)
+  AND ($7::bigint IS NULL OR to_amount <= $7)
+  AND ($8::text IS NULL OR $8 = 'in')
+  AND ($9::bigint IS NULL OR from_account_id = $9)
+ORDER BY created_at ASC, id ASC
+LIMIT $10)
+`
+
+type ListTransfersOldestFirstParams struct {
+	AccountID             int64              `json:"account_id"`
+	CursorCreatedAt       pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID              int64              `json:"cursor_id"`
+	StartTime             pgtype.Timestamptz `json:"start_time"`
+	EndTime               pgtype.Timestamptz `json:"end_time"`
+	MinAmount             pgtype.Int8        `json:"min_amount"`
+	MaxAmount             pgtype.Int8        `json:"max_amount"`
+	Direction             pgtype.Text        `json:"direction"`
+	CounterpartyAccountID pgtype.Int8        `json:"counterparty_account_id"`
+	Limit                 int64              `json:"limit"`
+}
+
+// ListTransfersOldestFirst returns the transfers of an account created after the cursor, oldest first, with the filters of ListTransfersNewestFirst
+func (q *Queries) ListTransfersOldestFirst(ctx context.Context, arg ListTransfersOldestFirstParams) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, listTransfersOldestFirst,
+		arg.AccountID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Direction,
+		arg.CounterpartyAccountID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.QuoteID,
+			&i.Fee,
+			&i.Status,
+			&i.AuthorizedAmount,
+			&i.HoldExpiresAt,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersSmallestFirst = `-- name: ListTransfersSmallestFirst :many
+(SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount FROM transfers
+WHERE from_account_id = $1
+  AND (amount, id) > ($2::bigint, $3::bigint)
+  AND ($4::timestamptz IS NULL OR created_at >= $4)
+  AND ($5::timestamptz IS NULL OR created_at < $5)
+  AND ($6::bigint IS NULL OR amount >= $6)
+  AND ($7::bigint IS NULL OR amount <= $7)
+  AND ($8::text IS NULL OR $8 = 'out')
+  AND ($9::bigint IS NULL OR to_account_id = $9)
+ORDER BY amount ASC, id ASC
+LIMIT $10)
+UNION ALL
+(SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount FROM transfers
+WHERE to_account_id = $1 AND from_account_id <> $1
+  AND (to_amount, id) > ($2::bigint, $3::bigint)
+  AND ($4::timestamptz IS NULL OR created_at >= $4)
+  AND ($5::timestamptz IS NULL OR created_at < $5)
+  AND ($6::bigint IS NULL OR to_amount >= $6)
+  AND ($7::bigint IS NULL OR to_amount <= $7)
+  AND ($8::text IS NULL OR $8 = 'in')
+  AND ($9::bigint IS NULL OR from_account_id = $9)
+ORDER BY to_amount ASC, id ASC
+LIMIT $10)
+`
+
+type ListTransfersSmallestFirstParams struct {
+	AccountID             int64              `json:"account_id"`
+	CursorAmount          int64              `json:"cursor_amount"`
+	CursorID              int64              `json:"cursor_id"`
+	StartTime             pgtype.Timestamptz `json:"start_time"`
+	EndTime               pgtype.Timestamptz `json:"end_time"`
+	MinAmount             pgtype.Int8        `json:"min_amount"`
+	MaxAmount             pgtype.Int8        `json:"max_amount"`
+	Direction             pgtype.Text        `json:"direction"`
+	CounterpartyAccountID pgtype.Int8        `json:"counterparty_account_id"`
+	Limit                 int64              `json:"limit"`
+}
+
+// ListTransfersSmallestFirst returns the transfers of an account above the cursor by the amount seen by the account then id,
+// smallest first, with the filters of ListTransfersNewestFirst
+func (q *Queries) ListTransfersSmallestFirst(ctx context.Context, arg ListTransfersSmallestFirstParams) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, listTransfersSmallestFirst,
+		arg.AccountID,
+		arg.CursorAmount,
+		arg.CursorID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Direction,
+		arg.CounterpartyAccountID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.QuoteID,
+			&i.Fee,
+			&i.Status,
+			&i.AuthorizedAmount,
+			&i.HoldExpiresAt,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const releaseTransfer = `-- name: ReleaseTransfer :one
+UPDATE transfers
+SET status = $1
+WHERE id = $2 AND status = 'pending'
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, quote_id, fee, status, authorized_amount, hold_expires_at, reversal_of, reversed_amount
+`
+
+type ReleaseTransferParams struct {
+	Status string `json:"status"`
+	ID     int64  `json:"id"`
+}
+
+// ReleaseTransfer closes a pending transfer without posting it
+func (q *Queries) ReleaseTransfer(ctx context.Context, arg ReleaseTransferParams) (Transfer, error) {
+	row := q.db.QueryRow(ctx, releaseTransfer, arg.Status, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.QuoteID,
+		&i.Fee,
+		&i.Status,
+		&i.AuthorizedAmount,
+		&i.HoldExpiresAt,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+	)
+	return i, err
+}
+
+const sumTransfersSince = `-- name: SumTransfersSince :one
+SELECT COALESCE(sum(amount), 0)::bigint AS total, count(*) AS count
+FROM transfers
+WHERE from_account_id = $1 AND created_at >= $2 AND status IN ('pending', 'posted')
+  AND reversal_of IS NULL
+`
+
+type SumTransfersSinceParams struct {
+	FromAccountID int64              `json:"from_account_id"`
+	Since         pgtype.Timestamptz `json:"since"`
+}
+
+type SumTransfersSinceRow struct {
+	Total int64 `json:"total"`
+	Count int64 `json:"count"`
+}
+
+// SumTransfersSince returns the total and the number of the transfers sent by an account since a time,
+// pending transfers count until their hold is released, refunds do not count
+func (q *Queries) SumTransfersSince(ctx context.Context, arg SumTransfersSinceParams) (SumTransfersSinceRow, error) {
+	row := q.db.QueryRow(ctx, sumTransfersSince, arg.FromAccountID, arg.Since)
+	var i SumTransfersSinceRow
+	err := row.Scan(&i.Total, &i.Count)
+	return i, err
+}
+
+const sumUserTransfersSince = `-- name: SumUserTransfersSince :one
+SELECT COALESCE(sum(t.amount) FILTER (WHERE a.currency = $1), 0)::bigint AS total, count(*) AS count
+FROM transfers t
+JOIN accounts a ON a.id = t.from_account_id
+WHERE a.owner = $2 AND t.created_at >= $3 AND t.status IN ('pending', 'posted')
+  AND t.reversal_of IS NULL
+`
+
+type SumUserTransfersSinceParams struct {
+	Currency string             `json:"currency"`
+	Owner    string             `json:"owner"`
+	Since    pgtype.Timestamptz `json:"since"`
+}
+
+type SumUserTransfersSinceRow struct {
+	Total int64 `json:"total"`
+	Count int64 `json:"count"`
+}
+
+// SumUserTransfersSince returns the total sent by the accounts of a user in a currency since a time,
+// and the number of the transfers sent by all of their accounts, refunds do not count
+func (q *Queries) SumUserTransfersSince(ctx context.Context, arg SumUserTransfersSinceParams) (SumUserTransfersSinceRow, error) {
+	row := q.db.QueryRow(ctx, sumUserTransfersSince, arg.Currency, arg.Owner, arg.Since)
+	var i SumUserTransfersSinceRow
+	err := row.Scan(&i.Total, &i.Count)
+	return i, err
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 	"github.com/the-eduardo/Go-Bank/util"
 	"testing"
@@ -54,9 +55,9 @@ func TestListTransfersByAccountId(t *testing.T) {
 	account1 := createRandomAccount(t)
 	account2 := createRandomAccount(t)
 	listParams := ListTransfersByAccountIdParams{
-		FromAccountID: account1.ID,
-		Limit:         5,
-		Offset:        5,
+		AccountID: account1.ID,
+		Limit:     5,
+		Offset:    5,
 	}
 	for i := 0; i < 10; i++ {
 		createRandomTransfer(account1, account2, t)
@@ -70,3 +71,63 @@ func TestListTransfersByAccountId(t *testing.T) {
 	}
 
 }
+
+func TestListTransfersByAccountIdFilters(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account3 := createRandomAccount(t)
+
+	var sent []Transfer
+	for _, amount := range []int64{30, 10, 20} {
+		transfer, err := testQueries.CreateNewTransfer(context.Background(), CreateNewTransferParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+		})
+		require.NoError(t, err)
+		sent = append(sent, transfer)
+	}
+	received := createRandomTransfer(account3, account1, t)
+
+	listTransfers := func(arg ListTransfersByAccountIdParams) []Transfer {
+		arg.AccountID = account1.ID
+		arg.Limit = 10
+		transfers, err := testQueries.ListTransfersByAccountId(context.Background(), arg)
+		require.NoError(t, err)
+		return transfers
+	}
+	transferIDs := func(transfers []Transfer) []int64 {
+		ids := make([]int64, len(transfers))
+		for i, transfer := range transfers {
+			ids[i] = transfer.ID
+		}
+		return ids
+	}
+
+	// newest first by default
+	transfers := listTransfers(ListTransfersByAccountIdParams{})
+	require.Equal(t, []int64{received.ID, sent[2].ID, sent[1].ID, sent[0].ID}, transferIDs(transfers))
+
+	transfers = listTransfers(ListTransfersByAccountIdParams{Direction: pgtype.Text{String: util.DirectionIn, Valid: true}})
+	require.Equal(t, []int64{received.ID}, transferIDs(transfers))
+
+	transfers = listTransfers(ListTransfersByAccountIdParams{
+		CounterpartyAccountID: pgtype.Int8{Int64: account2.ID, Valid: true},
+		SortBy:                util.SortByAmount,
+		SortOrder:             util.SortOrderAsc,
+	})
+	require.Equal(t, []int64{sent[1].ID, sent[2].ID, sent[0].ID}, transferIDs(transfers))
+
+	transfers = listTransfers(ListTransfersByAccountIdParams{
+		Direction: pgtype.Text{String: util.DirectionOut, Valid: true},
+		MinAmount: pgtype.Int8{Int64: 15, Valid: true},
+		MaxAmount: pgtype.Int8{Int64: 30, Valid: true},
+		SortBy:    util.SortByAmount,
+	})
+	require.Equal(t, []int64{sent[0].ID, sent[2].ID}, transferIDs(transfers))
+
+	transfers = listTransfers(ListTransfersByAccountIdParams{
+		StartTime: pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	})
+	require.Empty(t, transfers)
+}
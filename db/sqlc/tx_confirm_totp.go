@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+)
+
+type ConfirmTOTPTxParams struct {
+	Username string
+	// HashedRecoveryCodes replace any recovery code the user had before
+	HashedRecoveryCodes []string
+}
+
+type ConfirmTOTPTxResult struct {
+	Factor        TotpFactor
+	RecoveryCodes []RecoveryCode
+}
+
+// ConfirmTOTPTx enables two-factor authentication and stores a fresh set of recovery codes
+func (store *SQLStore) ConfirmTOTPTx(ctx context.Context, arg ConfirmTOTPTxParams) (ConfirmTOTPTxResult, error) {
+	var result ConfirmTOTPTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Factor, err = q.ConfirmTOTPFactor(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		err = q.DeleteRecoveryCodes(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		for _, hashedCode := range arg.HashedRecoveryCodes {
+			code, err := q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+				Username:   arg.Username,
+				HashedCode: hashedCode,
+			})
+			if err != nil {
+				return err
+			}
+			result.RecoveryCodes = append(result.RecoveryCodes, code)
+		}
+		return nil
+	})
+	return result, err
+}
@@ -1,6 +1,9 @@
 package db
 
-import "context"
+import (
+	"context"
+	"github.com/the-eduardo/Go-Bank/util"
+)
 
 type CreateUserTxParams struct {
 	CreateUserParams
@@ -19,6 +22,16 @@ func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams)
 		if err != nil {
 			return err
 		}
+		err = writeWebhookEvent(ctx, q, result.User.Username, util.WebhookUserCreated, UserCreatedEvent{
+			Username:  result.User.Username,
+			FullName:  result.User.FullName,
+			Email:     result.User.Email,
+			Role:      result.User.Role,
+			CreatedAt: result.User.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
 		return arg.AfterCreate(result.User)
 	})
 	return result, err
@@ -0,0 +1,47 @@
+package db
+
+import "context"
+
+// DepositTxParams contains the input parameters for the deposit transaction
+type DepositTxParams struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+	// Idempotency makes retries of the same deposit return the first result
+	Idempotency *IdempotencyParams `json:"-"`
+}
+
+// DepositTxResult is the result of the deposit transaction
+type DepositTxResult struct {
+	Account Account `json:"account"`
+	Entry   Entry   `json:"entry"`
+}
+
+// DepositTx credits an account against the cash-in system account of its currency
+func (store *SQLStore) DepositTx(ctx context.Context, arg DepositTxParams) (DepositTxResult, error) {
+	var result DepositTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		return execIdempotent(ctx, q, arg.Idempotency, "deposit", arg, &result, func() error {
+			account, err := q.GetAccount(ctx, arg.AccountID)
+			if err != nil {
+				return err
+			}
+			cashIn, err := q.GetSystemAccount(ctx, GetSystemAccountParams{Owner: SystemCashIn, Currency: account.Currency})
+			if err != nil {
+				return err
+			}
+			ledger, err := postJournal(ctx, q, LedgerTxParams{
+				Kind: JournalDeposit,
+				Postings: []Posting{
+					{AccountID: cashIn.ID, Amount: -arg.Amount},
+					{AccountID: arg.AccountID, Amount: arg.Amount},
+				},
+			})
+			if err != nil {
+				return err
+			}
+			result.Entry, result.Account = ledger.Entries[1], ledger.Accounts[1]
+			return nil
+		})
+	})
+	return result, err
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/the-eduardo/Go-Bank/fees"
+	"github.com/the-eduardo/Go-Bank/fx"
+)
+
+// ErrInvalidQuote is returned when a quote does not exist, belongs to another user,
+// has expired, was already used or does not match the currencies of the accounts
+var ErrInvalidQuote = errors.New("fx quote is invalid, expired or already used")
+
+// FXTransferTxParams contains the input parameters for the cross-currency transfer transaction
+type FXTransferTxParams struct {
+	FromAccountID int64       `json:"from_account_id"`
+	ToAccountID   int64       `json:"to_account_id"`
+	Amount        int64       `json:"amount"`
+	QuoteID       pgtype.UUID `json:"quote_id"`
+	FromCurrency  string      `json:"from_currency"`
+	ToCurrency    string      `json:"to_currency"`
+	Username      string      `json:"username"`
+	// Idempotency makes retries of the same transfer return the first result
+	Idempotency *IdempotencyParams `json:"-"`
+	// Limits are checked against the transfers already sent by the source account and its owner
+	Limits *TransferLimits `json:"-"`
+	// Fees charges the source account on top of the amount, nil transfers for free
+	Fees *fees.Schedule `json:"-"`
+}
+
+// FXTransferTx moves money between accounts with different currencies, converting the amount
+// with the rate locked by a quote. The quote is consumed by the transfer.
+func (store *SQLStore) FXTransferTx(ctx context.Context, arg FXTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		return execIdempotent(ctx, q, arg.Idempotency, "fx_transfer", arg, &result, func() error {
+			quote, err := q.UseFXQuote(ctx, UseFXQuoteParams{
+				ID:       arg.QuoteID,
+				Username: arg.Username,
+			})
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return ErrInvalidQuote
+				}
+				return err
+			}
+			if quote.FromCurrency != arg.FromCurrency || quote.ToCurrency != arg.ToCurrency {
+				return ErrInvalidQuote
+			}
+			// an amount that converts to nothing would post a zero leg to the destination account
+			toAmount, err := fx.Convert(arg.Amount, quote.Rate)
+			if err != nil {
+				return err
+			}
+			if toAmount <= 0 {
+				return fmt.Errorf("%w: %d %s converts to %d %s", fx.ErrInvalidAmount, arg.Amount, arg.FromCurrency, toAmount, arg.ToCurrency)
+			}
+			result.Transfer, err = q.CreateFXTransfer(ctx, CreateFXTransferParams{
+				FromAccountID: arg.FromAccountID,
+				ToAccountID:   arg.ToAccountID,
+				Amount:        arg.Amount,
+				ToAmount:      toAmount,
+				ExchangeRate:  quote.Rate,
+				QuoteID:       quote.ID,
+				Fee:           arg.Fees.Calculate(arg.FromCurrency, arg.Amount),
+			})
+			if err != nil {
+				return err
+			}
+
+			// the FX system accounts buy the source currency and sell the destination currency
+			fxFrom, err := q.GetSystemAccount(ctx, GetSystemAccountParams{Owner: SystemFX, Currency: arg.FromCurrency})
+			if err != nil {
+				return err
+			}
+			fxTo, err := q.GetSystemAccount(ctx, GetSystemAccountParams{Owner: SystemFX, Currency: arg.ToCurrency})
+			if err != nil {
+				return err
+			}
+			err = postTransfer(ctx, q, &result, JournalFXTransfer, arg.FromCurrency, []Posting{
+				{AccountID: arg.FromAccountID, Amount: -result.Transfer.Amount},
+				{AccountID: fxFrom.ID, Amount: result.Transfer.Amount},
+				{AccountID: fxTo.ID, Amount: -result.Transfer.ToAmount},
+				{AccountID: arg.ToAccountID, Amount: result.Transfer.ToAmount},
+			})
+			if err != nil {
+				return err
+			}
+			return checkTransferLimits(ctx, q, &result, arg.Limits)
+		})
+	})
+	return result, err
+}
@@ -0,0 +1,272 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/the-eduardo/Go-Bank/fees"
+	"sort"
+	"time"
+)
+
+// Transfer statuses
+const (
+	TransferStatusPending = "pending"
+	TransferStatusPosted  = "posted"
+	TransferStatusVoided  = "voided"
+	TransferStatusExpired = "expired"
+)
+
+var (
+	// ErrTransferNotPending is returned when a transfer that is not pending is captured or voided
+	ErrTransferNotPending = errors.New("transfer is not pending")
+	// ErrTransferHoldExpired is returned when a transfer is captured after its hold expired
+	ErrTransferHoldExpired = errors.New("transfer hold has expired")
+	// ErrCaptureExceedsAuthorization is returned when more than the authorized amount is captured
+	ErrCaptureExceedsAuthorization = errors.New("capture exceeds the authorized amount")
+)
+
+// held returns the amount of the available balance held by a pending transfer
+func (transfer Transfer) held() int64 {
+	return transfer.Amount + transfer.Fee
+}
+
+// AuthorizeTransferTxParams contains the input parameters for the authorize transaction
+type AuthorizeTransferTxParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	HoldExpiresAt time.Time `json:"hold_expires_at"`
+	// Idempotency makes retries of the same authorization return the first result
+	Idempotency *IdempotencyParams `json:"-"`
+	// Limits are checked when the hold is placed, the capture does not count again
+	Limits *TransferLimits `json:"-"`
+	// Fees are held on top of the amount, nil authorizes for free
+	Fees *fees.Schedule `json:"-"`
+}
+
+// AuthorizeTransferTxResult is the result of the authorize transaction
+type AuthorizeTransferTxResult struct {
+	Transfer    Transfer `json:"transfer"`
+	FromAccount Account  `json:"from_account"`
+}
+
+// AuthorizeTransferTx records a pending transfer and holds its amount and fee on the source account.
+// The hold lowers the available balance only, the ledger balance changes when the transfer is captured.
+func (store *SQLStore) AuthorizeTransferTx(ctx context.Context, arg AuthorizeTransferTxParams) (AuthorizeTransferTxResult, error) {
+	var result AuthorizeTransferTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		return execIdempotent(ctx, q, arg.Idempotency, "authorize_transfer", arg, &result, func() error {
+			fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
+			if err != nil {
+				return err
+			}
+			result.Transfer, err = q.CreatePendingTransfer(ctx, CreatePendingTransferParams{
+				FromAccountID: arg.FromAccountID,
+				ToAccountID:   arg.ToAccountID,
+				Amount:        arg.Amount,
+				Fee:           arg.Fees.Calculate(fromAccount.Currency, arg.Amount),
+				HoldExpiresAt: pgtype.Timestamptz{Time: arg.HoldExpiresAt, Valid: true},
+			})
+			if err != nil {
+				return err
+			}
+			result.FromAccount, err = q.AddAccountHold(ctx, AddAccountHoldParams{
+				ID:     arg.FromAccountID,
+				Amount: result.Transfer.held(),
+			})
+			if err != nil {
+				return balanceError(err)
+			}
+			err = publishAccountEvent(ctx, q, AccountEventHold, result.FromAccount, nil)
+			if err != nil {
+				return err
+			}
+			return checkTransferLimits(ctx, q, &TransferTxResult{
+				Transfer:    result.Transfer,
+				FromAccount: result.FromAccount,
+			}, arg.Limits)
+		})
+	})
+	return result, err
+}
+
+// CaptureTransferTxParams contains the input parameters for the capture transaction
+type CaptureTransferTxParams struct {
+	TransferID int64
+	// Amount is the part of the authorized amount to post, zero captures all of it
+	Amount int64
+	// Fees charges the fee of the captured amount, nil captures for free
+	Fees *fees.Schedule
+}
+
+// CaptureTransferTx releases the hold of a pending transfer and posts the captured amount to the ledger.
+// The part of the authorized amount that is not captured goes back to the available balance.
+func (store *SQLStore) CaptureTransferTx(ctx context.Context, arg CaptureTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		pending, err := getPendingTransfer(ctx, q, arg.TransferID)
+		if err != nil {
+			return err
+		}
+		if !pending.HoldExpiresAt.Time.After(time.Now()) {
+			return ErrTransferHoldExpired
+		}
+		amount := arg.Amount
+		if amount == 0 {
+			amount = pending.AuthorizedAmount.Int64
+		}
+		if amount > pending.AuthorizedAmount.Int64 {
+			return ErrCaptureExceedsAuthorization
+		}
+
+		fromAccount, err := q.GetAccount(ctx, pending.FromAccountID)
+		if err != nil {
+			return err
+		}
+		fee := arg.Fees.Calculate(fromAccount.Currency, amount)
+
+		// lock every account of the journal in id order before releasing the hold, so the capture
+		// takes its locks in the same order as the transfers posted at once and never deadlocks with them
+		accountIDs := []int64{pending.FromAccountID, pending.ToAccountID}
+		if fee > 0 {
+			feeAccount, err := q.GetSystemAccount(ctx, GetSystemAccountParams{Owner: SystemFees, Currency: fromAccount.Currency})
+			if err != nil {
+				return err
+			}
+			accountIDs = append(accountIDs, feeAccount.ID)
+		}
+		if err = lockAccounts(ctx, q, accountIDs); err != nil {
+			return err
+		}
+
+		_, err = q.AddAccountHold(ctx, AddAccountHoldParams{
+			ID:     pending.FromAccountID,
+			Amount: -pending.held(),
+		})
+		if err != nil {
+			return err
+		}
+		result.Transfer, err = q.CaptureTransfer(ctx, CaptureTransferParams{
+			ID:     pending.ID,
+			Amount: amount,
+			Fee:    fee,
+		})
+		if err != nil {
+			return err
+		}
+		return postTransfer(ctx, q, &result, JournalTransfer, fromAccount.Currency, []Posting{
+			{AccountID: pending.FromAccountID, Amount: -amount},
+			{AccountID: pending.ToAccountID, Amount: amount},
+		})
+	})
+	return result, err
+}
+
+// VoidTransferTxParams contains the input parameters for the void transaction
+type VoidTransferTxParams struct {
+	TransferID int64
+}
+
+// VoidTransferTxResult is the result of the void transaction
+type VoidTransferTxResult struct {
+	Transfer    Transfer
+	FromAccount Account
+}
+
+// VoidTransferTx cancels a pending transfer and gives its hold back to the available balance
+func (store *SQLStore) VoidTransferTx(ctx context.Context, arg VoidTransferTxParams) (VoidTransferTxResult, error) {
+	var result VoidTransferTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		pending, err := getPendingTransfer(ctx, q, arg.TransferID)
+		if err != nil {
+			return err
+		}
+		result.Transfer, result.FromAccount, err = releaseHold(ctx, q, pending, TransferStatusVoided)
+		return err
+	})
+	return result, err
+}
+
+// ExpireTransfersTxParams contains the input parameters for the expire transaction
+type ExpireTransfersTxParams struct {
+	Now   time.Time
+	Limit int64
+}
+
+// ExpireTransfersTxResult is the result of the expire transaction
+type ExpireTransfersTxResult struct {
+	Expired []Transfer
+}
+
+// ExpireTransfersTx releases the holds of the pending transfers that expired before now
+func (store *SQLStore) ExpireTransfersTx(ctx context.Context, arg ExpireTransfersTxParams) (ExpireTransfersTxResult, error) {
+	var result ExpireTransfersTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		expired, err := q.ListExpiredTransfersForUpdate(ctx, ListExpiredTransfersForUpdateParams{
+			HoldExpiresAt: pgtype.Timestamptz{Time: arg.Now, Valid: true},
+			Limit:         arg.Limit,
+		})
+		if err != nil {
+			return err
+		}
+
+		// release the holds in account id order, so concurrent journals never deadlock
+		sort.SliceStable(expired, func(i, j int) bool {
+			return expired[i].FromAccountID < expired[j].FromAccountID
+		})
+		result.Expired = make([]Transfer, len(expired))
+		for i, pending := range expired {
+			result.Expired[i], _, err = releaseHold(ctx, q, pending, TransferStatusExpired)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// getPendingTransfer locks a transfer and returns ErrTransferNotPending when its hold was already released
+func getPendingTransfer(ctx context.Context, q *Queries, id int64) (Transfer, error) {
+	transfer, err := q.GetTransferForUpdate(ctx, id)
+	if err != nil {
+		return transfer, err
+	}
+	if transfer.Status != TransferStatusPending {
+		return transfer, ErrTransferNotPending
+	}
+	return transfer, nil
+}
+
+// releaseHold closes a pending transfer with a final status and gives its hold back to the source account
+func releaseHold(ctx context.Context, q *Queries, pending Transfer, status string) (Transfer, Account, error) {
+	transfer, err := q.ReleaseTransfer(ctx, ReleaseTransferParams{
+		ID:     pending.ID,
+		Status: status,
+	})
+	if err != nil {
+		return transfer, Account{}, err
+	}
+	account, err := q.AddAccountHold(ctx, AddAccountHoldParams{
+		ID:     pending.FromAccountID,
+		Amount: -pending.held(),
+	})
+	if err != nil {
+		return transfer, account, err
+	}
+	return transfer, account, publishAccountEvent(ctx, q, AccountEventHold, account, nil)
+}
+
+// lockAccounts locks the accounts in id order
+func lockAccounts(ctx context.Context, q *Queries, accountIDs []int64) error {
+	sort.Slice(accountIDs, func(i, j int) bool {
+		return accountIDs[i] < accountIDs[j]
+	})
+	for _, id := range accountIDs {
+		if _, err := q.GetAccountForUpdate(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"time"
+)
+
+// DefaultIdempotencyWindow is used when no replay window is configured
+const DefaultIdempotencyWindow = 24 * time.Hour
+
+// ErrIdempotencyKeyConflict is returned when a key is reused with a different request
+// or while the first request with that key is still being processed
+var ErrIdempotencyKeyConflict = errors.New("idempotency key conflict")
+
+// IdempotencyParams identifies a client request that must be executed at most once
+type IdempotencyParams struct {
+	Key      string
+	Username string
+	Window   time.Duration
+}
+
+// hashRequest returns a stable fingerprint of an operation and its parameters
+func hashRequest(operation string, request any) (string, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append([]byte(operation+":"), body...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// execIdempotent runs fn with the queries of an open transaction. When idem is set, a replay of the
+// same request within the window unmarshals the stored response into result instead of running fn again.
+func execIdempotent(
+	ctx context.Context,
+	q *Queries,
+	idem *IdempotencyParams,
+	operation string,
+	request any,
+	result any,
+	fn func() error,
+) error {
+	if idem == nil {
+		return fn()
+	}
+	requestHash, err := hashRequest(operation, request)
+	if err != nil {
+		return fmt.Errorf("cannot hash request: %w", err)
+	}
+
+	stored, err := q.GetIdempotencyKeyForUpdate(ctx, GetIdempotencyKeyForUpdateParams{
+		Username: idem.Username,
+		Key:      idem.Key,
+	})
+	switch {
+	case err == nil:
+		if stored.RequestHash != requestHash {
+			return ErrIdempotencyKeyConflict
+		}
+		return json.Unmarshal(stored.ResponseBody, result)
+	case !errors.Is(err, pgx.ErrNoRows):
+		return err
+	}
+
+	if err = fn(); err != nil {
+		return err
+	}
+	responseBody, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("cannot marshal response: %w", err)
+	}
+	window := idem.Window
+	if window <= 0 {
+		window = DefaultIdempotencyWindow
+	}
+	_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Username:     idem.Username,
+		Key:          idem.Key,
+		RequestHash:  requestHash,
+		ResponseBody: responseBody,
+		ExpiresAt: pgtype.Timestamptz{
+			Time:  time.Now().Add(window),
+			Valid: true,
+		},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// a concurrent request with the same key committed first
+		return ErrIdempotencyKeyConflict
+	}
+	return err
+}
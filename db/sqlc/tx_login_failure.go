@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/the-eduardo/Go-Bank/util"
+	"time"
+)
+
+// Scopes of the login attempts, failures are counted per username and per client ip
+const (
+	LoginScopeUsername = "username"
+	LoginScopeClientIP = "client_ip"
+)
+
+type LoginFailureTxParams struct {
+	Username string
+	ClientIP string
+	// MaxUserAttempts and MaxClientIPAttempts are the failures that lock out for LockoutDuration,
+	// failures older than LockoutDuration are forgotten
+	MaxUserAttempts     int32
+	MaxClientIPAttempts int32
+	LockoutDuration     time.Duration
+}
+
+type LoginFailureTxResult struct {
+	UserAttempt     LoginAttempt
+	ClientIPAttempt LoginAttempt
+	// UserLockedOut is only set by the failure that reaches MaxUserAttempts, to notify the user once
+	UserLockedOut bool
+}
+
+// LoginFailureTx counts a failed login for the username and the client ip and applies the backoff
+func (store *SQLStore) LoginFailureTx(ctx context.Context, arg LoginFailureTxParams) (LoginFailureTxResult, error) {
+	var result LoginFailureTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.UserAttempt, err = countLoginFailure(ctx, q, LoginScopeUsername, arg.Username, arg.MaxUserAttempts, arg.LockoutDuration)
+		if err != nil {
+			return err
+		}
+		if arg.ClientIP != "" {
+			result.ClientIPAttempt, err = countLoginFailure(ctx, q, LoginScopeClientIP, arg.ClientIP, arg.MaxClientIPAttempts, arg.LockoutDuration)
+			if err != nil {
+				return err
+			}
+		}
+		result.UserLockedOut = result.UserAttempt.FailedCount == arg.MaxUserAttempts
+		return nil
+	})
+	return result, err
+}
+
+func countLoginFailure(
+	ctx context.Context,
+	q *Queries,
+	scope string,
+	key string,
+	maxAttempts int32,
+	lockout time.Duration,
+) (LoginAttempt, error) {
+	now := time.Now()
+	attempt, err := q.RecordLoginFailure(ctx, RecordLoginFailureParams{
+		Scope:       scope,
+		Key:         key,
+		ResetBefore: pgtype.Timestamptz{Time: now.Add(-lockout), Valid: true},
+	})
+	if err != nil {
+		return attempt, err
+	}
+	delay := util.LoginBackoff(attempt.FailedCount, maxAttempts, lockout)
+	if delay == 0 {
+		return attempt, nil
+	}
+	return q.LockLogin(ctx, LockLoginParams{
+		Scope:       scope,
+		Key:         key,
+		LockedUntil: pgtype.Timestamptz{Time: now.Add(delay), Valid: true},
+	})
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+)
+
+// ReconcileResult lists the inconsistencies found in the ledger
+type ReconcileResult struct {
+	UnreconciledAccounts []ListUnreconciledAccountsRow `json:"unreconciled_accounts"`
+	UnbalancedJournals   []ListUnbalancedJournalsRow   `json:"unbalanced_journals"`
+}
+
+// OK reports whether every balance matches its entries and every journal balances
+func (result ReconcileResult) OK() bool {
+	return len(result.UnreconciledAccounts) == 0 && len(result.UnbalancedJournals) == 0
+}
+
+// Reconcile proves that every account balance equals the sum of its entries and that every journal balances.
+// Both checks read the same snapshot, so transfers running meanwhile do not cause false mismatches.
+func (store *SQLStore) Reconcile(ctx context.Context) (ReconcileResult, error) {
+	var result ReconcileResult
+	txOptions := pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+	err := store.execTxWithOptions(ctx, txOptions, func(q *Queries) error {
+		var err error
+		result.UnreconciledAccounts, err = q.ListUnreconciledAccounts(ctx)
+		if err != nil {
+			return err
+		}
+		result.UnbalancedJournals, err = q.ListUnbalancedJournals(ctx)
+		return err
+	})
+	return result, err
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5/pgtype"
+	"time"
+)
+
+var (
+	// ErrSessionBlocked is returned when the session was revoked
+	ErrSessionBlocked = errors.New("session is blocked")
+	// ErrSessionExpired is returned when the session is past its expiry
+	ErrSessionExpired = errors.New("session has expired")
+	// ErrSessionMismatch is returned when the refresh token does not belong to the session
+	ErrSessionMismatch = errors.New("mismatched session token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is presented again.
+	// The whole session family is revoked by then.
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+type RenewSessionTxParams struct {
+	// SessionID, Username and RefreshToken identify the session being renewed
+	SessionID    pgtype.UUID
+	Username     string
+	RefreshToken string
+	// NewSession is the session of the new refresh token, its family is set by the transaction
+	NewSession CreateSessionParams
+}
+
+type RenewSessionTxResult struct {
+	OldSession Session
+	NewSession Session
+}
+
+// RenewSessionTx rotates a refresh token: it marks the old session as rotated and creates
+// the new one in the same family. Presenting a rotated refresh token again revokes the family.
+func (store *SQLStore) RenewSessionTx(ctx context.Context, arg RenewSessionTxParams) (RenewSessionTxResult, error) {
+	var result RenewSessionTxResult
+	reused := false
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		// Locking the row makes concurrent renewals of the same token see each other's rotation
+		result.OldSession, err = q.GetSessionForUpdate(ctx, arg.SessionID)
+		if err != nil {
+			return err
+		}
+		if result.OldSession.Username != arg.Username || result.OldSession.RefreshToken != arg.RefreshToken {
+			return ErrSessionMismatch
+		}
+		if result.OldSession.RotatedAt.Valid {
+			// The revocation must be committed, so the error is only returned after the transaction
+			reused = true
+			_, err = q.RevokeSessionFamily(ctx, result.OldSession.FamilyID)
+			return err
+		}
+		if result.OldSession.IsBlocked {
+			return ErrSessionBlocked
+		}
+		if time.Now().After(result.OldSession.ExpiresAt.Time) {
+			return ErrSessionExpired
+		}
+
+		result.OldSession, err = q.RotateSession(ctx, result.OldSession.ID)
+		if err != nil {
+			return err
+		}
+		newSession := arg.NewSession
+		newSession.FamilyID = result.OldSession.FamilyID
+		result.NewSession, err = q.CreateSession(ctx, newSession)
+		return err
+	})
+	if err == nil && reused {
+		err = ErrRefreshTokenReused
+	}
+	return result, err
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgtype"
+	"time"
+)
+
+type ResetPasswordTxParams struct {
+	HashedCode     string
+	HashedPassword string
+}
+
+type ResetPasswordTxResult struct {
+	User            User
+	RevokedSessions int64
+}
+
+// ResetPasswordTx consumes a reset code, sets the new password and revokes all sessions of the user
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResult, error) {
+	var result ResetPasswordTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		reset, err := q.UsePasswordReset(ctx, arg.HashedCode)
+		if err != nil {
+			return err
+		}
+		_, err = q.InvalidatePasswordResets(ctx, reset.Username)
+		if err != nil {
+			return err
+		}
+		result.User, err = q.UpdateUser(ctx, UpdateUserParams{
+			Username:          reset.Username,
+			HashedPassword:    pgtype.Text{String: arg.HashedPassword, Valid: true},
+			PasswordChangedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		result.RevokedSessions, err = q.RevokeAllSessions(ctx, reset.Username)
+		return err
+	})
+	return result, err
+}
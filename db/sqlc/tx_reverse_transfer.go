@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// ErrTransferNotReversible is returned for the transfers that cannot be refunded: pending, voided or expired
+	// transfers, reversals themselves and transfers between currencies, whose quote is no longer available
+	ErrTransferNotReversible = errors.New("transfer cannot be reversed")
+	// ErrTransferAlreadyReversed is returned once the whole amount of a transfer was reversed
+	ErrTransferAlreadyReversed = errors.New("transfer was already reversed")
+	// ErrReversalExceedsAmount is returned when a reversal gives back more than what is left of the transfer
+	ErrReversalExceedsAmount = errors.New("reversal exceeds the amount left to reverse")
+)
+
+// ReverseTransferTxParams contains the input parameters for the reverse transaction
+type ReverseTransferTxParams struct {
+	TransferID int64
+	// Amount is the part of the transfer to give back, zero reverses what is left of it
+	Amount int64
+}
+
+// ReverseTransferTxResult is the result of the reverse transaction.
+// Transfer is the reversal, it moves the money from the destination of the original transfer back to its source.
+type ReverseTransferTxResult struct {
+	TransferTxResult
+	Original Transfer
+}
+
+// ReverseTransferTx refunds all or part of a posted transfer with compensating entries.
+// The original transfer is locked while its reversed amount grows, so concurrent reversals cannot give back more than it moved.
+// The fee of the original transfer is not refunded.
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+		if original.Status != TransferStatusPosted || original.ReversalOf.Valid || original.QuoteID.Valid {
+			return ErrTransferNotReversible
+		}
+		left := original.Amount - original.ReversedAmount
+		if left == 0 {
+			return ErrTransferAlreadyReversed
+		}
+		amount := arg.Amount
+		if amount == 0 {
+			amount = left
+		}
+		if amount > left {
+			return ErrReversalExceedsAmount
+		}
+
+		result.Original, err = q.AddTransferReversedAmount(ctx, AddTransferReversedAmountParams{
+			ID:     original.ID,
+			Amount: amount,
+		})
+		if err != nil {
+			return err
+		}
+		result.Transfer, err = q.CreateReversalTransfer(ctx, CreateReversalTransferParams{
+			FromAccountID: original.ToAccountID,
+			ToAccountID:   original.FromAccountID,
+			Amount:        amount,
+			ReversalOf:    pgtype.Int8{Int64: original.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		return postTransfer(ctx, q, &result.TransferTxResult, JournalReversal, "", []Posting{
+			{AccountID: original.ToAccountID, Amount: -amount},
+			{AccountID: original.FromAccountID, Amount: amount},
+		})
+	})
+	return result, err
+}
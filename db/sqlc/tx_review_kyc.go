@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// KYC submission statuses
+const (
+	KYCStatusPending  = "pending"
+	KYCStatusApproved = "approved"
+	KYCStatusRejected = "rejected"
+)
+
+type ReviewKYCTxParams struct {
+	ID         int64
+	Approved   bool
+	ReviewedBy string
+	ReviewNote string
+}
+
+type ReviewKYCTxResult struct {
+	Submission KycSubmission
+	User       User
+}
+
+// ReviewKYCTx closes a pending submission, an approval moves the user to the submitted tier
+func (store *SQLStore) ReviewKYCTx(ctx context.Context, arg ReviewKYCTxParams) (ReviewKYCTxResult, error) {
+	var result ReviewKYCTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		reviewStatus := KYCStatusRejected
+		if arg.Approved {
+			reviewStatus = KYCStatusApproved
+		}
+		result.Submission, err = q.ReviewKYCSubmission(ctx, ReviewKYCSubmissionParams{
+			ID:     arg.ID,
+			Status: reviewStatus,
+			ReviewedBy: pgtype.Text{
+				String: arg.ReviewedBy,
+				Valid:  true,
+			},
+			ReviewNote: arg.ReviewNote,
+		})
+		if err != nil {
+			return err
+		}
+		if !arg.Approved {
+			result.User, err = q.GetUser(ctx, result.Submission.Username)
+			return err
+		}
+		result.User, err = q.SetUserKYCTier(ctx, SetUserKYCTierParams{
+			Username: result.Submission.Username,
+			KycTier:  result.Submission.Tier,
+		})
+		return err
+	})
+	return result, err
+}
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/the-eduardo/Go-Bank/fees"
+	"github.com/the-eduardo/Go-Bank/util"
+	"time"
+)
+
+// Scheduled transfer run statuses
+const (
+	ScheduledTransferRunSucceeded = "succeeded"
+	ScheduledTransferRunFailed    = "failed"
+)
+
+// ErrScheduledTransferAlreadyRun is returned when an occurrence of a scheduled transfer already has a run
+var ErrScheduledTransferAlreadyRun = errors.New("scheduled transfer already ran for this occurrence")
+
+// Recurrence returns how the scheduled transfer repeats
+func (scheduledTransfer ScheduledTransfer) Recurrence() util.Recurrence {
+	return util.Recurrence{
+		CronExpression: scheduledTransfer.CronExpression,
+		Interval:       time.Duration(scheduledTransfer.IntervalSeconds) * time.Second,
+	}
+}
+
+type ClaimDueScheduledTransfersTxParams struct {
+	Now   time.Time
+	Limit int64
+	// AfterClaim enqueues the run of a claimed transfer, an error releases every claim of the batch
+	AfterClaim func(scheduledTransfer ScheduledTransfer) error
+}
+
+type ClaimDueScheduledTransfersTxResult struct {
+	// Claimed holds the transfers as they were due, NextRunAt is the occurrence to run
+	Claimed []ScheduledTransfer
+}
+
+// ClaimDueScheduledTransfersTx moves the due transfers to their next run after now and enqueues their runs.
+// A transfer whose recurrence can no longer be computed is deactivated instead.
+func (store *SQLStore) ClaimDueScheduledTransfersTx(ctx context.Context, arg ClaimDueScheduledTransfersTxParams) (ClaimDueScheduledTransfersTxResult, error) {
+	var result ClaimDueScheduledTransfersTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		due, err := q.ListDueScheduledTransfersForUpdate(ctx, ListDueScheduledTransfersForUpdateParams{
+			NextRunAt: pgtype.Timestamptz{Time: arg.Now, Valid: true},
+			Limit:     arg.Limit,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Claimed = make([]ScheduledTransfer, 0, len(due))
+		for _, scheduledTransfer := range due {
+			update := UpdateScheduledTransferParams{ID: scheduledTransfer.ID}
+			next, err := scheduledTransfer.Recurrence().Next(scheduledTransfer.NextRunAt.Time, arg.Now)
+			if err != nil {
+				update.IsActive = pgtype.Bool{Bool: false, Valid: true}
+			} else {
+				update.NextRunAt = pgtype.Timestamptz{Time: next, Valid: true}
+			}
+			if _, err := q.UpdateScheduledTransfer(ctx, update); err != nil {
+				return err
+			}
+			if update.IsActive.Valid {
+				continue
+			}
+
+			if err := arg.AfterClaim(scheduledTransfer); err != nil {
+				return err
+			}
+			result.Claimed = append(result.Claimed, scheduledTransfer)
+		}
+		return nil
+	})
+	return result, err
+}
+
+type ExecuteScheduledTransferTxParams struct {
+	ScheduledTransfer ScheduledTransfer
+	// ScheduledFor is the occurrence to run, each one transfers at most once
+	ScheduledFor time.Time
+	Limits       *TransferLimits
+	Fees         *fees.Schedule
+}
+
+type ExecuteScheduledTransferTxResult struct {
+	TransferTxResult
+	Run ScheduledTransferRun
+}
+
+// ExecuteScheduledTransferTx transfers the amount of a scheduled transfer and records the run of the occurrence.
+// It returns ErrScheduledTransferAlreadyRun, without transferring, when the occurrence was already run.
+func (store *SQLStore) ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResult, error) {
+	var result ExecuteScheduledTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		scheduledTransfer := arg.ScheduledTransfer
+		err := transfer(ctx, q, TransferTxParams{
+			FromAccountID: scheduledTransfer.FromAccountID,
+			ToAccountID:   scheduledTransfer.ToAccountID,
+			Amount:        scheduledTransfer.Amount,
+			Limits:        arg.Limits,
+			Fees:          arg.Fees,
+		}, &result.TransferTxResult)
+		if err != nil {
+			return err
+		}
+
+		// a concurrent run of the same occurrence waits on the unique index and finds this row
+		result.Run, err = q.CreateScheduledTransferRun(ctx, CreateScheduledTransferRunParams{
+			ScheduledTransferID: scheduledTransfer.ID,
+			ScheduledFor:        pgtype.Timestamptz{Time: arg.ScheduledFor, Valid: true},
+			Status:              ScheduledTransferRunSucceeded,
+			TransferID:          pgtype.Int8{Int64: result.Transfer.ID, Valid: true},
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrScheduledTransferAlreadyRun
+		}
+		return err
+	})
+	return result, err
+}
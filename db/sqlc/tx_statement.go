@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/the-eduardo/Go-Bank/util"
+	"time"
+)
+
+// statementPageSize is how many entries or transfers are read per query
+const statementPageSize = 500
+
+// StatementTxParams contains the input parameters of the statement transaction
+type StatementTxParams struct {
+	AccountID int64     `json:"account_id"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+}
+
+// StatementTxResult is what a statement of an account between Start, included, and End, excluded, is built from
+type StatementTxResult struct {
+	Account Account `json:"account"`
+	// OpeningBalance is the sum of the entries before Start
+	OpeningBalance int64 `json:"opening_balance"`
+	// Entries are the entries of the range, oldest first
+	Entries []Entry `json:"entries"`
+	// Transfers are the transfers that posted the entries, keyed by the id of their journal.
+	// A transfer is found through its journal, so a captured hold is found by the time of its capture.
+	Transfers map[int64]Transfer `json:"transfers"`
+}
+
+// StatementTx reads the account, its opening balance, the entries of a date range and the transfers that posted them.
+// Every read sees the same snapshot, so the balances match the entries even while transfers post meanwhile.
+func (store *SQLStore) StatementTx(ctx context.Context, arg StatementTxParams) (StatementTxResult, error) {
+	var result StatementTxResult
+	txOptions := pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+	err := store.execTxWithOptions(ctx, txOptions, func(q *Queries) error {
+		var err error
+		result.Account, err = q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		result.OpeningBalance, err = q.SumEntriesBefore(ctx, SumEntriesBeforeParams{
+			AccountID: arg.AccountID,
+			Before:    pgtype.Timestamptz{Time: arg.Start, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		result.Entries, err = listStatementEntries(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+		result.Transfers, err = listStatementTransfers(ctx, q, result.Entries)
+		return err
+	})
+	return result, err
+}
+
+func listStatementEntries(ctx context.Context, q *Queries, arg StatementTxParams) ([]Entry, error) {
+	entries := []Entry{}
+	listArg := ListEntriesParams{
+		AccountID: arg.AccountID,
+		StartTime: pgtype.Timestamptz{Time: arg.Start, Valid: true},
+		EndTime:   pgtype.Timestamptz{Time: arg.End, Valid: true},
+		SortOrder: util.SortOrderAsc,
+		Limit:     statementPageSize,
+	}
+	for {
+		page, err := q.ListEntries(ctx, listArg)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, page...)
+		if len(page) < statementPageSize {
+			return entries, nil
+		}
+		last := page[len(page)-1]
+		listArg.StartAfter(util.PageToken{CreatedAt: last.CreatedAt.Time, ID: last.ID})
+	}
+}
+
+func listStatementTransfers(ctx context.Context, q *Queries, entries []Entry) (map[int64]Transfer, error) {
+	transfers := make(map[int64]Transfer)
+	var journalIDs []int64
+	for _, entry := range entries {
+		if entry.JournalID.Valid {
+			journalIDs = append(journalIDs, entry.JournalID.Int64)
+		}
+	}
+	for start := 0; start < len(journalIDs); start += statementPageSize {
+		end := min(start+statementPageSize, len(journalIDs))
+		rows, err := q.ListJournalTransfers(ctx, journalIDs[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			transfers[row.JournalID] = row.Transfer
+		}
+	}
+	return transfers, nil
+}
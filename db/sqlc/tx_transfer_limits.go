@@ -0,0 +1,180 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"time"
+)
+
+// ErrTransferLimitExceeded is wrapped by TransferLimitError
+var ErrTransferLimitExceeded = errors.New("transfer limit exceeded")
+
+// Transfer limit scopes
+const (
+	TransferLimitScopeAccount = "account"
+	TransferLimitScopeUser    = "user"
+)
+
+// Transfer limit periods. Hourly limits the number of transfers, the others limit the amount.
+const (
+	TransferLimitPerTransfer = "per_transfer"
+	TransferLimitHourly      = "hourly"
+	TransferLimitDaily       = "daily"
+	TransferLimitWeekly      = "weekly"
+)
+
+// TransferLimit caps outgoing transfers, a zero limit is not enforced.
+// Daily and weekly limits reset at the start of the UTC day and week, the hourly count is a sliding window.
+type TransferLimit struct {
+	PerTransfer int64 `json:"per_transfer"`
+	Daily       int64 `json:"daily"`
+	Weekly      int64 `json:"weekly"`
+	HourlyCount int64 `json:"hourly_count"`
+}
+
+// TransferLimits caps the outgoing transfers of the source account and of its owner.
+// The overrides stored for the source account replace the Account limits.
+// The User amounts sum the accounts of the owner in the transfer currency, the User count sums all of them.
+type TransferLimits struct {
+	Account TransferLimit `json:"account"`
+	User    TransferLimit `json:"user"`
+}
+
+// TransferLimitError tells the client which limit a transfer would break and how much of it is left
+type TransferLimitError struct {
+	Scope     string
+	Period    string
+	Limit     int64
+	Remaining int64
+}
+
+func (e *TransferLimitError) Error() string {
+	if e.Period == TransferLimitHourly {
+		return fmt.Sprintf("%s limit of %d transfers per hour exceeded, %d remaining", e.Scope, e.Limit, e.Remaining)
+	}
+	return fmt.Sprintf("%s %s transfer limit of %d exceeded, %d remaining", e.Scope, e.Period, e.Limit, e.Remaining)
+}
+
+func (e *TransferLimitError) Unwrap() error {
+	return ErrTransferLimitExceeded
+}
+
+// transferSums returns the total amount and the number of the transfers sent since a time, this one included
+type transferSums func(since time.Time) (total int64, count int64, err error)
+
+// checkTransferLimits must run after the transfer was posted. Posting locks the source account and the user
+// limits lock its owner, so the sums read here already contain every concurrent transfer that committed before.
+func checkTransferLimits(ctx context.Context, q *Queries, result *TransferTxResult, limits *TransferLimits) error {
+	if limits == nil {
+		return nil
+	}
+	transfer := result.Transfer
+
+	accountLimit, err := accountTransferLimit(ctx, q, transfer.FromAccountID, limits.Account)
+	if err != nil {
+		return err
+	}
+	err = checkTransferLimit(TransferLimitScopeAccount, accountLimit, transfer.Amount, func(since time.Time) (int64, int64, error) {
+		sent, err := q.SumTransfersSince(ctx, SumTransfersSinceParams{
+			FromAccountID: transfer.FromAccountID,
+			Since:         pgtype.Timestamptz{Time: since, Valid: true},
+		})
+		return sent.Total, sent.Count, err
+	})
+	if err != nil || limits.User == (TransferLimit{}) {
+		return err
+	}
+
+	owner := result.FromAccount.Owner
+	if _, err = q.GetUserForUpdate(ctx, owner); err != nil {
+		return err
+	}
+	return checkTransferLimit(TransferLimitScopeUser, limits.User, transfer.Amount, func(since time.Time) (int64, int64, error) {
+		sent, err := q.SumUserTransfersSince(ctx, SumUserTransfersSinceParams{
+			Currency: result.FromAccount.Currency,
+			Owner:    owner,
+			Since:    pgtype.Timestamptz{Time: since, Valid: true},
+		})
+		return sent.Total, sent.Count, err
+	})
+}
+
+// accountTransferLimit applies the overrides stored for the account to the defaults
+func accountTransferLimit(ctx context.Context, q *Queries, accountID int64, defaults TransferLimit) (TransferLimit, error) {
+	overrides, err := q.GetAccountTransferLimits(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return defaults, nil
+		}
+		return defaults, err
+	}
+	limit := defaults
+	if overrides.PerTransfer.Valid {
+		limit.PerTransfer = overrides.PerTransfer.Int64
+	}
+	if overrides.Daily.Valid {
+		limit.Daily = overrides.Daily.Int64
+	}
+	if overrides.Weekly.Valid {
+		limit.Weekly = overrides.Weekly.Int64
+	}
+	if overrides.HourlyCount.Valid {
+		limit.HourlyCount = overrides.HourlyCount.Int64
+	}
+	return limit, nil
+}
+
+func checkTransferLimit(scope string, limit TransferLimit, amount int64, sums transferSums) error {
+	if limit.PerTransfer > 0 && amount > limit.PerTransfer {
+		return &TransferLimitError{Scope: scope, Period: TransferLimitPerTransfer, Limit: limit.PerTransfer, Remaining: limit.PerTransfer}
+	}
+
+	now := time.Now().UTC()
+	if limit.HourlyCount > 0 {
+		_, count, err := sums(now.Add(-time.Hour))
+		if err != nil {
+			return err
+		}
+		if count > limit.HourlyCount {
+			return &TransferLimitError{
+				Scope:     scope,
+				Period:    TransferLimitHourly,
+				Limit:     limit.HourlyCount,
+				Remaining: max(limit.HourlyCount-(count-1), 0),
+			}
+		}
+	}
+
+	dayStart := now.Truncate(24 * time.Hour)
+	// time.Monday is 1, shift so that the week starts on monday
+	weekStart := dayStart.AddDate(0, 0, -(int(dayStart.Weekday())+6)%7)
+	amountLimits := []struct {
+		period string
+		limit  int64
+		since  time.Time
+	}{
+		{TransferLimitDaily, limit.Daily, dayStart},
+		{TransferLimitWeekly, limit.Weekly, weekStart},
+	}
+	for _, amountLimit := range amountLimits {
+		if amountLimit.limit <= 0 {
+			continue
+		}
+		total, _, err := sums(amountLimit.since)
+		if err != nil {
+			return err
+		}
+		if total > amountLimit.limit {
+			return &TransferLimitError{
+				Scope:     scope,
+				Period:    amountLimit.period,
+				Limit:     amountLimit.limit,
+				Remaining: max(amountLimit.limit-(total-amount), 0),
+			}
+		}
+	}
+	return nil
+}
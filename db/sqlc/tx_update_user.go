@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type UpdateUserTxParams struct {
+	UpdateUserParams
+	// PendingEmail is a new address, it only replaces the email once VerifyEmailTx confirms it
+	PendingEmail pgtype.Text
+	// AfterUpdate runs in the transaction, an error rolls the update back
+	AfterUpdate func(user User) error
+}
+
+type UpdateUserTxResult struct {
+	User            User
+	RevokedSessions int64
+}
+
+// UpdateUserTx updates a user and, when the password changes, revokes all of their sessions
+func (store *SQLStore) UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (UpdateUserTxResult, error) {
+	var result UpdateUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.UpdateUser(ctx, arg.UpdateUserParams)
+		if err != nil {
+			return err
+		}
+		if arg.PendingEmail.Valid {
+			result.User, err = q.SetPendingEmail(ctx, SetPendingEmailParams{
+				Username:     arg.Username,
+				PendingEmail: arg.PendingEmail,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if arg.HashedPassword.Valid {
+			result.RevokedSessions, err = q.RevokeAllSessions(ctx, result.User.Username)
+			if err != nil {
+				return err
+			}
+		}
+		if arg.AfterUpdate != nil {
+			return arg.AfterUpdate(result.User)
+		}
+		return nil
+	})
+	return result, err
+}
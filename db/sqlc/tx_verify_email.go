@@ -2,9 +2,13 @@ package db
 
 import (
 	"context"
+	"errors"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// ErrEmailChanged is returned for a verification of an address the user no longer uses or waits for
+var ErrEmailChanged = errors.New("email address was changed since this verification was sent")
+
 type VerifyEmailTxParams struct {
 	EmailID    int64
 	SecretCode string
@@ -12,6 +16,8 @@ type VerifyEmailTxParams struct {
 type VerifyEmailTxResult struct {
 	User        User
 	VerifyEmail VerifyEmail
+	// PreviousEmail is set when the verification switched the user to their pending email
+	PreviousEmail string
 }
 
 func (store *SQLStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResult, error) {
@@ -27,13 +33,29 @@ func (store *SQLStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParam
 		if err != nil {
 			return err
 		}
-		result.User, err = q.UpdateUser(ctx, UpdateUserParams{
-			Username: result.VerifyEmail.Username,
-			IsEmailVerified: pgtype.Bool{
-				Bool:  true,
-				Valid: true,
-			},
-		})
+		user, err := q.GetUser(ctx, result.VerifyEmail.Username)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case user.PendingEmail.Valid && user.PendingEmail.String == result.VerifyEmail.Email:
+			result.PreviousEmail = user.Email
+			result.User, err = q.SwitchToPendingEmail(ctx, SwitchToPendingEmailParams{
+				Username:     user.Username,
+				PendingEmail: user.PendingEmail,
+			})
+		case user.Email == result.VerifyEmail.Email:
+			result.User, err = q.UpdateUser(ctx, UpdateUserParams{
+				Username: user.Username,
+				IsEmailVerified: pgtype.Bool{
+					Bool:  true,
+					Valid: true,
+				},
+			})
+		default:
+			return ErrEmailChanged
+		}
 		return err
 	})
 	return result, err
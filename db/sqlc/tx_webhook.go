@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/the-eduardo/Go-Bank/util"
+)
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookGlobalRole is the only role whose global subscriptions receive the events of every user
+const WebhookGlobalRole = util.AdminRole
+
+// ErrWebhookDeliveryPending is returned when a delivery that is still being attempted is sent again
+var ErrWebhookDeliveryPending = errors.New("webhook delivery is still pending")
+
+// TransferPostedEvent is the data of a transfer.posted event, sent to the owner of each side of the transfer
+type TransferPostedEvent struct {
+	Transfer Transfer `json:"transfer"`
+	// Account and Entry are the side of the owner, with the balance right after the transfer
+	Account Account `json:"account"`
+	Entry   Entry   `json:"entry"`
+	// Fee is only sent to the owner of the source account
+	Fee *TransferFee `json:"fee,omitempty"`
+}
+
+// UserCreatedEvent is the data of a user.created event
+type UserCreatedEvent struct {
+	Username  string             `json:"username"`
+	FullName  string             `json:"full_name"`
+	Email     string             `json:"email"`
+	Role      string             `json:"role"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+// writeWebhookEvent writes an event to the outbox, it is only dispatched if the transaction commits
+func writeWebhookEvent(ctx context.Context, q *Queries, owner string, eventType string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+	_, err = q.CreateWebhookEvent(ctx, CreateWebhookEventParams{
+		Owner:     owner,
+		EventType: eventType,
+		Payload:   payload,
+	})
+	return err
+}
+
+// writeTransferPostedEvents writes a transfer.posted event for each customer account of a posted transfer
+func writeTransferPostedEvents(ctx context.Context, q *Queries, result *TransferTxResult) error {
+	sides := []TransferPostedEvent{
+		{Transfer: result.Transfer, Account: result.FromAccount, Entry: result.FromEntry, Fee: result.Fee},
+		{Transfer: result.Transfer, Account: result.ToAccount, Entry: result.ToEntry},
+	}
+	for _, side := range sides {
+		if side.Account.IsSystem {
+			continue
+		}
+		if err := writeWebhookEvent(ctx, q, side.Account.Owner, util.WebhookTransferPosted, side); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type DispatchWebhookEventsTxParams struct {
+	Limit int64
+	// AfterCreate enqueues a delivery, an error leaves every event of the batch to the next dispatch
+	AfterCreate func(delivery WebhookDelivery) error
+}
+
+type DispatchWebhookEventsTxResult struct {
+	Events     []WebhookEvent
+	Deliveries []WebhookDelivery
+}
+
+// DispatchWebhookEventsTx creates the deliveries of the events in the outbox and enqueues them.
+// An event without a matching subscription is dispatched without deliveries.
+func (store *SQLStore) DispatchWebhookEventsTx(ctx context.Context, arg DispatchWebhookEventsTxParams) (DispatchWebhookEventsTxResult, error) {
+	var result DispatchWebhookEventsTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Events, err = q.ListUndispatchedWebhookEventsForUpdate(ctx, arg.Limit)
+		if err != nil {
+			return err
+		}
+
+		result.Deliveries = []WebhookDelivery{}
+		for _, event := range result.Events {
+			deliveries, err := q.CreateWebhookDeliveries(ctx, CreateWebhookDeliveriesParams{
+				EventID:    event.ID,
+				EventType:  event.EventType,
+				Owner:      event.Owner,
+				GlobalRole: WebhookGlobalRole,
+			})
+			if err != nil {
+				return err
+			}
+			for _, delivery := range deliveries {
+				if err := arg.AfterCreate(delivery); err != nil {
+					return err
+				}
+			}
+			if err := q.MarkWebhookEventDispatched(ctx, event.ID); err != nil {
+				return err
+			}
+			result.Deliveries = append(result.Deliveries, deliveries...)
+		}
+		return nil
+	})
+	return result, err
+}
+
+type RedeliverWebhookTxParams struct {
+	DeliveryID int64
+	// AfterReset enqueues the delivery again, an error leaves it as it was
+	AfterReset func(delivery WebhookDelivery) error
+}
+
+type RedeliverWebhookTxResult struct {
+	Delivery WebhookDelivery
+}
+
+// RedeliverWebhookTx sends a delivery that succeeded or failed once more.
+// It returns ErrWebhookDeliveryPending when the delivery is still being attempted.
+func (store *SQLStore) RedeliverWebhookTx(ctx context.Context, arg RedeliverWebhookTxParams) (RedeliverWebhookTxResult, error) {
+	var result RedeliverWebhookTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Delivery, err = q.ResetWebhookDelivery(ctx, arg.DeliveryID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrWebhookDeliveryPending
+			}
+			return err
+		}
+		return arg.AfterReset(result.Delivery)
+	})
+	return result, err
+}
@@ -20,7 +20,7 @@ INSERT INTO users (
     email
 ) VALUES (
  $1, $2, $3, $4
-) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified
+) RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, pending_email, kyc_tier
 `
 
 type CreateUserParams struct {
@@ -47,12 +47,15 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
 		&i.IsEmailVerified,
+		&i.Role,
+		&i.PendingEmail,
+		&i.KycTier,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified FROM users
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, pending_email, kyc_tier FROM users
 WHERE username = $1 LIMIT 1
 `
 
@@ -67,6 +70,193 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
 		&i.IsEmailVerified,
+		&i.Role,
+		&i.PendingEmail,
+		&i.KycTier,
+	)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, pending_email, kyc_tier FROM users
+WHERE email = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.PendingEmail,
+		&i.KycTier,
+	)
+	return i, err
+}
+
+const getUserForUpdate = `-- name: GetUserForUpdate :one
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, pending_email, kyc_tier FROM users
+WHERE username = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+// GetUserForUpdate locks the user, the transfers checked against user limits are serialized on it
+func (q *Queries) GetUserForUpdate(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserForUpdate, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.PendingEmail,
+		&i.KycTier,
+	)
+	return i, err
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, pending_email, kyc_tier FROM users
+ORDER BY username
+LIMIT $1
+OFFSET $2
+`
+
+type ListUsersParams struct {
+	Limit  int64 `json:"limit"`
+	Offset int64 `json:"offset"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.Query(ctx, listUsers, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.Username,
+			&i.HashedPassword,
+			&i.FullName,
+			&i.Email,
+			&i.PasswordChangedAt,
+			&i.CreatedAt,
+			&i.IsEmailVerified,
+			&i.Role,
+			&i.PendingEmail,
+			&i.KycTier,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setPendingEmail = `-- name: SetPendingEmail :one
+UPDATE users
+SET pending_email = $2
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, pending_email, kyc_tier
+`
+
+type SetPendingEmailParams struct {
+	Username     string      `json:"username"`
+	PendingEmail pgtype.Text `json:"pending_email"`
+}
+
+func (q *Queries) SetPendingEmail(ctx context.Context, arg SetPendingEmailParams) (User, error) {
+	row := q.db.QueryRow(ctx, setPendingEmail, arg.Username, arg.PendingEmail)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.PendingEmail,
+		&i.KycTier,
+	)
+	return i, err
+}
+
+const setUserKYCTier = `-- name: SetUserKYCTier :one
+UPDATE users
+SET kyc_tier = $2
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, pending_email, kyc_tier
+`
+
+type SetUserKYCTierParams struct {
+	Username string `json:"username"`
+	KycTier  int32  `json:"kyc_tier"`
+}
+
+func (q *Queries) SetUserKYCTier(ctx context.Context, arg SetUserKYCTierParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserKYCTier, arg.Username, arg.KycTier)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.PendingEmail,
+		&i.KycTier,
+	)
+	return i, err
+}
+
+const switchToPendingEmail = `-- name: SwitchToPendingEmail :one
+UPDATE users
+SET email = pending_email,
+    pending_email = NULL,
+    is_email_verified = true
+WHERE username = $1 AND pending_email = $2
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, pending_email, kyc_tier
+`
+
+type SwitchToPendingEmailParams struct {
+	Username     string      `json:"username"`
+	PendingEmail pgtype.Text `json:"pending_email"`
+}
+
+// SwitchToPendingEmail makes the pending address the email of the user once it is verified
+func (q *Queries) SwitchToPendingEmail(ctx context.Context, arg SwitchToPendingEmailParams) (User, error) {
+	row := q.db.QueryRow(ctx, switchToPendingEmail, arg.Username, arg.PendingEmail)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.IsEmailVerified,
+		&i.Role,
+		&i.PendingEmail,
+		&i.KycTier,
 	)
 	return i, err
 }
@@ -81,7 +271,7 @@ SET
     is_email_verified = COALESCE($5, is_email_verified)
 WHERE
     username = $6
-RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified
+RETURNING username, hashed_password, full_name, email, password_changed_at, created_at, is_email_verified, role, pending_email, kyc_tier
 `
 
 type UpdateUserParams struct {
@@ -111,6 +301,9 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
 		&i.IsEmailVerified,
+		&i.Role,
+		&i.PendingEmail,
+		&i.KycTier,
 	)
 	return i, err
 }
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 	"github.com/the-eduardo/Go-Bank/util"
@@ -27,6 +28,7 @@ func createRandomUser(t *testing.T) User {
 	require.Equal(t, arg.HashedPassword, user.HashedPassword)
 	require.Equal(t, arg.FullName, user.FullName)
 	require.Equal(t, arg.Email, user.Email)
+	require.Equal(t, util.DepositorRole, user.Role)
 
 	require.NotZero(t, user.CreatedAt)
 
@@ -174,3 +176,59 @@ func TestUpdateAllUserFields(t *testing.T) {
 	require.WithinDuration(t, oldUser.CreatedAt.Time, updatedUser.CreatedAt.Time, time.Second)
 	require.WithinDuration(t, oldUser.PasswordChangedAt.Time, updatedUser.PasswordChangedAt.Time, time.Second)
 }
+
+func TestListUsers(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		createRandomUser(t)
+	}
+
+	users, err := testQueries.ListUsers(context.Background(), ListUsersParams{
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, users, 5)
+	for i := 1; i < len(users); i++ {
+		require.Less(t, users[i-1].Username, users[i].Username)
+	}
+}
+
+func TestGetUserByEmail(t *testing.T) {
+	user := createRandomUser(t)
+
+	found, err := testQueries.GetUserByEmail(context.Background(), user.Email)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, found.Username)
+
+	_, err = testQueries.GetUserByEmail(context.Background(), util.RandomEmail())
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestSwitchToPendingEmail(t *testing.T) {
+	user := createRandomUser(t)
+	newEmail := pgtype.Text{String: util.RandomEmail(), Valid: true}
+
+	pending, err := testQueries.SetPendingEmail(context.Background(), SetPendingEmailParams{
+		Username:     user.Username,
+		PendingEmail: newEmail,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Email, pending.Email)
+	require.Equal(t, newEmail, pending.PendingEmail)
+
+	// Only the address that is still pending can be switched to
+	_, err = testQueries.SwitchToPendingEmail(context.Background(), SwitchToPendingEmailParams{
+		Username:     user.Username,
+		PendingEmail: pgtype.Text{String: util.RandomEmail(), Valid: true},
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	switched, err := testQueries.SwitchToPendingEmail(context.Background(), SwitchToPendingEmailParams{
+		Username:     user.Username,
+		PendingEmail: newEmail,
+	})
+	require.NoError(t, err)
+	require.Equal(t, newEmail.String, switched.Email)
+	require.False(t, switched.PendingEmail.Valid)
+	require.True(t, switched.IsEmailVerified)
+}
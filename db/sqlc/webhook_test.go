@@ -0,0 +1,206 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/util"
+	"testing"
+)
+
+// createRandomUserWithRole creates a user with role, there is no query to change the role of a user so it is set by hand
+func createRandomUserWithRole(t *testing.T, role string) User {
+	user := createRandomUser(t)
+	_, err := testDB.Exec(context.Background(), "UPDATE users SET role = $1 WHERE username = $2", role, user.Username)
+	require.NoError(t, err)
+	user.Role = role
+	return user
+}
+
+func createRandomWebhookSubscription(t *testing.T, owner string, isGlobal bool, eventTypes ...string) WebhookSubscription {
+	arg := CreateWebhookSubscriptionParams{
+		Owner:      owner,
+		Url:        "https://partner.example.com/" + util.RandomString(8),
+		Secret:     util.RandomString(32),
+		EventTypes: eventTypes,
+		IsGlobal:   isGlobal,
+	}
+	subscription, err := testQueries.CreateWebhookSubscription(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Owner, subscription.Owner)
+	require.Equal(t, arg.Url, subscription.Url)
+	require.Equal(t, arg.Secret, subscription.Secret)
+	require.Equal(t, arg.EventTypes, subscription.EventTypes)
+	require.Equal(t, arg.IsGlobal, subscription.IsGlobal)
+	require.NotZero(t, subscription.CreatedAt)
+	return subscription
+}
+
+// dispatchWebhookEvents dispatches the whole outbox and returns the deliveries created by subscription
+func dispatchWebhookEvents(t *testing.T, store Store) map[int64][]WebhookDelivery {
+	deliveries := map[int64][]WebhookDelivery{}
+	for {
+		var enqueued []WebhookDelivery
+		result, err := store.DispatchWebhookEventsTx(context.Background(), DispatchWebhookEventsTxParams{
+			Limit: 100,
+			AfterCreate: func(delivery WebhookDelivery) error {
+				enqueued = append(enqueued, delivery)
+				return nil
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, enqueued, result.Deliveries)
+		for _, delivery := range result.Deliveries {
+			deliveries[delivery.SubscriptionID] = append(deliveries[delivery.SubscriptionID], delivery)
+		}
+		if len(result.Events) < 100 {
+			return deliveries
+		}
+	}
+}
+
+func TestWebhookOutbox(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createEmptyAccount(t, util.USD)
+	account2 := createEmptyAccount(t, util.USD)
+	subscription := createRandomWebhookSubscription(t, account1.Owner, false, util.WebhookTransferPosted)
+	other := createRandomWebhookSubscription(t, account1.Owner, false, util.WebhookUserCreated)
+	banker := createRandomUserWithRole(t, util.BankerRole)
+	staff := createRandomWebhookSubscription(t, banker.Username, false, util.WebhookTransferPosted)
+
+	_, err := store.DepositTx(context.Background(), DepositTxParams{AccountID: account1.ID, Amount: 100})
+	require.NoError(t, err)
+
+	// a transfer that is rolled back leaves nothing in the outbox
+	_, err = store.TransferTx(context.Background(), TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 1000})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 30})
+	require.NoError(t, err)
+
+	dispatched := dispatchWebhookEvents(t, store)
+	require.Len(t, dispatched[subscription.ID], 1)
+	delivery := dispatched[subscription.ID][0]
+	require.Equal(t, WebhookDeliveryPending, delivery.Status)
+	require.Zero(t, delivery.Attempts)
+	require.False(t, delivery.ResponseStatus.Valid)
+	require.Empty(t, dispatched[other.ID])
+	// the subscriptions of the staff only receive the events of their own accounts
+	require.Empty(t, dispatched[staff.ID])
+
+	event, err := testQueries.GetWebhookEvent(context.Background(), delivery.EventID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Owner, event.Owner)
+	require.Equal(t, util.WebhookTransferPosted, event.EventType)
+	require.True(t, event.DispatchedAt.Valid)
+
+	var data TransferPostedEvent
+	require.NoError(t, json.Unmarshal(event.Payload, &data))
+	require.Equal(t, transfer.Transfer.ID, data.Transfer.ID)
+	require.Equal(t, account1.ID, data.Account.ID)
+	require.Equal(t, int64(70), data.Account.Balance)
+	require.Equal(t, transfer.FromEntry.ID, data.Entry.ID)
+
+	// the events are only dispatched once
+	require.Empty(t, dispatchWebhookEvents(t, store)[subscription.ID])
+}
+
+func TestWebhookUserCreated(t *testing.T) {
+	store := NewStore(testDB)
+	admin := createRandomUserWithRole(t, util.AdminRole)
+	subscription := createRandomWebhookSubscription(t, admin.Username, true, util.WebhookUserCreated)
+	// a global subscription stops receiving the events once its owner is no longer an admin
+	demoted := createRandomUserWithRole(t, util.AdminRole)
+	revoked := createRandomWebhookSubscription(t, demoted.Username, true, util.WebhookUserCreated)
+	_, err := testDB.Exec(context.Background(), "UPDATE users SET role = $1 WHERE username = $2", util.BankerRole, demoted.Username)
+	require.NoError(t, err)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(6))
+	require.NoError(t, err)
+	result, err := store.CreateUserTx(context.Background(), CreateUserTxParams{
+		CreateUserParams: CreateUserParams{
+			Username:       util.RandomOwner(),
+			HashedPassword: hashedPassword,
+			FullName:       util.RandomOwner(),
+			Email:          util.RandomEmail(),
+		},
+		AfterCreate: func(user User) error {
+			return nil
+		},
+	})
+	require.NoError(t, err)
+
+	dispatched := dispatchWebhookEvents(t, store)
+	require.Empty(t, dispatched[revoked.ID])
+
+	// the global subscriptions receive the events of every user
+	var created *UserCreatedEvent
+	for _, delivery := range dispatched[subscription.ID] {
+		event, err := testQueries.GetWebhookEvent(context.Background(), delivery.EventID)
+		require.NoError(t, err)
+		if event.Owner == result.User.Username {
+			created = &UserCreatedEvent{}
+			require.NoError(t, json.Unmarshal(event.Payload, created))
+		}
+	}
+	require.NotNil(t, created)
+	require.Equal(t, result.User.Username, created.Username)
+	require.Equal(t, result.User.Email, created.Email)
+}
+
+func TestRedeliverWebhookTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createEmptyAccount(t, util.USD)
+	account2 := createEmptyAccount(t, util.USD)
+	subscription := createRandomWebhookSubscription(t, account2.Owner, false, util.WebhookTransferPosted)
+
+	_, err := store.DepositTx(context.Background(), DepositTxParams{AccountID: account1.ID, Amount: 100})
+	require.NoError(t, err)
+	_, err = store.TransferTx(context.Background(), TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10})
+	require.NoError(t, err)
+	deliveries := dispatchWebhookEvents(t, store)[subscription.ID]
+	require.Len(t, deliveries, 1)
+	delivery := deliveries[0]
+
+	redeliver := func() (RedeliverWebhookTxResult, error) {
+		return store.RedeliverWebhookTx(context.Background(), RedeliverWebhookTxParams{
+			DeliveryID: delivery.ID,
+			AfterReset: func(delivery WebhookDelivery) error {
+				return nil
+			},
+		})
+	}
+	_, err = redeliver()
+	require.ErrorIs(t, err, ErrWebhookDeliveryPending)
+
+	failed, err := testQueries.RecordWebhookDeliveryAttempt(context.Background(), RecordWebhookDeliveryAttemptParams{
+		ID:             delivery.ID,
+		Status:         WebhookDeliveryFailed,
+		ResponseStatus: pgtype.Int4{Int32: 500, Valid: true},
+		Error:          "receiver answered 500",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), failed.Attempts)
+	require.True(t, failed.LastAttemptAt.Valid)
+
+	result, err := redeliver()
+	require.NoError(t, err)
+	require.Equal(t, WebhookDeliveryPending, result.Delivery.Status)
+	require.Equal(t, int32(1), result.Delivery.Attempts)
+
+	rows, err := testQueries.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+		SubscriptionID: subscription.ID,
+		Limit:          5,
+		Offset:         0,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, delivery.ID, rows[0].WebhookDelivery.ID)
+	require.Equal(t, util.WebhookTransferPosted, rows[0].EventType)
+
+	// the deliveries are deleted with their subscription
+	require.NoError(t, testQueries.DeleteWebhookSubscription(context.Background(), subscription.ID))
+	_, err = testQueries.GetWebhookDelivery(context.Background(), delivery.ID)
+	require.Error(t, err)
+}
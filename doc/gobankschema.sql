@@ -7,7 +7,10 @@ CREATE TABLE "users" (
   "hashed_password" varchar NOT NULL,
   "full_name" varchar NOT NULL,
   "email" varchar UNIQUE NOT NULL,
+  "pending_email" varchar,
   "is_email_verified" boolean NOT NULL DEFAULT false,
+  "role" varchar NOT NULL DEFAULT 'depositor',
+  "kyc_tier" int NOT NULL DEFAULT 0,
   "password_changed_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
@@ -26,7 +29,11 @@ CREATE TABLE "accounts" (
   "id" bigserial PRIMARY KEY,
   "owner" varchar NOT NULL,
   "balance" bigint NOT NULL,
+  "available_balance" bigint NOT NULL,
+  "balance_version" bigint NOT NULL DEFAULT 0,
   "currency" varchar NOT NULL,
+  "overdraft_limit" bigint NOT NULL DEFAULT 0,
+  "is_system" boolean NOT NULL DEFAULT false,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -34,6 +41,14 @@ CREATE TABLE "entries" (
   "id" bigserial PRIMARY KEY,
   "account_id" bigint NOT NULL,
   "amount" bigint NOT NULL,
+  "journal_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "journals" (
+  "id" bigserial PRIMARY KEY,
+  "kind" varchar NOT NULL,
+  "transfer_id" bigint,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -42,6 +57,47 @@ CREATE TABLE "transfers" (
   "from_account_id" bigint NOT NULL,
   "to_account_id" bigint NOT NULL,
   "amount" bigint NOT NULL,
+  "to_amount" bigint NOT NULL,
+  "exchange_rate" numeric(20,8) NOT NULL DEFAULT 1,
+  "quote_id" uuid,
+  "fee" bigint NOT NULL DEFAULT 0,
+  "status" varchar NOT NULL DEFAULT 'posted',
+  "authorized_amount" bigint,
+  "hold_expires_at" timestamptz,
+  "reversal_of" bigint,
+  "reversed_amount" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "account_transfer_limits" (
+  "account_id" bigint PRIMARY KEY,
+  "per_transfer" bigint,
+  "daily" bigint,
+  "weekly" bigint,
+  "hourly_count" bigint,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "cron_expression" varchar NOT NULL DEFAULT '',
+  "interval_seconds" bigint NOT NULL DEFAULT 0,
+  "next_run_at" timestamptz NOT NULL,
+  "is_active" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "scheduled_transfer_runs" (
+  "id" bigserial PRIMARY KEY,
+  "scheduled_transfer_id" bigint NOT NULL,
+  "scheduled_for" timestamptz NOT NULL,
+  "status" varchar NOT NULL,
+  "transfer_id" bigint,
+  "error" varchar NOT NULL DEFAULT '',
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -53,6 +109,117 @@ CREATE TABLE "sessions" (
   "client_ip" varchar NOT NULL,
   "is_blocked" boolean NOT NULL DEFAULT false,
   "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "family_id" uuid NOT NULL,
+  "rotated_at" timestamptz
+);
+
+CREATE TABLE "idempotency_keys" (
+  "username" varchar NOT NULL,
+  "key" varchar NOT NULL,
+  "request_hash" varchar NOT NULL,
+  "response_body" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL,
+  PRIMARY KEY ("username", "key")
+);
+
+CREATE TABLE "fx_quotes" (
+  "id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_currency" varchar NOT NULL,
+  "to_currency" varchar NOT NULL,
+  "rate" numeric(20,8) NOT NULL,
+  "is_used" boolean NOT NULL DEFAULT false,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "totp_factors" (
+  "username" varchar PRIMARY KEY,
+  "secret" varchar NOT NULL,
+  "is_confirmed" boolean NOT NULL DEFAULT false,
+  "last_used_step" bigint NOT NULL DEFAULT 0,
+  "confirmed_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "recovery_codes" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "hashed_code" varchar NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "mfa_challenges" (
+  "id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "is_used" boolean NOT NULL DEFAULT false,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "login_attempts" (
+  "scope" varchar NOT NULL,
+  "key" varchar NOT NULL,
+  "failed_count" int NOT NULL DEFAULT 0,
+  "last_failed_at" timestamptz NOT NULL DEFAULT (now()),
+  "locked_until" timestamptz,
+  PRIMARY KEY ("scope", "key")
+);
+
+CREATE TABLE "password_resets" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "hashed_code" varchar UNIQUE NOT NULL,
+  "is_used" boolean NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL
+);
+
+CREATE TABLE "kyc_submissions" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "tier" int NOT NULL,
+  "document_type" varchar NOT NULL,
+  "document_number" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "reviewed_by" varchar,
+  "review_note" varchar NOT NULL DEFAULT '',
+  "reviewed_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_subscriptions" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "url" varchar NOT NULL,
+  "secret" varchar NOT NULL,
+  "event_types" varchar[] NOT NULL,
+  "is_global" boolean NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_events" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "dispatched_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_deliveries" (
+  "id" bigserial PRIMARY KEY,
+  "event_id" bigint NOT NULL,
+  "subscription_id" bigint NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" int NOT NULL DEFAULT 0,
+  "response_status" int,
+  "error" varchar NOT NULL DEFAULT '',
+  "last_attempt_at" timestamptz,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -60,17 +227,133 @@ CREATE INDEX ON "accounts" ("owner");
 
 CREATE UNIQUE INDEX ON "accounts" ("owner", "currency");
 
+CREATE INDEX "accounts_owner_keyset" ON "accounts" ("owner", "created_at", "id");
+
 CREATE INDEX ON "entries" ("account_id");
 
+CREATE INDEX ON "entries" ("journal_id");
+
+CREATE INDEX "entries_account_keyset" ON "entries" ("account_id", "created_at", "id");
+
+CREATE INDEX "entries_account_amount_keyset" ON "entries" ("account_id", abs("amount"), "id");
+
+CREATE INDEX ON "journals" ("transfer_id");
+
 CREATE INDEX ON "transfers" ("from_account_id");
 
 CREATE INDEX ON "transfers" ("to_account_id");
 
 CREATE INDEX ON "transfers" ("from_account_id", "to_account_id");
 
+CREATE INDEX "transfers_from_account_keyset" ON "transfers" ("from_account_id", "created_at", "id");
+
+CREATE INDEX "transfers_to_account_keyset" ON "transfers" ("to_account_id", "created_at", "id");
+
+CREATE INDEX "transfers_from_account_amount_keyset" ON "transfers" ("from_account_id", "amount", "id");
+
+CREATE INDEX "transfers_to_account_amount_keyset" ON "transfers" ("to_account_id", "to_amount", "id");
+
+CREATE INDEX ON "transfers" ("reversal_of");
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+
+CREATE INDEX ON "scheduled_transfers" ("next_run_at");
+
+CREATE UNIQUE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id", "scheduled_for");
+
+CREATE INDEX ON "sessions" ("family_id");
+
+CREATE INDEX ON "idempotency_keys" ("expires_at");
+
+CREATE UNIQUE INDEX ON "recovery_codes" ("username", "hashed_code");
+
+CREATE INDEX ON "password_resets" ("username");
+
+CREATE INDEX ON "kyc_submissions" ("username");
+
+CREATE INDEX ON "kyc_submissions" ("status", "created_at");
+
+CREATE INDEX ON "webhook_subscriptions" ("owner");
+
+CREATE INDEX "webhook_events_undispatched" ON "webhook_events" ("id");
+
+CREATE UNIQUE INDEX ON "webhook_deliveries" ("event_id", "subscription_id");
+
+CREATE INDEX ON "webhook_deliveries" ("subscription_id", "id");
+
+COMMENT ON TABLE "account_transfer_limits" IS 'a null limit uses the default from the config, 0 removes the limit';
+
+COMMENT ON COLUMN "users"."role" IS 'depositor, banker or admin';
+
+COMMENT ON COLUMN "users"."pending_email" IS 'new address waiting for verification, email keeps the current one until then';
+
+COMMENT ON COLUMN "users"."kyc_tier" IS '0 until a kyc submission is approved, it sets the daily transfer limit';
+
+COMMENT ON COLUMN "accounts"."available_balance" IS 'balance minus the holds of pending transfers';
+
+COMMENT ON COLUMN "accounts"."balance_version" IS 'incremented by every change of balance or available_balance, orders the account events';
+
+COMMENT ON COLUMN "accounts"."overdraft_limit" IS 'how far below zero the balance may go';
+
 COMMENT ON COLUMN "entries"."amount" IS 'can be negative or positive';
 
-COMMENT ON COLUMN "transfers"."amount" IS 'must be positive';
+COMMENT ON COLUMN "entries"."journal_id" IS 'null only for entries recorded before the ledger';
+
+COMMENT ON COLUMN "journals"."transfer_id" IS 'transfer posted by the journal, null for deposits and other journals';
+
+COMMENT ON COLUMN "transfers"."amount" IS 'must be positive, in the source account currency';
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'credited amount, in the destination account currency';
+
+COMMENT ON COLUMN "transfers"."fee" IS 'charged to the source account on top of amount, in its currency';
+
+COMMENT ON COLUMN "transfers"."status" IS 'pending while the amount and fee are held, posted once they reach the ledger, voided or expired when the hold is released';
+
+COMMENT ON COLUMN "transfers"."authorized_amount" IS 'amount held by the authorization, null for transfers posted at once';
+
+COMMENT ON COLUMN "transfers"."reversal_of" IS 'transfer refunded by this one, null for other transfers';
+
+COMMENT ON COLUMN "transfers"."reversed_amount" IS 'sum of the reversals of this transfer';
+
+COMMENT ON COLUMN "scheduled_transfers"."owner" IS 'owner of the source account, who gets the failure emails';
+
+COMMENT ON COLUMN "scheduled_transfers"."cron_expression" IS 'standard cron expression in UTC, empty when interval_seconds is set';
+
+COMMENT ON COLUMN "scheduled_transfers"."next_run_at" IS 'advanced when the run is enqueued, missed runs are skipped';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."scheduled_for" IS 'one run per occurrence, so a retried run never transfers twice';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."status" IS 'succeeded or failed';
+
+COMMENT ON COLUMN "sessions"."family_id" IS 'id of the login session this refresh token was rotated from';
+
+COMMENT ON COLUMN "sessions"."rotated_at" IS 'set once the refresh token is exchanged for a new one';
+
+COMMENT ON COLUMN "totp_factors"."last_used_step" IS 'time step of the last accepted code, older or equal steps are replays';
+
+COMMENT ON COLUMN "recovery_codes"."hashed_code" IS 'sha256 of the code, the codes are random enough not to need a slow hash';
+
+COMMENT ON COLUMN "login_attempts"."scope" IS 'username or client_ip';
+
+COMMENT ON COLUMN "login_attempts"."key" IS 'not a foreign key, failures for unknown usernames are counted too';
+
+COMMENT ON COLUMN "password_resets"."hashed_code" IS 'sha256 of the code sent by email';
+
+COMMENT ON COLUMN "kyc_submissions"."tier" IS 'kyc tier the user asks for';
+
+COMMENT ON COLUMN "kyc_submissions"."status" IS 'pending, approved or rejected';
+
+COMMENT ON COLUMN "webhook_subscriptions"."secret" IS 'signs the payloads with HMAC-SHA256, only shown when the subscription is created';
+
+COMMENT ON COLUMN "webhook_subscriptions"."is_global" IS 'only for admins, receives the events of every user instead of those of its owner';
+
+COMMENT ON COLUMN "webhook_events"."owner" IS 'user the event is about, only the subscriptions of this user and the global ones receive it';
+
+COMMENT ON COLUMN "webhook_events"."dispatched_at" IS 'set once a delivery is created for every matching subscription';
+
+COMMENT ON COLUMN "webhook_deliveries"."status" IS 'pending until an attempt succeeds or the retries run out';
+
+COMMENT ON COLUMN "webhook_deliveries"."response_status" IS 'http status of the last attempt, null when the receiver could not be reached';
 
 ALTER TABLE "verify_emails" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
 
@@ -78,8 +361,52 @@ ALTER TABLE "accounts" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username")
 
 ALTER TABLE "entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
 
+ALTER TABLE "entries" ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
+ALTER TABLE "journals" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
 ALTER TABLE "transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
 
 ALTER TABLE "transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
 
+ALTER TABLE "account_transfer_limits" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
 ALTER TABLE "sessions" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("quote_id") REFERENCES "fx_quotes" ("id");
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reversal_of") REFERENCES "transfers" ("id");
+
+ALTER TABLE "fx_quotes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "totp_factors" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "recovery_codes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "mfa_challenges" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "password_resets" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "kyc_submissions" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "kyc_submissions" ADD FOREIGN KEY ("reviewed_by") REFERENCES "users" ("username");
+
+ALTER TABLE "webhook_subscriptions" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "webhook_events" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("event_id") REFERENCES "webhook_events" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("subscription_id") REFERENCES "webhook_subscriptions" ("id") ON DELETE CASCADE;
@@ -0,0 +1,98 @@
+package events
+
+import (
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"sync"
+)
+
+// subscriptionBuffer is how many events a subscriber may fall behind before it is dropped
+const subscriptionBuffer = 64
+
+// Hub fans the account events out to the subscribers of each account
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[int64]map[*Subscription]struct{}
+}
+
+// NewHub creates a hub without subscribers
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: make(map[int64]map[*Subscription]struct{}),
+	}
+}
+
+// Subscription receives the events of one account until it is closed or dropped
+type Subscription struct {
+	hub       *Hub
+	accountID int64
+	events    chan db.AccountEvent
+}
+
+// Subscribe starts receiving the events of an account. The subscription must be closed when it is no longer read.
+func (hub *Hub) Subscribe(accountID int64) *Subscription {
+	subscription := &Subscription{
+		hub:       hub,
+		accountID: accountID,
+		events:    make(chan db.AccountEvent, subscriptionBuffer),
+	}
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	if hub.subscribers[accountID] == nil {
+		hub.subscribers[accountID] = make(map[*Subscription]struct{})
+	}
+	hub.subscribers[accountID][subscription] = struct{}{}
+	return subscription
+}
+
+// Events returns the events of the account in the order they were published.
+// The channel is closed when the hub drops the subscription, the subscriber then has to resume from its last event.
+func (subscription *Subscription) Events() <-chan db.AccountEvent {
+	return subscription.events
+}
+
+// Close stops the subscription
+func (subscription *Subscription) Close() {
+	hub := subscription.hub
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	hub.remove(subscription)
+}
+
+// Publish sends an event to the subscribers of its account without waiting for them.
+// A subscriber whose buffer is full is dropped rather than slowing the others down.
+func (hub *Hub) Publish(event db.AccountEvent) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	for subscription := range hub.subscribers[event.AccountID] {
+		select {
+		case subscription.events <- event:
+		default:
+			hub.remove(subscription)
+		}
+	}
+}
+
+// DropAll drops every subscription, for when events may have been missed
+func (hub *Hub) DropAll() {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	for _, subscriptions := range hub.subscribers {
+		for subscription := range subscriptions {
+			hub.remove(subscription)
+		}
+	}
+}
+
+// remove closes the events of a subscription that is still registered, the caller holds the lock
+func (hub *Hub) remove(subscription *Subscription) {
+	subscriptions := hub.subscribers[subscription.accountID]
+	if _, ok := subscriptions[subscription]; !ok {
+		return
+	}
+	delete(subscriptions, subscription)
+	if len(subscriptions) == 0 {
+		delete(hub.subscribers, subscription.accountID)
+	}
+	close(subscription.events)
+}
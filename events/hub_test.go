@@ -0,0 +1,78 @@
+package events
+
+import (
+	"github.com/stretchr/testify/require"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"testing"
+)
+
+func TestPublish(t *testing.T) {
+	hub := NewHub()
+	first := hub.Subscribe(1)
+	defer first.Close()
+	second := hub.Subscribe(1)
+	defer second.Close()
+	other := hub.Subscribe(2)
+	defer other.Close()
+
+	event := db.AccountEvent{Kind: db.AccountEventHold, AccountID: 1, Balance: 100, AvailableBalance: 40}
+	hub.Publish(event)
+
+	require.Equal(t, event, <-first.Events())
+	require.Equal(t, event, <-second.Events())
+	require.Empty(t, other.Events())
+}
+
+func TestClose(t *testing.T) {
+	hub := NewHub()
+	subscription := hub.Subscribe(1)
+	subscription.Close()
+	subscription.Close()
+
+	_, ok := <-subscription.Events()
+	require.False(t, ok)
+	require.Empty(t, hub.subscribers)
+
+	// publishing to an account without subscribers is a no-op
+	hub.Publish(db.AccountEvent{AccountID: 1})
+}
+
+func TestPublishDropsSlowSubscriber(t *testing.T) {
+	hub := NewHub()
+	slow := hub.Subscribe(1)
+	defer slow.Close()
+
+	for i := 0; i <= subscriptionBuffer; i++ {
+		hub.Publish(db.AccountEvent{AccountID: 1, Balance: int64(i)})
+	}
+
+	// the buffered events are still delivered before the channel reports the drop
+	for i := 0; i < subscriptionBuffer; i++ {
+		event, ok := <-slow.Events()
+		require.True(t, ok)
+		require.Equal(t, int64(i), event.Balance)
+	}
+	_, ok := <-slow.Events()
+	require.False(t, ok)
+
+	// a new subscription of the same account is not affected
+	fresh := hub.Subscribe(1)
+	defer fresh.Close()
+	hub.Publish(db.AccountEvent{AccountID: 1})
+	require.Len(t, fresh.Events(), 1)
+}
+
+func TestDropAll(t *testing.T) {
+	hub := NewHub()
+	first := hub.Subscribe(1)
+	second := hub.Subscribe(2)
+
+	hub.DropAll()
+
+	_, ok := <-first.Events()
+	require.False(t, ok)
+	_, ok = <-second.Events()
+	require.False(t, ok)
+	require.Empty(t, hub.subscribers)
+	first.Close()
+}
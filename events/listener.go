@@ -0,0 +1,64 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"time"
+)
+
+// listenRetryDelay is how long the listener waits before it connects again after an error
+const listenRetryDelay = 5 * time.Second
+
+// Listen publishes the account events notified by Postgres until ctx is done.
+// The listener takes a connection of its own out of the pool and connects again when it fails.
+// The events notified while it is not listening are lost, so every subscription is dropped and the subscribers resume from their last event.
+func (hub *Hub) Listen(ctx context.Context, pool *pgxpool.Pool) error {
+	for {
+		err := hub.listen(ctx, pool)
+		hub.DropAll()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Error().Err(err).Msg("account events listener failed")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+func (hub *Hub) listen(ctx context.Context, pool *pgxpool.Pool) error {
+	pooled, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	// the connection keeps listening until it is closed, so it never goes back to the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{db.AccountEventsChannel}.Sanitize())
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	log.Info().Msgf("listening to the %s channel", db.AccountEventsChannel)
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to wait for notification: %w", err)
+		}
+		var event db.AccountEvent
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			log.Error().Err(err).Str("payload", notification.Payload).Msg("invalid account event")
+			continue
+		}
+		hub.Publish(event)
+	}
+}
@@ -0,0 +1,123 @@
+package fees
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrInvalidSchedule is returned when a schedule has negative values or unordered tiers
+var ErrInvalidSchedule = errors.New("invalid fee schedule")
+
+// Schedule lists the fee rules of the bank. The rule of a currency replaces the default rule.
+type Schedule struct {
+	Default    Rule            `json:"default"`
+	Currencies map[string]Rule `json:"currencies"`
+}
+
+// Rule charges a flat amount plus a percentage of the transfer, in basis points.
+// The first tier that covers the amount adds its own flat amount and percentage.
+// The fee is then kept between Min and Max, a zero Max does not cap it.
+type Rule struct {
+	Flat       int64  `json:"flat"`
+	PercentBps int64  `json:"percent_bps"`
+	Tiers      []Tier `json:"tiers"`
+	Min        int64  `json:"min"`
+	Max        int64  `json:"max"`
+}
+
+// Tier covers the amounts up to UpTo included, a zero UpTo covers any amount and must come last
+type Tier struct {
+	UpTo       int64 `json:"up_to"`
+	Flat       int64 `json:"flat"`
+	PercentBps int64 `json:"percent_bps"`
+}
+
+// LoadSchedule reads a JSON schedule file. Without a file, transfers are free.
+func LoadSchedule(path string) (*Schedule, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read fee schedule: %w", err)
+	}
+	var schedule Schedule
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return nil, fmt.Errorf("cannot parse fee schedule: %w", err)
+	}
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// Validate checks every rule of the schedule
+func (schedule *Schedule) Validate() error {
+	if err := schedule.Default.validate(); err != nil {
+		return fmt.Errorf("%w: default: %s", ErrInvalidSchedule, err)
+	}
+	for currency, rule := range schedule.Currencies {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("%w: %s: %s", ErrInvalidSchedule, currency, err)
+		}
+	}
+	return nil
+}
+
+// Calculate returns the fee of a transfer in the currency of the source account.
+// A nil schedule charges nothing.
+func (schedule *Schedule) Calculate(currency string, amount int64) int64 {
+	if schedule == nil {
+		return 0
+	}
+	rule, ok := schedule.Currencies[currency]
+	if !ok {
+		rule = schedule.Default
+	}
+	return rule.Calculate(amount)
+}
+
+// Calculate returns the fee of an amount
+func (rule Rule) Calculate(amount int64) int64 {
+	fee := rule.Flat + percentOf(amount, rule.PercentBps)
+	for _, tier := range rule.Tiers {
+		if tier.UpTo == 0 || amount <= tier.UpTo {
+			fee += tier.Flat + percentOf(amount, tier.PercentBps)
+			break
+		}
+	}
+	fee = max(fee, rule.Min)
+	if rule.Max > 0 {
+		fee = min(fee, rule.Max)
+	}
+	return fee
+}
+
+func (rule Rule) validate() error {
+	if rule.Flat < 0 || rule.PercentBps < 0 || rule.Min < 0 || rule.Max < 0 {
+		return fmt.Errorf("values must not be negative")
+	}
+	if rule.Max > 0 && rule.Min > rule.Max {
+		return fmt.Errorf("min must not be greater than max")
+	}
+	for i, tier := range rule.Tiers {
+		if tier.UpTo < 0 || tier.Flat < 0 || tier.PercentBps < 0 {
+			return fmt.Errorf("tier %d: values must not be negative", i)
+		}
+		if tier.UpTo == 0 && i != len(rule.Tiers)-1 {
+			return fmt.Errorf("tier %d: only the last tier may be unbounded", i)
+		}
+		if i > 0 && tier.UpTo != 0 && tier.UpTo <= rule.Tiers[i-1].UpTo {
+			return fmt.Errorf("tier %d: tiers must be in ascending order", i)
+		}
+	}
+	return nil
+}
+
+// percentOf applies basis points to an amount in minor units, rounding half up.
+// The amount is split so that large amounts do not overflow.
+func percentOf(amount int64, bps int64) int64 {
+	return amount/10000*bps + (amount%10000*bps+5000)/10000
+}
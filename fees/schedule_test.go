@@ -0,0 +1,75 @@
+package fees
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/util"
+	"math"
+	"testing"
+)
+
+func TestLoadSchedule(t *testing.T) {
+	schedule, err := LoadSchedule("schedule.json")
+	require.NoError(t, err)
+
+	// tiered
+	require.Equal(t, int64(25), schedule.Calculate(util.USD, 10000))
+	require.Equal(t, int64(51), schedule.Calculate(util.USD, 10100))
+	require.Equal(t, int64(5000), schedule.Calculate(util.USD, 10000000))
+	// flat and percentage
+	require.Equal(t, int64(50), schedule.Calculate(util.EUR, 10000))
+	// percentage with a minimum
+	require.Equal(t, int64(50), schedule.Calculate(util.BRL, 100))
+	// currencies without a rule use the default one
+	require.Zero(t, schedule.Calculate("JPY", 10000))
+
+	schedule, err = LoadSchedule("")
+	require.NoError(t, err)
+	require.Nil(t, schedule)
+	require.Zero(t, schedule.Calculate(util.USD, 10000))
+}
+
+func TestRuleCalculate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		rule   Rule
+		amount int64
+		fee    int64
+	}{
+		{"Free", Rule{}, 1000, 0},
+		{"Flat", Rule{Flat: 30}, 1000, 30},
+		{"Percentage", Rule{PercentBps: 150}, 1000, 15},
+		{"RoundsHalfUp", Rule{PercentBps: 50}, 101, 1},
+		{"RoundsDown", Rule{PercentBps: 40}, 101, 0},
+		{"Capped", Rule{PercentBps: 1000, Max: 50}, 1000, 50},
+		{"Minimum", Rule{PercentBps: 10, Min: 5}, 1000, 5},
+		{"FirstTier", Rule{Tiers: []Tier{{UpTo: 1000, Flat: 10}, {PercentBps: 100}}}, 1000, 10},
+		{"LastTier", Rule{Tiers: []Tier{{UpTo: 1000, Flat: 10}, {PercentBps: 100}}}, 2000, 20},
+		{"NoMatchingTier", Rule{Flat: 5, Tiers: []Tier{{UpTo: 1000, Flat: 10}}}, 2000, 5},
+		{"LargeAmount", Rule{PercentBps: 1}, math.MaxInt64, math.MaxInt64/10000 + 1},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.fee, tc.rule.Calculate(tc.amount))
+		})
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	invalid := []Rule{
+		{Flat: -1},
+		{Min: 10, Max: 5},
+		{Tiers: []Tier{{UpTo: 0, Flat: 1}, {UpTo: 100}}},
+		{Tiers: []Tier{{UpTo: 100}, {UpTo: 50}}},
+		{Tiers: []Tier{{UpTo: 100, PercentBps: -5}}},
+	}
+	for _, rule := range invalid {
+		schedule := Schedule{Currencies: map[string]Rule{util.USD: rule}}
+		require.ErrorIs(t, schedule.Validate(), ErrInvalidSchedule)
+	}
+
+	schedule := Schedule{Default: Rule{Tiers: []Tier{{UpTo: 100}, {UpTo: 200}, {}}}}
+	require.NoError(t, schedule.Validate())
+}
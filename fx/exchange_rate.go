@@ -0,0 +1,139 @@
+package fx
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgtype"
+	"math/big"
+	"strings"
+)
+
+// RateDecimals is the number of decimal places an exchange rate keeps
+const RateDecimals = 8
+
+// RateScale is the value of a rate of 1
+const RateScale ExchangeRate = 100_000_000
+
+var ErrInvalidRate = errors.New("invalid exchange rate")
+
+// ErrInvalidAmount is returned when a converted amount does not fit in int64 or is not positive
+var ErrInvalidAmount = errors.New("invalid converted amount")
+
+// ExchangeRate is an exact decimal rate with RateDecimals decimal places, held as the rate times RateScale.
+// It is stored as a numeric column and written in JSON as a plain decimal number, so it never goes through a float.
+type ExchangeRate int64
+
+// ParseExchangeRate reads a decimal rate like "0.92",
+// the digits after RateDecimals are rounded half away from zero
+func ParseExchangeRate(value string) (ExchangeRate, error) {
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidRate, value)
+	}
+	scaled := divRound(
+		new(big.Int).Mul(rat.Num(), big.NewInt(int64(RateScale))),
+		rat.Denom(),
+	)
+	if !scaled.IsInt64() {
+		return 0, fmt.Errorf("%w: %q is out of range", ErrInvalidRate, value)
+	}
+	return ExchangeRate(scaled.Int64()), nil
+}
+
+// String writes the rate as a decimal number without trailing zeros
+func (rate ExchangeRate) String() string {
+	value := new(big.Int).SetInt64(int64(rate))
+	sign := ""
+	if value.Sign() < 0 {
+		sign = "-"
+		value.Neg(value)
+	}
+	units, fraction := new(big.Int).QuoRem(value, big.NewInt(int64(RateScale)), new(big.Int))
+	if fraction.Sign() == 0 {
+		return sign + units.String()
+	}
+	decimals := strings.TrimRight(fmt.Sprintf("%0*s", RateDecimals, fraction.String()), "0")
+	return sign + units.String() + "." + decimals
+}
+
+func (rate ExchangeRate) MarshalJSON() ([]byte, error) {
+	return []byte(rate.String()), nil
+}
+
+func (rate *ExchangeRate) UnmarshalJSON(data []byte) error {
+	value, err := ParseExchangeRate(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+	*rate = value
+	return nil
+}
+
+// ScanNumeric reads the rate from a numeric column
+func (rate *ExchangeRate) ScanNumeric(value pgtype.Numeric) error {
+	if !value.Valid || value.NaN || value.InfinityModifier != pgtype.Finite {
+		return fmt.Errorf("%w: cannot scan %v", ErrInvalidRate, value)
+	}
+	scaled := new(big.Int).Set(value.Int)
+	exp := value.Exp + RateDecimals
+	if exp >= 0 {
+		scaled.Mul(scaled, pow10(exp))
+	} else {
+		scaled = divRound(scaled, pow10(-exp))
+	}
+	if !scaled.IsInt64() {
+		return fmt.Errorf("%w: numeric out of range", ErrInvalidRate)
+	}
+	*rate = ExchangeRate(scaled.Int64())
+	return nil
+}
+
+// NumericValue writes the rate to a numeric column
+func (rate ExchangeRate) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(int64(rate)), Exp: -RateDecimals, Valid: true}, nil
+}
+
+// Convert applies a rate to an amount in minor units.
+// The product is computed exactly and rounded half away from zero to the nearest minor unit,
+// a result that does not fit in int64 is rejected.
+func Convert(amount int64, rate ExchangeRate) (int64, error) {
+	converted := divRound(
+		new(big.Int).Mul(big.NewInt(amount), big.NewInt(int64(rate))),
+		big.NewInt(int64(RateScale)),
+	)
+	if !converted.IsInt64() {
+		return 0, fmt.Errorf("%w: %d at %s is out of range", ErrInvalidAmount, amount, rate)
+	}
+	return converted.Int64(), nil
+}
+
+// crossRate divides two rates against the same base, rounding like ParseExchangeRate
+func crossRate(from, to ExchangeRate) (ExchangeRate, error) {
+	rate := divRound(
+		new(big.Int).Mul(big.NewInt(int64(to)), big.NewInt(int64(RateScale))),
+		big.NewInt(int64(from)),
+	)
+	if !rate.IsInt64() || rate.Sign() <= 0 {
+		return 0, fmt.Errorf("%w: %s / %s is out of range", ErrInvalidRate, to, from)
+	}
+	return ExchangeRate(rate.Int64()), nil
+}
+
+// divRound divides two integers, rounding half away from zero
+func divRound(numerator, denominator *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	twice := new(big.Int).Abs(remainder)
+	twice.Lsh(twice, 1)
+	if twice.Cmp(new(big.Int).Abs(denominator)) >= 0 {
+		if numerator.Sign()*denominator.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient
+}
+
+func pow10(exp int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
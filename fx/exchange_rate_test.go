@@ -0,0 +1,84 @@
+package fx
+
+import (
+	"encoding/json"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestParseExchangeRate(t *testing.T) {
+	rate, err := ParseExchangeRate("0.92")
+	require.NoError(t, err)
+	require.Equal(t, ExchangeRate(92_000_000), rate)
+	require.Equal(t, "0.92", rate.String())
+
+	// the digits after the 8th decimal place are rounded half away from zero
+	rate, err = ParseExchangeRate("1.123456785")
+	require.NoError(t, err)
+	require.Equal(t, "1.12345679", rate.String())
+
+	require.Equal(t, "1", RateScale.String())
+
+	_, err = ParseExchangeRate("abc")
+	require.ErrorIs(t, err, ErrInvalidRate)
+	_, err = ParseExchangeRate("1e20")
+	require.ErrorIs(t, err, ErrInvalidRate)
+}
+
+func TestConvert(t *testing.T) {
+	requireConverted(t, 92, 100, 92_000_000)
+	requireConverted(t, 1, 1, 50_000_000)
+	requireConverted(t, 0, 1, 49_000_000)
+
+	// 1.005 has no exact float64, 100 * 1.005 gives 100.49999999999999 and rounds to 100
+	rate, err := ParseExchangeRate("1.005")
+	require.NoError(t, err)
+	floatRate := 1.005
+	require.Equal(t, float64(100), math.Round(100*floatRate))
+	requireConverted(t, 101, 100, rate)
+
+	// the product does not overflow int64 before the division
+	requireConverted(t, math.MaxInt64/10, math.MaxInt64/10, RateScale)
+
+	// but a result too large for int64 is rejected instead of wrapping around
+	_, err = Convert(math.MaxInt64/10, 11*RateScale)
+	require.ErrorIs(t, err, ErrInvalidAmount)
+}
+
+func requireConverted(t *testing.T, expected int64, amount int64, rate ExchangeRate) {
+	converted, err := Convert(amount, rate)
+	require.NoError(t, err)
+	require.Equal(t, expected, converted)
+}
+
+func TestExchangeRateJSON(t *testing.T) {
+	var table Table
+	err := json.Unmarshal([]byte(`{"base": "USD", "rates": {"EUR": 0.1, "BRL": "5.45"}}`), &table)
+	require.NoError(t, err)
+	require.Equal(t, ExchangeRate(10_000_000), table.Rates["EUR"])
+	require.Equal(t, ExchangeRate(545_000_000), table.Rates["BRL"])
+
+	data, err := json.Marshal(Rate{From: "USD", To: "EUR", Value: table.Rates["EUR"]})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"from": "USD", "to": "EUR", "value": 0.1}`, string(data))
+}
+
+func TestExchangeRateNumeric(t *testing.T) {
+	rate, err := ParseExchangeRate("5.92391304")
+	require.NoError(t, err)
+	numeric, err := rate.NumericValue()
+	require.NoError(t, err)
+
+	var scanned ExchangeRate
+	require.NoError(t, scanned.ScanNumeric(numeric))
+	require.Equal(t, rate, scanned)
+
+	// the database may return the value with another exponent
+	require.NoError(t, scanned.ScanNumeric(pgtype.Numeric{Int: big.NewInt(92), Exp: -2, Valid: true}))
+	require.Equal(t, ExchangeRate(92_000_000), scanned)
+
+	require.ErrorIs(t, scanned.ScanNumeric(pgtype.Numeric{}), ErrInvalidRate)
+}
@@ -0,0 +1,54 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HTTPProvider fetches rates from an HTTP endpoint answering GET <url>?base=<currency>
+// with a JSON rate table
+type HTTPProvider struct {
+	client *http.Client
+	url    string
+}
+
+// NewHTTPProvider creates a new HTTPProvider
+func NewHTTPProvider(url string) RateProvider {
+	return &HTTPProvider{
+		client: &http.Client{Timeout: 5 * time.Second},
+		url:    url,
+	}
+}
+
+func (provider *HTTPProvider) GetRate(ctx context.Context, from, to string) (Rate, error) {
+	endpoint, err := url.Parse(provider.url)
+	if err != nil {
+		return Rate{}, fmt.Errorf("invalid rates url: %w", err)
+	}
+	query := endpoint.Query()
+	query.Set("base", from)
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return Rate{}, err
+	}
+	resp, err := provider.client.Do(req)
+	if err != nil {
+		return Rate{}, fmt.Errorf("cannot fetch rates: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Rate{}, fmt.Errorf("cannot fetch rates: unexpected status %d", resp.StatusCode)
+	}
+
+	var table Table
+	if err := json.NewDecoder(resp.Body).Decode(&table); err != nil {
+		return Rate{}, fmt.Errorf("cannot parse rates: %w", err)
+	}
+	return table.rate(from, to)
+}
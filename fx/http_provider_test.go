@@ -0,0 +1,38 @@
+package fx
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/util"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPProvider(t *testing.T) {
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, util.EUR, r.URL.Query().Get("base"))
+		_, err := w.Write([]byte(`{"base": "EUR", "rates": {"USD": 1.08}}`))
+		require.NoError(t, err)
+	}))
+	defer stub.Close()
+
+	provider := NewHTTPProvider(stub.URL)
+	rate, err := provider.GetRate(context.Background(), util.EUR, util.USD)
+	require.NoError(t, err)
+	require.Equal(t, "1.08", rate.Value.String())
+
+	_, err = provider.GetRate(context.Background(), util.EUR, util.BRL)
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
+
+func TestHTTPProviderUnavailable(t *testing.T) {
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer stub.Close()
+
+	provider := NewHTTPProvider(stub.URL)
+	_, err := provider.GetRate(context.Background(), util.EUR, util.USD)
+	require.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	ProviderStatic = "static"
+	ProviderHTTP   = "http"
+)
+
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// Rate is the price of one unit of From expressed in To
+type Rate struct {
+	From  string       `json:"from"`
+	To    string       `json:"to"`
+	Value ExchangeRate `json:"value"`
+}
+
+// RateProvider is an interface for looking up exchange rates
+type RateProvider interface {
+	// GetRate returns the current rate to convert from one currency to another
+	GetRate(ctx context.Context, from, to string) (Rate, error)
+}
+
+// NewRateProvider creates the provider selected in the config.
+// The source is a file path for the static provider and a URL for the HTTP provider.
+func NewRateProvider(kind string, source string) (RateProvider, error) {
+	switch kind {
+	case ProviderStatic, "":
+		if source == "" {
+			return NewStaticProvider(Table{}), nil
+		}
+		return NewStaticProviderFromFile(source)
+	case ProviderHTTP:
+		return NewHTTPProvider(source), nil
+	}
+	return nil, fmt.Errorf("unsupported rate provider %q", kind)
+}
+
+// Table lists the rates of every currency against a base currency
+type Table struct {
+	Base  string                  `json:"base"`
+	Rates map[string]ExchangeRate `json:"rates"`
+}
+
+// rate derives the cross rate between two currencies of the table
+func (table Table) rate(from, to string) (Rate, error) {
+	fromRate, ok := table.baseRate(from)
+	if !ok {
+		return Rate{}, fmt.Errorf("%w: %s", ErrRateNotFound, from)
+	}
+	toRate, ok := table.baseRate(to)
+	if !ok {
+		return Rate{}, fmt.Errorf("%w: %s", ErrRateNotFound, to)
+	}
+	value, err := crossRate(fromRate, toRate)
+	if err != nil {
+		return Rate{}, err
+	}
+	return Rate{From: from, To: to, Value: value}, nil
+}
+
+func (table Table) baseRate(currency string) (ExchangeRate, bool) {
+	if currency == table.Base {
+		return RateScale, true
+	}
+	rate, ok := table.Rates[currency]
+	return rate, ok && rate > 0
+}
@@ -0,0 +1,35 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// StaticProvider serves rates from a fixed table
+type StaticProvider struct {
+	table Table
+}
+
+// NewStaticProvider creates a StaticProvider from a rate table
+func NewStaticProvider(table Table) RateProvider {
+	return &StaticProvider{table: table}
+}
+
+// NewStaticProviderFromFile creates a StaticProvider from a JSON rate table file
+func NewStaticProviderFromFile(path string) (RateProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read rates file: %w", err)
+	}
+	var table Table
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("cannot parse rates file: %w", err)
+	}
+	return NewStaticProvider(table), nil
+}
+
+func (provider *StaticProvider) GetRate(_ context.Context, from, to string) (Rate, error) {
+	return provider.table.rate(from, to)
+}
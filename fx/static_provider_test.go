@@ -0,0 +1,27 @@
+package fx
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/util"
+	"testing"
+)
+
+func TestStaticProvider(t *testing.T) {
+	provider, err := NewStaticProviderFromFile("rates.json")
+	require.NoError(t, err)
+
+	rate, err := provider.GetRate(context.Background(), util.USD, util.EUR)
+	require.NoError(t, err)
+	require.Equal(t, util.USD, rate.From)
+	require.Equal(t, util.EUR, rate.To)
+	require.Equal(t, ExchangeRate(92_000_000), rate.Value)
+
+	// cross rates are derived through the base currency, 5.45 / 0.92 = 5.923913043...
+	rate, err = provider.GetRate(context.Background(), util.EUR, util.BRL)
+	require.NoError(t, err)
+	require.Equal(t, "5.92391304", rate.Value.String())
+
+	_, err = provider.GetRate(context.Background(), util.USD, "JPY")
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
@@ -2,9 +2,18 @@ package gapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/the-eduardo/Go-Bank/api"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
 	"github.com/the-eduardo/Go-Bank/token"
+	"github.com/the-eduardo/Go-Bank/util"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"slices"
 	"strings"
 )
 
@@ -13,7 +22,17 @@ const (
 	authorizationBearer = "bearer"
 )
 
-func (server *Server) autorizeUser(ctx context.Context) (*token.Payload, error) {
+var (
+	// allRoles can call the customer RPCs
+	allRoles = []string{util.DepositorRole, util.BankerRole, util.AdminRole}
+	// adminRoles can call the admin RPCs
+	adminRoles = []string{util.AdminRole}
+)
+
+var errPermissionDenied = errors.New("permission denied")
+
+// autorizeUser verifies the access token and checks that its role is one of accessibleRoles
+func (server *Server) autorizeUser(ctx context.Context, accessibleRoles []string) (*token.Payload, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		return nil, fmt.Errorf("metadata not provided")
@@ -32,10 +51,136 @@ func (server *Server) autorizeUser(ctx context.Context) (*token.Payload, error)
 		return nil, fmt.Errorf("unsupported authorization type")
 	}
 	accessToken := fields[1]
-	payload, err := server.tokenMaker.VerifyToken(accessToken)
+	payload, err := server.tokenMaker.VerifyToken(accessToken, token.TokenTypeAccessToken)
 	if err != nil {
 		return nil, fmt.Errorf("invalid access token")
 	}
+	if !slices.Contains(accessibleRoles, payload.Role) {
+		return nil, fmt.Errorf("%w: role %q cannot call this method", errPermissionDenied, payload.Role)
+	}
+	if err := server.checkSession(ctx, payload); err != nil {
+		return nil, err
+	}
 	return payload, nil
 
 }
+
+// checkSession rejects the access tokens of a session that was logged out or revoked.
+// Access tokens issued before they carried their session have none, they stay valid until they expire.
+func (server *Server) checkSession(ctx context.Context, payload *token.Payload) error {
+	if payload.SessionID == uuid.Nil {
+		return nil
+	}
+	sessionID, err := api.ConvertGoogleUUIDToPGTypeUUID(payload.SessionID)
+	if err != nil {
+		return fmt.Errorf("invalid access token")
+	}
+	session, err := server.store.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("session not found")
+		}
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	if session.IsBlocked {
+		return fmt.Errorf("session is blocked")
+	}
+	return nil
+}
+
+// authorizeAccount loads an account and makes sure it belongs to the authenticated user.
+// Staff roles may access the accounts of any user. The returned error is already a gRPC status error.
+func (server *Server) authorizeAccount(ctx context.Context, authPayload *token.Payload, accountID int64) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return account, status.Errorf(codes.NotFound, "account not found")
+		}
+		return account, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+	if account.Owner != authPayload.Username && !util.IsStaffRole(authPayload.Role) {
+		return account, status.Errorf(codes.PermissionDenied, "account does not belong to the authenticated user")
+	}
+	return account, nil
+}
+
+// getTransferDestination loads the account a transfer is sent to.
+// System accounts belong to the ledger and never receive transfers, so they are not found either.
+// The returned error is already a gRPC status error.
+func (server *Server) getTransferDestination(ctx context.Context, accountID int64) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err == nil && account.IsSystem {
+		err = pgx.ErrNoRows
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return account, status.Errorf(codes.NotFound, "destination account not found")
+		}
+		return account, status.Errorf(codes.Internal, "failed to get destination account: %s", err)
+	}
+	return account, nil
+}
+
+// authorizeScheduledTransfer loads a scheduled transfer and makes sure it belongs to the authenticated user.
+// Staff roles may access the scheduled transfers of any user. The returned error is already a gRPC status error.
+func (server *Server) authorizeScheduledTransfer(ctx context.Context, authPayload *token.Payload, id int64) (db.ScheduledTransfer, error) {
+	scheduledTransfer, err := server.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return scheduledTransfer, status.Errorf(codes.NotFound, "scheduled transfer not found")
+		}
+		return scheduledTransfer, status.Errorf(codes.Internal, "failed to get scheduled transfer: %s", err)
+	}
+	if scheduledTransfer.Owner != authPayload.Username && !util.IsStaffRole(authPayload.Role) {
+		return scheduledTransfer, status.Errorf(codes.PermissionDenied, "scheduled transfer does not belong to the authenticated user")
+	}
+	return scheduledTransfer, nil
+}
+
+// authorizeTransfer loads a transfer and makes sure the authenticated user owns one of its accounts.
+// Staff roles may access the transfers of any user. The returned error is already a gRPC status error.
+func (server *Server) authorizeTransfer(ctx context.Context, authPayload *token.Payload, id int64) (db.Transfer, error) {
+	transfer, err := server.store.GetTransferById(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return transfer, status.Errorf(codes.NotFound, "transfer not found")
+		}
+		return transfer, status.Errorf(codes.Internal, "failed to get transfer: %s", err)
+	}
+
+	_, err = server.authorizeAccount(ctx, authPayload, transfer.FromAccountID)
+	if err != nil && status.Code(err) == codes.PermissionDenied {
+		_, err = server.authorizeAccount(ctx, authPayload, transfer.ToAccountID)
+	}
+	return transfer, err
+}
+
+// authorizeWebhookSubscription loads a webhook subscription and makes sure it belongs to the authenticated user.
+// Staff roles may access the subscriptions of any user. The returned error is already a gRPC status error.
+func (server *Server) authorizeWebhookSubscription(ctx context.Context, authPayload *token.Payload, id int64) (db.WebhookSubscription, error) {
+	subscription, err := server.store.GetWebhookSubscription(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return subscription, status.Errorf(codes.NotFound, "webhook subscription not found")
+		}
+		return subscription, status.Errorf(codes.Internal, "failed to get webhook subscription: %s", err)
+	}
+	if subscription.Owner != authPayload.Username && !util.IsStaffRole(authPayload.Role) {
+		return subscription, status.Errorf(codes.PermissionDenied, "webhook subscription does not belong to the authenticated user")
+	}
+	return subscription, nil
+}
+
+// authorizeWebhookDelivery loads a webhook delivery and makes sure its subscription belongs to the authenticated user.
+// Staff roles may access the deliveries of any user. The returned error is already a gRPC status error.
+func (server *Server) authorizeWebhookDelivery(ctx context.Context, authPayload *token.Payload, id int64) (db.WebhookDelivery, error) {
+	delivery, err := server.store.GetWebhookDelivery(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return delivery, status.Errorf(codes.NotFound, "webhook delivery not found")
+		}
+		return delivery, status.Errorf(codes.Internal, "failed to get webhook delivery: %s", err)
+	}
+	_, err = server.authorizeWebhookSubscription(ctx, authPayload, delivery.SubscriptionID)
+	return delivery, err
+}
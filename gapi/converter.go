@@ -1,8 +1,11 @@
 package gapi
 
 import (
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	db "github.com/the-eduardo/Go-Bank/db/sqlc"
 	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/statement"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -13,5 +16,226 @@ func convertUser(user db.User) *pb.User {
 		Email:             user.Email,
 		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt.Time),
 		CreatedAt:         timestamppb.New(user.CreatedAt.Time),
+		Role:              user.Role,
+		PendingEmail:      user.PendingEmail.String,
+		IsEmailVerified:   user.IsEmailVerified,
+		KycTier:           user.KycTier,
 	}
 }
+
+func convertKYCSubmission(submission db.KycSubmission) *pb.KYCSubmission {
+	resp := &pb.KYCSubmission{
+		Id:             submission.ID,
+		Username:       submission.Username,
+		Tier:           submission.Tier,
+		DocumentType:   submission.DocumentType,
+		DocumentNumber: submission.DocumentNumber,
+		Status:         submission.Status,
+		ReviewedBy:     submission.ReviewedBy.String,
+		ReviewNote:     submission.ReviewNote,
+		CreatedAt:      timestamppb.New(submission.CreatedAt.Time),
+	}
+	if submission.ReviewedAt.Valid {
+		resp.ReviewedAt = timestamppb.New(submission.ReviewedAt.Time)
+	}
+	return resp
+}
+
+func convertAccountTransferLimits(limits db.AccountTransferLimit) *pb.AccountTransferLimits {
+	return &pb.AccountTransferLimits{
+		AccountId:   limits.AccountID,
+		PerTransfer: convertInt8(limits.PerTransfer),
+		Daily:       convertInt8(limits.Daily),
+		Weekly:      convertInt8(limits.Weekly),
+		HourlyCount: convertInt8(limits.HourlyCount),
+		UpdatedAt:   timestamppb.New(limits.UpdatedAt.Time),
+	}
+}
+
+// convertInt8 maps a null column to an unset optional field
+func convertInt8(value pgtype.Int8) *int64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Int64
+}
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:               account.ID,
+		Owner:            account.Owner,
+		Balance:          account.Balance,
+		Currency:         account.Currency,
+		CreatedAt:        timestamppb.New(account.CreatedAt.Time),
+		OverdraftLimit:   account.OverdraftLimit,
+		AvailableBalance: account.AvailableBalance,
+	}
+}
+
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	resp := &pb.Transfer{
+		Id:               transfer.ID,
+		FromAccountId:    transfer.FromAccountID,
+		ToAccountId:      transfer.ToAccountID,
+		Amount:           transfer.Amount,
+		CreatedAt:        timestamppb.New(transfer.CreatedAt.Time),
+		ToAmount:         transfer.ToAmount,
+		ExchangeRate:     transfer.ExchangeRate.String(),
+		QuoteId:          convertUUID(transfer.QuoteID),
+		Fee:              transfer.Fee,
+		Status:           transfer.Status,
+		AuthorizedAmount: convertInt8(transfer.AuthorizedAmount),
+		ReversalOf:       convertInt8(transfer.ReversalOf),
+		ReversedAmount:   transfer.ReversedAmount,
+	}
+	if transfer.HoldExpiresAt.Valid {
+		resp.HoldExpiresAt = timestamppb.New(transfer.HoldExpiresAt.Time)
+	}
+	return resp
+}
+
+func convertScheduledTransfer(scheduledTransfer db.ScheduledTransfer) *pb.ScheduledTransfer {
+	return &pb.ScheduledTransfer{
+		Id:              scheduledTransfer.ID,
+		Owner:           scheduledTransfer.Owner,
+		FromAccountId:   scheduledTransfer.FromAccountID,
+		ToAccountId:     scheduledTransfer.ToAccountID,
+		Amount:          scheduledTransfer.Amount,
+		CronExpression:  scheduledTransfer.CronExpression,
+		IntervalSeconds: scheduledTransfer.IntervalSeconds,
+		NextRunAt:       timestamppb.New(scheduledTransfer.NextRunAt.Time),
+		IsActive:        scheduledTransfer.IsActive,
+		CreatedAt:       timestamppb.New(scheduledTransfer.CreatedAt.Time),
+	}
+}
+
+func convertScheduledTransferRun(run db.ScheduledTransferRun) *pb.ScheduledTransferRun {
+	return &pb.ScheduledTransferRun{
+		Id:           run.ID,
+		ScheduledFor: timestamppb.New(run.ScheduledFor.Time),
+		Status:       run.Status,
+		TransferId:   convertInt8(run.TransferID),
+		Error:        run.Error,
+		CreatedAt:    timestamppb.New(run.CreatedAt.Time),
+	}
+}
+
+// convertTransferFee returns nil for free transfers
+func convertTransferFee(fee *db.TransferFee) *pb.TransferFee {
+	if fee == nil {
+		return nil
+	}
+	return &pb.TransferFee{
+		Amount:   fee.Amount,
+		Currency: fee.Currency,
+		Entry:    convertEntry(fee.Entry),
+	}
+}
+
+func convertFxQuote(quote db.FxQuote) *pb.FxQuote {
+	return &pb.FxQuote{
+		Id:           convertUUID(quote.ID),
+		FromCurrency: quote.FromCurrency,
+		ToCurrency:   quote.ToCurrency,
+		Rate:         quote.Rate.String(),
+		ExpiresAt:    timestamppb.New(quote.ExpiresAt.Time),
+	}
+}
+
+// convertUUID returns the string form of a nullable uuid, or an empty string when it is null
+func convertUUID(id pgtype.UUID) string {
+	if !id.Valid {
+		return ""
+	}
+	return uuid.UUID(id.Bytes).String()
+}
+
+func convertEntry(entry db.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id:        entry.ID,
+		AccountId: entry.AccountID,
+		Amount:    entry.Amount,
+		CreatedAt: timestamppb.New(entry.CreatedAt.Time),
+	}
+}
+
+func convertStatement(accountStatement *statement.Statement) *pb.Statement {
+	lines := make([]*pb.StatementLine, len(accountStatement.Lines))
+	for i, line := range accountStatement.Lines {
+		lines[i] = &pb.StatementLine{
+			Time:        timestamppb.New(line.Time),
+			EntryId:     line.EntryID,
+			Description: line.Description,
+			Amount:      line.Amount,
+			Balance:     line.Balance,
+		}
+	}
+	return &pb.Statement{
+		AccountId:      accountStatement.Account.ID,
+		Owner:          accountStatement.Account.Owner,
+		Currency:       accountStatement.Account.Currency,
+		StartTime:      timestamppb.New(accountStatement.Start),
+		EndTime:        timestamppb.New(accountStatement.End),
+		OpeningBalance: accountStatement.OpeningBalance,
+		ClosingBalance: accountStatement.ClosingBalance,
+		Lines:          lines,
+	}
+}
+
+// convertSession leaves out the refresh token, which must never leave the server after login
+func convertSession(session db.Session) *pb.Session {
+	return &pb.Session{
+		Id:        convertUUID(session.ID),
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		ExpiresAt: timestamppb.New(session.ExpiresAt.Time),
+		CreatedAt: timestamppb.New(session.CreatedAt.Time),
+	}
+}
+
+func convertAccountEvent(event db.AccountEvent, eventID int64) *pb.AccountEvent {
+	resp := &pb.AccountEvent{
+		EventId:          eventID,
+		Kind:             event.Kind,
+		AccountId:        event.AccountID,
+		Balance:          event.Balance,
+		AvailableBalance: event.AvailableBalance,
+		BalanceVersion:   event.BalanceVersion,
+	}
+	if event.Entry != nil {
+		resp.Entry = convertEntry(*event.Entry)
+	}
+	return resp
+}
+
+// convertWebhookSubscription leaves out the secret, which is only returned when the subscription is created
+func convertWebhookSubscription(subscription db.WebhookSubscription) *pb.WebhookSubscription {
+	return &pb.WebhookSubscription{
+		Id:         subscription.ID,
+		Owner:      subscription.Owner,
+		Url:        subscription.Url,
+		EventTypes: subscription.EventTypes,
+		CreatedAt:  timestamppb.New(subscription.CreatedAt.Time),
+		IsGlobal:   subscription.IsGlobal,
+	}
+}
+
+func convertWebhookDelivery(delivery db.WebhookDelivery, eventType string) *pb.WebhookDelivery {
+	resp := &pb.WebhookDelivery{
+		Id:             delivery.ID,
+		EventId:        delivery.EventID,
+		EventType:      eventType,
+		SubscriptionId: delivery.SubscriptionID,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		Error:          delivery.Error,
+		CreatedAt:      timestamppb.New(delivery.CreatedAt.Time),
+	}
+	if delivery.ResponseStatus.Valid {
+		resp.ResponseStatus = &delivery.ResponseStatus.Int32
+	}
+	if delivery.LastAttemptAt.Valid {
+		resp.LastAttemptAt = timestamppb.New(delivery.LastAttemptAt.Time)
+	}
+	return resp
+}
@@ -1,6 +1,10 @@
 package gapi
 
 import (
+	"errors"
+	"fmt"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/policy"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -25,3 +29,34 @@ func invalidArgumentError(violations []*errdetails.BadRequest_FieldViolation) er
 func unauthenticatedError(err error) error {
 	return status.Errorf(codes.Unauthenticated, "unauthorized: %s", err)
 }
+
+// authorizationError maps the errors of autorizeUser for RPCs that are restricted to some roles
+func authorizationError(err error) error {
+	if errors.Is(err, errPermissionDenied) {
+		return status.Errorf(codes.PermissionDenied, "%s", err)
+	}
+	return unauthenticatedError(err)
+}
+
+// transferLimitError reports which limit a transfer would break, with what is left of it as a quota failure
+func transferLimitError(limitErr *db.TransferLimitError) error {
+	statusExhausted := status.New(codes.ResourceExhausted, limitErr.Error())
+	statusDetails, err := statusExhausted.WithDetails(&errdetails.QuotaFailure{
+		Violations: []*errdetails.QuotaFailure_Violation{{
+			Subject:     fmt.Sprintf("%s_%s_transfer_limit", limitErr.Scope, limitErr.Period),
+			Description: fmt.Sprintf("limit %d, remaining %d", limitErr.Limit, limitErr.Remaining),
+		}},
+	})
+	if err != nil {
+		return statusExhausted.Err()
+	}
+	return statusDetails.Err()
+}
+
+// transferPolicyError maps the errors of policy.TransferLimits
+func transferPolicyError(err error) error {
+	if errors.Is(err, policy.ErrEmailNotVerified) || errors.Is(err, policy.ErrKYCRequired) {
+		return status.Errorf(codes.FailedPrecondition, "%s", err)
+	}
+	return status.Errorf(codes.Internal, "failed to check transfer policy: %s", err)
+}
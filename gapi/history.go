@@ -0,0 +1,94 @@
+package gapi
+
+import (
+	"fmt"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// historyRequest is implemented by the requests that list the transaction history of an account
+type historyRequest interface {
+	GetStartTime() *timestamppb.Timestamp
+	GetEndTime() *timestamppb.Timestamp
+	GetMinAmount() int64
+	GetMaxAmount() int64
+	GetDirection() string
+	GetCounterpartyAccountId() int64
+	GetSortBy() string
+	GetSortOrder() string
+}
+
+// historyFilter holds the filters of a history request, the ones left empty are null
+type historyFilter struct {
+	startTime             pgtype.Timestamptz
+	endTime               pgtype.Timestamptz
+	minAmount             pgtype.Int8
+	maxAmount             pgtype.Int8
+	direction             pgtype.Text
+	counterpartyAccountID pgtype.Int8
+}
+
+func newHistoryFilter(req historyRequest) historyFilter {
+	var filter historyFilter
+	if req.GetStartTime() != nil {
+		filter.startTime = pgtype.Timestamptz{Time: req.GetStartTime().AsTime(), Valid: true}
+	}
+	if req.GetEndTime() != nil {
+		filter.endTime = pgtype.Timestamptz{Time: req.GetEndTime().AsTime(), Valid: true}
+	}
+	filter.minAmount = pgtype.Int8{Int64: req.GetMinAmount(), Valid: req.GetMinAmount() != 0}
+	filter.maxAmount = pgtype.Int8{Int64: req.GetMaxAmount(), Valid: req.GetMaxAmount() != 0}
+	filter.direction = pgtype.Text{String: req.GetDirection(), Valid: req.GetDirection() != ""}
+	filter.counterpartyAccountID = pgtype.Int8{Int64: req.GetCounterpartyAccountId(), Valid: req.GetCounterpartyAccountId() != 0}
+	return filter
+}
+
+func validateHistoryRequest(req historyRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetStartTime() != nil {
+		if err := req.GetStartTime().CheckValid(); err != nil {
+			violations = append(violations, fieldViolation("start_time", err))
+		}
+	}
+	if req.GetEndTime() != nil {
+		if err := req.GetEndTime().CheckValid(); err != nil {
+			violations = append(violations, fieldViolation("end_time", err))
+		} else if req.GetStartTime() != nil && !req.GetEndTime().AsTime().After(req.GetStartTime().AsTime()) {
+			violations = append(violations, fieldViolation("end_time", fmt.Errorf("must be after start_time")))
+		}
+	}
+	if req.GetMinAmount() != 0 {
+		if err := val.ValidateAmount(req.GetMinAmount()); err != nil {
+			violations = append(violations, fieldViolation("min_amount", err))
+		}
+	}
+	if req.GetMaxAmount() != 0 {
+		if err := val.ValidateAmount(req.GetMaxAmount()); err != nil {
+			violations = append(violations, fieldViolation("max_amount", err))
+		} else if req.GetMaxAmount() < req.GetMinAmount() {
+			violations = append(violations, fieldViolation("max_amount", fmt.Errorf("must not be less than min_amount")))
+		}
+	}
+	if req.GetDirection() != "" {
+		if err := val.ValidateDirection(req.GetDirection()); err != nil {
+			violations = append(violations, fieldViolation("direction", err))
+		}
+	}
+	if req.GetCounterpartyAccountId() != 0 {
+		if err := val.ValidateID(req.GetCounterpartyAccountId()); err != nil {
+			violations = append(violations, fieldViolation("counterparty_account_id", err))
+		}
+	}
+	if req.GetSortBy() != "" {
+		if err := val.ValidateSortBy(req.GetSortBy()); err != nil {
+			violations = append(violations, fieldViolation("sort_by", err))
+		}
+	}
+	if req.GetSortOrder() != "" {
+		if err := val.ValidateSortOrder(req.GetSortOrder()); err != nil {
+			violations = append(violations, fieldViolation("sort_order", err))
+		}
+	}
+	return violations
+}
@@ -0,0 +1,29 @@
+package gapi
+
+import (
+	"encoding/json"
+	"github.com/the-eduardo/Go-Bank/token"
+	"net/http"
+)
+
+// JWKSPath is where the public keys of the token maker are served
+const JWKSPath = "/.well-known/jwks.json"
+
+// JWKSHandler serves the public keys that verify our tokens.
+// It answers 404 when the tokens are signed with a symmetric key, since there is nothing to publish.
+func (server *Server) JWKSHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		maker, ok := server.tokenMaker.(token.PublicKeyMaker)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		_ = json.NewEncoder(w).Encode(maker.JWKS())
+	})
+}
@@ -0,0 +1,75 @@
+package gapi
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/token"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestJWKSHandler(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	publicKeyMaker, err := token.NewPasetoPublicMaker(privateKey, "key-1")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		method        string
+		tokenMaker    func(server *Server) token.Maker
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			method: http.MethodGet,
+			tokenMaker: func(server *Server) token.Maker {
+				return publicKeyMaker
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var jwks token.JSONWebKeySet
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &jwks))
+				require.Len(t, jwks.Keys, 1)
+				require.Equal(t, "key-1", jwks.Keys[0].KeyID)
+			},
+		},
+		{
+			name:   "SymmetricMaker",
+			method: http.MethodGet,
+			tokenMaker: func(server *Server) token.Maker {
+				return server.tokenMaker
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "MethodNotAllowed",
+			method: http.MethodPost,
+			tokenMaker: func(server *Server) token.Maker {
+				return publicKeyMaker
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil, nil)
+			server.tokenMaker = tc.tokenMaker(server)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tc.method, JWKSPath, nil)
+			server.JWKSHandler().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
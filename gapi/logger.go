@@ -37,6 +37,34 @@ func GrpcLogger(
 	return result, err
 }
 
+// GrpcStreamLogger logs the streaming calls when they end
+func GrpcStreamLogger(
+	srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	startTime := time.Now()
+	err := handler(srv, stream)
+	duration := time.Since(startTime)
+
+	statusCode := codes.Unknown
+	if st, ok := status.FromError(err); ok {
+		statusCode = st.Code()
+	}
+	loggerLevel := log.Info()
+	if err != nil {
+		loggerLevel = log.Error()
+	}
+	loggerLevel.Str("protocol", "grpc").
+		Str("method", info.FullMethod).
+		Int("status_code", int(statusCode)).
+		Str("status_text", statusCode.String()).
+		Dur("duration", duration).
+		Msg("received a gRPC stream")
+
+	return err
+}
+
 type ResponseRecorder struct {
 	http.ResponseWriter
 	statusCode int
@@ -52,6 +80,11 @@ func (rec *ResponseRecorder) Write(body []byte) (int, error) {
 	return rec.ResponseWriter.Write(body)
 }
 
+// Unwrap lets http.ResponseController flush the streamed responses through the recorder
+func (rec *ResponseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
 func HttpLogger(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
 		startTime := time.Now()
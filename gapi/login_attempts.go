@@ -0,0 +1,78 @@
+package gapi
+
+import (
+	"context"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/worker"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// errInvalidCredentials is the same for an unknown username and a wrong password,
+// so that logins cannot be used to find out which usernames exist
+var errInvalidCredentials = status.Errorf(codes.Unauthenticated, "invalid username or password")
+
+// checkLoginLockout refuses a login while the username or the client ip is backing off after failed attempts
+func (server *Server) checkLoginLockout(ctx context.Context, username string, clientIP string) error {
+	lockedUntil, err := server.store.GetLoginLockout(ctx, db.GetLoginLockoutParams{
+		Username: username,
+		ClientIp: clientIP,
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to check login attempts")
+	}
+	if lockedUntil.Valid && lockedUntil.Time.After(time.Now()) {
+		return status.Errorf(codes.ResourceExhausted, "too many failed login attempts, try again after %s",
+			lockedUntil.Time.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// loginFailed counts a failed attempt for the username and the client ip and returns failure.
+// The user is notified by email when the attempt locks them out.
+func (server *Server) loginFailed(ctx context.Context, username string, clientIP string, userExists bool, failure error) error {
+	result, err := server.store.LoginFailureTx(ctx, db.LoginFailureTxParams{
+		Username:            username,
+		ClientIP:            clientIP,
+		MaxUserAttempts:     server.config.LoginMaxAttempts,
+		MaxClientIPAttempts: server.config.LoginMaxAttemptsPerIP,
+		LockoutDuration:     server.config.LoginLockoutDuration,
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to record login attempt")
+	}
+	if result.UserLockedOut && userExists {
+		taskPayload := &worker.PayloadSendLockoutEmail{
+			Username:    username,
+			ClientIP:    clientIP,
+			LockedUntil: result.UserAttempt.LockedUntil.Time,
+		}
+		opts := []asynq.Option{
+			asynq.MaxRetry(7),
+			asynq.Timeout(15 * time.Second),
+			asynq.Queue(worker.QueueEmail),
+		}
+		// The lockout is already in place, a lost notification must not change the response
+		err = server.taskDistributor.DistributeTaskSendLockoutEmail(ctx, taskPayload, opts...)
+		if err != nil {
+			log.Error().Err(err).Str("username", username).Msg("cannot distribute lockout email")
+		}
+	}
+	return failure
+}
+
+// resetLoginAttempts forgets the failed attempts of a user once they log in.
+// The client ip is not reset, otherwise logging into an own account would clear the backoff of an attacker.
+func (server *Server) resetLoginAttempts(ctx context.Context, username string) error {
+	err := server.store.ResetLoginAttempts(ctx, db.ResetLoginAttemptsParams{
+		Scope: db.LoginScopeUsername,
+		Key:   username,
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to reset login attempts")
+	}
+	return nil
+}
@@ -3,8 +3,10 @@ package gapi
 import (
 	"context"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/events"
 	"github.com/the-eduardo/Go-Bank/token"
 	"github.com/the-eduardo/Go-Bank/util"
 	"github.com/the-eduardo/Go-Bank/worker"
@@ -13,20 +15,30 @@ import (
 	"time"
 )
 
+// testDailyTransferLimits are the kyc tier limits of the test server
+var testDailyTransferLimits = []int64{0, 100000, 1000000}
+
 func newTestServer(t *testing.T, store db.Store, taskDistributor worker.TaskDistributor) *Server {
 	config := util.Config{
-		TokenSymmetricKey:   util.RandomString(32),
-		AccessTokenDuration: time.Minute,
+		TokenSymmetricKey:      util.RandomString(32),
+		AccessTokenDuration:    time.Minute,
+		RefreshTokenDuration:   time.Hour,
+		LoginMaxAttempts:       5,
+		LoginMaxAttemptsPerIP:  20,
+		LoginLockoutDuration:   15 * time.Minute,
+		RequireVerifiedEmail:   true,
+		KYCDailyTransferLimits: testDailyTransferLimits,
+		TransferHoldDuration:   time.Hour,
 	}
 
-	server, err := NewServer(config, store, taskDistributor)
+	server, err := NewServer(config, store, taskDistributor, events.NewHub())
 	require.NoError(t, err)
 
 	return server
 }
 
-func newContextWithBearerToken(t *testing.T, tokenMaker token.Maker, username string, duration time.Duration) context.Context {
-	accessToken, _, err := tokenMaker.CreateToken(username, duration)
+func newContextWithBearerToken(t *testing.T, tokenMaker token.Maker, username string, role string, duration time.Duration) context.Context {
+	accessToken, _, err := tokenMaker.CreateToken(username, role, uuid.Nil, duration, token.TokenTypeAccessToken)
 	require.NoError(t, err)
 
 	bearerToken := fmt.Sprintf("%s %s", authorizationBearer, accessToken)
@@ -38,3 +50,9 @@ func newContextWithBearerToken(t *testing.T, tokenMaker token.Maker, username st
 
 	return metadata.NewIncomingContext(context.Background(), md)
 }
+
+func newContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	md = metadata.Join(md, metadata.Pairs(idempotencyKeyHeader, key))
+	return metadata.NewIncomingContext(ctx, md)
+}
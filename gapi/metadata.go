@@ -10,11 +10,13 @@ const (
 	grpcGatewayUserAgentHeader = "grpcgateway-user-agent"
 	userAgentHeader            = "user-agent"
 	xForwardedForHeader        = "x-forwarded-for"
+	idempotencyKeyHeader       = "idempotency-key"
 )
 
 type Metadata struct {
-	UserAgent string
-	ClientIP  string
+	UserAgent      string
+	ClientIP       string
+	IdempotencyKey string
 }
 
 func (server *Server) extractMetadata(ctx context.Context) *Metadata {
@@ -29,6 +31,9 @@ func (server *Server) extractMetadata(ctx context.Context) *Metadata {
 		if clientIps := md.Get(xForwardedForHeader); len(clientIps) > 0 {
 			mtdt.ClientIP = clientIps[0]
 		}
+		if keys := md.Get(idempotencyKeyHeader); len(keys) > 0 {
+			mtdt.IdempotencyKey = keys[0]
+		}
 	}
 	if p, ok := peer.FromContext(ctx); ok {
 		mtdt.ClientIP = p.Addr.String()
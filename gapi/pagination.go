@@ -0,0 +1,36 @@
+package gapi
+
+import (
+	"fmt"
+	"github.com/the-eduardo/Go-Bank/util"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// validatePagination checks that a list request asks for its page either by page_id or by a page_token
+// issued for the same sort order. Leaving both empty asks for the first page.
+func validatePagination(pageID int32, pageToken string, sortBy string, sortOrder string) (violations []*errdetails.BadRequest_FieldViolation) {
+	if pageToken == "" {
+		if pageID != 0 {
+			if err := val.ValidatePageID(pageID); err != nil {
+				violations = append(violations, fieldViolation("page_id", err))
+			}
+		}
+		return violations
+	}
+	if pageID != 0 {
+		violations = append(violations, fieldViolation("page_id", fmt.Errorf("must be empty when page_token is set")))
+	}
+	if _, err := util.DecodePageToken(pageToken, sortBy, sortOrder); err != nil {
+		violations = append(violations, fieldViolation("page_token", err))
+	}
+	return violations
+}
+
+// pageOffset returns the number of rows before a page asked by page_id
+func pageOffset(pageID int32, pageSize int32) int64 {
+	if pageID < 1 {
+		return 0
+	}
+	return int64(pageID-1) * int64(pageSize)
+}
@@ -0,0 +1,112 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+func (server *Server) AuthorizeTransfer(ctx context.Context, req *pb.AuthorizeTransferRequest) (*pb.AuthorizeTransferResponse, error) {
+	authPayload, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+	if violations := validateAuthorizeTransferRequest(req, server.extractMetadata(ctx)); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	fromAccount, err := server.authorizeAccount(ctx, authPayload, req.GetFromAccountId())
+	if err != nil {
+		return nil, err
+	}
+	if fromAccount.Currency != req.GetCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", fromAccount.ID, fromAccount.Currency, req.GetCurrency())
+	}
+	// The policy applies to the owner of the money, also when staff authorizes on their behalf
+	owner, err := server.store.GetUser(ctx, fromAccount.Owner)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get account owner: %s", err)
+	}
+	limits, err := server.policy.TransferLimits(owner)
+	if err != nil {
+		return nil, transferPolicyError(err)
+	}
+
+	toAccount, err := server.getTransferDestination(ctx, req.GetToAccountId())
+	if err != nil {
+		return nil, err
+	}
+	// authorizations cannot hold a quote, so both accounts must use the same currency
+	if toAccount.Currency != req.GetCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", toAccount.ID, toAccount.Currency, req.GetCurrency())
+	}
+
+	var idempotency *db.IdempotencyParams
+	if key := server.extractMetadata(ctx).IdempotencyKey; key != "" {
+		idempotency = &db.IdempotencyParams{
+			Key:      key,
+			Username: authPayload.Username,
+			Window:   server.config.IdempotencyKeyTTL,
+		}
+	}
+
+	result, err := server.store.AuthorizeTransferTx(ctx, db.AuthorizeTransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        req.GetAmount(),
+		HoldExpiresAt: time.Now().UTC().Add(server.config.TransferHoldDuration),
+		Idempotency:   idempotency,
+		Limits:        limits,
+		Fees:          server.feeSchedule,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyConflict) {
+			return nil, status.Errorf(codes.AlreadyExists, "idempotency key was already used for a different request")
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			return nil, status.Errorf(codes.FailedPrecondition, "account [%d] has insufficient funds", fromAccount.ID)
+		}
+		var limitErr *db.TransferLimitError
+		if errors.As(err, &limitErr) {
+			return nil, transferLimitError(limitErr)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to authorize transfer: %s", err)
+	}
+
+	resp := &pb.AuthorizeTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+	}
+	return resp, nil
+}
+
+func validateAuthorizeTransferRequest(req *pb.AuthorizeTransferRequest, mtdt *Metadata) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+	if err := val.ValidateID(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	}
+	if req.GetFromAccountId() == req.GetToAccountId() {
+		violations = append(violations, fieldViolation("to_account_id", fmt.Errorf("cannot transfer to the same account")))
+	}
+	if err := val.ValidateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+	if mtdt.IdempotencyKey != "" {
+		if err := val.ValidateIdempotencyKey(mtdt.IdempotencyKey); err != nil {
+			violations = append(violations, fieldViolation(idempotencyKeyHeader, err))
+		}
+	}
+	return violations
+}
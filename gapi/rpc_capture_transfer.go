@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CaptureTransfer(ctx context.Context, req *pb.CaptureTransferRequest) (*pb.CaptureTransferResponse, error) {
+	authPayload, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+	if violations := validateCaptureTransferRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	// the caller must own one side of the transfer
+	transfer, err := server.authorizeTransfer(ctx, authPayload, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := server.store.CaptureTransferTx(ctx, db.CaptureTransferTxParams{
+		TransferID: transfer.ID,
+		Amount:     req.GetAmount(),
+		Fees:       server.feeSchedule,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrTransferNotPending) || errors.Is(err, db.ErrTransferHoldExpired) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		if errors.Is(err, db.ErrCaptureExceedsAuthorization) {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			return nil, status.Errorf(codes.FailedPrecondition, "account [%d] has insufficient funds", transfer.FromAccountID)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to capture transfer: %s", err)
+	}
+
+	resp := &pb.CaptureTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+		Fee:         convertTransferFee(result.Fee),
+	}
+	return resp, nil
+}
+
+func validateCaptureTransferRequest(req *pb.CaptureTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+	if req.Amount != nil {
+		if err := val.ValidateAmount(req.GetAmount()); err != nil {
+			violations = append(violations, fieldViolation("amount", err))
+		}
+	}
+	return violations
+}
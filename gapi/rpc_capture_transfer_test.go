@@ -0,0 +1,241 @@
+package gapi
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/the-eduardo/Go-Bank/db/mock"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/token"
+	"github.com/the-eduardo/Go-Bank/util"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"testing"
+	"time"
+)
+
+func TestCaptureTransferAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	user3, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account2.ID = account1.ID + 1
+
+	pending := db.Transfer{
+		ID:               util.RandomInt(1, 1000),
+		FromAccountID:    account1.ID,
+		ToAccountID:      account2.ID,
+		Amount:           100,
+		ToAmount:         100,
+		Status:           db.TransferStatusPending,
+		AuthorizedAmount: pgtype.Int8{Int64: 100, Valid: true},
+		HoldExpiresAt:    pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+	captured := pending
+	captured.Amount = 60
+	captured.ToAmount = 60
+	captured.Status = db.TransferStatusPosted
+
+	testCases := []struct {
+		name          string
+		req           *pb.CaptureTransferRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, res *pb.CaptureTransferResponse, err error)
+	}{
+		{
+			name: "PartialCapture",
+			req: &pb.CaptureTransferRequest{
+				Id:     pending.ID,
+				Amount: proto.Int64(60),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferById(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+
+				arg := db.CaptureTransferTxParams{
+					TransferID: pending.ID,
+					Amount:     60,
+				}
+				store.EXPECT().
+					CaptureTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferTxResult{
+						Transfer:    captured,
+						FromAccount: account1,
+						ToAccount:   account2,
+						FromEntry:   db.Entry{ID: 1, AccountID: account1.ID, Amount: -60},
+						ToEntry:     db.Entry{ID: 2, AccountID: account2.ID, Amount: 60},
+					}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CaptureTransferResponse, err error) {
+				require.NoError(t, err)
+				transfer := res.GetTransfer()
+				require.Equal(t, db.TransferStatusPosted, transfer.GetStatus())
+				require.Equal(t, int64(60), transfer.GetAmount())
+				require.Equal(t, int64(100), transfer.GetAuthorizedAmount())
+				require.Equal(t, int64(-60), res.GetFromEntry().GetAmount())
+				require.Equal(t, int64(60), res.GetToEntry().GetAmount())
+			},
+		},
+		{
+			name: "CapturedByDestinationOwner",
+			req: &pb.CaptureTransferRequest{
+				Id: pending.ID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferById(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				arg := db.CaptureTransferTxParams{
+					TransferID: pending.ID,
+				}
+				store.EXPECT().
+					CaptureTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: captured}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user2.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CaptureTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, db.TransferStatusPosted, res.GetTransfer().GetStatus())
+			},
+		},
+		{
+			name: "NotPending",
+			req: &pb.CaptureTransferRequest{
+				Id: pending.ID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferById(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(captured, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					CaptureTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrTransferNotPending)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CaptureTransferResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.FailedPrecondition, st.Code())
+			},
+		},
+		{
+			name: "ExceedsAuthorization",
+			req: &pb.CaptureTransferRequest{
+				Id:     pending.ID,
+				Amount: proto.Int64(101),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferById(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					CaptureTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrCaptureExceedsAuthorization)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CaptureTransferResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			req: &pb.CaptureTransferRequest{
+				Id: pending.ID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferById(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CaptureTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user3.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CaptureTransferResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.PermissionDenied, st.Code())
+			},
+		},
+		{
+			name: "NotFound",
+			req: &pb.CaptureTransferRequest{
+				Id: pending.ID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferById(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(db.Transfer{}, pgx.ErrNoRows)
+				store.EXPECT().CaptureTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CaptureTransferResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.NotFound, st.Code())
+			},
+		},
+		{
+			name: "InvalidAmount",
+			req: &pb.CaptureTransferRequest{
+				Id:     pending.ID,
+				Amount: proto.Int64(0),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferById(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CaptureTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user1.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CaptureTransferResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			ctx := tc.buildContext(t, server.tokenMaker)
+			res, err := server.CaptureTransfer(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
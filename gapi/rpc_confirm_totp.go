@@ -0,0 +1,95 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/util"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// recoveryCodeCount is how many recovery codes are handed out when two-factor authentication is enabled
+const recoveryCodeCount = 10
+
+func (server *Server) ConfirmTOTP(ctx context.Context, req *pb.ConfirmTOTPRequest) (*pb.ConfirmTOTPResponse, error) {
+	authPayload, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+	if violations := validateConfirmTOTPRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	factor, err := server.store.GetTOTPFactor(ctx, authPayload.Username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "totp enrollment not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find totp factor")
+	}
+	if factor.IsConfirmed {
+		return nil, status.Errorf(codes.AlreadyExists, "two-factor authentication is already enabled")
+	}
+	err = server.useTOTPCode(ctx, factor, req.GetCode())
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := util.NewRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot create recovery codes")
+	}
+	hashedCodes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hashedCodes[i] = util.HashRecoveryCode(code)
+	}
+	_, err = server.store.ConfirmTOTPTx(ctx, db.ConfirmTOTPTxParams{
+		Username:            authPayload.Username,
+		HashedRecoveryCodes: hashedCodes,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.AlreadyExists, "two-factor authentication is already enabled")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to confirm totp: %s", err)
+	}
+
+	// The plain codes are only shown once, the database keeps their hash
+	resp := &pb.ConfirmTOTPResponse{
+		RecoveryCodes: recoveryCodes,
+	}
+	return resp, nil
+}
+
+// useTOTPCode checks a code of the authenticator app and marks its time step as used,
+// so the same code cannot be accepted twice
+func (server *Server) useTOTPCode(ctx context.Context, factor db.TotpFactor, code string) error {
+	step, ok := util.ValidateTOTP(factor.Secret, code, time.Now())
+	if !ok {
+		return status.Errorf(codes.Unauthenticated, "invalid totp code")
+	}
+	rows, err := server.store.UseTOTPStep(ctx, db.UseTOTPStepParams{
+		Username:     factor.Username,
+		LastUsedStep: step,
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to use totp code")
+	}
+	if rows == 0 {
+		return status.Errorf(codes.Unauthenticated, "totp code was already used")
+	}
+	return nil
+}
+
+func validateConfirmTOTPRequest(req *pb.ConfirmTOTPRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateTOTPCode(req.GetCode()); err != nil {
+		violations = append(violations, fieldViolation("code", err))
+	}
+	return violations
+}
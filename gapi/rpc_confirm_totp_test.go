@@ -0,0 +1,190 @@
+package gapi
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/the-eduardo/Go-Bank/db/mock"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/token"
+	"github.com/the-eduardo/Go-Bank/util"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestConfirmTOTPAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	factor := randomTOTPFactor(t, user)
+	factor.IsConfirmed = false
+	code, err := totp.GenerateCode(factor.Secret, time.Now())
+	require.NoError(t, err)
+	staleCode, err := totp.GenerateCode(factor.Secret, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		req           *pb.ConfirmTOTPRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, res *pb.ConfirmTOTPResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.ConfirmTOTPRequest{Code: code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(factor, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					ConfirmTOTPTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ConfirmTOTPTxParams) (db.ConfirmTOTPTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Len(t, arg.HashedRecoveryCodes, recoveryCodeCount)
+						return db.ConfirmTOTPTxResult{}, nil
+					})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ConfirmTOTPResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, res.GetRecoveryCodes(), recoveryCodeCount)
+			},
+		},
+		{
+			name: "WrongCode",
+			req:  &pb.ConfirmTOTPRequest{Code: staleCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(factor, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					ConfirmTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ConfirmTOTPResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "AlreadyConfirmed",
+			req:  &pb.ConfirmTOTPRequest{Code: code},
+			buildStubs: func(store *mockdb.MockStore) {
+				confirmed := factor
+				confirmed.IsConfirmed = true
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(confirmed, nil)
+				store.EXPECT().
+					ConfirmTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ConfirmTOTPResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.AlreadyExists, st.Code())
+			},
+		},
+		{
+			name: "NotEnrolled",
+			req:  &pb.ConfirmTOTPRequest{Code: code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.TotpFactor{}, pgx.ErrNoRows)
+				store.EXPECT().
+					ConfirmTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ConfirmTOTPResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.NotFound, st.Code())
+			},
+		},
+		{
+			name: "InvalidCode",
+			req:  &pb.ConfirmTOTPRequest{Code: "12ab"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ConfirmTOTPResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "NoAuthorization",
+			req:  &pb.ConfirmTOTPRequest{Code: code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponse: func(t *testing.T, res *pb.ConfirmTOTPResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			ctx := tc.buildContext(t, server.tokenMaker)
+			res, err := server.ConfirmTOTP(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/the-eduardo/Go-Bank/api"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/fx"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+func (server *Server) CreateFxQuote(ctx context.Context, req *pb.CreateFxQuoteRequest) (*pb.CreateFxQuoteResponse, error) {
+	authPayload, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+	if violations := validateCreateFxQuoteRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	rate, err := server.rateProvider.GetRate(ctx, req.GetFromCurrency(), req.GetToCurrency())
+	if err != nil {
+		if errors.Is(err, fx.ErrRateNotFound) {
+			return nil, status.Errorf(codes.NotFound, "%s", err)
+		}
+		return nil, status.Errorf(codes.Unavailable, "failed to get exchange rate: %s", err)
+	}
+
+	quoteID, err := api.ConvertGoogleUUIDToPGTypeUUID(uuid.New())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate quote id: %s", err)
+	}
+	quote, err := server.store.CreateFXQuote(ctx, db.CreateFXQuoteParams{
+		ID:           quoteID,
+		Username:     authPayload.Username,
+		FromCurrency: req.GetFromCurrency(),
+		ToCurrency:   req.GetToCurrency(),
+		Rate:         rate.Value,
+		ExpiresAt:    api.TimeToPGTimestamptz(time.Now().Add(server.config.FXQuoteDuration)),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create quote: %s", err)
+	}
+
+	resp := &pb.CreateFxQuoteResponse{
+		Quote: convertFxQuote(quote),
+	}
+	return resp, nil
+}
+
+func validateCreateFxQuoteRequest(req *pb.CreateFxQuoteRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateCurrency(req.GetFromCurrency()); err != nil {
+		violations = append(violations, fieldViolation("from_currency", err))
+	}
+	if err := val.ValidateCurrency(req.GetToCurrency()); err != nil {
+		violations = append(violations, fieldViolation("to_currency", err))
+	}
+	if req.GetFromCurrency() == req.GetToCurrency() {
+		violations = append(violations, fieldViolation("to_currency", fmt.Errorf("must differ from the source currency")))
+	}
+	return violations
+}
@@ -0,0 +1,114 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/util"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+func (server *Server) CreateScheduledTransfer(ctx context.Context, req *pb.CreateScheduledTransferRequest) (*pb.CreateScheduledTransferResponse, error) {
+	authPayload, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+	if violations := validateCreateScheduledTransferRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	fromAccount, err := server.authorizeAccount(ctx, authPayload, req.GetFromAccountId())
+	if err != nil {
+		return nil, err
+	}
+	if fromAccount.Currency != req.GetCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", fromAccount.ID, fromAccount.Currency, req.GetCurrency())
+	}
+	toAccount, err := server.getTransferDestination(ctx, req.GetToAccountId())
+	if err != nil {
+		return nil, err
+	}
+	// scheduled transfers cannot hold a quote, so both accounts must use the same currency
+	if toAccount.Currency != req.GetCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", toAccount.ID, toAccount.Currency, req.GetCurrency())
+	}
+
+	recurrence := scheduledTransferRecurrence(req.GetCronExpression(), req.GetIntervalSeconds())
+	now := time.Now().UTC()
+	startAt := now
+	if req.StartAt != nil {
+		startAt = req.GetStartAt().AsTime()
+	}
+	nextRunAt, err := recurrence.Next(startAt, now)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+
+	// The schedule belongs to the owner of the money, also when staff creates it on their behalf
+	scheduledTransfer, err := server.store.CreateScheduledTransfer(ctx, db.CreateScheduledTransferParams{
+		Owner:           fromAccount.Owner,
+		FromAccountID:   fromAccount.ID,
+		ToAccountID:     toAccount.ID,
+		Amount:          req.GetAmount(),
+		CronExpression:  recurrence.CronExpression,
+		IntervalSeconds: req.GetIntervalSeconds(),
+		NextRunAt:       pgtype.Timestamptz{Time: nextRunAt, Valid: true},
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create scheduled transfer: %s", err)
+	}
+
+	resp := &pb.CreateScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(scheduledTransfer),
+	}
+	return resp, nil
+}
+
+// scheduledTransferRecurrence builds the recurrence of a request, an unset field is left empty
+func scheduledTransferRecurrence(cronExpression string, intervalSeconds int64) util.Recurrence {
+	return util.Recurrence{
+		CronExpression: cronExpression,
+		Interval:       time.Duration(intervalSeconds) * time.Second,
+	}
+}
+
+// recurrenceField names the recurrence field a violation is reported on
+func recurrenceField(cronExpression *string) string {
+	if cronExpression == nil {
+		return "interval_seconds"
+	}
+	return "cron_expression"
+}
+
+func validateCreateScheduledTransferRequest(req *pb.CreateScheduledTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+	if err := val.ValidateID(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	}
+	if req.GetFromAccountId() == req.GetToAccountId() {
+		violations = append(violations, fieldViolation("to_account_id", fmt.Errorf("cannot transfer to the same account")))
+	}
+	if err := val.ValidateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+	if err := val.ValidateRecurrence(req.GetCronExpression(), req.GetIntervalSeconds()); err != nil {
+		violations = append(violations, fieldViolation(recurrenceField(req.CronExpression), err))
+	}
+	if req.StartAt != nil {
+		if err := req.GetStartAt().CheckValid(); err != nil {
+			violations = append(violations, fieldViolation("start_at", err))
+		}
+	}
+	return violations
+}
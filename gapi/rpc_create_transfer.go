@@ -0,0 +1,175 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/the-eduardo/Go-Bank/api"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/fx"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	authPayload, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+	if violations := validateCreateTransferRequest(req, server.extractMetadata(ctx)); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	fromAccount, err := server.authorizeAccount(ctx, authPayload, req.GetFromAccountId())
+	if err != nil {
+		return nil, err
+	}
+	if fromAccount.Currency != req.GetCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", fromAccount.ID, fromAccount.Currency, req.GetCurrency())
+	}
+	// The policy applies to the owner of the money, also when staff transfers on their behalf
+	owner, err := server.store.GetUser(ctx, fromAccount.Owner)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get account owner: %s", err)
+	}
+	limits, err := server.policy.TransferLimits(owner)
+	if err != nil {
+		return nil, transferPolicyError(err)
+	}
+
+	toAccount, err := server.getTransferDestination(ctx, req.GetToAccountId())
+	if err != nil {
+		return nil, err
+	}
+	// The destination currency may differ from the source currency only for quoted transfers
+	if req.QuoteId == nil && toAccount.Currency != req.GetCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", toAccount.ID, toAccount.Currency, req.GetCurrency())
+	}
+	var quoteID pgtype.UUID
+	if req.QuoteId != nil {
+		quoteID, _ = api.ConvertGoogleUUIDToPGTypeUUID(uuid.MustParse(req.GetQuoteId()))
+		if err := server.validateConvertedAmount(ctx, quoteID, req.GetAmount()); err != nil {
+			return nil, err
+		}
+	}
+
+	var idempotency *db.IdempotencyParams
+	if key := server.extractMetadata(ctx).IdempotencyKey; key != "" {
+		idempotency = &db.IdempotencyParams{
+			Key:      key,
+			Username: authPayload.Username,
+			Window:   server.config.IdempotencyKeyTTL,
+		}
+	}
+
+	var result db.TransferTxResult
+	if req.QuoteId == nil {
+		arg := db.TransferTxParams{
+			FromAccountID: req.GetFromAccountId(),
+			ToAccountID:   req.GetToAccountId(),
+			Amount:        req.GetAmount(),
+			Idempotency:   idempotency,
+			Limits:        limits,
+			Fees:          server.feeSchedule,
+		}
+		result, err = server.store.TransferTx(ctx, arg)
+	} else {
+		arg := db.FXTransferTxParams{
+			FromAccountID: req.GetFromAccountId(),
+			ToAccountID:   req.GetToAccountId(),
+			Amount:        req.GetAmount(),
+			QuoteID:       quoteID,
+			FromCurrency:  fromAccount.Currency,
+			ToCurrency:    toAccount.Currency,
+			Username:      authPayload.Username,
+			Idempotency:   idempotency,
+			Limits:        limits,
+			Fees:          server.feeSchedule,
+		}
+		result, err = server.store.FXTransferTx(ctx, arg)
+	}
+	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyConflict) {
+			return nil, status.Errorf(codes.AlreadyExists, "idempotency key was already used for a different request")
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			return nil, status.Errorf(codes.FailedPrecondition, "account [%d] has insufficient funds", fromAccount.ID)
+		}
+		if errors.Is(err, db.ErrInvalidQuote) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		if errors.Is(err, fx.ErrInvalidAmount) {
+			return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("amount", err)})
+		}
+		var limitErr *db.TransferLimitError
+		if errors.As(err, &limitErr) {
+			return nil, transferLimitError(limitErr)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to transfer: %s", err)
+	}
+
+	resp := &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+		Fee:         convertTransferFee(result.Fee),
+	}
+	return resp, nil
+}
+
+// validateConvertedAmount rejects an amount that the rate of the quote converts to nothing,
+// before the transfer uses up the quote
+func (server *Server) validateConvertedAmount(ctx context.Context, quoteID pgtype.UUID, amount int64) error {
+	quote, err := server.store.GetFXQuote(ctx, quoteID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return status.Errorf(codes.FailedPrecondition, "%s", db.ErrInvalidQuote)
+		}
+		return status.Errorf(codes.Internal, "failed to get fx quote: %s", err)
+	}
+	toAmount, err := fx.Convert(amount, quote.Rate)
+	if err == nil && toAmount <= 0 {
+		err = fmt.Errorf("%w: %d %s converts to %d %s", fx.ErrInvalidAmount, amount, quote.FromCurrency, toAmount, quote.ToCurrency)
+	}
+	if err != nil {
+		return invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("amount", err)})
+	}
+	return nil
+}
+
+func validateCreateTransferRequest(req *pb.CreateTransferRequest, mtdt *Metadata) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+	if err := val.ValidateID(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	}
+	if req.GetFromAccountId() == req.GetToAccountId() {
+		violations = append(violations, fieldViolation("to_account_id", fmt.Errorf("cannot transfer to the same account")))
+	}
+	if err := val.ValidateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+	if req.QuoteId != nil {
+		if err := val.ValidateUUID(req.GetQuoteId()); err != nil {
+			violations = append(violations, fieldViolation("quote_id", err))
+		}
+	}
+	if mtdt.IdempotencyKey != "" {
+		if err := val.ValidateIdempotencyKey(mtdt.IdempotencyKey); err != nil {
+			violations = append(violations, fieldViolation(idempotencyKeyHeader, err))
+		}
+	}
+	return violations
+}
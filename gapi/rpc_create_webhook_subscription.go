@@ -0,0 +1,56 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/util"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// webhookSecretLength is the length of the generated secrets that sign the deliveries
+const webhookSecretLength = 32
+
+func (server *Server) CreateWebhookSubscription(ctx context.Context, req *pb.CreateWebhookSubscriptionRequest) (*pb.CreateWebhookSubscriptionResponse, error) {
+	authPayload, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+	if violations := validateCreateWebhookSubscriptionRequest(req, server.config.WebhookAllowPrivateHosts); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+	// A global subscription receives the transfers of every customer, it is not part of the staff roles
+	if req.GetIsGlobal() && authPayload.Role != db.WebhookGlobalRole {
+		return nil, status.Errorf(codes.PermissionDenied, "only admins can create global webhook subscriptions")
+	}
+
+	subscription, err := server.store.CreateWebhookSubscription(ctx, db.CreateWebhookSubscriptionParams{
+		Owner:      authPayload.Username,
+		Url:        req.GetUrl(),
+		Secret:     util.RandomString(webhookSecretLength),
+		EventTypes: req.GetEventTypes(),
+		IsGlobal:   req.GetIsGlobal(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create webhook subscription: %s", err)
+	}
+
+	resp := &pb.CreateWebhookSubscriptionResponse{
+		Subscription: convertWebhookSubscription(subscription),
+		Secret:       subscription.Secret,
+	}
+	return resp, nil
+}
+
+func validateCreateWebhookSubscriptionRequest(req *pb.CreateWebhookSubscriptionRequest, allowPrivateHosts bool) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateWebhookURL(req.GetUrl(), allowPrivateHosts); err != nil {
+		violations = append(violations, fieldViolation("url", err))
+	}
+	if err := val.ValidateWebhookEventTypes(req.GetEventTypes()); err != nil {
+		violations = append(violations, fieldViolation("event_types", err))
+	}
+	return violations
+}
@@ -0,0 +1,292 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/the-eduardo/Go-Bank/db/mock"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/token"
+	"github.com/the-eduardo/Go-Bank/util"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestCreateWebhookSubscriptionAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	url := "https://partner.example.com/hooks"
+	eventTypes := []string{util.WebhookTransferPosted}
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateWebhookSubscriptionRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, res *pb.CreateWebhookSubscriptionResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.CreateWebhookSubscriptionRequest{
+				Url:        url,
+				EventTypes: eventTypes,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookSubscription(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateWebhookSubscriptionParams) (db.WebhookSubscription, error) {
+						require.Equal(t, user.Username, arg.Owner)
+						require.Equal(t, url, arg.Url)
+						require.Equal(t, eventTypes, arg.EventTypes)
+						require.Len(t, arg.Secret, webhookSecretLength)
+						require.False(t, arg.IsGlobal)
+						return db.WebhookSubscription{
+							ID:         1,
+							Owner:      arg.Owner,
+							Url:        arg.Url,
+							Secret:     arg.Secret,
+							EventTypes: arg.EventTypes,
+						}, nil
+					})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateWebhookSubscriptionResponse, err error) {
+				require.NoError(t, err)
+				subscription := res.GetSubscription()
+				require.Equal(t, int64(1), subscription.GetId())
+				require.Equal(t, user.Username, subscription.GetOwner())
+				require.Equal(t, url, subscription.GetUrl())
+				require.Equal(t, eventTypes, subscription.GetEventTypes())
+				require.Len(t, res.GetSecret(), webhookSecretLength)
+			},
+		},
+		{
+			name: "InternalError",
+			req: &pb.CreateWebhookSubscriptionRequest{
+				Url:        url,
+				EventTypes: eventTypes,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookSubscription(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.WebhookSubscription{}, fmt.Errorf("connection lost"))
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateWebhookSubscriptionResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Internal, st.Code())
+			},
+		},
+		{
+			name: "InvalidURL",
+			req: &pb.CreateWebhookSubscriptionRequest{
+				Url:        "ftp://partner.example.com/hooks",
+				EventTypes: eventTypes,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateWebhookSubscriptionResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "UnsupportedEventType",
+			req: &pb.CreateWebhookSubscriptionRequest{
+				Url:        url,
+				EventTypes: []string{util.WebhookTransferPosted, "account.deleted"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateWebhookSubscriptionResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "LoopbackURL",
+			req: &pb.CreateWebhookSubscriptionRequest{
+				Url:        "http://localhost:8080/hooks",
+				EventTypes: eventTypes,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateWebhookSubscriptionResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "PrivateURL",
+			req: &pb.CreateWebhookSubscriptionRequest{
+				Url:        "http://10.0.0.5/hooks",
+				EventTypes: eventTypes,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateWebhookSubscriptionResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "LinkLocalURL",
+			req: &pb.CreateWebhookSubscriptionRequest{
+				Url:        "http://169.254.169.254/latest/meta-data",
+				EventTypes: eventTypes,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateWebhookSubscriptionResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "GlobalByAdmin",
+			req: &pb.CreateWebhookSubscriptionRequest{
+				Url:        url,
+				EventTypes: []string{util.WebhookUserCreated},
+				IsGlobal:   true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookSubscription(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateWebhookSubscriptionParams) (db.WebhookSubscription, error) {
+						require.True(t, arg.IsGlobal)
+						return db.WebhookSubscription{
+							ID:         1,
+							Owner:      arg.Owner,
+							Url:        arg.Url,
+							Secret:     arg.Secret,
+							EventTypes: arg.EventTypes,
+							IsGlobal:   arg.IsGlobal,
+						}, nil
+					})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "admin_user", util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateWebhookSubscriptionResponse, err error) {
+				require.NoError(t, err)
+				require.True(t, res.GetSubscription().GetIsGlobal())
+			},
+		},
+		{
+			name: "GlobalByBanker",
+			req: &pb.CreateWebhookSubscriptionRequest{
+				Url:        url,
+				EventTypes: eventTypes,
+				IsGlobal:   true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "banker_user", util.BankerRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateWebhookSubscriptionResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.PermissionDenied, st.Code())
+			},
+		},
+		{
+			name: "NoEventTypes",
+			req: &pb.CreateWebhookSubscriptionRequest{
+				Url: url,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateWebhookSubscriptionResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "NoAuthorization",
+			req: &pb.CreateWebhookSubscriptionRequest{
+				Url:        url,
+				EventTypes: eventTypes,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateWebhookSubscriptionResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			ctx := tc.buildContext(t, server.tokenMaker)
+			res, err := server.CreateWebhookSubscription(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package gapi
+
+import (
+	"context"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) DeleteWebhookSubscription(ctx context.Context, req *pb.DeleteWebhookSubscriptionRequest) (*pb.DeleteWebhookSubscriptionResponse, error) {
+	authPayload, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+	if violations := validateDeleteWebhookSubscriptionRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	_, err = server.authorizeWebhookSubscription(ctx, authPayload, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	// the deliveries are deleted with it, the ones still queued are skipped by the worker
+	err = server.store.DeleteWebhookSubscription(ctx, req.GetId())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete webhook subscription: %s", err)
+	}
+	return &pb.DeleteWebhookSubscriptionResponse{}, nil
+}
+
+func validateDeleteWebhookSubscriptionRequest(req *pb.DeleteWebhookSubscriptionRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+	return violations
+}
@@ -0,0 +1,49 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) EnrollTOTP(ctx context.Context, req *pb.EnrollTOTPRequest) (*pb.EnrollTOTPResponse, error) {
+	authPayload, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	secret, uri, err := util.NewTOTPKey(server.config.TOTPIssuer, user.Email)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot create totp key")
+	}
+	// Enrolling again before the confirmation replaces the secret
+	_, err = server.store.UpsertTOTPFactor(ctx, db.UpsertTOTPFactorParams{
+		Username: user.Username,
+		Secret:   secret,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.AlreadyExists, "two-factor authentication is already enabled")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to enroll totp: %s", err)
+	}
+
+	resp := &pb.EnrollTOTPResponse{
+		Secret:     secret,
+		OtpauthUri: uri,
+	}
+	return resp, nil
+}
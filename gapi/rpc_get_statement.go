@@ -0,0 +1,73 @@
+package gapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/statement"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// maxStatementPeriod keeps a statement to about a year of entries
+const maxStatementPeriod = 366 * 24 * time.Hour
+
+func (server *Server) GetStatement(ctx context.Context, req *pb.GetStatementRequest) (*pb.GetStatementResponse, error) {
+	authPayload, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+	if violations := validateGetStatementRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	_, err = server.authorizeAccount(ctx, authPayload, req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	accountStatement, err := statement.Generate(ctx, server.store, req.GetAccountId(), req.GetStartTime().AsTime(), req.GetEndTime().AsTime())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate statement: %s", err)
+	}
+	var csv, pdf bytes.Buffer
+	if err = accountStatement.WriteCSV(&csv); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to write statement csv: %s", err)
+	}
+	if err = accountStatement.WritePDF(&pdf); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to write statement pdf: %s", err)
+	}
+
+	resp := &pb.GetStatementResponse{
+		Statement: convertStatement(accountStatement),
+		Csv:       csv.Bytes(),
+		Pdf:       pdf.Bytes(),
+	}
+	return resp, nil
+}
+
+func validateGetStatementRequest(req *pb.GetStatementRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	if err := req.GetStartTime().CheckValid(); err != nil {
+		violations = append(violations, fieldViolation("start_time", err))
+	}
+	if err := req.GetEndTime().CheckValid(); err != nil {
+		violations = append(violations, fieldViolation("end_time", err))
+	}
+	if violations != nil {
+		return violations
+	}
+	period := req.GetEndTime().AsTime().Sub(req.GetStartTime().AsTime())
+	if period <= 0 {
+		violations = append(violations, fieldViolation("end_time", fmt.Errorf("must be after start_time")))
+	} else if period > maxStatementPeriod {
+		violations = append(violations, fieldViolation("end_time", fmt.Errorf("must be at most %d days after start_time", maxStatementPeriod/(24*time.Hour))))
+	}
+	return violations
+}
@@ -0,0 +1,252 @@
+package gapi
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/the-eduardo/Go-Bank/db/mock"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/token"
+	"github.com/the-eduardo/Go-Bank/util"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"testing"
+	"time"
+)
+
+func TestGetStatementAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	start := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	entries := []db.Entry{
+		{ID: 1, AccountID: account.ID, Amount: 100, CreatedAt: pgtype.Timestamptz{Time: start.Add(time.Hour), Valid: true}},
+		{ID: 2, AccountID: account.ID, Amount: -30, CreatedAt: pgtype.Timestamptz{Time: start.Add(2 * time.Hour), Valid: true}},
+	}
+
+	buildStatementStubs := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+			Times(1).
+			Return(account, nil)
+		store.EXPECT().
+			StatementTx(gomock.Any(), gomock.Eq(db.StatementTxParams{AccountID: account.ID, Start: start, End: end})).
+			Times(1).
+			Return(db.StatementTxResult{
+				Account:        account,
+				OpeningBalance: account.Balance - 70,
+				Entries:        entries,
+				Transfers:      map[int64]db.Transfer{},
+			}, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.GetStatementRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, res *pb.GetStatementResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.GetStatementRequest{
+				AccountId: account.ID,
+				StartTime: timestamppb.New(start),
+				EndTime:   timestamppb.New(end),
+			},
+			buildStubs: buildStatementStubs,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetStatementResponse, err error) {
+				require.NoError(t, err)
+				statement := res.GetStatement()
+				require.Equal(t, account.ID, statement.GetAccountId())
+				require.Equal(t, account.Balance, statement.GetClosingBalance())
+				require.Equal(t, account.Balance-70, statement.GetOpeningBalance())
+				require.Len(t, statement.GetLines(), 2)
+				require.Equal(t, "credit", statement.GetLines()[0].GetDescription())
+				require.Equal(t, account.Balance-70+100, statement.GetLines()[0].GetBalance())
+				require.Equal(t, "debit", statement.GetLines()[1].GetDescription())
+				require.True(t, bytes.HasPrefix(res.GetCsv(), []byte("date,entry_id,description,amount,balance\n")))
+				require.True(t, bytes.HasPrefix(res.GetPdf(), []byte("%PDF-")))
+			},
+		},
+		{
+			name: "BankerAccessOtherAccount",
+			req: &pb.GetStatementRequest{
+				AccountId: account.ID,
+				StartTime: timestamppb.New(start),
+				EndTime:   timestamppb.New(end),
+			},
+			buildStubs: buildStatementStubs,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "banker_user", util.BankerRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetStatementResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, res.GetStatement().GetAccountId())
+			},
+		},
+		{
+			name: "PermissionDenied",
+			req: &pb.GetStatementRequest{
+				AccountId: account.ID,
+				StartTime: timestamppb.New(start),
+				EndTime:   timestamppb.New(end),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					StatementTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "unauthorized_user", util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetStatementResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.PermissionDenied, st.Code())
+			},
+		},
+		{
+			name: "InternalError",
+			req: &pb.GetStatementRequest{
+				AccountId: account.ID,
+				StartTime: timestamppb.New(start),
+				EndTime:   timestamppb.New(end),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					StatementTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.StatementTxResult{}, sql.ErrConnDone)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetStatementResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Internal, st.Code())
+			},
+		},
+		{
+			name: "EndBeforeStart",
+			req: &pb.GetStatementRequest{
+				AccountId: account.ID,
+				StartTime: timestamppb.New(end),
+				EndTime:   timestamppb.New(start),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetStatementResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "PeriodTooLong",
+			req: &pb.GetStatementRequest{
+				AccountId: account.ID,
+				StartTime: timestamppb.New(start),
+				EndTime:   timestamppb.New(start.AddDate(2, 0, 0)),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetStatementResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "MissingTimes",
+			req:  &pb.GetStatementRequest{AccountId: account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetStatementResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "NoAuthorization",
+			req: &pb.GetStatementRequest{
+				AccountId: account.ID,
+				StartTime: timestamppb.New(start),
+				EndTime:   timestamppb.New(end),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponse: func(t *testing.T, res *pb.GetStatementResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			ctx := tc.buildContext(t, server.tokenMaker)
+			res, err := server.GetStatement(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/util"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	authPayload, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+	if violations := validateListAccountsRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	arg := db.ListAccountsParams{
+		Owner:  authPayload.Username,
+		Limit:  int64(req.GetPageSize()),
+		Offset: pageOffset(req.GetPageId(), req.GetPageSize()),
+	}
+	if req.GetPageToken() != "" {
+		token, _ := util.DecodePageToken(req.GetPageToken(), "", "")
+		arg.StartAfter(token)
+	}
+	accounts, err := server.store.ListAccounts(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list accounts: %s", err)
+	}
+
+	resp := &pb.ListAccountsResponse{
+		Accounts:      make([]*pb.Account, len(accounts)),
+		NextPageToken: arg.NextPageToken(accounts),
+	}
+	for i, account := range accounts {
+		resp.Accounts[i] = convertAccount(account)
+	}
+	return resp, nil
+}
+
+func validateListAccountsRequest(req *pb.ListAccountsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	violations = validatePagination(req.GetPageId(), req.GetPageToken(), "", "")
+	if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	return violations
+}
@@ -0,0 +1,47 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListAllAccounts(ctx context.Context, req *pb.ListAllAccountsRequest) (*pb.ListAllAccountsResponse, error) {
+	_, err := server.autorizeUser(ctx, adminRoles)
+	if err != nil {
+		return nil, authorizationError(err)
+	}
+	if violations := validateListAllAccountsRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	accounts, err := server.store.ListAllAccounts(ctx, db.ListAllAccountsParams{
+		Limit:  int64(req.GetPageSize()),
+		Offset: int64(req.GetPageId()-1) * int64(req.GetPageSize()),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list accounts: %s", err)
+	}
+
+	resp := &pb.ListAllAccountsResponse{
+		Accounts: make([]*pb.Account, len(accounts)),
+	}
+	for i, account := range accounts {
+		resp.Accounts[i] = convertAccount(account)
+	}
+	return resp, nil
+}
+
+func validateListAllAccountsRequest(req *pb.ListAllAccountsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidatePageID(req.GetPageId()); err != nil {
+		violations = append(violations, fieldViolation("page_id", err))
+	}
+	if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	return violations
+}
@@ -0,0 +1,60 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListKYCSubmissions(ctx context.Context, req *pb.ListKYCSubmissionsRequest) (*pb.ListKYCSubmissionsResponse, error) {
+	_, err := server.autorizeUser(ctx, adminRoles)
+	if err != nil {
+		return nil, authorizationError(err)
+	}
+	if violations := validateListKYCSubmissionsRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	submissionStatus := db.KYCStatusPending
+	if req.Status != nil {
+		submissionStatus = req.GetStatus()
+	}
+	submissions, err := server.store.ListKYCSubmissions(ctx, db.ListKYCSubmissionsParams{
+		Status: submissionStatus,
+		Limit:  int64(req.GetPageSize()),
+		Offset: int64(req.GetPageId()-1) * int64(req.GetPageSize()),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list kyc submissions: %s", err)
+	}
+
+	resp := &pb.ListKYCSubmissionsResponse{
+		Submissions: make([]*pb.KYCSubmission, len(submissions)),
+	}
+	for i, submission := range submissions {
+		resp.Submissions[i] = convertKYCSubmission(submission)
+	}
+	return resp, nil
+}
+
+func validateListKYCSubmissionsRequest(req *pb.ListKYCSubmissionsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.Status != nil {
+		switch req.GetStatus() {
+		case db.KYCStatusPending, db.KYCStatusApproved, db.KYCStatusRejected:
+		default:
+			violations = append(violations, fieldViolation("status", fmt.Errorf("must be pending, approved or rejected")))
+		}
+	}
+	if err := val.ValidatePageID(req.GetPageId()); err != nil {
+		violations = append(violations, fieldViolation("page_id", err))
+	}
+	if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	return violations
+}
@@ -0,0 +1,70 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/util"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListTransfers(ctx context.Context, req *pb.ListTransfersRequest) (*pb.ListTransfersResponse, error) {
+	authPayload, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+	if violations := validateListTransfersRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	_, err = server.authorizeAccount(ctx, authPayload, req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	filter := newHistoryFilter(req)
+	arg := db.ListTransfersByAccountIdParams{
+		AccountID:             req.GetAccountId(),
+		StartTime:             filter.startTime,
+		EndTime:               filter.endTime,
+		MinAmount:             filter.minAmount,
+		MaxAmount:             filter.maxAmount,
+		Direction:             filter.direction,
+		CounterpartyAccountID: filter.counterpartyAccountID,
+		SortBy:                req.GetSortBy(),
+		SortOrder:             req.GetSortOrder(),
+		Limit:                 int64(req.GetPageSize()),
+		Offset:                pageOffset(req.GetPageId(), req.GetPageSize()),
+	}
+	if req.GetPageToken() != "" {
+		token, _ := util.DecodePageToken(req.GetPageToken(), req.GetSortBy(), req.GetSortOrder())
+		arg.StartAfter(token)
+	}
+	transfers, err := server.store.ListTransfersByAccountId(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list transfers: %s", err)
+	}
+
+	resp := &pb.ListTransfersResponse{
+		Transfers:     make([]*pb.Transfer, len(transfers)),
+		NextPageToken: arg.NextPageToken(transfers),
+	}
+	for i, transfer := range transfers {
+		resp.Transfers[i] = convertTransfer(transfer)
+	}
+	return resp, nil
+}
+
+func validateListTransfersRequest(req *pb.ListTransfersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	violations = append(violations, validatePagination(req.GetPageId(), req.GetPageToken(), req.GetSortBy(), req.GetSortOrder())...)
+	if err := val.ValidateHistoryPageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	return append(violations, validateHistoryRequest(req)...)
+}
@@ -0,0 +1,324 @@
+package gapi
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/the-eduardo/Go-Bank/db/mock"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/token"
+	"github.com/the-eduardo/Go-Bank/util"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"testing"
+	"time"
+)
+
+func TestListTransfersAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	transfer := db.Transfer{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: account.ID,
+		ToAccountID:   account.ID + 1,
+		Amount:        util.RandomMoney(),
+		Status:        db.TransferStatusPosted,
+	}
+	start := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	testCases := []struct {
+		name          string
+		req           *pb.ListTransfersRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, res *pb.ListTransfersResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.ListTransfersRequest{
+				AccountId: account.ID,
+				PageId:    1,
+				PageSize:  5,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					ListTransfersByAccountId(gomock.Any(), gomock.Eq(db.ListTransfersByAccountIdParams{
+						AccountID: account.ID,
+						Limit:     5,
+					})).
+					Times(1).
+					Return([]db.Transfer{transfer}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, res.GetTransfers(), 1)
+				require.Equal(t, transfer.ID, res.GetTransfers()[0].GetId())
+				require.Empty(t, res.GetNextPageToken())
+			},
+		},
+		{
+			name: "Filtered",
+			req: &pb.ListTransfersRequest{
+				AccountId:             account.ID,
+				PageId:                3,
+				PageSize:              100,
+				StartTime:             timestamppb.New(start),
+				EndTime:               timestamppb.New(end),
+				MinAmount:             10,
+				MaxAmount:             100,
+				Direction:             util.DirectionIn,
+				CounterpartyAccountId: transfer.ToAccountID,
+				SortBy:                util.SortByAmount,
+				SortOrder:             util.SortOrderAsc,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					ListTransfersByAccountId(gomock.Any(), gomock.Eq(db.ListTransfersByAccountIdParams{
+						AccountID:             account.ID,
+						StartTime:             pgtype.Timestamptz{Time: start, Valid: true},
+						EndTime:               pgtype.Timestamptz{Time: end, Valid: true},
+						MinAmount:             pgtype.Int8{Int64: 10, Valid: true},
+						MaxAmount:             pgtype.Int8{Int64: 100, Valid: true},
+						Direction:             pgtype.Text{String: util.DirectionIn, Valid: true},
+						CounterpartyAccountID: pgtype.Int8{Int64: transfer.ToAccountID, Valid: true},
+						SortBy:                util.SortByAmount,
+						SortOrder:             util.SortOrderAsc,
+						Limit:                 100,
+						Offset:                200,
+					})).
+					Times(1).
+					Return([]db.Transfer{}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.NoError(t, err)
+				require.Empty(t, res.GetTransfers())
+			},
+		},
+		{
+			name: "NextPage",
+			req: &pb.ListTransfersRequest{
+				AccountId: account.ID,
+				PageSize:  5,
+				SortBy:    util.SortByAmount,
+				PageToken: util.PageToken{
+					SortBy:    util.SortByAmount,
+					CreatedAt: start,
+					Amount:    50,
+					ID:        transfer.ID,
+				}.Encode(),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				page := make([]db.Transfer, 5)
+				for i := range page {
+					page[i] = transfer
+					page[i].ID = transfer.ID + int64(i) + 1
+					page[i].CreatedAt = pgtype.Timestamptz{Time: start, Valid: true}
+				}
+				store.EXPECT().
+					ListTransfersByAccountId(gomock.Any(), gomock.Eq(db.ListTransfersByAccountIdParams{
+						AccountID:       account.ID,
+						CursorID:        pgtype.Int8{Int64: transfer.ID, Valid: true},
+						SortBy:          util.SortByAmount,
+						CursorAmount:    pgtype.Int8{Int64: 50, Valid: true},
+						CursorCreatedAt: pgtype.Timestamptz{Time: start, Valid: true},
+						Limit:           5,
+					})).
+					Times(1).
+					Return(page, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, res.GetTransfers(), 5)
+
+				last := res.GetTransfers()[4]
+				token, err := util.DecodePageToken(res.GetNextPageToken(), util.SortByAmount, "")
+				require.NoError(t, err)
+				require.Equal(t, last.GetId(), token.ID)
+				require.Equal(t, last.GetAmount(), token.Amount)
+				require.True(t, start.Equal(token.CreatedAt))
+			},
+		},
+		{
+			name: "InvalidPageToken",
+			req: &pb.ListTransfersRequest{
+				AccountId: account.ID,
+				PageId:    2,
+				PageSize:  5,
+				SortOrder: util.SortOrderAsc,
+				PageToken: util.PageToken{CreatedAt: start, ID: transfer.ID}.Encode(),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+
+				var fields []string
+				for _, detail := range st.Details() {
+					for _, violation := range detail.(*errdetails.BadRequest).GetFieldViolations() {
+						fields = append(fields, violation.GetField())
+					}
+				}
+				require.ElementsMatch(t, []string{"page_id", "page_token"}, fields)
+			},
+		},
+		{
+			name: "InvalidFilters",
+			req: &pb.ListTransfersRequest{
+				AccountId: account.ID,
+				PageId:    1,
+				PageSize:  5,
+				StartTime: timestamppb.New(end),
+				EndTime:   timestamppb.New(start),
+				MinAmount: 100,
+				MaxAmount: 10,
+				Direction: "sideways",
+				SortBy:    "owner",
+				SortOrder: "random",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+
+				var fields []string
+				for _, detail := range st.Details() {
+					for _, violation := range detail.(*errdetails.BadRequest).GetFieldViolations() {
+						fields = append(fields, violation.GetField())
+					}
+				}
+				require.ElementsMatch(t, []string{"end_time", "max_amount", "direction", "sort_by", "sort_order"}, fields)
+			},
+		},
+		{
+			name: "PageSizeTooLarge",
+			req: &pb.ListTransfersRequest{
+				AccountId: account.ID,
+				PageId:    1,
+				PageSize:  util.MaxHistoryPageSize + 1,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "PermissionDenied",
+			req: &pb.ListTransfersRequest{
+				AccountId: account.ID,
+				PageId:    1,
+				PageSize:  5,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					ListTransfersByAccountId(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "unauthorized_user", util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.PermissionDenied, st.Code())
+			},
+		},
+		{
+			name: "NoAuthorization",
+			req: &pb.ListTransfersRequest{
+				AccountId: account.ID,
+				PageId:    1,
+				PageSize:  5,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			ctx := tc.buildContext(t, server.tokenMaker)
+			res, err := server.ListTransfers(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
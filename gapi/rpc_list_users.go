@@ -0,0 +1,47 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	_, err := server.autorizeUser(ctx, adminRoles)
+	if err != nil {
+		return nil, authorizationError(err)
+	}
+	if violations := validateListUsersRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	users, err := server.store.ListUsers(ctx, db.ListUsersParams{
+		Limit:  int64(req.GetPageSize()),
+		Offset: int64(req.GetPageId()-1) * int64(req.GetPageSize()),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list users: %s", err)
+	}
+
+	resp := &pb.ListUsersResponse{
+		Users: make([]*pb.User, len(users)),
+	}
+	for i, user := range users {
+		resp.Users[i] = convertUser(user)
+	}
+	return resp, nil
+}
+
+func validateListUsersRequest(req *pb.ListUsersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidatePageID(req.GetPageId()); err != nil {
+		violations = append(violations, fieldViolation("page_id", err))
+	}
+	if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	return violations
+}
@@ -0,0 +1,48 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ListWebhookSubscriptions(ctx context.Context, req *pb.ListWebhookSubscriptionsRequest) (*pb.ListWebhookSubscriptionsResponse, error) {
+	authPayload, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+	if violations := validateListWebhookSubscriptionsRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	subscriptions, err := server.store.ListWebhookSubscriptions(ctx, db.ListWebhookSubscriptionsParams{
+		Owner:  authPayload.Username,
+		Limit:  int64(req.GetPageSize()),
+		Offset: int64(req.GetPageId()-1) * int64(req.GetPageSize()),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list webhook subscriptions: %s", err)
+	}
+
+	resp := &pb.ListWebhookSubscriptionsResponse{
+		Subscriptions: make([]*pb.WebhookSubscription, len(subscriptions)),
+	}
+	for i, subscription := range subscriptions {
+		resp.Subscriptions[i] = convertWebhookSubscription(subscription)
+	}
+	return resp, nil
+}
+
+func validateListWebhookSubscriptionsRequest(req *pb.ListWebhookSubscriptionsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidatePageID(req.GetPageId()); err != nil {
+		violations = append(violations, fieldViolation("page_id", err))
+	}
+	if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	return violations
+}
@@ -3,17 +3,19 @@ package gapi
 import (
 	"context"
 	"errors"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/the-eduardo/Go-Bank/api"
 	db "github.com/the-eduardo/Go-Bank/db/sqlc"
 	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/token"
 	"github.com/the-eduardo/Go-Bank/util"
 	"github.com/the-eduardo/Go-Bank/val"
-	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
 )
 
 func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
@@ -21,33 +23,93 @@ func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (
 		return nil, invalidArgumentError(violations)
 	}
 
+	mtdt := server.extractMetadata(ctx)
+	err := server.checkLoginLockout(ctx, req.GetUsername(), mtdt.ClientIP)
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := server.store.GetUser(ctx, req.GetUsername())
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, status.Errorf(codes.NotFound, "user not found")
+			util.CheckPasswordOfMissingUser(req.GetPassword())
+			return nil, server.loginFailed(ctx, req.GetUsername(), mtdt.ClientIP, false, errInvalidCredentials)
 		}
 		return nil, status.Errorf(codes.Internal, "failed to find user")
 	}
+	if user.HashedPassword == "" {
+		// System users have no password and never log in, they fail like a missing user
+		util.CheckPasswordOfMissingUser(req.GetPassword())
+		return nil, server.loginFailed(ctx, user.Username, mtdt.ClientIP, false, errInvalidCredentials)
+	}
 	err = util.CheckPassword(user.HashedPassword, req.Password)
 	if err != nil {
-		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
-			return nil, status.Errorf(codes.Unauthenticated, "invalid password")
-		}
-		return nil, status.Errorf(codes.Internal, "failed to verify password")
+		return nil, server.loginFailed(ctx, user.Username, mtdt.ClientIP, true, errInvalidCredentials)
 	}
-	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
-		user.Username,
-		server.config.AccessTokenDuration,
-	)
+
+	factor, err := server.store.GetTOTPFactor(ctx, user.Username)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, status.Errorf(codes.Internal, "failed to find totp factor")
+	}
+	if err == nil && factor.IsConfirmed {
+		return server.createMFAChallenge(ctx, user)
+	}
+
+	return server.createLoginSession(ctx, user)
+}
+
+// createMFAChallenge answers a correct password when two-factor authentication is enabled,
+// the tokens are only issued by VerifyMFA
+func (server *Server) createMFAChallenge(ctx context.Context, user db.User) (*pb.LoginUserResponse, error) {
+	challengeID := uuid.New()
+	id, err := api.ConvertGoogleUUIDToPGTypeUUID(challengeID)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "cannot create access token")
+		return nil, status.Errorf(codes.Internal, "cannot convert challenge id")
 	}
+	challenge, err := server.store.CreateMFAChallenge(ctx, db.CreateMFAChallengeParams{
+		ID:        id,
+		Username:  user.Username,
+		ExpiresAt: api.TimeToPGTimestamptz(time.Now().Add(server.config.MFAChallengeDuration)),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot create mfa challenge")
+	}
+
+	resp := &pb.LoginUserResponse{
+		MfaRequired:           true,
+		MfaChallengeToken:     challengeID.String(),
+		MfaChallengeExpiresAt: timestamppb.New(challenge.ExpiresAt.Time),
+	}
+	return resp, nil
+}
+
+// createLoginSession issues the access and refresh tokens of a completed login
+func (server *Server) createLoginSession(ctx context.Context, user db.User) (*pb.LoginUserResponse, error) {
+	err := server.resetLoginAttempts(ctx, user.Username)
+	if err != nil {
+		return nil, err
+	}
+
 	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
 		user.Username,
-		server.config.RefreshTokenDuration)
+		user.Role,
+		uuid.Nil,
+		server.config.RefreshTokenDuration,
+		token.TokenTypeRefreshToken,
+	)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "cannot create refresh token")
 	}
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
+		user.Username,
+		user.Role,
+		refreshPayload.ID,
+		server.config.AccessTokenDuration,
+		token.TokenTypeAccessToken,
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot create access token")
+	}
 	fixedRefreshPayload, err := api.ConvertGoogleUUIDToPGTypeUUID(refreshPayload.ID)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "cannot convert refresh payload")
@@ -62,6 +124,8 @@ func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (
 		ClientIp:     mtdt.ClientIP,
 		IsBlocked:    false,
 		ExpiresAt:    fixedRefreshPayloadExpiresAt,
+		// A login starts a new family of rotated refresh tokens
+		FamilyID: fixedRefreshPayload,
 	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "cannot create session")
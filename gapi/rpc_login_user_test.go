@@ -0,0 +1,368 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/the-eduardo/Go-Bank/db/mock"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/worker"
+	mockwk "github.com/the-eduardo/Go-Bank/worker/mock"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestLoginUserAPI(t *testing.T) {
+	user, password := randomUser(t)
+	factor := randomTOTPFactor(t, user)
+	lockedUntil := time.Now().Add(15 * time.Minute)
+
+	notLocked := func(store *mockdb.MockStore, username string) {
+		store.EXPECT().
+			GetLoginLockout(gomock.Any(), gomock.Eq(db.GetLoginLockoutParams{Username: username})).
+			Times(1).
+			Return(pgtype.Timestamptz{}, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.LoginUserRequest
+		buildStubs    func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor)
+		checkResponse func(t *testing.T, res *pb.LoginUserResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.LoginUserRequest{
+				Username: user.Username,
+				Password: password,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				notLocked(store, user.Username)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.TotpFactor{}, pgx.ErrNoRows)
+				store.EXPECT().
+					ResetLoginAttempts(gomock.Any(), gomock.Eq(db.ResetLoginAttemptsParams{
+						Scope: db.LoginScopeUsername,
+						Key:   user.Username,
+					})).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.False(t, res.GetMfaRequired())
+				require.NotEmpty(t, res.GetAccessToken())
+				require.NotEmpty(t, res.GetRefreshToken())
+			},
+		},
+		{
+			name: "UnconfirmedTOTP",
+			req: &pb.LoginUserRequest{
+				Username: user.Username,
+				Password: password,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				unconfirmed := factor
+				unconfirmed.IsConfirmed = false
+				notLocked(store, user.Username)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(unconfirmed, nil)
+				store.EXPECT().
+					CreateMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					ResetLoginAttempts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.False(t, res.GetMfaRequired())
+				require.NotEmpty(t, res.GetAccessToken())
+			},
+		},
+		{
+			name: "MFARequired",
+			req: &pb.LoginUserRequest{
+				Username: user.Username,
+				Password: password,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				notLocked(store, user.Username)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(factor, nil)
+				store.EXPECT().
+					CreateMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateMFAChallengeParams) (db.MfaChallenge, error) {
+						require.Equal(t, user.Username, arg.Username)
+						return db.MfaChallenge{ID: arg.ID, Username: arg.Username, ExpiresAt: arg.ExpiresAt}, nil
+					})
+				// The failed attempts are only forgotten once the second step succeeds
+				store.EXPECT().
+					ResetLoginAttempts(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.True(t, res.GetMfaRequired())
+				require.NotEmpty(t, res.GetMfaChallengeToken())
+				require.NotNil(t, res.GetMfaChallengeExpiresAt())
+				require.Empty(t, res.GetAccessToken())
+				require.Empty(t, res.GetRefreshToken())
+			},
+		},
+		{
+			name: "IncorrectPassword",
+			req: &pb.LoginUserRequest{
+				Username: user.Username,
+				Password: "incorrect",
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				notLocked(store, user.Username)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					LoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.LoginFailureTxParams) (db.LoginFailureTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, int32(5), arg.MaxUserAttempts)
+						return db.LoginFailureTxResult{}, nil
+					})
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Any()).
+					Times(0)
+				taskDistributor.EXPECT().
+					DistributeTaskSendLockoutEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.ErrorIs(t, err, errInvalidCredentials)
+			},
+		},
+		{
+			name: "SystemUser",
+			req: &pb.LoginUserRequest{
+				Username: "system_fees",
+				Password: password,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				notLocked(store, "system_fees")
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("system_fees")).
+					Times(1).
+					Return(db.User{Username: "system_fees"}, nil)
+				// System users have nobody to notify either
+				store.EXPECT().
+					LoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginFailureTxResult{UserLockedOut: true}, nil)
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Any()).
+					Times(0)
+				taskDistributor.EXPECT().
+					DistributeTaskSendLockoutEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.ErrorIs(t, err, errInvalidCredentials)
+			},
+		},
+		{
+			name: "UserNotFound",
+			req: &pb.LoginUserRequest{
+				Username: "not_found",
+				Password: password,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				notLocked(store, "not_found")
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("not_found")).
+					Times(1).
+					Return(db.User{}, pgx.ErrNoRows)
+				// Unknown usernames are locked out too, but there is nobody to notify
+				store.EXPECT().
+					LoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginFailureTxResult{UserLockedOut: true}, nil)
+				taskDistributor.EXPECT().
+					DistributeTaskSendLockoutEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.ErrorIs(t, err, errInvalidCredentials)
+			},
+		},
+		{
+			name: "LockoutNotification",
+			req: &pb.LoginUserRequest{
+				Username: user.Username,
+				Password: "incorrect",
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				notLocked(store, user.Username)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					LoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginFailureTxResult{
+						UserAttempt: db.LoginAttempt{
+							Scope:       db.LoginScopeUsername,
+							Key:         user.Username,
+							FailedCount: 5,
+							LockedUntil: pgtype.Timestamptz{Time: lockedUntil, Valid: true},
+						},
+						UserLockedOut: true,
+					}, nil)
+				taskPayload := &worker.PayloadSendLockoutEmail{
+					Username:    user.Username,
+					LockedUntil: lockedUntil,
+				}
+				taskDistributor.EXPECT().
+					DistributeTaskSendLockoutEmail(gomock.Any(), gomock.Eq(taskPayload), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.ErrorIs(t, err, errInvalidCredentials)
+			},
+		},
+		{
+			name: "LockedOut",
+			req: &pb.LoginUserRequest{
+				Username: user.Username,
+				Password: password,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetLoginLockout(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(pgtype.Timestamptz{Time: lockedUntil, Valid: true}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.ResourceExhausted, st.Code())
+			},
+		},
+		{
+			name: "LockoutExpired",
+			req: &pb.LoginUserRequest{
+				Username: user.Username,
+				Password: password,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetLoginLockout(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(pgtype.Timestamptz{Time: time.Now().Add(-time.Second), Valid: true}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TotpFactor{}, pgx.ErrNoRows)
+				store.EXPECT().
+					ResetLoginAttempts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, res.GetAccessToken())
+			},
+		},
+		{
+			name: "InternalError",
+			req: &pb.LoginUserRequest{
+				Username: user.Username,
+				Password: "incorrect",
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				notLocked(store, user.Username)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					LoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginFailureTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Internal, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			taskCtrl := gomock.NewController(t)
+			defer taskCtrl.Finish()
+			taskDistributor := mockwk.NewMockTaskDistributor(taskCtrl)
+
+			tc.buildStubs(store, taskDistributor)
+			server := newTestServer(t, store, taskDistributor)
+
+			res, err := server.LoginUser(context.Background(), tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/the-eduardo/Go-Bank/api"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/token"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Logout revokes the session of the given refresh token, which also rejects the access tokens issued for it.
+// It does not need an access token, so a client whose access token already expired can still log out.
+func (server *Server) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	if violations := validateLogoutRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.GetRefreshToken(), token.TokenTypeRefreshToken)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token")
+	}
+	sessionID, err := api.ConvertGoogleUUIDToPGTypeUUID(refreshPayload.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot convert refresh payload")
+	}
+	_, err = server.store.RevokeSession(ctx, db.RevokeSessionParams{
+		ID:       sessionID,
+		Username: refreshPayload.Username,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "session not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to revoke session: %s", err)
+	}
+	return &pb.LogoutResponse{}, nil
+}
+
+func validateLogoutRequest(req *pb.LogoutRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetRefreshToken() == "" {
+		violations = append(violations, fieldViolation("refresh_token", errors.New("refresh token is required")))
+	}
+	return violations
+}
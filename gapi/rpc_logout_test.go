@@ -0,0 +1,223 @@
+package gapi
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/the-eduardo/Go-Bank/db/mock"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/token"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestLogoutAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		buildReq      func(t *testing.T, tokenMaker token.Maker) (*pb.LogoutRequest, *token.Payload)
+		buildStubs    func(store *mockdb.MockStore, payload *token.Payload)
+		checkResponse func(t *testing.T, res *pb.LogoutResponse, err error)
+	}{
+		{
+			name: "OK",
+			buildReq: func(t *testing.T, tokenMaker token.Maker) (*pb.LogoutRequest, *token.Payload) {
+				refreshToken, payload, err := tokenMaker.CreateToken(user.Username, user.Role, uuid.Nil, time.Minute, token.TokenTypeRefreshToken)
+				require.NoError(t, err)
+				return &pb.LogoutRequest{RefreshToken: refreshToken}, payload
+			},
+			buildStubs: func(store *mockdb.MockStore, payload *token.Payload) {
+				arg := db.RevokeSessionParams{
+					ID:       pgtype.UUID{Bytes: payload.ID, Valid: true},
+					Username: user.Username,
+				}
+				store.EXPECT().
+					RevokeSession(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.Session{ID: arg.ID, Username: user.Username, IsBlocked: true}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.LogoutResponse, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, res)
+			},
+		},
+		{
+			name: "SessionNotFound",
+			buildReq: func(t *testing.T, tokenMaker token.Maker) (*pb.LogoutRequest, *token.Payload) {
+				refreshToken, payload, err := tokenMaker.CreateToken(user.Username, user.Role, uuid.Nil, time.Minute, token.TokenTypeRefreshToken)
+				require.NoError(t, err)
+				return &pb.LogoutRequest{RefreshToken: refreshToken}, payload
+			},
+			buildStubs: func(store *mockdb.MockStore, payload *token.Payload) {
+				store.EXPECT().
+					RevokeSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, res *pb.LogoutResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.NotFound, st.Code())
+			},
+		},
+		{
+			name: "ExpiredRefreshToken",
+			buildReq: func(t *testing.T, tokenMaker token.Maker) (*pb.LogoutRequest, *token.Payload) {
+				refreshToken, payload, err := tokenMaker.CreateToken(user.Username, user.Role, uuid.Nil, -time.Minute, token.TokenTypeRefreshToken)
+				require.NoError(t, err)
+				return &pb.LogoutRequest{RefreshToken: refreshToken}, payload
+			},
+			buildStubs: func(store *mockdb.MockStore, payload *token.Payload) {
+				store.EXPECT().RevokeSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LogoutResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "MissingRefreshToken",
+			buildReq: func(t *testing.T, tokenMaker token.Maker) (*pb.LogoutRequest, *token.Payload) {
+				return &pb.LogoutRequest{}, nil
+			},
+			buildStubs: func(store *mockdb.MockStore, payload *token.Payload) {
+				store.EXPECT().RevokeSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LogoutResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "AccessToken",
+			buildReq: func(t *testing.T, tokenMaker token.Maker) (*pb.LogoutRequest, *token.Payload) {
+				accessToken, payload, err := tokenMaker.CreateToken(user.Username, user.Role, uuid.Nil, time.Minute, token.TokenTypeAccessToken)
+				require.NoError(t, err)
+				return &pb.LogoutRequest{RefreshToken: accessToken}, payload
+			},
+			buildStubs: func(store *mockdb.MockStore, payload *token.Payload) {
+				store.EXPECT().
+					RevokeSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LogoutResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			server := newTestServer(t, store, nil)
+			req, payload := tc.buildReq(t, server.tokenMaker)
+			tc.buildStubs(store, payload)
+
+			res, err := server.Logout(context.Background(), req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestRevokedRefreshTokenAsBearer(t *testing.T) {
+	user, _ := randomUser(t)
+
+	storeCtrl := gomock.NewController(t)
+	defer storeCtrl.Finish()
+	store := mockdb.NewMockStore(storeCtrl)
+	server := newTestServer(t, store, nil)
+
+	refreshToken, payload, err := server.tokenMaker.CreateToken(user.Username, user.Role, uuid.Nil, time.Minute, token.TokenTypeRefreshToken)
+	require.NoError(t, err)
+	store.EXPECT().
+		RevokeSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.Session{ID: pgtype.UUID{Bytes: payload.ID, Valid: true}, Username: user.Username, IsBlocked: true}, nil)
+	_, err = server.Logout(context.Background(), &pb.LogoutRequest{RefreshToken: refreshToken})
+	require.NoError(t, err)
+
+	// The refresh token of the revoked session must not work as an access token either
+	store.EXPECT().
+		ListSessions(gomock.Any(), gomock.Any()).
+		Times(0)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{
+		authorizationHeader: []string{authorizationBearer + " " + refreshToken},
+	})
+	_, err = server.ListSessions(ctx, &pb.ListSessionsRequest{})
+	require.Error(t, err)
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, codes.Unauthenticated, st.Code())
+}
+
+func TestLoggedOutAccessToken(t *testing.T) {
+	user, _ := randomUser(t)
+
+	storeCtrl := gomock.NewController(t)
+	defer storeCtrl.Finish()
+	store := mockdb.NewMockStore(storeCtrl)
+	server := newTestServer(t, store, nil)
+
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, uuid.Nil, time.Minute, token.TokenTypeRefreshToken)
+	require.NoError(t, err)
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, refreshPayload.ID, time.Minute, token.TokenTypeAccessToken)
+	require.NoError(t, err)
+	require.Equal(t, refreshPayload.ID, accessPayload.SessionID)
+	session := db.Session{ID: pgtype.UUID{Bytes: refreshPayload.ID, Valid: true}, Username: user.Username}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{
+		authorizationHeader: []string{authorizationBearer + " " + accessToken},
+	})
+
+	// The access token works while its session is active
+	store.EXPECT().
+		GetSession(gomock.Any(), gomock.Eq(session.ID)).
+		Times(1).
+		Return(session, nil)
+	store.EXPECT().
+		ListSessions(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.Session{session}, nil)
+	_, err = server.ListSessions(ctx, &pb.ListSessionsRequest{PageId: 1, PageSize: 5})
+	require.NoError(t, err)
+
+	blockedSession := session
+	blockedSession.IsBlocked = true
+	store.EXPECT().
+		RevokeSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(blockedSession, nil)
+	_, err = server.Logout(context.Background(), &pb.LogoutRequest{RefreshToken: refreshToken})
+	require.NoError(t, err)
+
+	// and is rejected once the session is logged out, before it expires
+	store.EXPECT().
+		GetSession(gomock.Any(), gomock.Eq(session.ID)).
+		Times(1).
+		Return(blockedSession, nil)
+	_, err = server.ListSessions(ctx, &pb.ListSessionsRequest{PageId: 1, PageSize: 5})
+	require.Error(t, err)
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, codes.Unauthenticated, st.Code())
+}
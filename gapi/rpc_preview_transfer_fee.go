@@ -0,0 +1,37 @@
+package gapi
+
+import (
+	"context"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (server *Server) PreviewTransferFee(ctx context.Context, req *pb.PreviewTransferFeeRequest) (*pb.PreviewTransferFeeResponse, error) {
+	_, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+	if violations := validatePreviewTransferFeeRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	fee := server.feeSchedule.Calculate(req.GetCurrency(), req.GetAmount())
+	resp := &pb.PreviewTransferFeeResponse{
+		Amount:   req.GetAmount(),
+		Fee:      fee,
+		Total:    req.GetAmount() + fee,
+		Currency: req.GetCurrency(),
+	}
+	return resp, nil
+}
+
+func validatePreviewTransferFeeRequest(req *pb.PreviewTransferFeeRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+	return violations
+}
@@ -0,0 +1,112 @@
+package gapi
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/fees"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/token"
+	"github.com/the-eduardo/Go-Bank/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestPreviewTransferFeeAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	schedule := &fees.Schedule{
+		Default:    fees.Rule{Flat: 10},
+		Currencies: map[string]fees.Rule{util.EUR: {PercentBps: 100}},
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.PreviewTransferFeeRequest
+		schedule      *fees.Schedule
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, res *pb.PreviewTransferFeeResponse, err error)
+	}{
+		{
+			name:     "OK",
+			req:      &pb.PreviewTransferFeeRequest{Amount: 1000, Currency: util.USD},
+			schedule: schedule,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.PreviewTransferFeeResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(1000), res.GetAmount())
+				require.Equal(t, int64(10), res.GetFee())
+				require.Equal(t, int64(1010), res.GetTotal())
+				require.Equal(t, util.USD, res.GetCurrency())
+			},
+		},
+		{
+			name:     "CurrencyRule",
+			req:      &pb.PreviewTransferFeeRequest{Amount: 1000, Currency: util.EUR},
+			schedule: schedule,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.PreviewTransferFeeResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(10), res.GetFee())
+				require.Equal(t, util.EUR, res.GetCurrency())
+			},
+		},
+		{
+			name: "NoSchedule",
+			req:  &pb.PreviewTransferFeeRequest{Amount: 1000, Currency: util.USD},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.PreviewTransferFeeResponse, err error) {
+				require.NoError(t, err)
+				require.Zero(t, res.GetFee())
+				require.Equal(t, int64(1000), res.GetTotal())
+			},
+		},
+		{
+			name:     "InvalidCurrency",
+			req:      &pb.PreviewTransferFeeRequest{Amount: 1000, Currency: "XYZ"},
+			schedule: schedule,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.PreviewTransferFeeResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name:     "NoAuthorization",
+			req:      &pb.PreviewTransferFeeRequest{Amount: 1000, Currency: util.USD},
+			schedule: schedule,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponse: func(t *testing.T, res *pb.PreviewTransferFeeResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil, nil)
+			server.feeSchedule = tc.schedule
+
+			ctx := tc.buildContext(t, server.tokenMaker)
+			res, err := server.PreviewTransferFee(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/val"
+	"github.com/the-eduardo/Go-Bank/worker"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) RedeliverWebhook(ctx context.Context, req *pb.RedeliverWebhookRequest) (*pb.RedeliverWebhookResponse, error) {
+	authPayload, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+	if violations := validateRedeliverWebhookRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	delivery, err := server.authorizeWebhookDelivery(ctx, authPayload, req.GetDeliveryId())
+	if err != nil {
+		return nil, err
+	}
+	event, err := server.store.GetWebhookEvent(ctx, delivery.EventID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get webhook event: %s", err)
+	}
+
+	// the delivery goes back to pending with a new round of retries, its previous attempts stay counted
+	result, err := server.store.RedeliverWebhookTx(ctx, db.RedeliverWebhookTxParams{
+		DeliveryID: delivery.ID,
+		AfterReset: func(delivery db.WebhookDelivery) error {
+			taskPayload := &worker.PayloadDeliverWebhook{DeliveryID: delivery.ID}
+			return server.taskDistributor.DistributeTaskDeliverWebhook(ctx, taskPayload, worker.WebhookDeliveryOptions(delivery)...)
+		},
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrWebhookDeliveryPending) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to redeliver webhook: %s", err)
+	}
+
+	resp := &pb.RedeliverWebhookResponse{
+		Delivery: convertWebhookDelivery(result.Delivery, event.EventType),
+	}
+	return resp, nil
+}
+
+func validateRedeliverWebhookRequest(req *pb.RedeliverWebhookRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetDeliveryId()); err != nil {
+		violations = append(violations, fieldViolation("delivery_id", err))
+	}
+	return violations
+}
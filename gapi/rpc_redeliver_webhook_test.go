@@ -0,0 +1,216 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/the-eduardo/Go-Bank/db/mock"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/token"
+	"github.com/the-eduardo/Go-Bank/util"
+	"github.com/the-eduardo/Go-Bank/worker"
+	mockwk "github.com/the-eduardo/Go-Bank/worker/mock"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestRedeliverWebhookAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	subscription := db.WebhookSubscription{
+		ID:         util.RandomInt(1, 1000),
+		Owner:      user.Username,
+		Url:        "https://partner.example.com/hooks",
+		Secret:     util.RandomString(webhookSecretLength),
+		EventTypes: []string{util.WebhookTransferPosted},
+	}
+	event := db.WebhookEvent{
+		ID:        util.RandomInt(1, 1000),
+		Owner:     user.Username,
+		EventType: util.WebhookTransferPosted,
+		Payload:   []byte(`{}`),
+	}
+	delivery := db.WebhookDelivery{
+		ID:             util.RandomInt(1, 1000),
+		EventID:        event.ID,
+		SubscriptionID: subscription.ID,
+		Status:         db.WebhookDeliveryFailed,
+		Attempts:       11,
+		ResponseStatus: pgtype.Int4{Int32: 500, Valid: true},
+		Error:          "receiver answered 500 Internal Server Error",
+	}
+	reset := delivery
+	reset.Status = db.WebhookDeliveryPending
+
+	testCases := []struct {
+		name          string
+		req           *pb.RedeliverWebhookRequest
+		buildStubs    func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, res *pb.RedeliverWebhookResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.RedeliverWebhookRequest{DeliveryId: delivery.ID},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(delivery, nil)
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+				store.EXPECT().GetWebhookEvent(gomock.Any(), gomock.Eq(event.ID)).Times(1).Return(event, nil)
+				store.EXPECT().
+					RedeliverWebhookTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.RedeliverWebhookTxParams) (db.RedeliverWebhookTxResult, error) {
+						require.Equal(t, delivery.ID, arg.DeliveryID)
+						if err := arg.AfterReset(reset); err != nil {
+							return db.RedeliverWebhookTxResult{}, err
+						}
+						return db.RedeliverWebhookTxResult{Delivery: reset}, nil
+					})
+				taskDistributor.EXPECT().
+					DistributeTaskDeliverWebhook(gomock.Any(), gomock.Eq(&worker.PayloadDeliverWebhook{DeliveryID: delivery.ID}), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.RedeliverWebhookResponse, err error) {
+				require.NoError(t, err)
+				redelivery := res.GetDelivery()
+				require.Equal(t, delivery.ID, redelivery.GetId())
+				require.Equal(t, db.WebhookDeliveryPending, redelivery.GetStatus())
+				require.Equal(t, util.WebhookTransferPosted, redelivery.GetEventType())
+				require.Equal(t, int32(11), redelivery.GetAttempts())
+				require.Equal(t, int32(500), redelivery.GetResponseStatus())
+			},
+		},
+		{
+			name: "EnqueueFailed",
+			req:  &pb.RedeliverWebhookRequest{DeliveryId: delivery.ID},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(delivery, nil)
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+				store.EXPECT().GetWebhookEvent(gomock.Any(), gomock.Eq(event.ID)).Times(1).Return(event, nil)
+				store.EXPECT().
+					RedeliverWebhookTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.RedeliverWebhookTxParams) (db.RedeliverWebhookTxResult, error) {
+						return db.RedeliverWebhookTxResult{}, arg.AfterReset(reset)
+					})
+				taskDistributor.EXPECT().
+					DistributeTaskDeliverWebhook(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(fmt.Errorf("redis is down"))
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.RedeliverWebhookResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Internal, st.Code())
+			},
+		},
+		{
+			name: "StillPending",
+			req:  &pb.RedeliverWebhookRequest{DeliveryId: delivery.ID},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(reset, nil)
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+				store.EXPECT().GetWebhookEvent(gomock.Any(), gomock.Eq(event.ID)).Times(1).Return(event, nil)
+				store.EXPECT().
+					RedeliverWebhookTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RedeliverWebhookTxResult{}, db.ErrWebhookDeliveryPending)
+				taskDistributor.EXPECT().DistributeTaskDeliverWebhook(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.RedeliverWebhookResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.FailedPrecondition, st.Code())
+			},
+		},
+		{
+			name: "NotFound",
+			req:  &pb.RedeliverWebhookRequest{DeliveryId: delivery.ID},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(db.WebhookDelivery{}, pgx.ErrNoRows)
+				store.EXPECT().RedeliverWebhookTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.RedeliverWebhookResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.NotFound, st.Code())
+			},
+		},
+		{
+			name: "PermissionDenied",
+			req:  &pb.RedeliverWebhookRequest{DeliveryId: delivery.ID},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(delivery, nil)
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+				store.EXPECT().RedeliverWebhookTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "other_user", util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.RedeliverWebhookResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.PermissionDenied, st.Code())
+			},
+		},
+		{
+			name: "InvalidDeliveryID",
+			req:  &pb.RedeliverWebhookRequest{DeliveryId: 0},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().GetWebhookDelivery(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.RedeliverWebhookResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			taskCtrl := gomock.NewController(t)
+			defer taskCtrl.Finish()
+			taskDistributor := mockwk.NewMockTaskDistributor(taskCtrl)
+
+			tc.buildStubs(store, taskDistributor)
+			server := newTestServer(t, store, taskDistributor)
+
+			ctx := tc.buildContext(t, server.tokenMaker)
+			res, err := server.RedeliverWebhook(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/the-eduardo/Go-Bank/api"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/token"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (server *Server) RenewAccessToken(ctx context.Context, req *pb.RenewAccessTokenRequest) (*pb.RenewAccessTokenResponse, error) {
+	if violations := validateRenewAccessTokenRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.GetRefreshToken(), token.TokenTypeRefreshToken)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token")
+	}
+	sessionID, err := api.ConvertGoogleUUIDToPGTypeUUID(refreshPayload.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot convert refresh payload")
+	}
+
+	// The role may have changed since the login, the new tokens carry the current one
+	user, err := server.store.GetUser(ctx, refreshPayload.Username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	refreshToken, newRefreshPayload, err := server.tokenMaker.CreateToken(
+		user.Username,
+		user.Role,
+		uuid.Nil,
+		server.config.RefreshTokenDuration,
+		token.TokenTypeRefreshToken,
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot create refresh token")
+	}
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
+		user.Username,
+		user.Role,
+		newRefreshPayload.ID,
+		server.config.AccessTokenDuration,
+		token.TokenTypeAccessToken,
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot create access token")
+	}
+	newSessionID, err := api.ConvertGoogleUUIDToPGTypeUUID(newRefreshPayload.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot convert refresh payload")
+	}
+
+	mtdt := server.extractMetadata(ctx)
+	_, err = server.store.RenewSessionTx(ctx, db.RenewSessionTxParams{
+		SessionID:    sessionID,
+		Username:     refreshPayload.Username,
+		RefreshToken: req.GetRefreshToken(),
+		NewSession: db.CreateSessionParams{
+			ID:           newSessionID,
+			Username:     refreshPayload.Username,
+			RefreshToken: refreshToken,
+			UserAgent:    mtdt.UserAgent,
+			ClientIp:     mtdt.ClientIP,
+			IsBlocked:    false,
+			ExpiresAt:    api.TimeToPGTimestamptz(newRefreshPayload.ExpiredAt),
+		},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, status.Errorf(codes.NotFound, "session not found")
+		case errors.Is(err, db.ErrRefreshTokenReused):
+			return nil, status.Errorf(codes.Unauthenticated, "%s: all sessions of this login were revoked", err)
+		case errors.Is(err, db.ErrSessionBlocked),
+			errors.Is(err, db.ErrSessionExpired),
+			errors.Is(err, db.ErrSessionMismatch):
+			return nil, status.Errorf(codes.Unauthenticated, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to renew session: %s", err)
+	}
+
+	resp := &pb.RenewAccessTokenResponse{
+		SessionId:             newRefreshPayload.ID.String(),
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		AccessTokenExpiresAt:  timestamppb.New(accessPayload.ExpiredAt),
+		RefreshTokenExpiresAt: timestamppb.New(newRefreshPayload.ExpiredAt),
+	}
+	return resp, nil
+}
+
+func validateRenewAccessTokenRequest(req *pb.RenewAccessTokenRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetRefreshToken() == "" {
+		violations = append(violations, fieldViolation("refresh_token", errors.New("refresh token is required")))
+	}
+	return violations
+}
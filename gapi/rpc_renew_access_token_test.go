@@ -0,0 +1,243 @@
+package gapi
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/the-eduardo/Go-Bank/db/mock"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/token"
+	"github.com/the-eduardo/Go-Bank/util"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestRenewAccessTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	newRefreshToken := func(t *testing.T, tokenMaker token.Maker, duration time.Duration) (*pb.RenewAccessTokenRequest, *token.Payload) {
+		refreshToken, payload, err := tokenMaker.CreateToken(user.Username, user.Role, uuid.Nil, duration, token.TokenTypeRefreshToken)
+		require.NoError(t, err)
+		return &pb.RenewAccessTokenRequest{RefreshToken: refreshToken}, payload
+	}
+
+	testCases := []struct {
+		name          string
+		buildReq      func(t *testing.T, tokenMaker token.Maker) (*pb.RenewAccessTokenRequest, *token.Payload)
+		buildStubs    func(store *mockdb.MockStore, req *pb.RenewAccessTokenRequest, payload *token.Payload)
+		checkResponse func(t *testing.T, tokenMaker token.Maker, req *pb.RenewAccessTokenRequest, res *pb.RenewAccessTokenResponse, err error)
+	}{
+		{
+			name: "OK",
+			buildReq: func(t *testing.T, tokenMaker token.Maker) (*pb.RenewAccessTokenRequest, *token.Payload) {
+				return newRefreshToken(t, tokenMaker, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, req *pb.RenewAccessTokenRequest, payload *token.Payload) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RenewSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.RenewSessionTxParams) (db.RenewSessionTxResult, error) {
+						require.Equal(t, pgtype.UUID{Bytes: payload.ID, Valid: true}, arg.SessionID)
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, req.GetRefreshToken(), arg.RefreshToken)
+						require.Equal(t, user.Username, arg.NewSession.Username)
+						require.NotEqual(t, arg.SessionID, arg.NewSession.ID)
+						require.NotEqual(t, arg.RefreshToken, arg.NewSession.RefreshToken)
+						return db.RenewSessionTxResult{
+							NewSession: db.Session{ID: arg.NewSession.ID, FamilyID: arg.SessionID},
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, tokenMaker token.Maker, req *pb.RenewAccessTokenRequest, res *pb.RenewAccessTokenResponse, err error) {
+				require.NoError(t, err)
+				require.NotEqual(t, req.GetRefreshToken(), res.GetRefreshToken())
+
+				accessPayload, err := tokenMaker.VerifyToken(res.GetAccessToken(), token.TokenTypeAccessToken)
+				require.NoError(t, err)
+				require.Equal(t, user.Username, accessPayload.Username)
+				require.Equal(t, user.Role, accessPayload.Role)
+
+				refreshPayload, err := tokenMaker.VerifyToken(res.GetRefreshToken(), token.TokenTypeRefreshToken)
+				require.NoError(t, err)
+				require.Equal(t, refreshPayload.ID.String(), res.GetSessionId())
+			},
+		},
+		{
+			name: "RoleChanged",
+			buildReq: func(t *testing.T, tokenMaker token.Maker) (*pb.RenewAccessTokenRequest, *token.Payload) {
+				return newRefreshToken(t, tokenMaker, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, req *pb.RenewAccessTokenRequest, payload *token.Payload) {
+				promoted := user
+				promoted.Role = util.BankerRole
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(promoted, nil)
+				store.EXPECT().
+					RenewSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.RenewSessionTxParams) (db.RenewSessionTxResult, error) {
+						return db.RenewSessionTxResult{
+							NewSession: db.Session{ID: arg.NewSession.ID, FamilyID: arg.SessionID},
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, tokenMaker token.Maker, req *pb.RenewAccessTokenRequest, res *pb.RenewAccessTokenResponse, err error) {
+				require.NoError(t, err)
+
+				accessPayload, err := tokenMaker.VerifyToken(res.GetAccessToken(), token.TokenTypeAccessToken)
+				require.NoError(t, err)
+				require.Equal(t, util.BankerRole, accessPayload.Role)
+
+				refreshPayload, err := tokenMaker.VerifyToken(res.GetRefreshToken(), token.TokenTypeRefreshToken)
+				require.NoError(t, err)
+				require.Equal(t, util.BankerRole, refreshPayload.Role)
+			},
+		},
+		{
+			name: "UserNotFound",
+			buildReq: func(t *testing.T, tokenMaker token.Maker) (*pb.RenewAccessTokenRequest, *token.Payload) {
+				return newRefreshToken(t, tokenMaker, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, req *pb.RenewAccessTokenRequest, payload *token.Payload) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{}, pgx.ErrNoRows)
+				store.EXPECT().RenewSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, tokenMaker token.Maker, req *pb.RenewAccessTokenRequest, res *pb.RenewAccessTokenResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.NotFound, st.Code())
+			},
+		},
+		{
+			name: "RefreshTokenReused",
+			buildReq: func(t *testing.T, tokenMaker token.Maker) (*pb.RenewAccessTokenRequest, *token.Payload) {
+				return newRefreshToken(t, tokenMaker, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, req *pb.RenewAccessTokenRequest, payload *token.Payload) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RenewSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RenewSessionTxResult{}, db.ErrRefreshTokenReused)
+			},
+			checkResponse: func(t *testing.T, tokenMaker token.Maker, req *pb.RenewAccessTokenRequest, res *pb.RenewAccessTokenResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "SessionBlocked",
+			buildReq: func(t *testing.T, tokenMaker token.Maker) (*pb.RenewAccessTokenRequest, *token.Payload) {
+				return newRefreshToken(t, tokenMaker, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, req *pb.RenewAccessTokenRequest, payload *token.Payload) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RenewSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RenewSessionTxResult{}, db.ErrSessionBlocked)
+			},
+			checkResponse: func(t *testing.T, tokenMaker token.Maker, req *pb.RenewAccessTokenRequest, res *pb.RenewAccessTokenResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "SessionNotFound",
+			buildReq: func(t *testing.T, tokenMaker token.Maker) (*pb.RenewAccessTokenRequest, *token.Payload) {
+				return newRefreshToken(t, tokenMaker, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, req *pb.RenewAccessTokenRequest, payload *token.Payload) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RenewSessionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RenewSessionTxResult{}, pgx.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, tokenMaker token.Maker, req *pb.RenewAccessTokenRequest, res *pb.RenewAccessTokenResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.NotFound, st.Code())
+			},
+		},
+		{
+			name: "ExpiredRefreshToken",
+			buildReq: func(t *testing.T, tokenMaker token.Maker) (*pb.RenewAccessTokenRequest, *token.Payload) {
+				return newRefreshToken(t, tokenMaker, -time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, req *pb.RenewAccessTokenRequest, payload *token.Payload) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().RenewSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, tokenMaker token.Maker, req *pb.RenewAccessTokenRequest, res *pb.RenewAccessTokenResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "MissingRefreshToken",
+			buildReq: func(t *testing.T, tokenMaker token.Maker) (*pb.RenewAccessTokenRequest, *token.Payload) {
+				return &pb.RenewAccessTokenRequest{}, nil
+			},
+			buildStubs: func(store *mockdb.MockStore, req *pb.RenewAccessTokenRequest, payload *token.Payload) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().RenewSessionTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, tokenMaker token.Maker, req *pb.RenewAccessTokenRequest, res *pb.RenewAccessTokenResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			server := newTestServer(t, store, nil)
+			req, payload := tc.buildReq(t, server.tokenMaker)
+			tc.buildStubs(store, req, payload)
+
+			res, err := server.RenewAccessToken(context.Background(), req)
+			tc.checkResponse(t, server.tokenMaker, req, res, err)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/val"
+	"github.com/the-eduardo/Go-Bank/worker"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+func (server *Server) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	if violations := validateRequestPasswordResetRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	// An unknown email gets the same answer, so that resets cannot be used to find out who has an account
+	resp := &pb.RequestPasswordResetResponse{}
+	user, err := server.store.GetUserByEmail(ctx, req.GetEmail())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return resp, nil
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+
+	taskPayload := &worker.PayloadSendPasswordReset{
+		Username: user.Username,
+	}
+	opts := []asynq.Option{
+		asynq.MaxRetry(7),
+		asynq.Timeout(15 * time.Second),
+		asynq.Queue(worker.QueueEmail),
+	}
+	err = server.taskDistributor.DistributeTaskSendPasswordReset(ctx, taskPayload, opts...)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to distribute password reset email")
+	}
+	return resp, nil
+}
+
+func validateRequestPasswordResetRequest(req *pb.RequestPasswordResetRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateEmail(req.GetEmail()); err != nil {
+		violations = append(violations, fieldViolation("email", err))
+	}
+	return violations
+}
@@ -0,0 +1,55 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/util"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	if violations := validateResetPasswordRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	hashedPassword, err := util.HashPassword(req.GetNewPassword())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to hash password: %s", err)
+	}
+	txResult, err := server.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		HashedCode:     util.HashSecretCode(req.GetCode()),
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "reset code is invalid, expired or already used")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to reset password: %s", err)
+	}
+	// Proving the email is enough to lift a lockout caused by someone guessing the old password
+	err = server.resetLoginAttempts(ctx, txResult.User.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ResetPasswordResponse{
+		IsReset: true,
+	}
+	return resp, nil
+}
+
+func validateResetPasswordRequest(req *pb.ResetPasswordRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateString(req.GetCode(), 16, 128); err != nil {
+		violations = append(violations, fieldViolation("code", err))
+	}
+	if err := val.ValidatePassword(req.GetNewPassword()); err != nil {
+		violations = append(violations, fieldViolation("new_password", err))
+	}
+	return violations
+}
@@ -0,0 +1,65 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ReviewKYC(ctx context.Context, req *pb.ReviewKYCRequest) (*pb.ReviewKYCResponse, error) {
+	authPayload, err := server.autorizeUser(ctx, adminRoles)
+	if err != nil {
+		return nil, authorizationError(err)
+	}
+	if violations := validateReviewKYCRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	submission, err := server.store.GetKYCSubmission(ctx, req.GetSubmissionId())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "kyc submission not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get kyc submission: %s", err)
+	}
+	if submission.Username == authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "cannot review your own kyc submission")
+	}
+
+	txResult, err := server.store.ReviewKYCTx(ctx, db.ReviewKYCTxParams{
+		ID:         submission.ID,
+		Approved:   req.GetApproved(),
+		ReviewedBy: authPayload.Username,
+		ReviewNote: req.GetReviewNote(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.FailedPrecondition, "kyc submission was already reviewed")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to review kyc submission: %s", err)
+	}
+
+	resp := &pb.ReviewKYCResponse{
+		Submission: convertKYCSubmission(txResult.Submission),
+		User:       convertUser(txResult.User),
+	}
+	return resp, nil
+}
+
+func validateReviewKYCRequest(req *pb.ReviewKYCRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetSubmissionId()); err != nil {
+		violations = append(violations, fieldViolation("submission_id", err))
+	}
+	if req.GetReviewNote() != "" {
+		if err := val.ValidateString(req.GetReviewNote(), 1, 500); err != nil {
+			violations = append(violations, fieldViolation("review_note", err))
+		}
+	}
+	return violations
+}
@@ -0,0 +1,199 @@
+package gapi
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/the-eduardo/Go-Bank/db/mock"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/token"
+	"github.com/the-eduardo/Go-Bank/util"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestReviewKYCAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	admin.Role = util.AdminRole
+	user, _ := randomUser(t)
+
+	submission := db.KycSubmission{
+		ID:             util.RandomInt(1, 1000),
+		Username:       user.Username,
+		Tier:           util.KYCTierBasic,
+		DocumentType:   "passport",
+		DocumentNumber: util.RandomString(9),
+		Status:         db.KYCStatusPending,
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.ReviewKYCRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, res *pb.ReviewKYCResponse, err error)
+	}{
+		{
+			name: "Approve",
+			req: &pb.ReviewKYCRequest{
+				SubmissionId: submission.ID,
+				Approved:     true,
+				ReviewNote:   "documents match",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetKYCSubmission(gomock.Any(), gomock.Eq(submission.ID)).
+					Times(1).
+					Return(submission, nil)
+				arg := db.ReviewKYCTxParams{
+					ID:         submission.ID,
+					Approved:   true,
+					ReviewedBy: admin.Username,
+					ReviewNote: "documents match",
+				}
+				approved := submission
+				approved.Status = db.KYCStatusApproved
+				approved.ReviewedBy = pgtype.Text{String: admin.Username, Valid: true}
+				upgraded := user
+				upgraded.KycTier = submission.Tier
+				store.EXPECT().
+					ReviewKYCTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ReviewKYCTxResult{Submission: approved, User: upgraded}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, admin.Username, admin.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ReviewKYCResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, db.KYCStatusApproved, res.GetSubmission().GetStatus())
+				require.Equal(t, admin.Username, res.GetSubmission().GetReviewedBy())
+				require.Equal(t, int32(util.KYCTierBasic), res.GetUser().GetKycTier())
+			},
+		},
+		{
+			name: "AlreadyReviewed",
+			req: &pb.ReviewKYCRequest{
+				SubmissionId: submission.ID,
+				Approved:     false,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetKYCSubmission(gomock.Any(), gomock.Eq(submission.ID)).
+					Times(1).
+					Return(submission, nil)
+				store.EXPECT().
+					ReviewKYCTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewKYCTxResult{}, pgx.ErrNoRows)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, admin.Username, admin.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ReviewKYCResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.FailedPrecondition, st.Code())
+			},
+		},
+		{
+			name: "OwnSubmission",
+			req: &pb.ReviewKYCRequest{
+				SubmissionId: submission.ID,
+				Approved:     true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				own := submission
+				own.Username = admin.Username
+				store.EXPECT().
+					GetKYCSubmission(gomock.Any(), gomock.Eq(submission.ID)).
+					Times(1).
+					Return(own, nil)
+				store.EXPECT().
+					ReviewKYCTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, admin.Username, admin.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ReviewKYCResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.PermissionDenied, st.Code())
+			},
+		},
+		{
+			name: "NotFound",
+			req: &pb.ReviewKYCRequest{
+				SubmissionId: submission.ID,
+				Approved:     true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetKYCSubmission(gomock.Any(), gomock.Eq(submission.ID)).
+					Times(1).
+					Return(db.KycSubmission{}, pgx.ErrNoRows)
+				store.EXPECT().
+					ReviewKYCTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, admin.Username, admin.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ReviewKYCResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.NotFound, st.Code())
+			},
+		},
+		{
+			name: "NotAdmin",
+			req: &pb.ReviewKYCRequest{
+				SubmissionId: submission.ID,
+				Approved:     true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetKYCSubmission(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					ReviewKYCTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.BankerRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ReviewKYCResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.PermissionDenied, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			ctx := tc.buildContext(t, server.tokenMaker)
+			res, err := server.ReviewKYC(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) SetAccountTransferLimits(ctx context.Context, req *pb.SetAccountTransferLimitsRequest) (*pb.SetAccountTransferLimitsResponse, error) {
+	_, err := server.autorizeUser(ctx, adminRoles)
+	if err != nil {
+		return nil, authorizationError(err)
+	}
+	if violations := validateSetAccountTransferLimitsRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	limits, err := server.store.UpsertAccountTransferLimits(ctx, db.UpsertAccountTransferLimitsParams{
+		AccountID:   req.GetAccountId(),
+		PerTransfer: optionalInt8(req.PerTransfer),
+		Daily:       optionalInt8(req.Daily),
+		Weekly:      optionalInt8(req.Weekly),
+		HourlyCount: optionalInt8(req.HourlyCount),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to set transfer limits: %s", err)
+	}
+
+	resp := &pb.SetAccountTransferLimitsResponse{
+		Limits: convertAccountTransferLimits(limits),
+	}
+	return resp, nil
+}
+
+// optionalInt8 maps an unset optional field to a null column
+func optionalInt8(value *int64) pgtype.Int8 {
+	if value == nil {
+		return pgtype.Int8{}
+	}
+	return pgtype.Int8{Int64: *value, Valid: true}
+}
+
+func validateSetAccountTransferLimitsRequest(req *pb.SetAccountTransferLimitsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	limits := []struct {
+		field string
+		value *int64
+	}{
+		{"per_transfer", req.PerTransfer},
+		{"daily", req.Daily},
+		{"weekly", req.Weekly},
+		{"hourly_count", req.HourlyCount},
+	}
+	for _, limit := range limits {
+		if limit.value == nil {
+			continue
+		}
+		if err := val.ValidateTransferLimit(*limit.value); err != nil {
+			violations = append(violations, fieldViolation(limit.field, err))
+		}
+	}
+	return violations
+}
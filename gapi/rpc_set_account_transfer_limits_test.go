@@ -0,0 +1,150 @@
+package gapi
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/the-eduardo/Go-Bank/db/mock"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/token"
+	"github.com/the-eduardo/Go-Bank/util"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"testing"
+	"time"
+)
+
+func TestSetAccountTransferLimitsAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	admin.Role = util.AdminRole
+	accountID := util.RandomInt(1, 1000)
+
+	testCases := []struct {
+		name          string
+		req           *pb.SetAccountTransferLimitsRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, res *pb.SetAccountTransferLimitsResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.SetAccountTransferLimitsRequest{
+				AccountId:   accountID,
+				Daily:       proto.Int64(5000),
+				HourlyCount: proto.Int64(0),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpsertAccountTransferLimitsParams{
+					AccountID:   accountID,
+					Daily:       pgtype.Int8{Int64: 5000, Valid: true},
+					HourlyCount: pgtype.Int8{Int64: 0, Valid: true},
+				}
+				store.EXPECT().
+					UpsertAccountTransferLimits(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.AccountTransferLimit{
+						AccountID:   accountID,
+						Daily:       arg.Daily,
+						HourlyCount: arg.HourlyCount,
+					}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, admin.Username, admin.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.SetAccountTransferLimitsResponse, err error) {
+				require.NoError(t, err)
+				limits := res.GetLimits()
+				require.Equal(t, accountID, limits.GetAccountId())
+				require.Equal(t, int64(5000), limits.GetDaily())
+				require.NotNil(t, limits.HourlyCount)
+				require.Zero(t, limits.GetHourlyCount())
+				require.Nil(t, limits.PerTransfer)
+				require.Nil(t, limits.Weekly)
+			},
+		},
+		{
+			name: "NegativeLimit",
+			req: &pb.SetAccountTransferLimitsRequest{
+				AccountId: accountID,
+				Weekly:    proto.Int64(-1),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertAccountTransferLimits(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, admin.Username, admin.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.SetAccountTransferLimitsResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "AccountNotFound",
+			req: &pb.SetAccountTransferLimitsRequest{
+				AccountId: accountID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertAccountTransferLimits(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountTransferLimit{}, &pgconn.PgError{Code: "23503"})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, admin.Username, admin.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.SetAccountTransferLimitsResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.NotFound, st.Code())
+			},
+		},
+		{
+			name: "NotAdmin",
+			req: &pb.SetAccountTransferLimitsRequest{
+				AccountId: accountID,
+				Daily:     proto.Int64(5000),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertAccountTransferLimits(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, admin.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.SetAccountTransferLimitsResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.PermissionDenied, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			ctx := tc.buildContext(t, server.tokenMaker)
+			res, err := server.SetAccountTransferLimits(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
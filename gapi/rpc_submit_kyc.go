@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) SubmitKYC(ctx context.Context, req *pb.SubmitKYCRequest) (*pb.SubmitKYCResponse, error) {
+	authPayload, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+	if violations := validateSubmitKYCRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+	if req.GetTier() <= user.KycTier {
+		return nil, status.Errorf(codes.FailedPrecondition, "user already has kyc tier %d", user.KycTier)
+	}
+
+	submission, err := server.store.CreateKYCSubmission(ctx, db.CreateKYCSubmissionParams{
+		Username:       user.Username,
+		Tier:           req.GetTier(),
+		DocumentType:   req.GetDocumentType(),
+		DocumentNumber: req.GetDocumentNumber(),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		// a user has at most one pending submission
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, status.Errorf(codes.AlreadyExists, "a kyc submission is already waiting for review")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create kyc submission: %s", err)
+	}
+
+	resp := &pb.SubmitKYCResponse{
+		Submission: convertKYCSubmission(submission),
+	}
+	return resp, nil
+}
+
+func validateSubmitKYCRequest(req *pb.SubmitKYCRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateKYCTier(req.GetTier()); err != nil {
+		violations = append(violations, fieldViolation("tier", err))
+	}
+	if err := val.ValidateString(req.GetDocumentType(), 2, 50); err != nil {
+		violations = append(violations, fieldViolation("document_type", err))
+	}
+	if err := val.ValidateString(req.GetDocumentNumber(), 3, 50); err != nil {
+		violations = append(violations, fieldViolation("document_number", err))
+	}
+	return violations
+}
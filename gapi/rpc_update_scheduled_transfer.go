@@ -0,0 +1,84 @@
+package gapi
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+func (server *Server) UpdateScheduledTransfer(ctx context.Context, req *pb.UpdateScheduledTransferRequest) (*pb.UpdateScheduledTransferResponse, error) {
+	authPayload, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+	if violations := validateUpdateScheduledTransferRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	scheduledTransfer, err := server.authorizeScheduledTransfer(ctx, authPayload, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	arg := db.UpdateScheduledTransferParams{
+		ID:     scheduledTransfer.ID,
+		Amount: optionalInt8(req.Amount),
+	}
+	if req.IsActive != nil {
+		arg.IsActive = pgtype.Bool{Bool: req.GetIsActive(), Valid: true}
+	}
+
+	// The next run only moves when the recurrence changes or when a paused transfer resumes,
+	// the runs missed while it was paused are skipped
+	now := time.Now().UTC()
+	recurrence := scheduledTransfer.Recurrence()
+	last := scheduledTransfer.NextRunAt.Time
+	changed := req.CronExpression != nil || req.IntervalSeconds != nil
+	if changed {
+		recurrence = scheduledTransferRecurrence(req.GetCronExpression(), req.GetIntervalSeconds())
+		arg.CronExpression = pgtype.Text{String: recurrence.CronExpression, Valid: true}
+		arg.IntervalSeconds = pgtype.Int8{Int64: req.GetIntervalSeconds(), Valid: true}
+		last = now
+	}
+	resumed := req.GetIsActive() && !scheduledTransfer.IsActive
+	if changed || (resumed && !last.After(now)) {
+		nextRunAt, err := recurrence.Next(last, now)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		arg.NextRunAt = pgtype.Timestamptz{Time: nextRunAt, Valid: true}
+	}
+
+	scheduledTransfer, err = server.store.UpdateScheduledTransfer(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update scheduled transfer: %s", err)
+	}
+
+	resp := &pb.UpdateScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(scheduledTransfer),
+	}
+	return resp, nil
+}
+
+func validateUpdateScheduledTransferRequest(req *pb.UpdateScheduledTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+	if req.Amount != nil {
+		if err := val.ValidateAmount(req.GetAmount()); err != nil {
+			violations = append(violations, fieldViolation("amount", err))
+		}
+	}
+	if req.CronExpression != nil || req.IntervalSeconds != nil {
+		if err := val.ValidateRecurrence(req.GetCronExpression(), req.GetIntervalSeconds()); err != nil {
+			violations = append(violations, fieldViolation(recurrenceField(req.CronExpression), err))
+		}
+	}
+	return violations
+}
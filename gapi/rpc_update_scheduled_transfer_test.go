@@ -0,0 +1,212 @@
+package gapi
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/the-eduardo/Go-Bank/db/mock"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/token"
+	"github.com/the-eduardo/Go-Bank/util"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"testing"
+	"time"
+)
+
+func TestUpdateScheduledTransferAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+
+	// a daily transfer that was paused three and a half days ago
+	lastRun := time.Now().UTC().Add(-84 * time.Hour).Truncate(time.Second)
+	paused := db.ScheduledTransfer{
+		ID:              util.RandomInt(1, 1000),
+		Owner:           user.Username,
+		FromAccountID:   1,
+		ToAccountID:     2,
+		Amount:          10,
+		IntervalSeconds: 86400,
+		NextRunAt:       pgtype.Timestamptz{Time: lastRun, Valid: true},
+		IsActive:        false,
+	}
+	active := paused
+	active.IsActive = true
+	active.NextRunAt = pgtype.Timestamptz{Time: time.Now().UTC().Add(time.Hour), Valid: true}
+
+	testCases := []struct {
+		name          string
+		req           *pb.UpdateScheduledTransferRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, res *pb.UpdateScheduledTransferResponse, err error)
+	}{
+		{
+			name: "ResumeSkipsMissedRuns",
+			req: &pb.UpdateScheduledTransferRequest{
+				Id:       paused.ID,
+				IsActive: proto.Bool(true),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(paused.ID)).Times(1).Return(paused, nil)
+				arg := db.UpdateScheduledTransferParams{
+					ID:        paused.ID,
+					IsActive:  pgtype.Bool{Bool: true, Valid: true},
+					NextRunAt: pgtype.Timestamptz{Time: lastRun.Add(96 * time.Hour), Valid: true},
+				}
+				updated := paused
+				updated.IsActive = true
+				updated.NextRunAt = arg.NextRunAt
+				store.EXPECT().UpdateScheduledTransfer(gomock.Any(), gomock.Eq(arg)).Times(1).Return(updated, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateScheduledTransferResponse, err error) {
+				require.NoError(t, err)
+				require.True(t, res.GetScheduledTransfer().GetIsActive())
+				require.Equal(t, lastRun.Add(96*time.Hour), res.GetScheduledTransfer().GetNextRunAt().AsTime())
+			},
+		},
+		{
+			name: "Pause",
+			req: &pb.UpdateScheduledTransferRequest{
+				Id:       active.ID,
+				IsActive: proto.Bool(false),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(active.ID)).Times(1).Return(active, nil)
+				arg := db.UpdateScheduledTransferParams{
+					ID:       active.ID,
+					IsActive: pgtype.Bool{Bool: false, Valid: true},
+				}
+				store.EXPECT().UpdateScheduledTransfer(gomock.Any(), gomock.Eq(arg)).Times(1).Return(paused, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateScheduledTransferResponse, err error) {
+				require.NoError(t, err)
+				require.False(t, res.GetScheduledTransfer().GetIsActive())
+			},
+		},
+		{
+			name: "ChangeRecurrence",
+			req: &pb.UpdateScheduledTransferRequest{
+				Id:             active.ID,
+				Amount:         proto.Int64(20),
+				CronExpression: proto.String("@monthly"),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(active.ID)).Times(1).Return(active, nil)
+				store.EXPECT().
+					UpdateScheduledTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.UpdateScheduledTransferParams) (db.ScheduledTransfer, error) {
+						require.Equal(t, pgtype.Int8{Int64: 20, Valid: true}, arg.Amount)
+						require.Equal(t, pgtype.Text{String: "@monthly", Valid: true}, arg.CronExpression)
+						require.Equal(t, pgtype.Int8{Int64: 0, Valid: true}, arg.IntervalSeconds)
+						require.True(t, arg.NextRunAt.Valid)
+						require.Equal(t, 1, arg.NextRunAt.Time.Day())
+						require.False(t, arg.IsActive.Valid)
+						updated := active
+						updated.Amount = arg.Amount.Int64
+						updated.CronExpression = arg.CronExpression.String
+						updated.IntervalSeconds = 0
+						updated.NextRunAt = arg.NextRunAt
+						return updated, nil
+					})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateScheduledTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(20), res.GetScheduledTransfer().GetAmount())
+				require.Equal(t, "@monthly", res.GetScheduledTransfer().GetCronExpression())
+				require.Zero(t, res.GetScheduledTransfer().GetIntervalSeconds())
+			},
+		},
+		{
+			name: "InvalidCronExpression",
+			req: &pb.UpdateScheduledTransferRequest{
+				Id:             active.ID,
+				CronExpression: proto.String("every day"),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateScheduledTransferResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "NotOwner",
+			req: &pb.UpdateScheduledTransferRequest{
+				Id:       active.ID,
+				IsActive: proto.Bool(false),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(active.ID)).Times(1).Return(active, nil)
+				store.EXPECT().UpdateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, other.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateScheduledTransferResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.PermissionDenied, st.Code())
+			},
+		},
+		{
+			name: "NotFound",
+			req: &pb.UpdateScheduledTransferRequest{
+				Id:       active.ID,
+				IsActive: proto.Bool(false),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(active.ID)).Times(1).Return(db.ScheduledTransfer{}, pgx.ErrNoRows)
+				store.EXPECT().UpdateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateScheduledTransferResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.NotFound, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			ctx := tc.buildContext(t, server.tokenMaker)
+			res, err := server.UpdateScheduledTransfer(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
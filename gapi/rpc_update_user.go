@@ -3,12 +3,14 @@ package gapi
 import (
 	"context"
 	"errors"
+	"github.com/hibiken/asynq"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	db "github.com/the-eduardo/Go-Bank/db/sqlc"
 	"github.com/the-eduardo/Go-Bank/pb"
 	"github.com/the-eduardo/Go-Bank/util"
 	"github.com/the-eduardo/Go-Bank/val"
+	"github.com/the-eduardo/Go-Bank/worker"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -16,7 +18,7 @@ import (
 )
 
 func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
-	authPayload, err := server.autorizeUser(ctx)
+	authPayload, err := server.autorizeUser(ctx, allRoles)
 	if err != nil {
 		return nil, unauthenticatedError(err)
 	}
@@ -24,7 +26,9 @@ func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 		return nil, invalidArgumentError(violations)
 	}
 	if authPayload.Username != req.GetUsername() {
-		return nil, status.Errorf(codes.PermissionDenied, "cannot update other users")
+		if err := server.authorizeUpdateOtherUser(ctx, authPayload.Role, req); err != nil {
+			return nil, err
+		}
 	}
 
 	arg := db.UpdateUserParams{
@@ -38,11 +42,41 @@ func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 		}
 	}
 
-	// Set Email if provided
+	txArg := db.UpdateUserTxParams{}
+	// A new email stays pending until the user verifies it, the current one is kept until then
 	if req.GetEmail() != "" {
-		arg.Email = pgtype.Text{
-			String: req.GetEmail(),
-			Valid:  true,
+		user, err := server.store.GetUser(ctx, req.GetUsername())
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, status.Errorf(codes.NotFound, "user not found")
+			}
+			return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+		}
+		if req.GetEmail() != user.Email {
+			owner, err := server.store.GetUserByEmail(ctx, req.GetEmail())
+			if err == nil && owner.Username != user.Username {
+				return nil, status.Errorf(codes.AlreadyExists, "email is already in use")
+			}
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return nil, status.Errorf(codes.Internal, "failed to get user by email: %s", err)
+			}
+			txArg.PendingEmail = pgtype.Text{
+				String: req.GetEmail(),
+				Valid:  true,
+			}
+			txArg.AfterUpdate = func(user db.User) error {
+				taskPayload := &worker.PayloadSendVerifyEmail{
+					Username: user.Username,
+					Email:    user.PendingEmail.String,
+				}
+				opts := []asynq.Option{
+					asynq.MaxRetry(7),
+					asynq.ProcessIn(3 * time.Second),
+					asynq.Timeout(15 * time.Second),
+					asynq.Queue(worker.QueueEmail),
+				}
+				return server.taskDistributor.DistributeTaskSendVerifyEmail(ctx, taskPayload, opts...)
+			}
 		}
 	}
 	// Set HashedPassword if password is provided and not empty
@@ -60,7 +94,9 @@ func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 			Valid: true,
 		}
 	}
-	user, err := server.store.UpdateUser(ctx, arg)
+	// UpdateUserTx also revokes every session of the user when the password changes
+	txArg.UpdateUserParams = arg
+	txResult, err := server.store.UpdateUserTx(ctx, txArg)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, status.Errorf(codes.NotFound, "user not found")
@@ -68,11 +104,33 @@ func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 		return nil, status.Errorf(codes.Internal, "failed to update user: %s", err)
 	}
 	resp := &pb.UpdateUserResponse{
-		User: convertUser(user),
+		User: convertUser(txResult.User),
 	}
 	return resp, nil
 }
 
+// authorizeUpdateOtherUser lets an admin change the name of a user with a lower role.
+// The password and email of someone else only change through the password reset, which the owner of the email completes.
+func (server *Server) authorizeUpdateOtherUser(ctx context.Context, role string, req *pb.UpdateUserRequest) error {
+	if role != util.AdminRole {
+		return status.Errorf(codes.PermissionDenied, "cannot update other users")
+	}
+	if req.GetPassword() != "" || req.GetEmail() != "" {
+		return status.Errorf(codes.PermissionDenied, "cannot change the password or email of other users")
+	}
+	user, err := server.store.GetUser(ctx, req.GetUsername())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return status.Errorf(codes.NotFound, "user not found")
+		}
+		return status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+	if !util.OutranksRole(role, user.Role) {
+		return status.Errorf(codes.PermissionDenied, "cannot update a user with an equal or higher role")
+	}
+	return nil
+}
+
 func validateUpdateUserRequest(req *pb.UpdateUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
 	if err := val.ValidateUsername(req.GetUsername()); err != nil {
 		violations = append(violations, fieldViolation("username", err))
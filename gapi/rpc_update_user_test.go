@@ -10,6 +10,8 @@ import (
 	"github.com/the-eduardo/Go-Bank/pb"
 	"github.com/the-eduardo/Go-Bank/token"
 	"github.com/the-eduardo/Go-Bank/util"
+	"github.com/the-eduardo/Go-Bank/worker"
+	mockwk "github.com/the-eduardo/Go-Bank/worker/mock"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -19,15 +21,18 @@ import (
 
 func TestUpdateUserAPI(t *testing.T) {
 	user, _ := randomUser(t)
+	admin, _ := randomUser(t)
+	admin.Role = util.AdminRole
 
 	newName := util.RandomOwner()
 	newEmail := util.RandomEmail()
 	invalidEmail := "invalid-email"
+	newPassword := util.RandomString(8)
 
 	testCases := []struct {
 		name          string
 		req           *pb.UpdateUserRequest
-		buildStubs    func(store *mockdb.MockStore)
+		buildStubs    func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor)
 		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
 		checkResponse func(t *testing.T, res *pb.UpdateUserResponse, err error)
 	}{
@@ -38,34 +43,52 @@ func TestUpdateUserAPI(t *testing.T) {
 				FullName: &newName,
 				Email:    &newEmail,
 			},
-			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.UpdateUserParams{
-					Username: user.Username,
-					FullName: pgtype.Text{
-						String: newName,
-						Valid:  true,
-					},
-					Email: pgtype.Text{
-						String: newEmail,
-						Valid:  true,
-					},
-				}
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(newEmail)).
+					Times(1).
+					Return(db.User{}, pgx.ErrNoRows)
 				updatedUser := db.User{
 					Username:          user.Username,
 					HashedPassword:    user.HashedPassword,
 					FullName:          newName,
-					Email:             newEmail,
+					Email:             user.Email,
 					PasswordChangedAt: user.PasswordChangedAt,
 					CreatedAt:         user.CreatedAt,
 					IsEmailVerified:   user.IsEmailVerified,
+					PendingEmail: pgtype.Text{
+						String: newEmail,
+						Valid:  true,
+					},
 				}
 				store.EXPECT().
-					UpdateUser(gomock.Any(), gomock.Eq(arg)).
+					UpdateUserTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(updatedUser, nil)
+					DoAndReturn(func(ctx context.Context, arg db.UpdateUserTxParams) (db.UpdateUserTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, newName, arg.FullName.String)
+						// The email itself only changes once the new address is verified
+						require.False(t, arg.Email.Valid)
+						require.Equal(t, newEmail, arg.PendingEmail.String)
+						require.NotNil(t, arg.AfterUpdate)
+						err := arg.AfterUpdate(updatedUser)
+						return db.UpdateUserTxResult{User: updatedUser}, err
+					})
+				taskPayload := &worker.PayloadSendVerifyEmail{
+					Username: user.Username,
+					Email:    newEmail,
+				}
+				taskDistributor.EXPECT().
+					DistributeTaskSendVerifyEmail(gomock.Any(), gomock.Eq(taskPayload), gomock.Any()).
+					Times(1).
+					Return(nil)
 			},
 			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
-				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
 			},
 			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
 				require.NoError(t, err)
@@ -73,7 +96,101 @@ func TestUpdateUserAPI(t *testing.T) {
 				updatedUser := res.GetUser()
 				require.Equal(t, user.Username, updatedUser.Username)
 				require.Equal(t, newName, updatedUser.FullName)
-				require.Equal(t, newEmail, updatedUser.Email)
+				require.Equal(t, user.Email, updatedUser.Email)
+				require.Equal(t, newEmail, updatedUser.PendingEmail)
+			},
+		},
+		{
+			name: "SameEmail",
+			req: &pb.UpdateUserRequest{
+				Username: user.Username,
+				Email:    &user.Email,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.UpdateUserTxParams) (db.UpdateUserTxResult, error) {
+						require.False(t, arg.PendingEmail.Valid)
+						require.Nil(t, arg.AfterUpdate)
+						return db.UpdateUserTxResult{User: user}, nil
+					})
+				taskDistributor.EXPECT().
+					DistributeTaskSendVerifyEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.Email, res.GetUser().GetEmail())
+				require.Empty(t, res.GetUser().GetPendingEmail())
+			},
+		},
+		{
+			name: "EmailInUse",
+			req: &pb.UpdateUserRequest{
+				Username: user.Username,
+				Email:    &newEmail,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				other, _ := randomUser(t)
+				other.Email = newEmail
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(newEmail)).
+					Times(1).
+					Return(other, nil)
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.AlreadyExists, st.Code())
+			},
+		},
+		{
+			name: "ChangePassword",
+			req: &pb.UpdateUserRequest{
+				Username: user.Username,
+				Password: &newPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.UpdateUserTxParams) (db.UpdateUserTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.True(t, arg.HashedPassword.Valid)
+						require.NoError(t, util.CheckPassword(arg.HashedPassword.String, newPassword))
+						require.True(t, arg.PasswordChangedAt.Valid)
+						return db.UpdateUserTxResult{User: user, RevokedSessions: 2}, nil
+					})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, res)
+				require.Equal(t, user.Username, res.GetUser().GetUsername())
 			},
 		},
 		{
@@ -83,19 +200,23 @@ func TestUpdateUserAPI(t *testing.T) {
 				FullName: &newName,
 				Email:    &newEmail,
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
 				store.EXPECT().
-					UpdateUser(gomock.Any(), gomock.Any()).
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
 					Return(db.User{}, pgx.ErrNoRows)
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
-				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
 			},
 			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
 				require.Error(t, err)
-				_, ok := status.FromError(err)
+				st, ok := status.FromError(err)
 				require.True(t, ok)
+				require.Equal(t, codes.NotFound, st.Code())
 			},
 		},
 		{
@@ -105,13 +226,13 @@ func TestUpdateUserAPI(t *testing.T) {
 				FullName: &newName,
 				Email:    &invalidEmail,
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
 				store.EXPECT().
-					UpdateUser(gomock.Any(), gomock.Any()).
+					UpdateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
-				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
 			},
 			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
 				require.Error(t, err)
@@ -120,6 +241,126 @@ func TestUpdateUserAPI(t *testing.T) {
 				require.Equal(t, codes.InvalidArgument, st.Code())
 			},
 		},
+		{
+			name: "AdminUpdatesName",
+			req: &pb.UpdateUserRequest{
+				Username: user.Username,
+				FullName: &newName,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.UpdateUserTxParams) (db.UpdateUserTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, newName, arg.FullName.String)
+						require.False(t, arg.HashedPassword.Valid)
+						require.False(t, arg.PendingEmail.Valid)
+						updatedUser := user
+						updatedUser.FullName = newName
+						return db.UpdateUserTxResult{User: updatedUser}, nil
+					})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, admin.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, newName, res.GetUser().FullName)
+			},
+		},
+		{
+			name: "BankerUpdatesAdmin",
+			req: &pb.UpdateUserRequest{
+				Username: admin.Username,
+				FullName: &newName,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "banker_user", util.BankerRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.PermissionDenied, st.Code())
+			},
+		},
+		{
+			name: "BankerChangesPassword",
+			req: &pb.UpdateUserRequest{
+				Username: user.Username,
+				Password: &newPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "banker_user", util.BankerRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.PermissionDenied, st.Code())
+			},
+		},
+		{
+			name: "AdminChangesPassword",
+			req: &pb.UpdateUserRequest{
+				Username: user.Username,
+				Password: &newPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, admin.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.PermissionDenied, st.Code())
+			},
+		},
+		{
+			name: "AdminUpdatesAdmin",
+			req: &pb.UpdateUserRequest{
+				Username: admin.Username,
+				FullName: &newName,
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(admin.Username)).
+					Times(1).
+					Return(admin, nil)
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "other_admin", util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.PermissionDenied, st.Code())
+			},
+		},
 		{
 			name: "ExpiredToken",
 			req: &pb.UpdateUserRequest{
@@ -127,13 +368,13 @@ func TestUpdateUserAPI(t *testing.T) {
 				FullName: &newName,
 				Email:    &newEmail,
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
 				store.EXPECT().
-					UpdateUser(gomock.Any(), gomock.Any()).
+					UpdateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
-				return newContextWithBearerToken(t, tokenMaker, user.Username, -time.Minute)
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, -time.Minute)
 			},
 			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
 				require.Error(t, err)
@@ -149,9 +390,9 @@ func TestUpdateUserAPI(t *testing.T) {
 				FullName: &newName,
 				Email:    &newEmail,
 			},
-			buildStubs: func(store *mockdb.MockStore) {
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
 				store.EXPECT().
-					UpdateUser(gomock.Any(), gomock.Any()).
+					UpdateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
@@ -174,8 +415,12 @@ func TestUpdateUserAPI(t *testing.T) {
 			defer storeCtrl.Finish()
 			store := mockdb.NewMockStore(storeCtrl)
 
-			tc.buildStubs(store)
-			server := newTestServer(t, store, nil)
+			taskCtrl := gomock.NewController(t)
+			defer taskCtrl.Finish()
+			taskDistributor := mockwk.NewMockTaskDistributor(taskCtrl)
+
+			tc.buildStubs(store, taskDistributor)
+			server := newTestServer(t, store, taskDistributor)
 
 			ctx := tc.buildContext(t, server.tokenMaker)
 			res, err := server.UpdateUser(ctx, tc.req)
@@ -2,13 +2,17 @@ package gapi
 
 import (
 	"context"
+	"errors"
+	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog/log"
 	db "github.com/the-eduardo/Go-Bank/db/sqlc"
 	"github.com/the-eduardo/Go-Bank/pb"
 	"github.com/the-eduardo/Go-Bank/util"
+	"github.com/the-eduardo/Go-Bank/worker"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"time"
 )
 
 func (server *Server) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
@@ -21,8 +25,28 @@ func (server *Server) VerifyEmail(ctx context.Context, req *pb.VerifyEmailReques
 		SecretCode: req.GetSecretCode(),
 	})
 	if err != nil {
+		if errors.Is(err, db.ErrEmailChanged) {
+			return nil, status.Errorf(codes.FailedPrecondition, "email address was changed, please verify the new one")
+		}
 		return nil, status.Errorf(codes.Internal, "failed to verify email")
 	}
+	if txResult.PreviousEmail != "" {
+		taskPayload := &worker.PayloadSendEmailChanged{
+			Username: txResult.User.Username,
+			OldEmail: txResult.PreviousEmail,
+			NewEmail: txResult.User.Email,
+		}
+		opts := []asynq.Option{
+			asynq.MaxRetry(7),
+			asynq.Timeout(15 * time.Second),
+			asynq.Queue(worker.QueueEmail),
+		}
+		// The email is already switched, a lost notification should not fail the verification
+		err = server.taskDistributor.DistributeTaskSendEmailChanged(ctx, taskPayload, opts...)
+		if err != nil {
+			log.Error().Err(err).Str("username", txResult.User.Username).Msg("failed to distribute email changed notification")
+		}
+	}
 	resp := &pb.VerifyEmailResponse{
 		IsVerified: txResult.User.IsEmailVerified,
 	}
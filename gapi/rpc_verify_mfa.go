@@ -0,0 +1,127 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/the-eduardo/Go-Bank/api"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/util"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxMFAAttempts is how many codes can be tried against one challenge before the login must restart
+const maxMFAAttempts = 5
+
+func (server *Server) VerifyMFA(ctx context.Context, req *pb.VerifyMFARequest) (*pb.VerifyMFAResponse, error) {
+	if violations := validateVerifyMFARequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	challengeID, err := api.ConvertGoogleUUIDToPGTypeUUID(uuid.MustParse(req.GetChallengeToken()))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid challenge token: %s", err)
+	}
+	challenge, err := server.store.AttemptMFAChallenge(ctx, db.AttemptMFAChallengeParams{
+		ID:          challengeID,
+		MaxAttempts: maxMFAAttempts,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.Unauthenticated, "mfa challenge is invalid, expired or used")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find mfa challenge")
+	}
+	// Wrong codes count as failed logins too, otherwise a new challenge every few codes would allow guessing them
+	mtdt := server.extractMetadata(ctx)
+	err = server.checkLoginLockout(ctx, challenge.Username, mtdt.ClientIP)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Code != nil {
+		factor, err := server.store.GetTOTPFactor(ctx, challenge.Username)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, status.Errorf(codes.Unauthenticated, "two-factor authentication is not enabled")
+			}
+			return nil, status.Errorf(codes.Internal, "failed to find totp factor")
+		}
+		err = server.useTOTPCode(ctx, factor, req.GetCode())
+		if err != nil {
+			if status.Code(err) == codes.Unauthenticated {
+				return nil, server.loginFailed(ctx, challenge.Username, mtdt.ClientIP, true, err)
+			}
+			return nil, err
+		}
+	} else {
+		_, err = server.store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+			Username:   challenge.Username,
+			HashedCode: util.HashRecoveryCode(req.GetRecoveryCode()),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				failure := status.Errorf(codes.Unauthenticated, "invalid recovery code")
+				return nil, server.loginFailed(ctx, challenge.Username, mtdt.ClientIP, true, failure)
+			}
+			return nil, status.Errorf(codes.Internal, "failed to use recovery code")
+		}
+	}
+
+	// A challenge only completes one login, a concurrent request with another code fails here
+	_, err = server.store.UseMFAChallenge(ctx, challengeID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.Unauthenticated, "mfa challenge was already used")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to use mfa challenge")
+	}
+
+	user, err := server.store.GetUser(ctx, challenge.Username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find user")
+	}
+	login, err := server.createLoginSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.VerifyMFAResponse{
+		User:                  login.GetUser(),
+		SessionId:             login.GetSessionId(),
+		AccessToken:           login.GetAccessToken(),
+		RefreshToken:          login.GetRefreshToken(),
+		AccessTokenExpiresAt:  login.GetAccessTokenExpiresAt(),
+		RefreshTokenExpiresAt: login.GetRefreshTokenExpiresAt(),
+	}
+	return resp, nil
+}
+
+func validateVerifyMFARequest(req *pb.VerifyMFARequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateUUID(req.GetChallengeToken()); err != nil {
+		violations = append(violations, fieldViolation("challenge_token", err))
+	}
+	switch {
+	case req.Code != nil && req.RecoveryCode != nil:
+		violations = append(violations, fieldViolation("recovery_code", errors.New("send either a code or a recovery code")))
+	case req.Code != nil:
+		if err := val.ValidateTOTPCode(req.GetCode()); err != nil {
+			violations = append(violations, fieldViolation("code", err))
+		}
+	case req.RecoveryCode != nil:
+		if err := val.ValidateRecoveryCode(req.GetRecoveryCode()); err != nil {
+			violations = append(violations, fieldViolation("recovery_code", err))
+		}
+	default:
+		violations = append(violations, fieldViolation("code", errors.New("a code or a recovery code is required")))
+	}
+	return violations
+}
@@ -0,0 +1,343 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/api"
+	mockdb "github.com/the-eduardo/Go-Bank/db/mock"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/util"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func randomTOTPFactor(t *testing.T, user db.User) db.TotpFactor {
+	secret, _, err := util.NewTOTPKey("GoBank", user.Email)
+	require.NoError(t, err)
+	return db.TotpFactor{
+		Username:    user.Username,
+		Secret:      secret,
+		IsConfirmed: true,
+	}
+}
+
+func TestVerifyMFAAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	factor := randomTOTPFactor(t, user)
+	code, err := totp.GenerateCode(factor.Secret, time.Now())
+	require.NoError(t, err)
+	recoveryCode := "abcde-fghij"
+	challengeToken := uuid.NewString()
+	challenge := db.MfaChallenge{
+		Username:  user.Username,
+		Attempts:  1,
+		ExpiresAt: api.TimeToPGTimestamptz(time.Now().Add(time.Minute)),
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.VerifyMFARequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.VerifyMFAResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.VerifyMFARequest{
+				ChallengeToken: challengeToken,
+				Code:           &code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AttemptMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.AttemptMFAChallengeParams) (db.MfaChallenge, error) {
+						require.Equal(t, challengeToken, convertUUID(arg.ID))
+						require.Equal(t, int32(maxMFAAttempts), arg.MaxAttempts)
+						return challenge, nil
+					})
+				store.EXPECT().
+					GetLoginLockout(gomock.Any(), gomock.Eq(db.GetLoginLockoutParams{Username: user.Username})).
+					Times(1).
+					Return(pgtype.Timestamptz{}, nil)
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(factor, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					UseMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ResetLoginAttempts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyMFAResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.Username, res.GetUser().GetUsername())
+				require.NotEmpty(t, res.GetAccessToken())
+				require.NotEmpty(t, res.GetRefreshToken())
+			},
+		},
+		{
+			name: "RecoveryCode",
+			req: &pb.VerifyMFARequest{
+				ChallengeToken: challengeToken,
+				RecoveryCode:   &recoveryCode,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AttemptMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					GetLoginLockout(gomock.Any(), gomock.Eq(db.GetLoginLockoutParams{Username: user.Username})).
+					Times(1).
+					Return(pgtype.Timestamptz{}, nil)
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Any()).
+					Times(0)
+				arg := db.UseRecoveryCodeParams{
+					Username:   user.Username,
+					HashedCode: util.HashRecoveryCode(recoveryCode),
+				}
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.RecoveryCode{}, nil)
+				store.EXPECT().
+					UseMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ResetLoginAttempts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyMFAResponse, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, res.GetAccessToken())
+			},
+		},
+		{
+			name: "ReplayedCode",
+			req: &pb.VerifyMFARequest{
+				ChallengeToken: challengeToken,
+				Code:           &code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AttemptMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					GetLoginLockout(gomock.Any(), gomock.Eq(db.GetLoginLockoutParams{Username: user.Username})).
+					Times(1).
+					Return(pgtype.Timestamptz{}, nil)
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(factor, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					LoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginFailureTxResult{}, nil)
+				store.EXPECT().
+					UseMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyMFAResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "UsedRecoveryCode",
+			req: &pb.VerifyMFARequest{
+				ChallengeToken: challengeToken,
+				RecoveryCode:   &recoveryCode,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AttemptMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					GetLoginLockout(gomock.Any(), gomock.Eq(db.GetLoginLockoutParams{Username: user.Username})).
+					Times(1).
+					Return(pgtype.Timestamptz{}, nil)
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RecoveryCode{}, pgx.ErrNoRows)
+				store.EXPECT().
+					LoginFailureTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginFailureTxResult{}, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyMFAResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "LockedOut",
+			req: &pb.VerifyMFARequest{
+				ChallengeToken: challengeToken,
+				Code:           &code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AttemptMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					GetLoginLockout(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(pgtype.Timestamptz{Time: time.Now().Add(time.Minute), Valid: true}, nil)
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyMFAResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.ResourceExhausted, st.Code())
+			},
+		},
+		{
+			name: "ChallengeNotAvailable",
+			req: &pb.VerifyMFARequest{
+				ChallengeToken: challengeToken,
+				Code:           &code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AttemptMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MfaChallenge{}, pgx.ErrNoRows)
+				store.EXPECT().
+					GetTOTPFactor(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyMFAResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+		{
+			name: "MissingCode",
+			req: &pb.VerifyMFARequest{
+				ChallengeToken: challengeToken,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AttemptMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyMFAResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "InvalidChallengeToken",
+			req: &pb.VerifyMFARequest{
+				ChallengeToken: "invalid",
+				Code:           &code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AttemptMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyMFAResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "InternalError",
+			req: &pb.VerifyMFARequest{
+				ChallengeToken: challengeToken,
+				Code:           &code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AttemptMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MfaChallenge{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyMFAResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Internal, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			res, err := server.VerifyMFA(context.Background(), tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) VoidTransfer(ctx context.Context, req *pb.VoidTransferRequest) (*pb.VoidTransferResponse, error) {
+	authPayload, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+	if violations := validateVoidTransferRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	// the caller must own one side of the transfer
+	transfer, err := server.authorizeTransfer(ctx, authPayload, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := server.store.VoidTransferTx(ctx, db.VoidTransferTxParams{
+		TransferID: transfer.ID,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrTransferNotPending) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to void transfer: %s", err)
+	}
+
+	resp := &pb.VoidTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+	}
+	return resp, nil
+}
+
+func validateVoidTransferRequest(req *pb.VoidTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+	return violations
+}
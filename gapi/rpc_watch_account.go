@@ -0,0 +1,150 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// accountEventSnapshot is the kind of the event that starts a stream, on top of the kinds notified by the store
+const accountEventSnapshot = "snapshot"
+
+// replayPageSize is how many entries are read per query when a stream resumes
+const replayPageSize = 100
+
+// accountEventStream is where the account events are sent, the gRPC stream or the server-sent events of the gateway
+type accountEventStream interface {
+	Context() context.Context
+	Send(*pb.AccountEvent) error
+}
+
+func (server *Server) WatchAccount(req *pb.WatchAccountRequest, stream pb.GoBank_WatchAccountServer) error {
+	return server.watchAccount(req, stream)
+}
+
+// watchAccount sends the entries posted since req.LastEventId, then a snapshot of the account, then the live events until the client leaves.
+// Every event carries the id of the last entry sent, so the client resumes from any of them without missing an entry.
+func (server *Server) watchAccount(req *pb.WatchAccountRequest, stream accountEventStream) error {
+	ctx := stream.Context()
+	authPayload, err := server.autorizeUser(ctx, allRoles)
+	if err != nil {
+		return unauthenticatedError(err)
+	}
+	if violations := validateWatchAccountRequest(req); violations != nil {
+		return invalidArgumentError(violations)
+	}
+
+	_, err = server.authorizeAccount(ctx, authPayload, req.GetAccountId())
+	if err != nil {
+		return err
+	}
+
+	// subscribe before reading the store, so no event is missed between the snapshot and the live events
+	subscription := server.hub.Subscribe(req.GetAccountId())
+	defer subscription.Close()
+
+	// the snapshot is read first, the replay stops at its last entry and the live events start after it
+	snapshot, err := server.store.GetAccountSnapshot(ctx, req.GetAccountId())
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+	if req.GetLastEventId() > 0 {
+		err = server.replayEntries(ctx, stream, req.GetAccountId(), req.GetLastEventId(), snapshot.LastEntryID)
+		if err != nil {
+			return err
+		}
+	}
+
+	lastEntryID := snapshot.LastEntryID
+	balanceVersion := snapshot.BalanceVersion
+	err = stream.Send(&pb.AccountEvent{
+		EventId:          lastEntryID,
+		Kind:             accountEventSnapshot,
+		AccountId:        snapshot.ID,
+		Balance:          snapshot.Balance,
+		AvailableBalance: snapshot.AvailableBalance,
+		BalanceVersion:   balanceVersion,
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return status.Errorf(codes.Unavailable, "stream fell behind the account events, resume from the last event")
+			}
+			// an entry or a hold committed before the snapshot is already in its balances
+			if event.BalanceVersion <= balanceVersion {
+				continue
+			}
+			balanceVersion = event.BalanceVersion
+			if event.Entry != nil {
+				lastEntryID = event.Entry.ID
+			}
+			if err = stream.Send(convertAccountEvent(event, lastEntryID)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// replayEntries sends the entries posted after afterID, up to untilID included
+func (server *Server) replayEntries(ctx context.Context, stream accountEventStream, accountID int64, afterID int64, untilID int64) error {
+	for afterID < untilID {
+		entries, err := server.store.ListEntriesAfter(ctx, db.ListEntriesAfterParams{
+			AccountID: accountID,
+			AfterID:   afterID,
+			Limit:     replayPageSize,
+		})
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to list entries: %s", err)
+		}
+		for _, entry := range entries {
+			// the entries after the snapshot are sent as live events
+			if entry.ID > untilID {
+				return nil
+			}
+			err = stream.Send(&pb.AccountEvent{
+				EventId:   entry.ID,
+				Kind:      db.AccountEventEntry,
+				AccountId: accountID,
+				Balance:   entry.Balance,
+				Entry: convertEntry(db.Entry{
+					ID:        entry.ID,
+					AccountID: entry.AccountID,
+					Amount:    entry.Amount,
+					CreatedAt: entry.CreatedAt,
+					JournalID: entry.JournalID,
+				}),
+				Replayed: true,
+			})
+			if err != nil {
+				return err
+			}
+			afterID = entry.ID
+		}
+		if len(entries) < replayPageSize {
+			return nil
+		}
+	}
+	return nil
+}
+
+func validateWatchAccountRequest(req *pb.WatchAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	if req.GetLastEventId() < 0 {
+		violations = append(violations, fieldViolation("last_event_id", fmt.Errorf("must not be negative")))
+	}
+	return violations
+}
@@ -0,0 +1,520 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/the-eduardo/Go-Bank/db/mock"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/events"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/token"
+	"github.com/the-eduardo/Go-Bank/util"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+// fakeEventStream records the events sent and ends the stream once it has want of them
+type fakeEventStream struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	want   int
+	sent   []*pb.AccountEvent
+}
+
+func (stream *fakeEventStream) Context() context.Context {
+	return stream.ctx
+}
+
+func (stream *fakeEventStream) Send(event *pb.AccountEvent) error {
+	stream.sent = append(stream.sent, event)
+	if len(stream.sent) == stream.want {
+		stream.cancel()
+	}
+	return nil
+}
+
+func randomEntryAfter(accountID int64, id int64, balance int64) db.ListEntriesAfterRow {
+	return db.ListEntriesAfterRow{
+		ID:        id,
+		AccountID: accountID,
+		Amount:    util.RandomMoney(),
+		Balance:   balance,
+	}
+}
+
+// accountSnapshot reads the account after its entry lastEntryID, the tests use the id of an entry as the version of its balances
+func accountSnapshot(account db.Account, lastEntryID int64) db.GetAccountSnapshotRow {
+	return db.GetAccountSnapshotRow{
+		ID:               account.ID,
+		Balance:          account.Balance,
+		AvailableBalance: account.AvailableBalance,
+		BalanceVersion:   lastEntryID,
+		LastEntryID:      lastEntryID,
+	}
+}
+
+func TestWatchAccountAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.AvailableBalance = account.Balance
+
+	entryEvent := func(id int64) db.AccountEvent {
+		return db.AccountEvent{
+			Kind:             db.AccountEventEntry,
+			AccountID:        account.ID,
+			Balance:          account.Balance,
+			AvailableBalance: account.AvailableBalance,
+			BalanceVersion:   id,
+			Entry:            &db.Entry{ID: id, AccountID: account.ID, Amount: 10},
+		}
+	}
+	holdEvent := func(balanceVersion int64) db.AccountEvent {
+		return db.AccountEvent{
+			Kind:             db.AccountEventHold,
+			AccountID:        account.ID,
+			Balance:          account.Balance,
+			AvailableBalance: account.AvailableBalance - 10,
+			BalanceVersion:   balanceVersion,
+		}
+	}
+
+	// buildSnapshot reads the account to authorize, then the snapshot ending at lastEntryID, and publishes the events once subscribed
+	buildSnapshot := func(store *mockdb.MockStore, lastEntryID int64, publish func()) {
+		gomock.InOrder(
+			store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil),
+			store.EXPECT().
+				GetAccountSnapshot(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				DoAndReturn(func(_ context.Context, _ int64) (db.GetAccountSnapshotRow, error) {
+					publish()
+					return accountSnapshot(account, lastEntryID), nil
+				}),
+		)
+	}
+
+	testCases := []struct {
+		name         string
+		req          *pb.WatchAccountRequest
+		want         int
+		buildStubs   func(store *mockdb.MockStore, hub *events.Hub)
+		buildContext func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResult  func(t *testing.T, sent []*pb.AccountEvent, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.WatchAccountRequest{AccountId: account.ID},
+			want: 3,
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				buildSnapshot(store, 4, func() {
+					hub.Publish(holdEvent(5))
+					hub.Publish(db.AccountEvent{Kind: db.AccountEventEntry, AccountID: account.ID + 1, Entry: &db.Entry{ID: 9}})
+					hub.Publish(entryEvent(10))
+				})
+				store.EXPECT().
+					ListEntriesAfter(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResult: func(t *testing.T, sent []*pb.AccountEvent, err error) {
+				require.NoError(t, err)
+				require.Len(t, sent, 3)
+
+				// the snapshot carries the last entry of the account even when the client does not resume
+				require.Equal(t, accountEventSnapshot, sent[0].GetKind())
+				require.Equal(t, int64(4), sent[0].GetEventId())
+				require.Equal(t, account.Balance, sent[0].GetBalance())
+				require.Equal(t, account.AvailableBalance, sent[0].GetAvailableBalance())
+
+				require.Equal(t, db.AccountEventHold, sent[1].GetKind())
+				require.Equal(t, int64(4), sent[1].GetEventId())
+				require.Equal(t, account.AvailableBalance-10, sent[1].GetAvailableBalance())
+				require.Equal(t, int64(5), sent[1].GetBalanceVersion())
+				require.Nil(t, sent[1].GetEntry())
+
+				require.Equal(t, db.AccountEventEntry, sent[2].GetKind())
+				require.Equal(t, int64(10), sent[2].GetEventId())
+				require.Equal(t, int64(10), sent[2].GetEntry().GetId())
+				require.False(t, sent[2].GetReplayed())
+			},
+		},
+		{
+			name: "HoldBeforeSnapshot",
+			req:  &pb.WatchAccountRequest{AccountId: account.ID},
+			want: 2,
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				buildSnapshot(store, 4, func() {
+					// the hold was committed before the snapshot, its available balance is older
+					hub.Publish(holdEvent(4))
+					hub.Publish(entryEvent(5))
+				})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResult: func(t *testing.T, sent []*pb.AccountEvent, err error) {
+				require.NoError(t, err)
+				require.Len(t, sent, 2)
+				require.Equal(t, accountEventSnapshot, sent[0].GetKind())
+				require.Equal(t, int64(4), sent[0].GetBalanceVersion())
+				require.Equal(t, db.AccountEventEntry, sent[1].GetKind())
+				require.Equal(t, int64(5), sent[1].GetBalanceVersion())
+			},
+		},
+		{
+			name: "Resume",
+			req:  &pb.WatchAccountRequest{AccountId: account.ID, LastEventId: 5},
+			want: 4,
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				buildSnapshot(store, 7, func() {
+					// entry 7 was replayed already
+					hub.Publish(entryEvent(7))
+					hub.Publish(entryEvent(8))
+				})
+				store.EXPECT().
+					ListEntriesAfter(gomock.Any(), gomock.Eq(db.ListEntriesAfterParams{
+						AccountID: account.ID,
+						AfterID:   5,
+						Limit:     replayPageSize,
+					})).
+					Times(1).
+					Return([]db.ListEntriesAfterRow{
+						randomEntryAfter(account.ID, 6, 100),
+						randomEntryAfter(account.ID, 7, 200),
+					}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResult: func(t *testing.T, sent []*pb.AccountEvent, err error) {
+				require.NoError(t, err)
+				require.Len(t, sent, 4)
+
+				require.Equal(t, int64(6), sent[0].GetEventId())
+				require.Equal(t, int64(100), sent[0].GetBalance())
+				require.True(t, sent[0].GetReplayed())
+				require.Equal(t, int64(7), sent[1].GetEventId())
+				require.Equal(t, int64(200), sent[1].GetBalance())
+
+				require.Equal(t, accountEventSnapshot, sent[2].GetKind())
+				require.Equal(t, int64(7), sent[2].GetEventId())
+
+				require.Equal(t, int64(8), sent[3].GetEventId())
+				require.False(t, sent[3].GetReplayed())
+			},
+		},
+		{
+			name: "ResumeManyPages",
+			req:  &pb.WatchAccountRequest{AccountId: account.ID, LastEventId: 1},
+			want: replayPageSize + 2,
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				buildSnapshot(store, replayPageSize+2, func() {})
+				page := make([]db.ListEntriesAfterRow, replayPageSize)
+				for i := range page {
+					page[i] = randomEntryAfter(account.ID, int64(i+2), account.Balance)
+				}
+				last := page[replayPageSize-1].ID
+				gomock.InOrder(
+					store.EXPECT().
+						ListEntriesAfter(gomock.Any(), gomock.Eq(db.ListEntriesAfterParams{AccountID: account.ID, AfterID: 1, Limit: replayPageSize})).
+						Times(1).
+						Return(page, nil),
+					store.EXPECT().
+						ListEntriesAfter(gomock.Any(), gomock.Eq(db.ListEntriesAfterParams{AccountID: account.ID, AfterID: last, Limit: replayPageSize})).
+						Times(1).
+						Return([]db.ListEntriesAfterRow{randomEntryAfter(account.ID, last+1, account.Balance)}, nil),
+				)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResult: func(t *testing.T, sent []*pb.AccountEvent, err error) {
+				require.NoError(t, err)
+				require.Len(t, sent, replayPageSize+2)
+				snapshot := sent[replayPageSize+1]
+				require.Equal(t, accountEventSnapshot, snapshot.GetKind())
+				require.Equal(t, int64(replayPageSize+2), snapshot.GetEventId())
+			},
+		},
+		{
+			name: "ResumeStopsAtSnapshot",
+			req:  &pb.WatchAccountRequest{AccountId: account.ID, LastEventId: 5},
+			want: 3,
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				buildSnapshot(store, 6, func() {
+					hub.Publish(entryEvent(7))
+				})
+				// entry 7 was committed after the snapshot, it is sent as a live event
+				store.EXPECT().
+					ListEntriesAfter(gomock.Any(), gomock.Eq(db.ListEntriesAfterParams{AccountID: account.ID, AfterID: 5, Limit: replayPageSize})).
+					Times(1).
+					Return([]db.ListEntriesAfterRow{
+						randomEntryAfter(account.ID, 6, 100),
+						randomEntryAfter(account.ID, 7, 200),
+					}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResult: func(t *testing.T, sent []*pb.AccountEvent, err error) {
+				require.NoError(t, err)
+				require.Len(t, sent, 3)
+				require.Equal(t, int64(6), sent[0].GetEventId())
+				require.True(t, sent[0].GetReplayed())
+				require.Equal(t, accountEventSnapshot, sent[1].GetKind())
+				require.Equal(t, int64(6), sent[1].GetEventId())
+				require.Equal(t, int64(7), sent[2].GetEventId())
+				require.False(t, sent[2].GetReplayed())
+			},
+		},
+		{
+			name: "ResumeUpToDate",
+			req:  &pb.WatchAccountRequest{AccountId: account.ID, LastEventId: 5},
+			want: 1,
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				buildSnapshot(store, 5, func() {})
+				store.EXPECT().
+					ListEntriesAfter(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResult: func(t *testing.T, sent []*pb.AccountEvent, err error) {
+				require.NoError(t, err)
+				require.Len(t, sent, 1)
+				require.Equal(t, accountEventSnapshot, sent[0].GetKind())
+				require.Equal(t, int64(5), sent[0].GetEventId())
+			},
+		},
+		{
+			name: "Dropped",
+			req:  &pb.WatchAccountRequest{AccountId: account.ID},
+			want: 2,
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				buildSnapshot(store, 0, hub.DropAll)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResult: func(t *testing.T, sent []*pb.AccountEvent, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unavailable, st.Code())
+				require.Len(t, sent, 1)
+			},
+		},
+		{
+			name: "BankerAccessOtherAccount",
+			req:  &pb.WatchAccountRequest{AccountId: account.ID},
+			want: 1,
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				buildSnapshot(store, 0, func() {})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "banker_user", util.BankerRole, time.Minute)
+			},
+			checkResult: func(t *testing.T, sent []*pb.AccountEvent, err error) {
+				require.NoError(t, err)
+				require.Len(t, sent, 1)
+			},
+		},
+		{
+			name: "PermissionDenied",
+			req:  &pb.WatchAccountRequest{AccountId: account.ID},
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, "unauthorized_user", util.DepositorRole, time.Minute)
+			},
+			checkResult: func(t *testing.T, sent []*pb.AccountEvent, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.PermissionDenied, st.Code())
+				require.Empty(t, sent)
+			},
+		},
+		{
+			name: "NotFound",
+			req:  &pb.WatchAccountRequest{AccountId: account.ID},
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, pgx.ErrNoRows)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResult: func(t *testing.T, sent []*pb.AccountEvent, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.NotFound, st.Code())
+			},
+		},
+		{
+			name: "ReplayInternalError",
+			req:  &pb.WatchAccountRequest{AccountId: account.ID, LastEventId: 5},
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				buildSnapshot(store, 6, func() {})
+				store.EXPECT().
+					ListEntriesAfter(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListEntriesAfterRow{}, sql.ErrConnDone)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResult: func(t *testing.T, sent []*pb.AccountEvent, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Internal, st.Code())
+				require.Empty(t, sent)
+			},
+		},
+		{
+			name: "InvalidAccountID",
+			req:  &pb.WatchAccountRequest{AccountId: 0},
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResult: func(t *testing.T, sent []*pb.AccountEvent, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "NegativeLastEventID",
+			req:  &pb.WatchAccountRequest{AccountId: account.ID, LastEventId: -1},
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResult: func(t *testing.T, sent []*pb.AccountEvent, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "NoAuthorization",
+			req:  &pb.WatchAccountRequest{AccountId: account.ID},
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResult: func(t *testing.T, sent []*pb.AccountEvent, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			server := newTestServer(t, store, nil)
+			tc.buildStubs(store, server.hub)
+
+			// the timeout ends a stream that never gets the events it waits for
+			ctx, cancel := context.WithTimeout(tc.buildContext(t, server.tokenMaker), 5*time.Second)
+			defer cancel()
+			stream := &fakeEventStream{ctx: ctx, cancel: cancel, want: tc.want}
+			err := server.watchAccount(tc.req, stream)
+			tc.checkResult(t, stream.sent, err)
+		})
+	}
+}
+
+// TestWatchAccountReconnect connects without resuming, misses an entry while disconnected,
+// then reconnects from the id of the snapshot and gets the missed entry replayed
+func TestWatchAccountReconnect(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store, nil)
+
+	watch := func(lastEventID int64, want int) []*pb.AccountEvent {
+		ctx, cancel := context.WithTimeout(
+			newContextWithBearerToken(t, server.tokenMaker, user.Username, util.DepositorRole, time.Minute),
+			5*time.Second,
+		)
+		defer cancel()
+		stream := &fakeEventStream{ctx: ctx, cancel: cancel, want: want}
+		err := server.watchAccount(&pb.WatchAccountRequest{AccountId: account.ID, LastEventId: lastEventID}, stream)
+		require.NoError(t, err)
+		require.Len(t, stream.sent, want)
+		return stream.sent
+	}
+
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(2).Return(account, nil)
+	gomock.InOrder(
+		store.EXPECT().
+			GetAccountSnapshot(gomock.Any(), gomock.Eq(account.ID)).
+			Times(1).
+			Return(accountSnapshot(account, 6), nil),
+		// entry 7 is posted while the client is away
+		store.EXPECT().
+			GetAccountSnapshot(gomock.Any(), gomock.Eq(account.ID)).
+			Times(1).
+			Return(accountSnapshot(account, 7), nil),
+		store.EXPECT().
+			ListEntriesAfter(gomock.Any(), gomock.Eq(db.ListEntriesAfterParams{AccountID: account.ID, AfterID: 6, Limit: replayPageSize})).
+			Times(1).
+			Return([]db.ListEntriesAfterRow{randomEntryAfter(account.ID, 7, account.Balance)}, nil),
+	)
+
+	sent := watch(0, 1)
+	require.Equal(t, accountEventSnapshot, sent[0].GetKind())
+	require.Equal(t, int64(6), sent[0].GetEventId())
+
+	sent = watch(sent[0].GetEventId(), 2)
+	require.Equal(t, int64(7), sent[0].GetEventId())
+	require.Equal(t, int64(7), sent[0].GetEntry().GetId())
+	require.True(t, sent[0].GetReplayed())
+	require.Equal(t, accountEventSnapshot, sent[1].GetKind())
+	require.Equal(t, int64(7), sent[1].GetEventId())
+}
@@ -3,7 +3,11 @@ package gapi
 import (
 	"fmt"
 	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/events"
+	"github.com/the-eduardo/Go-Bank/fees"
+	"github.com/the-eduardo/Go-Bank/fx"
 	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/policy"
 	"github.com/the-eduardo/Go-Bank/token"
 	"github.com/the-eduardo/Go-Bank/util"
 	"github.com/the-eduardo/Go-Bank/worker"
@@ -16,20 +20,42 @@ type Server struct {
 	store           db.Store
 	tokenMaker      token.Maker
 	taskDistributor worker.TaskDistributor
+	rateProvider    fx.RateProvider
+	feeSchedule     *fees.Schedule
+	policy          policy.Policy
+	hub             *events.Hub
 }
 
-// NewServer creates a new gRPC server
-func NewServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor) (*Server, error) {
-	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+// NewServer creates a new gRPC server, the account events of the hub are streamed by WatchAccount
+func NewServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor, hub *events.Hub) (*Server, error) {
+	tokenMaker, err := token.NewMaker(token.MakerConfig{
+		Kind:           config.TokenMaker,
+		SymmetricKey:   config.TokenSymmetricKey,
+		PrivateKeyFile: config.TokenPrivateKeyFile,
+		KeyID:          config.TokenKeyID,
+		KeyringFile:    config.TokenKeyringFile,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
+	rateProvider, err := fx.NewRateProvider(config.FXRateProvider, config.FXRatesSource)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create rate provider: %w", err)
+	}
+	feeSchedule, err := fees.LoadSchedule(config.FeeScheduleFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load fee schedule: %w", err)
+	}
 
 	server := &Server{
 		config:          config,
 		store:           store,
 		tokenMaker:      tokenMaker,
 		taskDistributor: taskDistributor,
+		rateProvider:    rateProvider,
+		feeSchedule:     feeSchedule,
+		policy:          policy.New(config),
+		hub:             hub,
 	}
 	return server, nil
 
@@ -0,0 +1,166 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// WatchAccountPath is where the gateway serves WatchAccount as server-sent events
+const WatchAccountPath = "/v1/watch_account"
+
+// lastEventIDHeader is sent by EventSource when it reconnects, with the id of the last event it received
+const lastEventIDHeader = "Last-Event-ID"
+
+// sseKeepAliveInterval is how often a comment is written on an idle stream, so proxies do not close it
+const sseKeepAliveInterval = 15 * time.Second
+
+// sseMarshaler writes the events like the gateway writes its responses
+var sseMarshaler = protojson.MarshalOptions{UseProtoNames: true}
+
+// WatchAccountHandler serves WatchAccount as server-sent events, for browsers and clients without gRPC.
+// The account is the account_id query parameter and the stream resumes from the Last-Event-ID header,
+// or from the last_event_id query parameter. The errors before the first event are answered like the gateway does,
+// the ones after it are sent as an error event before the stream ends.
+func (server *Server) WatchAccountHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		req, err := parseWatchAccountRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		md := metadata.MD{}
+		if authHeader := r.Header.Get("Authorization"); authHeader != "" {
+			md.Set(authorizationHeader, authHeader)
+		}
+		ctx, cancel := context.WithCancel(metadata.NewIncomingContext(r.Context(), md))
+		stream := &sseStream{
+			ctx:        ctx,
+			w:          w,
+			controller: http.NewResponseController(w),
+		}
+		keepAliveDone := make(chan struct{})
+		go func() {
+			defer close(keepAliveDone)
+			stream.keepAlive()
+		}()
+		defer func() {
+			cancel()
+			<-keepAliveDone
+		}()
+
+		err = server.watchAccount(req, stream)
+		if err != nil {
+			stream.fail(status.Convert(err))
+		}
+	})
+}
+
+func parseWatchAccountRequest(r *http.Request) (*pb.WatchAccountRequest, error) {
+	req := &pb.WatchAccountRequest{}
+	var err error
+	if value := r.URL.Query().Get("account_id"); value != "" {
+		req.AccountId, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid account_id: %w", err)
+		}
+	}
+	value := r.Header.Get(lastEventIDHeader)
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value != "" {
+		req.LastEventId, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid last event id: %w", err)
+		}
+	}
+	return req, nil
+}
+
+// sseStream writes the account events as server-sent events, the response starts with the first event
+type sseStream struct {
+	ctx        context.Context
+	w          http.ResponseWriter
+	controller *http.ResponseController
+	mu         sync.Mutex
+	started    bool
+}
+
+func (stream *sseStream) Context() context.Context {
+	return stream.ctx
+}
+
+func (stream *sseStream) Send(event *pb.AccountEvent) error {
+	data, err := sseMarshaler.Marshal(event)
+	if err != nil {
+		return err
+	}
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	if !stream.started {
+		header := stream.w.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("X-Accel-Buffering", "no")
+		stream.w.WriteHeader(http.StatusOK)
+		stream.started = true
+	}
+	return stream.write(fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", event.GetEventId(), event.GetKind(), data))
+}
+
+// keepAlive writes a comment on the started stream every sseKeepAliveInterval until the context is done
+func (stream *sseStream) keepAlive() {
+	ticker := time.NewTicker(sseKeepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stream.ctx.Done():
+			return
+		case <-ticker.C:
+			stream.mu.Lock()
+			if stream.started {
+				_ = stream.write(": keep-alive\n\n")
+			}
+			stream.mu.Unlock()
+		}
+	}
+}
+
+// fail answers with the error status, or ends a started stream with an error event
+func (stream *sseStream) fail(st *status.Status) {
+	data, err := sseMarshaler.Marshal(st.Proto())
+	if err != nil {
+		data = []byte(`{"message":"failed to marshal error"}`)
+	}
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	if !stream.started {
+		stream.w.Header().Set("Content-Type", "application/json")
+		stream.w.WriteHeader(runtime.HTTPStatusFromCode(st.Code()))
+		_, _ = stream.w.Write(data)
+		return
+	}
+	_ = stream.write(fmt.Sprintf("event: error\ndata: %s\n\n", data))
+}
+
+// write sends a piece of the stream right away, the caller holds the lock
+func (stream *sseStream) write(text string) error {
+	if _, err := stream.w.Write([]byte(text)); err != nil {
+		return err
+	}
+	return stream.controller.Flush()
+}
@@ -0,0 +1,248 @@
+package gapi
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/the-eduardo/Go-Bank/db/mock"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/events"
+	"github.com/the-eduardo/Go-Bank/pb"
+	"github.com/the-eduardo/Go-Bank/token"
+	"github.com/the-eduardo/Go-Bank/util"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serverSentEvent is an event read back from the stream
+type serverSentEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// readServerSentEvents reads n events, or all of them until the stream ends when n is negative
+func readServerSentEvents(t *testing.T, body io.Reader, n int) []serverSentEvent {
+	var sent []serverSentEvent
+	var current serverSentEvent
+	scanner := bufio.NewScanner(body)
+	for n < 0 || len(sent) < n {
+		if !scanner.Scan() {
+			require.NoError(t, scanner.Err())
+			require.Negative(t, n, "the stream ended after %d events", len(sent))
+			return sent
+		}
+		line := scanner.Text()
+		switch {
+		case line == "":
+			sent = append(sent, current)
+			current = serverSentEvent{}
+		case strings.HasPrefix(line, ":"):
+		case strings.HasPrefix(line, "id: "):
+			current.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			current.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	return sent
+}
+
+func TestWatchAccountHandler(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	authorized := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		accessToken, _, err := tokenMaker.CreateToken(user.Username, util.DepositorRole, uuid.Nil, time.Minute, token.TokenTypeAccessToken)
+		require.NoError(t, err)
+		request.Header.Set("Authorization", fmt.Sprintf("%s %s", authorizationBearer, accessToken))
+	}
+
+	testCases := []struct {
+		name          string
+		method        string
+		query         string
+		lastEventID   string
+		buildStubs    func(store *mockdb.MockStore, hub *events.Hub)
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		checkResponse func(t *testing.T, res *http.Response)
+	}{
+		{
+			name:        "OK",
+			method:      http.MethodGet,
+			query:       fmt.Sprintf("account_id=%d&last_event_id=1", account.ID),
+			lastEventID: "5",
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				gomock.InOrder(
+					store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil),
+					store.EXPECT().
+						GetAccountSnapshot(gomock.Any(), gomock.Eq(account.ID)).
+						Times(1).
+						DoAndReturn(func(_ context.Context, _ int64) (db.GetAccountSnapshotRow, error) {
+							hub.Publish(db.AccountEvent{
+								Kind:           db.AccountEventEntry,
+								AccountID:      account.ID,
+								Balance:        account.Balance + 10,
+								BalanceVersion: 7,
+								Entry:          &db.Entry{ID: 7, AccountID: account.ID, Amount: 10},
+							})
+							return accountSnapshot(account, 6), nil
+						}),
+					store.EXPECT().
+						ListEntriesAfter(gomock.Any(), gomock.Eq(db.ListEntriesAfterParams{AccountID: account.ID, AfterID: 5, Limit: replayPageSize})).
+						Times(1).
+						Return([]db.ListEntriesAfterRow{randomEntryAfter(account.ID, 6, account.Balance)}, nil),
+				)
+			},
+			setupAuth: authorized,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				require.Equal(t, http.StatusOK, res.StatusCode)
+				require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+				sent := readServerSentEvents(t, res.Body, 3)
+				require.Equal(t, "6", sent[0].id)
+				require.Equal(t, db.AccountEventEntry, sent[0].event)
+				require.Equal(t, "6", sent[1].id)
+				require.Equal(t, accountEventSnapshot, sent[1].event)
+				require.Equal(t, "7", sent[2].id)
+				require.Equal(t, db.AccountEventEntry, sent[2].event)
+
+				var event pb.AccountEvent
+				require.NoError(t, protojson.Unmarshal([]byte(sent[2].data), &event))
+				require.Equal(t, account.ID, event.GetAccountId())
+				require.Equal(t, account.Balance+10, event.GetBalance())
+				require.Equal(t, int64(10), event.GetEntry().GetAmount())
+			},
+		},
+		{
+			name:   "Dropped",
+			method: http.MethodGet,
+			query:  fmt.Sprintf("account_id=%d", account.ID),
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				gomock.InOrder(
+					store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil),
+					store.EXPECT().
+						GetAccountSnapshot(gomock.Any(), gomock.Eq(account.ID)).
+						Times(1).
+						DoAndReturn(func(_ context.Context, _ int64) (db.GetAccountSnapshotRow, error) {
+							hub.DropAll()
+							return accountSnapshot(account, 0), nil
+						}),
+				)
+			},
+			setupAuth: authorized,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				require.Equal(t, http.StatusOK, res.StatusCode)
+				sent := readServerSentEvents(t, res.Body, -1)
+				require.Len(t, sent, 2)
+				require.Equal(t, accountEventSnapshot, sent[0].event)
+				require.Equal(t, "error", sent[1].event)
+
+				var st status.Status
+				require.NoError(t, protojson.Unmarshal([]byte(sent[1].data), &st))
+				require.Contains(t, st.GetMessage(), "resume from the last event")
+			},
+		},
+		{
+			name:   "NoAuthorization",
+			method: http.MethodGet,
+			query:  fmt.Sprintf("account_id=%d", account.ID),
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			checkResponse: func(t *testing.T, res *http.Response) {
+				require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+				require.Equal(t, "application/json", res.Header.Get("Content-Type"))
+			},
+		},
+		{
+			name:   "PermissionDenied",
+			method: http.MethodGet,
+			query:  fmt.Sprintf("account_id=%d", account.ID),
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				other := account
+				other.Owner = "other_user"
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(other, nil)
+			},
+			setupAuth: authorized,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				require.Equal(t, http.StatusForbidden, res.StatusCode)
+			},
+		},
+		{
+			name:   "InvalidAccountID",
+			method: http.MethodGet,
+			query:  "account_id=abc",
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			setupAuth: authorized,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				require.Equal(t, http.StatusBadRequest, res.StatusCode)
+			},
+		},
+		{
+			name:   "MissingAccountID",
+			method: http.MethodGet,
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			setupAuth: authorized,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				require.Equal(t, http.StatusBadRequest, res.StatusCode)
+				require.Equal(t, "application/json", res.Header.Get("Content-Type"))
+			},
+		},
+		{
+			name:   "MethodNotAllowed",
+			method: http.MethodPost,
+			query:  fmt.Sprintf("account_id=%d", account.ID),
+			buildStubs: func(store *mockdb.MockStore, hub *events.Hub) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			setupAuth: authorized,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				require.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			server := newTestServer(t, store, nil)
+			tc.buildStubs(store, server.hub)
+
+			httpServer := httptest.NewServer(server.WatchAccountHandler())
+			defer httpServer.Close()
+
+			request, err := http.NewRequest(tc.method, fmt.Sprintf("%s%s?%s", httpServer.URL, WatchAccountPath, tc.query), nil)
+			require.NoError(t, err)
+			if tc.lastEventID != "" {
+				request.Header.Set(lastEventIDHeader, tc.lastEventID)
+			}
+			tc.setupAuth(t, request, server.tokenMaker)
+
+			res, err := httpServer.Client().Do(request)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			tc.checkResponse(t, res)
+		})
+	}
+}
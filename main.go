@@ -8,6 +8,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/events"
 	"github.com/the-eduardo/Go-Bank/gapi"
 	"github.com/the-eduardo/Go-Bank/mail"
 	"github.com/the-eduardo/Go-Bank/pb"
@@ -19,6 +20,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strings"
 )
 
 func main() {
@@ -53,9 +55,22 @@ func main() {
 	}
 	taskDistributor := worker.NewRedisTaskDistributor(redisOtp)
 
+	// one hub listens to the account events for both servers
+	hub := events.NewHub()
+
 	go runTaskProcessor(config, redisOtp, store)
-	go runGatewayServer(config, store, taskDistributor)
-	runGrpcServer(config, store, taskDistributor)
+	go runScheduler(config, redisOtp)
+	go runEventListener(conn, hub)
+	go runGatewayServer(config, store, taskDistributor, hub)
+	runGrpcServer(config, store, taskDistributor, hub)
+}
+
+func runEventListener(conn *pgxpool.Pool, hub *events.Hub) {
+	log.Info().Msg("starting account events listener")
+	err := hub.Listen(context.Background(), conn)
+	if err != nil {
+		log.Fatal().Msgf("cannot listen to account events: %v", err)
+	}
 }
 
 func runTaskProcessor(config util.Config, redisOpt asynq.RedisClientOpt, store db.Store) {
@@ -67,14 +82,33 @@ func runTaskProcessor(config util.Config, redisOpt asynq.RedisClientOpt, store d
 		log.Fatal().Msgf("cannot start task processor: %v", err)
 	}
 }
-func runGrpcServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor) {
-	server, err := gapi.NewServer(config, store, taskDistributor)
+
+func runScheduler(config util.Config, redisOpt asynq.RedisClientOpt) {
+	scheduler, err := worker.NewScheduler(redisOpt, worker.SchedulerIntervals{
+		SessionCleanup:        config.SessionCleanupInterval,
+		ScheduledTransferPoll: config.ScheduledTransferPollInterval,
+		TransferHoldExpiry:    config.TransferHoldExpiryInterval,
+		WebhookDispatch:       config.WebhookDispatchInterval,
+	})
+	if err != nil {
+		log.Fatal().Msgf("cannot create scheduler: %v", err)
+	}
+	log.Info().Msg("starting scheduler")
+	err = scheduler.Start()
+	if err != nil {
+		log.Fatal().Msgf("cannot start scheduler: %v", err)
+	}
+}
+
+func runGrpcServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor, hub *events.Hub) {
+	server, err := gapi.NewServer(config, store, taskDistributor, hub)
 	if err != nil {
 		log.Fatal().Msgf("cannot create grpc server: %v", err)
 	}
 
 	grpcLogger := grpc.UnaryInterceptor(gapi.GrpcLogger)
-	grpcServer := grpc.NewServer(grpcLogger)
+	grpcStreamLogger := grpc.StreamInterceptor(gapi.GrpcStreamLogger)
+	grpcServer := grpc.NewServer(grpcLogger, grpcStreamLogger)
 	pb.RegisterGoBankServer(grpcServer, server)
 	reflection.Register(grpcServer)
 
@@ -89,8 +123,8 @@ func runGrpcServer(config util.Config, store db.Store, taskDistributor worker.Ta
 	}
 }
 
-func runGatewayServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor) {
-	server, err := gapi.NewServer(config, store, taskDistributor)
+func runGatewayServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor, hub *events.Hub) {
+	server, err := gapi.NewServer(config, store, taskDistributor, hub)
 	if err != nil {
 		log.Fatal().Msgf("cannot create grpc server: %v", err)
 	}
@@ -105,7 +139,15 @@ func runGatewayServer(config util.Config, store db.Store, taskDistributor worker
 		},
 	})
 
-	grpcMux := runtime.NewServeMux(jsonOption)
+	// headerMatcher forwards the Idempotency-Key header to the gRPC metadata
+	headerMatcher := runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+		if strings.EqualFold(key, "Idempotency-Key") {
+			return "idempotency-key", true
+		}
+		return runtime.DefaultHeaderMatcher(key)
+	})
+
+	grpcMux := runtime.NewServeMux(jsonOption, headerMatcher)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -117,6 +159,8 @@ func runGatewayServer(config util.Config, store db.Store, taskDistributor worker
 
 	mux := http.NewServeMux()
 	mux.Handle("/", grpcMux)
+	mux.Handle(gapi.JWKSPath, server.JWKSHandler())
+	mux.Handle(gapi.WatchAccountPath, server.WatchAccountHandler())
 
 	listener, err := net.Listen("tcp", config.HTTPServerAddress)
 	if err != nil {
@@ -0,0 +1,208 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v5.27.2
+// source: account_transfer_limits.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// AccountTransferLimits holds the overrides of an account, an unset limit uses the default from the config
+type AccountTransferLimits struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AccountId   int64                  `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	PerTransfer *int64                 `protobuf:"varint,2,opt,name=per_transfer,json=perTransfer,proto3,oneof" json:"per_transfer,omitempty"`
+	Daily       *int64                 `protobuf:"varint,3,opt,name=daily,proto3,oneof" json:"daily,omitempty"`
+	Weekly      *int64                 `protobuf:"varint,4,opt,name=weekly,proto3,oneof" json:"weekly,omitempty"`
+	HourlyCount *int64                 `protobuf:"varint,5,opt,name=hourly_count,json=hourlyCount,proto3,oneof" json:"hourly_count,omitempty"`
+	UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+}
+
+func (x *AccountTransferLimits) Reset() {
+	*x = AccountTransferLimits{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_account_transfer_limits_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AccountTransferLimits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountTransferLimits) ProtoMessage() {}
+
+func (x *AccountTransferLimits) ProtoReflect() protoreflect.Message {
+	mi := &file_account_transfer_limits_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountTransferLimits.ProtoReflect.Descriptor instead.
+func (*AccountTransferLimits) Descriptor() ([]byte, []int) {
+	return file_account_transfer_limits_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AccountTransferLimits) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *AccountTransferLimits) GetPerTransfer() int64 {
+	if x != nil && x.PerTransfer != nil {
+		return *x.PerTransfer
+	}
+	return 0
+}
+
+func (x *AccountTransferLimits) GetDaily() int64 {
+	if x != nil && x.Daily != nil {
+		return *x.Daily
+	}
+	return 0
+}
+
+func (x *AccountTransferLimits) GetWeekly() int64 {
+	if x != nil && x.Weekly != nil {
+		return *x.Weekly
+	}
+	return 0
+}
+
+func (x *AccountTransferLimits) GetHourlyCount() int64 {
+	if x != nil && x.HourlyCount != nil {
+		return *x.HourlyCount
+	}
+	return 0
+}
+
+func (x *AccountTransferLimits) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+var File_account_transfer_limits_proto protoreflect.FileDescriptor
+
+var file_account_transfer_limits_proto_rawDesc = []byte{
+	0x0a, 0x1d, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x65, 0x72, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x02, 0x70, 0x62, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x22, 0xb0, 0x02, 0x0a, 0x15, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x1d,
+	0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x26, 0x0a,
+	0x0c, 0x70, 0x65, 0x72, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x0b, 0x70, 0x65, 0x72, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x65, 0x72, 0x88, 0x01, 0x01, 0x12, 0x19, 0x0a, 0x05, 0x64, 0x61, 0x69, 0x6c, 0x79, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x03, 0x48, 0x01, 0x52, 0x05, 0x64, 0x61, 0x69, 0x6c, 0x79, 0x88, 0x01, 0x01,
+	0x12, 0x1b, 0x0a, 0x06, 0x77, 0x65, 0x65, 0x6b, 0x6c, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03,
+	0x48, 0x02, 0x52, 0x06, 0x77, 0x65, 0x65, 0x6b, 0x6c, 0x79, 0x88, 0x01, 0x01, 0x12, 0x26, 0x0a,
+	0x0c, 0x68, 0x6f, 0x75, 0x72, 0x6c, 0x79, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x03, 0x48, 0x03, 0x52, 0x0b, 0x68, 0x6f, 0x75, 0x72, 0x6c, 0x79, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x88, 0x01, 0x01, 0x12, 0x39, 0x0a, 0x0a, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64,
+	0x5f, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74,
+	0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65,
+	0x72, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x64, 0x61, 0x69, 0x6c, 0x79, 0x42, 0x09, 0x0a, 0x07, 0x5f,
+	0x77, 0x65, 0x65, 0x6b, 0x6c, 0x79, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x68, 0x6f, 0x75, 0x72, 0x6c,
+	0x79, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x42, 0x23, 0x5a, 0x21, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x68, 0x65, 0x2d, 0x65, 0x64, 0x75, 0x61, 0x72, 0x64,
+	0x6f, 0x2f, 0x47, 0x6f, 0x2d, 0x42, 0x61, 0x6e, 0x6b, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_account_transfer_limits_proto_rawDescOnce sync.Once
+	file_account_transfer_limits_proto_rawDescData = file_account_transfer_limits_proto_rawDesc
+)
+
+func file_account_transfer_limits_proto_rawDescGZIP() []byte {
+	file_account_transfer_limits_proto_rawDescOnce.Do(func() {
+		file_account_transfer_limits_proto_rawDescData = protoimpl.X.CompressGZIP(file_account_transfer_limits_proto_rawDescData)
+	})
+	return file_account_transfer_limits_proto_rawDescData
+}
+
+var file_account_transfer_limits_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_account_transfer_limits_proto_goTypes = []any{
+	(*AccountTransferLimits)(nil), // 0: pb.AccountTransferLimits
+	(*timestamppb.Timestamp)(nil), // 1: google.protobuf.Timestamp
+}
+var file_account_transfer_limits_proto_depIdxs = []int32{
+	1, // 0: pb.AccountTransferLimits.updated_at:type_name -> google.protobuf.Timestamp
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_account_transfer_limits_proto_init() }
+func file_account_transfer_limits_proto_init() {
+	if File_account_transfer_limits_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_account_transfer_limits_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*AccountTransferLimits); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_account_transfer_limits_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_account_transfer_limits_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_account_transfer_limits_proto_goTypes,
+		DependencyIndexes: file_account_transfer_limits_proto_depIdxs,
+		MessageInfos:      file_account_transfer_limits_proto_msgTypes,
+	}.Build()
+	File_account_transfer_limits_proto = out.File
+	file_account_transfer_limits_proto_rawDesc = nil
+	file_account_transfer_limits_proto_goTypes = nil
+	file_account_transfer_limits_proto_depIdxs = nil
+}
@@ -0,0 +1,237 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v5.27.2
+// source: rpc_list_kyc_submissions.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListKYCSubmissionsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// status defaults to pending
+	Status   *string `protobuf:"bytes,1,opt,name=status,proto3,oneof" json:"status,omitempty"`
+	PageId   int32   `protobuf:"varint,2,opt,name=page_id,json=pageId,proto3" json:"page_id,omitempty"`
+	PageSize int32   `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+}
+
+func (x *ListKYCSubmissionsRequest) Reset() {
+	*x = ListKYCSubmissionsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_list_kyc_submissions_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListKYCSubmissionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListKYCSubmissionsRequest) ProtoMessage() {}
+
+func (x *ListKYCSubmissionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_list_kyc_submissions_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListKYCSubmissionsRequest.ProtoReflect.Descriptor instead.
+func (*ListKYCSubmissionsRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_list_kyc_submissions_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ListKYCSubmissionsRequest) GetStatus() string {
+	if x != nil && x.Status != nil {
+		return *x.Status
+	}
+	return ""
+}
+
+func (x *ListKYCSubmissionsRequest) GetPageId() int32 {
+	if x != nil {
+		return x.PageId
+	}
+	return 0
+}
+
+func (x *ListKYCSubmissionsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListKYCSubmissionsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Submissions []*KYCSubmission `protobuf:"bytes,1,rep,name=submissions,proto3" json:"submissions,omitempty"`
+}
+
+func (x *ListKYCSubmissionsResponse) Reset() {
+	*x = ListKYCSubmissionsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_list_kyc_submissions_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListKYCSubmissionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListKYCSubmissionsResponse) ProtoMessage() {}
+
+func (x *ListKYCSubmissionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_list_kyc_submissions_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListKYCSubmissionsResponse.ProtoReflect.Descriptor instead.
+func (*ListKYCSubmissionsResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_list_kyc_submissions_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListKYCSubmissionsResponse) GetSubmissions() []*KYCSubmission {
+	if x != nil {
+		return x.Submissions
+	}
+	return nil
+}
+
+var File_rpc_list_kyc_submissions_proto protoreflect.FileDescriptor
+
+var file_rpc_list_kyc_submissions_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x72, 0x70, 0x63, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x5f, 0x6b, 0x79, 0x63, 0x5f, 0x73,
+	0x75, 0x62, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x02, 0x70, 0x62, 0x1a, 0x14, 0x6b, 0x79, 0x63, 0x5f, 0x73, 0x75, 0x62, 0x6d, 0x69, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x79, 0x0a, 0x19, 0x4c, 0x69,
+	0x73, 0x74, 0x4b, 0x59, 0x43, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x88, 0x01, 0x01, 0x12, 0x17, 0x0a, 0x07, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x70, 0x61, 0x67, 0x65, 0x49, 0x64, 0x12, 0x1b, 0x0a,
+	0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x51, 0x0a, 0x1a, 0x4c, 0x69, 0x73, 0x74, 0x4b, 0x59, 0x43,
+	0x53, 0x75, 0x62, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x33, 0x0a, 0x0b, 0x73, 0x75, 0x62, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x4b, 0x59,
+	0x43, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x73, 0x75, 0x62,
+	0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x42, 0x23, 0x5a, 0x21, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x68, 0x65, 0x2d, 0x65, 0x64, 0x75, 0x61, 0x72,
+	0x64, 0x6f, 0x2f, 0x47, 0x6f, 0x2d, 0x42, 0x61, 0x6e, 0x6b, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_rpc_list_kyc_submissions_proto_rawDescOnce sync.Once
+	file_rpc_list_kyc_submissions_proto_rawDescData = file_rpc_list_kyc_submissions_proto_rawDesc
+)
+
+func file_rpc_list_kyc_submissions_proto_rawDescGZIP() []byte {
+	file_rpc_list_kyc_submissions_proto_rawDescOnce.Do(func() {
+		file_rpc_list_kyc_submissions_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_list_kyc_submissions_proto_rawDescData)
+	})
+	return file_rpc_list_kyc_submissions_proto_rawDescData
+}
+
+var file_rpc_list_kyc_submissions_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_list_kyc_submissions_proto_goTypes = []any{
+	(*ListKYCSubmissionsRequest)(nil),  // 0: pb.ListKYCSubmissionsRequest
+	(*ListKYCSubmissionsResponse)(nil), // 1: pb.ListKYCSubmissionsResponse
+	(*KYCSubmission)(nil),              // 2: pb.KYCSubmission
+}
+var file_rpc_list_kyc_submissions_proto_depIdxs = []int32{
+	2, // 0: pb.ListKYCSubmissionsResponse.submissions:type_name -> pb.KYCSubmission
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_rpc_list_kyc_submissions_proto_init() }
+func file_rpc_list_kyc_submissions_proto_init() {
+	if File_rpc_list_kyc_submissions_proto != nil {
+		return
+	}
+	file_kyc_submission_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_rpc_list_kyc_submissions_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*ListKYCSubmissionsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_list_kyc_submissions_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*ListKYCSubmissionsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_rpc_list_kyc_submissions_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_list_kyc_submissions_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_list_kyc_submissions_proto_goTypes,
+		DependencyIndexes: file_rpc_list_kyc_submissions_proto_depIdxs,
+		MessageInfos:      file_rpc_list_kyc_submissions_proto_msgTypes,
+	}.Build()
+	File_rpc_list_kyc_submissions_proto = out.File
+	file_rpc_list_kyc_submissions_proto_rawDesc = nil
+	file_rpc_list_kyc_submissions_proto_goTypes = nil
+	file_rpc_list_kyc_submissions_proto_depIdxs = nil
+}
@@ -0,0 +1,247 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v5.27.2
+// source: rpc_review_kyc.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ReviewKYCRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SubmissionId int64  `protobuf:"varint,1,opt,name=submission_id,json=submissionId,proto3" json:"submission_id,omitempty"`
+	Approved     bool   `protobuf:"varint,2,opt,name=approved,proto3" json:"approved,omitempty"`
+	ReviewNote   string `protobuf:"bytes,3,opt,name=review_note,json=reviewNote,proto3" json:"review_note,omitempty"`
+}
+
+func (x *ReviewKYCRequest) Reset() {
+	*x = ReviewKYCRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_review_kyc_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReviewKYCRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReviewKYCRequest) ProtoMessage() {}
+
+func (x *ReviewKYCRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_review_kyc_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReviewKYCRequest.ProtoReflect.Descriptor instead.
+func (*ReviewKYCRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_review_kyc_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ReviewKYCRequest) GetSubmissionId() int64 {
+	if x != nil {
+		return x.SubmissionId
+	}
+	return 0
+}
+
+func (x *ReviewKYCRequest) GetApproved() bool {
+	if x != nil {
+		return x.Approved
+	}
+	return false
+}
+
+func (x *ReviewKYCRequest) GetReviewNote() string {
+	if x != nil {
+		return x.ReviewNote
+	}
+	return ""
+}
+
+type ReviewKYCResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Submission *KYCSubmission `protobuf:"bytes,1,opt,name=submission,proto3" json:"submission,omitempty"`
+	User       *User          `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+}
+
+func (x *ReviewKYCResponse) Reset() {
+	*x = ReviewKYCResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_review_kyc_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReviewKYCResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReviewKYCResponse) ProtoMessage() {}
+
+func (x *ReviewKYCResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_review_kyc_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReviewKYCResponse.ProtoReflect.Descriptor instead.
+func (*ReviewKYCResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_review_kyc_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ReviewKYCResponse) GetSubmission() *KYCSubmission {
+	if x != nil {
+		return x.Submission
+	}
+	return nil
+}
+
+func (x *ReviewKYCResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+var File_rpc_review_kyc_proto protoreflect.FileDescriptor
+
+var file_rpc_review_kyc_proto_rawDesc = []byte{
+	0x0a, 0x14, 0x72, 0x70, 0x63, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x5f, 0x6b, 0x79, 0x63,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x1a, 0x14, 0x6b, 0x79, 0x63, 0x5f,
+	0x73, 0x75, 0x62, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x1a, 0x0a, 0x75, 0x73, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x74, 0x0a, 0x10,
+	0x52, 0x65, 0x76, 0x69, 0x65, 0x77, 0x4b, 0x59, 0x43, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x23, 0x0a, 0x0d, 0x73, 0x75, 0x62, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x73, 0x75, 0x62, 0x6d, 0x69, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x65,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x65,
+	0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x5f, 0x6e, 0x6f, 0x74, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x65, 0x76, 0x69, 0x65, 0x77, 0x4e, 0x6f,
+	0x74, 0x65, 0x22, 0x64, 0x0a, 0x11, 0x52, 0x65, 0x76, 0x69, 0x65, 0x77, 0x4b, 0x59, 0x43, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x31, 0x0a, 0x0a, 0x73, 0x75, 0x62, 0x6d, 0x69,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x62,
+	0x2e, 0x4b, 0x59, 0x43, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0a,
+	0x73, 0x75, 0x62, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x04, 0x75, 0x73,
+	0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x73,
+	0x65, 0x72, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x42, 0x23, 0x5a, 0x21, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x68, 0x65, 0x2d, 0x65, 0x64, 0x75, 0x61, 0x72,
+	0x64, 0x6f, 0x2f, 0x47, 0x6f, 0x2d, 0x42, 0x61, 0x6e, 0x6b, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_rpc_review_kyc_proto_rawDescOnce sync.Once
+	file_rpc_review_kyc_proto_rawDescData = file_rpc_review_kyc_proto_rawDesc
+)
+
+func file_rpc_review_kyc_proto_rawDescGZIP() []byte {
+	file_rpc_review_kyc_proto_rawDescOnce.Do(func() {
+		file_rpc_review_kyc_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_review_kyc_proto_rawDescData)
+	})
+	return file_rpc_review_kyc_proto_rawDescData
+}
+
+var file_rpc_review_kyc_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_review_kyc_proto_goTypes = []any{
+	(*ReviewKYCRequest)(nil),  // 0: pb.ReviewKYCRequest
+	(*ReviewKYCResponse)(nil), // 1: pb.ReviewKYCResponse
+	(*KYCSubmission)(nil),     // 2: pb.KYCSubmission
+	(*User)(nil),              // 3: pb.User
+}
+var file_rpc_review_kyc_proto_depIdxs = []int32{
+	2, // 0: pb.ReviewKYCResponse.submission:type_name -> pb.KYCSubmission
+	3, // 1: pb.ReviewKYCResponse.user:type_name -> pb.User
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_rpc_review_kyc_proto_init() }
+func file_rpc_review_kyc_proto_init() {
+	if File_rpc_review_kyc_proto != nil {
+		return
+	}
+	file_kyc_submission_proto_init()
+	file_user_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_rpc_review_kyc_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*ReviewKYCRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_review_kyc_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*ReviewKYCResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_review_kyc_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_review_kyc_proto_goTypes,
+		DependencyIndexes: file_rpc_review_kyc_proto_depIdxs,
+		MessageInfos:      file_rpc_review_kyc_proto_msgTypes,
+	}.Build()
+	File_rpc_review_kyc_proto = out.File
+	file_rpc_review_kyc_proto_rawDesc = nil
+	file_rpc_review_kyc_proto_goTypes = nil
+	file_rpc_review_kyc_proto_depIdxs = nil
+}
@@ -0,0 +1,262 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v5.27.2
+// source: rpc_set_account_transfer_limits.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// SetAccountTransferLimitsRequest replaces every override of the account, 0 removes a limit
+type SetAccountTransferLimitsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AccountId   int64  `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	PerTransfer *int64 `protobuf:"varint,2,opt,name=per_transfer,json=perTransfer,proto3,oneof" json:"per_transfer,omitempty"`
+	Daily       *int64 `protobuf:"varint,3,opt,name=daily,proto3,oneof" json:"daily,omitempty"`
+	Weekly      *int64 `protobuf:"varint,4,opt,name=weekly,proto3,oneof" json:"weekly,omitempty"`
+	HourlyCount *int64 `protobuf:"varint,5,opt,name=hourly_count,json=hourlyCount,proto3,oneof" json:"hourly_count,omitempty"`
+}
+
+func (x *SetAccountTransferLimitsRequest) Reset() {
+	*x = SetAccountTransferLimitsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_set_account_transfer_limits_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetAccountTransferLimitsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetAccountTransferLimitsRequest) ProtoMessage() {}
+
+func (x *SetAccountTransferLimitsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_set_account_transfer_limits_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetAccountTransferLimitsRequest.ProtoReflect.Descriptor instead.
+func (*SetAccountTransferLimitsRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_set_account_transfer_limits_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SetAccountTransferLimitsRequest) GetAccountId() int64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *SetAccountTransferLimitsRequest) GetPerTransfer() int64 {
+	if x != nil && x.PerTransfer != nil {
+		return *x.PerTransfer
+	}
+	return 0
+}
+
+func (x *SetAccountTransferLimitsRequest) GetDaily() int64 {
+	if x != nil && x.Daily != nil {
+		return *x.Daily
+	}
+	return 0
+}
+
+func (x *SetAccountTransferLimitsRequest) GetWeekly() int64 {
+	if x != nil && x.Weekly != nil {
+		return *x.Weekly
+	}
+	return 0
+}
+
+func (x *SetAccountTransferLimitsRequest) GetHourlyCount() int64 {
+	if x != nil && x.HourlyCount != nil {
+		return *x.HourlyCount
+	}
+	return 0
+}
+
+type SetAccountTransferLimitsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Limits *AccountTransferLimits `protobuf:"bytes,1,opt,name=limits,proto3" json:"limits,omitempty"`
+}
+
+func (x *SetAccountTransferLimitsResponse) Reset() {
+	*x = SetAccountTransferLimitsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_set_account_transfer_limits_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetAccountTransferLimitsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetAccountTransferLimitsResponse) ProtoMessage() {}
+
+func (x *SetAccountTransferLimitsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_set_account_transfer_limits_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetAccountTransferLimitsResponse.ProtoReflect.Descriptor instead.
+func (*SetAccountTransferLimitsResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_set_account_transfer_limits_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SetAccountTransferLimitsResponse) GetLimits() *AccountTransferLimits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+var File_rpc_set_account_transfer_limits_proto protoreflect.FileDescriptor
+
+var file_rpc_set_account_transfer_limits_proto_rawDesc = []byte{
+	0x0a, 0x25, 0x72, 0x70, 0x63, 0x5f, 0x73, 0x65, 0x74, 0x5f, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x1a, 0x1d, 0x61, 0x63, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x5f, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xff, 0x01, 0x0a, 0x1f, 0x53,
+	0x65, 0x74, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65,
+	0x72, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d,
+	0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x26, 0x0a,
+	0x0c, 0x70, 0x65, 0x72, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x0b, 0x70, 0x65, 0x72, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x65, 0x72, 0x88, 0x01, 0x01, 0x12, 0x19, 0x0a, 0x05, 0x64, 0x61, 0x69, 0x6c, 0x79, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x03, 0x48, 0x01, 0x52, 0x05, 0x64, 0x61, 0x69, 0x6c, 0x79, 0x88, 0x01, 0x01,
+	0x12, 0x1b, 0x0a, 0x06, 0x77, 0x65, 0x65, 0x6b, 0x6c, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03,
+	0x48, 0x02, 0x52, 0x06, 0x77, 0x65, 0x65, 0x6b, 0x6c, 0x79, 0x88, 0x01, 0x01, 0x12, 0x26, 0x0a,
+	0x0c, 0x68, 0x6f, 0x75, 0x72, 0x6c, 0x79, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x03, 0x48, 0x03, 0x52, 0x0b, 0x68, 0x6f, 0x75, 0x72, 0x6c, 0x79, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x88, 0x01, 0x01, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x64, 0x61, 0x69, 0x6c, 0x79,
+	0x42, 0x09, 0x0a, 0x07, 0x5f, 0x77, 0x65, 0x65, 0x6b, 0x6c, 0x79, 0x42, 0x0f, 0x0a, 0x0d, 0x5f,
+	0x68, 0x6f, 0x75, 0x72, 0x6c, 0x79, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x55, 0x0a, 0x20,
+	0x53, 0x65, 0x74, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x65, 0x72, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x31, 0x0a, 0x06, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x66, 0x65, 0x72, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x52, 0x06, 0x6c, 0x69, 0x6d,
+	0x69, 0x74, 0x73, 0x42, 0x23, 0x5a, 0x21, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x74, 0x68, 0x65, 0x2d, 0x65, 0x64, 0x75, 0x61, 0x72, 0x64, 0x6f, 0x2f, 0x47, 0x6f,
+	0x2d, 0x42, 0x61, 0x6e, 0x6b, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_rpc_set_account_transfer_limits_proto_rawDescOnce sync.Once
+	file_rpc_set_account_transfer_limits_proto_rawDescData = file_rpc_set_account_transfer_limits_proto_rawDesc
+)
+
+func file_rpc_set_account_transfer_limits_proto_rawDescGZIP() []byte {
+	file_rpc_set_account_transfer_limits_proto_rawDescOnce.Do(func() {
+		file_rpc_set_account_transfer_limits_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_set_account_transfer_limits_proto_rawDescData)
+	})
+	return file_rpc_set_account_transfer_limits_proto_rawDescData
+}
+
+var file_rpc_set_account_transfer_limits_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_set_account_transfer_limits_proto_goTypes = []any{
+	(*SetAccountTransferLimitsRequest)(nil),  // 0: pb.SetAccountTransferLimitsRequest
+	(*SetAccountTransferLimitsResponse)(nil), // 1: pb.SetAccountTransferLimitsResponse
+	(*AccountTransferLimits)(nil),            // 2: pb.AccountTransferLimits
+}
+var file_rpc_set_account_transfer_limits_proto_depIdxs = []int32{
+	2, // 0: pb.SetAccountTransferLimitsResponse.limits:type_name -> pb.AccountTransferLimits
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_rpc_set_account_transfer_limits_proto_init() }
+func file_rpc_set_account_transfer_limits_proto_init() {
+	if File_rpc_set_account_transfer_limits_proto != nil {
+		return
+	}
+	file_account_transfer_limits_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_rpc_set_account_transfer_limits_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*SetAccountTransferLimitsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_set_account_transfer_limits_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*SetAccountTransferLimitsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_rpc_set_account_transfer_limits_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_set_account_transfer_limits_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_set_account_transfer_limits_proto_goTypes,
+		DependencyIndexes: file_rpc_set_account_transfer_limits_proto_depIdxs,
+		MessageInfos:      file_rpc_set_account_transfer_limits_proto_msgTypes,
+	}.Build()
+	File_rpc_set_account_transfer_limits_proto = out.File
+	file_rpc_set_account_transfer_limits_proto_rawDesc = nil
+	file_rpc_set_account_transfer_limits_proto_goTypes = nil
+	file_rpc_set_account_transfer_limits_proto_depIdxs = nil
+}
@@ -0,0 +1,233 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v5.27.2
+// source: rpc_submit_kyc.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SubmitKYCRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tier           int32  `protobuf:"varint,1,opt,name=tier,proto3" json:"tier,omitempty"`
+	DocumentType   string `protobuf:"bytes,2,opt,name=document_type,json=documentType,proto3" json:"document_type,omitempty"`
+	DocumentNumber string `protobuf:"bytes,3,opt,name=document_number,json=documentNumber,proto3" json:"document_number,omitempty"`
+}
+
+func (x *SubmitKYCRequest) Reset() {
+	*x = SubmitKYCRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_submit_kyc_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitKYCRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitKYCRequest) ProtoMessage() {}
+
+func (x *SubmitKYCRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_submit_kyc_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitKYCRequest.ProtoReflect.Descriptor instead.
+func (*SubmitKYCRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_submit_kyc_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SubmitKYCRequest) GetTier() int32 {
+	if x != nil {
+		return x.Tier
+	}
+	return 0
+}
+
+func (x *SubmitKYCRequest) GetDocumentType() string {
+	if x != nil {
+		return x.DocumentType
+	}
+	return ""
+}
+
+func (x *SubmitKYCRequest) GetDocumentNumber() string {
+	if x != nil {
+		return x.DocumentNumber
+	}
+	return ""
+}
+
+type SubmitKYCResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Submission *KYCSubmission `protobuf:"bytes,1,opt,name=submission,proto3" json:"submission,omitempty"`
+}
+
+func (x *SubmitKYCResponse) Reset() {
+	*x = SubmitKYCResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_submit_kyc_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitKYCResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitKYCResponse) ProtoMessage() {}
+
+func (x *SubmitKYCResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_submit_kyc_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitKYCResponse.ProtoReflect.Descriptor instead.
+func (*SubmitKYCResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_submit_kyc_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SubmitKYCResponse) GetSubmission() *KYCSubmission {
+	if x != nil {
+		return x.Submission
+	}
+	return nil
+}
+
+var File_rpc_submit_kyc_proto protoreflect.FileDescriptor
+
+var file_rpc_submit_kyc_proto_rawDesc = []byte{
+	0x0a, 0x14, 0x72, 0x70, 0x63, 0x5f, 0x73, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x5f, 0x6b, 0x79, 0x63,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x1a, 0x14, 0x6b, 0x79, 0x63, 0x5f,
+	0x73, 0x75, 0x62, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x22, 0x74, 0x0a, 0x10, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x4b, 0x59, 0x43, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x04, 0x74, 0x69, 0x65, 0x72, 0x12, 0x23, 0x0a, 0x0d, 0x64, 0x6f, 0x63, 0x75,
+	0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0c, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x27, 0x0a,
+	0x0f, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x64, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74,
+	0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x22, 0x46, 0x0a, 0x11, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74,
+	0x4b, 0x59, 0x43, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x31, 0x0a, 0x0a, 0x73,
+	0x75, 0x62, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x11, 0x2e, 0x70, 0x62, 0x2e, 0x4b, 0x59, 0x43, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x52, 0x0a, 0x73, 0x75, 0x62, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x42, 0x23,
+	0x5a, 0x21, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x68, 0x65,
+	0x2d, 0x65, 0x64, 0x75, 0x61, 0x72, 0x64, 0x6f, 0x2f, 0x47, 0x6f, 0x2d, 0x42, 0x61, 0x6e, 0x6b,
+	0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_rpc_submit_kyc_proto_rawDescOnce sync.Once
+	file_rpc_submit_kyc_proto_rawDescData = file_rpc_submit_kyc_proto_rawDesc
+)
+
+func file_rpc_submit_kyc_proto_rawDescGZIP() []byte {
+	file_rpc_submit_kyc_proto_rawDescOnce.Do(func() {
+		file_rpc_submit_kyc_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_submit_kyc_proto_rawDescData)
+	})
+	return file_rpc_submit_kyc_proto_rawDescData
+}
+
+var file_rpc_submit_kyc_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rpc_submit_kyc_proto_goTypes = []any{
+	(*SubmitKYCRequest)(nil),  // 0: pb.SubmitKYCRequest
+	(*SubmitKYCResponse)(nil), // 1: pb.SubmitKYCResponse
+	(*KYCSubmission)(nil),     // 2: pb.KYCSubmission
+}
+var file_rpc_submit_kyc_proto_depIdxs = []int32{
+	2, // 0: pb.SubmitKYCResponse.submission:type_name -> pb.KYCSubmission
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_rpc_submit_kyc_proto_init() }
+func file_rpc_submit_kyc_proto_init() {
+	if File_rpc_submit_kyc_proto != nil {
+		return
+	}
+	file_kyc_submission_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_rpc_submit_kyc_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*SubmitKYCRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_submit_kyc_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*SubmitKYCResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_submit_kyc_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_submit_kyc_proto_goTypes,
+		DependencyIndexes: file_rpc_submit_kyc_proto_depIdxs,
+		MessageInfos:      file_rpc_submit_kyc_proto_msgTypes,
+	}.Build()
+	File_rpc_submit_kyc_proto = out.File
+	file_rpc_submit_kyc_proto_rawDesc = nil
+	file_rpc_submit_kyc_proto_goTypes = nil
+	file_rpc_submit_kyc_proto_depIdxs = nil
+}
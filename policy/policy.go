@@ -0,0 +1,73 @@
+package policy
+
+import (
+	"errors"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/util"
+)
+
+var (
+	// ErrEmailNotVerified is returned when the owner of the source account has not verified their email
+	ErrEmailNotVerified = errors.New("email must be verified before moving money")
+	// ErrKYCRequired is returned when the kyc tier of the owner does not allow any transfer
+	ErrKYCRequired = errors.New("kyc tier does not allow transfers, please submit your documents")
+)
+
+// Policy decides who may move money and how much
+type Policy struct {
+	requireVerifiedEmail bool
+	// dailyTransferLimits holds the limit of each kyc tier by index, higher tiers use the last one
+	dailyTransferLimits []int64
+	accountLimit        db.TransferLimit
+	userLimit           db.TransferLimit
+}
+
+// New creates a policy from the configuration. A limit that is not configured is not enforced.
+func New(config util.Config) Policy {
+	return Policy{
+		requireVerifiedEmail: config.RequireVerifiedEmail,
+		dailyTransferLimits:  config.KYCDailyTransferLimits,
+		accountLimit: db.TransferLimit{
+			PerTransfer: config.AccountTransferMaxAmount,
+			Daily:       config.AccountDailyTransferLimit,
+			Weekly:      config.AccountWeeklyTransferLimit,
+			HourlyCount: config.AccountHourlyTransferCount,
+		},
+		userLimit: db.TransferLimit{
+			PerTransfer: config.UserTransferMaxAmount,
+			Daily:       config.UserDailyTransferLimit,
+			Weekly:      config.UserWeeklyTransferLimit,
+			HourlyCount: config.UserHourlyTransferCount,
+		},
+	}
+}
+
+// DailyTransferLimit returns the daily limit of a kyc tier, limited is false when no limits are configured
+func (policy Policy) DailyTransferLimit(tier int32) (limit int64, limited bool) {
+	if len(policy.dailyTransferLimits) == 0 {
+		return 0, false
+	}
+	index := min(max(int(tier), 0), len(policy.dailyTransferLimits)-1)
+	return policy.dailyTransferLimits[index], true
+}
+
+// TransferLimits checks that the owner of the source account may send money.
+// The returned limits must be passed to the transfer transaction, which enforces them atomically
+// after applying the overrides of the source account.
+func (policy Policy) TransferLimits(owner db.User) (*db.TransferLimits, error) {
+	if policy.requireVerifiedEmail && !owner.IsEmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+	limits := &db.TransferLimits{Account: policy.accountLimit, User: policy.userLimit}
+	limit, limited := policy.DailyTransferLimit(owner.KycTier)
+	if !limited {
+		return limits, nil
+	}
+	if limit <= 0 {
+		return nil, ErrKYCRequired
+	}
+	if limits.User.Daily <= 0 || limit < limits.User.Daily {
+		limits.User.Daily = limit
+	}
+	return limits, nil
+}
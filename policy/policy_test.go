@@ -0,0 +1,65 @@
+package policy
+
+import (
+	"github.com/stretchr/testify/require"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/util"
+	"testing"
+)
+
+func TestTransferLimits(t *testing.T) {
+	policy := New(util.Config{
+		RequireVerifiedEmail:   true,
+		KYCDailyTransferLimits: []int64{0, 1000, 5000},
+	})
+	user := db.User{Username: util.RandomOwner(), IsEmailVerified: true}
+
+	_, err := policy.TransferLimits(db.User{KycTier: util.KYCTierFull})
+	require.ErrorIs(t, err, ErrEmailNotVerified)
+
+	_, err = policy.TransferLimits(user)
+	require.ErrorIs(t, err, ErrKYCRequired)
+
+	user.KycTier = util.KYCTierBasic
+	limits, err := policy.TransferLimits(user)
+	require.NoError(t, err)
+	require.Equal(t, &db.TransferLimits{User: db.TransferLimit{Daily: 1000}}, limits)
+
+	// Tiers above the configured ones use the highest limit
+	user.KycTier = util.KYCTierFull + 1
+	limits, err = policy.TransferLimits(user)
+	require.NoError(t, err)
+	require.Equal(t, &db.TransferLimits{User: db.TransferLimit{Daily: 5000}}, limits)
+}
+
+func TestTransferLimitsNotConfigured(t *testing.T) {
+	policy := New(util.Config{})
+
+	limits, err := policy.TransferLimits(db.User{})
+	require.NoError(t, err)
+	require.Equal(t, &db.TransferLimits{}, limits)
+}
+
+func TestTransferLimitsDefaults(t *testing.T) {
+	policy := New(util.Config{
+		KYCDailyTransferLimits:     []int64{0, 1000, 5000},
+		AccountTransferMaxAmount:   500,
+		AccountDailyTransferLimit:  2000,
+		AccountWeeklyTransferLimit: 8000,
+		AccountHourlyTransferCount: 10,
+		UserDailyTransferLimit:     3000,
+		UserHourlyTransferCount:    20,
+	})
+	account := db.TransferLimit{PerTransfer: 500, Daily: 2000, Weekly: 8000, HourlyCount: 10}
+
+	// The kyc limit lowers the daily limit of the user
+	limits, err := policy.TransferLimits(db.User{KycTier: util.KYCTierBasic})
+	require.NoError(t, err)
+	require.Equal(t, account, limits.Account)
+	require.Equal(t, db.TransferLimit{Daily: 1000, HourlyCount: 20}, limits.User)
+
+	// but never raises it
+	limits, err = policy.TransferLimits(db.User{KycTier: util.KYCTierFull})
+	require.NoError(t, err)
+	require.Equal(t, db.TransferLimit{Daily: 3000, HourlyCount: 20}, limits.User)
+}
@@ -0,0 +1,40 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// WriteCSV writes one row per line of the statement, between a row with the opening balance and a row with the closing balance
+func (statement *Statement) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	rows := [][]string{
+		{"date", "entry_id", "description", "amount", "balance"},
+		{formatTime(statement.Start), "", "opening balance", "", formatAmount(statement.OpeningBalance)},
+	}
+	for _, line := range statement.Lines {
+		rows = append(rows, []string{
+			formatTime(line.Time),
+			strconv.FormatInt(line.EntryID, 10),
+			line.Description,
+			formatAmount(line.Amount),
+			formatAmount(line.Balance),
+		})
+	}
+	rows = append(rows, []string{formatTime(statement.End), "", "closing balance", "", formatAmount(statement.ClosingBalance)})
+
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatAmount(amount int64) string {
+	return strconv.FormatInt(amount, 10)
+}
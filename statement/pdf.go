@@ -0,0 +1,127 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The statement is laid out on A4 pages in Courier, one of the standard fonts every PDF reader provides,
+// so the columns line up without embedding a font
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 40
+	pdfFontSize     = 9
+	pdfLeading      = 11
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+)
+
+// pdfRowFormat fits the 94 columns of Courier 9pt between the margins
+const pdfRowFormat = "%-20s %8s %-38s %12s %12s"
+
+// WritePDF writes the statement as a PDF document
+func (statement *Statement) WritePDF(w io.Writer) error {
+	return writePDF(w, statement.textLines())
+}
+
+func (statement *Statement) textLines() []string {
+	account := statement.Account
+	lines := []string{
+		fmt.Sprintf("Statement of account %d", account.ID),
+		fmt.Sprintf("Owner: %s", account.Owner),
+		fmt.Sprintf("Currency: %s", account.Currency),
+		fmt.Sprintf("Period: %s to %s", formatTime(statement.Start), formatTime(statement.End)),
+		"",
+		fmt.Sprintf(pdfRowFormat, "Date", "Entry", "Description", "Amount", "Balance"),
+		fmt.Sprintf(pdfRowFormat, formatTime(statement.Start), "", "Opening balance", "", formatAmount(statement.OpeningBalance)),
+	}
+	for _, line := range statement.Lines {
+		description := line.Description
+		if len(description) > 38 {
+			description = description[:35] + "..."
+		}
+		lines = append(lines, fmt.Sprintf(pdfRowFormat,
+			formatTime(line.Time),
+			formatAmount(line.EntryID),
+			description,
+			formatAmount(line.Amount),
+			formatAmount(line.Balance),
+		))
+	}
+	return append(lines, fmt.Sprintf(pdfRowFormat, formatTime(statement.End), "", "Closing balance", "", formatAmount(statement.ClosingBalance)))
+}
+
+// writePDF writes the lines of text as a PDF document, breaking them into as many pages as needed.
+// Object 1 is the catalog, 2 the page tree and 3 the font, then every page is followed by its content stream.
+func writePDF(w io.Writer, lines []string) error {
+	var pages [][]string
+	for len(lines) > pdfLinesPerPage {
+		pages = append(pages, lines[:pdfLinesPerPage])
+		lines = lines[pdfLinesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	var buf bytes.Buffer
+	var offsets []int
+	writeObject := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	writeObject("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	writeObject("<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>")
+	for i, page := range pages {
+		writeObject(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 5+2*i,
+		))
+		content := pdfPageContent(page)
+		writeObject(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// pdfPageContent draws the lines from the top of the page, one leading apart
+func pdfPageContent(lines []string) string {
+	var content strings.Builder
+	fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin)
+	for _, line := range lines {
+		fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscape(line))
+	}
+	content.WriteString("ET")
+	return content.String()
+}
+
+// pdfEscape escapes the delimiters of a PDF string and replaces what the standard font encoding cannot show
+func pdfEscape(text string) string {
+	var escaped strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			escaped.WriteRune('\\')
+			escaped.WriteRune(r)
+		case r < ' ' || r > '~':
+			escaped.WriteRune('?')
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+	return escaped.String()
+}
@@ -0,0 +1,116 @@
+package statement
+
+import (
+	"context"
+	"fmt"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"time"
+)
+
+// Store is the part of the store a statement is read from
+type Store interface {
+	StatementTx(ctx context.Context, arg db.StatementTxParams) (db.StatementTxResult, error)
+}
+
+// Line is an entry of the account with the balance right after it
+type Line struct {
+	Time        time.Time
+	EntryID     int64
+	Description string
+	Amount      int64
+	Balance     int64
+}
+
+// Statement lists the entries of an account between Start, included, and End, excluded
+type Statement struct {
+	Account        db.Account
+	Start          time.Time
+	End            time.Time
+	OpeningBalance int64
+	ClosingBalance int64
+	Lines          []Line
+}
+
+// Generate builds the statement of an account for a date range.
+// The balances are the sums of the entries up to the start and the end of the range, all read from one snapshot.
+func Generate(ctx context.Context, store Store, accountID int64, start time.Time, end time.Time) (*Statement, error) {
+	result, err := store.StatementTx(ctx, db.StatementTxParams{
+		AccountID: accountID,
+		Start:     start,
+		End:       end,
+	})
+	if err != nil {
+		return nil, err
+	}
+	statement := &Statement{
+		Account:        result.Account,
+		Start:          start,
+		End:            end,
+		OpeningBalance: result.OpeningBalance,
+	}
+
+	describer := newDescriber(accountID, result.Transfers)
+	balance := statement.OpeningBalance
+	statement.Lines = make([]Line, len(result.Entries))
+	for i, entry := range result.Entries {
+		balance += entry.Amount
+		statement.Lines[i] = Line{
+			Time:        entry.CreatedAt.Time,
+			EntryID:     entry.ID,
+			Description: describer.describe(entry),
+			Amount:      entry.Amount,
+			Balance:     balance,
+		}
+	}
+	statement.ClosingBalance = balance
+	return statement, nil
+}
+
+// describer matches the entries with the transfers that posted them through the journal of the entries.
+// Entries without a transfer, like deposits, are described by their sign.
+type describer struct {
+	accountID int64
+	// transfers are keyed by the id of the journal that posted them
+	transfers map[int64]db.Transfer
+	used      map[string]bool
+}
+
+func newDescriber(accountID int64, transfers map[int64]db.Transfer) *describer {
+	return &describer{
+		accountID: accountID,
+		transfers: transfers,
+		used:      make(map[string]bool),
+	}
+}
+
+func (describer *describer) describe(entry db.Entry) string {
+	transfer, ok := describer.transfers[entry.JournalID.Int64]
+	if entry.JournalID.Valid && ok {
+		name := fmt.Sprintf("transfer %d", transfer.ID)
+		if transfer.ReversalOf.Valid {
+			name = fmt.Sprintf("refund %d of transfer %d", transfer.ID, transfer.ReversalOf.Int64)
+		}
+		switch {
+		case transfer.FromAccountID == describer.accountID && entry.Amount == -transfer.Amount && describer.use(transfer, "amount"):
+			return fmt.Sprintf("%s to account %d", name, transfer.ToAccountID)
+		case transfer.FromAccountID == describer.accountID && entry.Amount == -transfer.Fee && describer.use(transfer, "fee"):
+			return fmt.Sprintf("fee of transfer %d", transfer.ID)
+		case transfer.ToAccountID == describer.accountID && entry.Amount == transfer.ToAmount && describer.use(transfer, "amount"):
+			return fmt.Sprintf("%s from account %d", name, transfer.FromAccountID)
+		}
+	}
+	if entry.Amount > 0 {
+		return "credit"
+	}
+	return "debit"
+}
+
+// use marks a part of a transfer as described, so two entries of the same amount get both parts
+func (describer *describer) use(transfer db.Transfer, part string) bool {
+	key := fmt.Sprintf("%d:%s", transfer.ID, part)
+	if describer.used[key] {
+		return false
+	}
+	describer.used[key] = true
+	return true
+}
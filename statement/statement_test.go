@@ -0,0 +1,158 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/the-eduardo/Go-Bank/db/mock"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/util"
+	"go.uber.org/mock/gomock"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func timestamp(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: true}
+}
+
+func testStatement(t *testing.T) *Statement {
+	start := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	account := db.Account{ID: 1, Owner: util.RandomOwner(), Currency: util.USD, Balance: 1000}
+
+	sent := start.Add(time.Hour)
+	received := start.Add(2 * time.Hour)
+	refunded := start.Add(3 * time.Hour)
+	captured := start.Add(4 * time.Hour)
+	journal := func(id int64) pgtype.Int8 {
+		return pgtype.Int8{Int64: id, Valid: true}
+	}
+	entries := []db.Entry{
+		{ID: 2, AccountID: account.ID, Amount: -100, JournalID: journal(21), CreatedAt: timestamp(sent)},
+		{ID: 3, AccountID: account.ID, Amount: -5, JournalID: journal(21), CreatedAt: timestamp(sent)},
+		{ID: 4, AccountID: account.ID, Amount: 300, JournalID: journal(22), CreatedAt: timestamp(received)},
+		{ID: 5, AccountID: account.ID, Amount: 40, JournalID: journal(23), CreatedAt: timestamp(refunded)},
+		{ID: 6, AccountID: account.ID, Amount: -60, JournalID: journal(24), CreatedAt: timestamp(captured)},
+	}
+	transfers := map[int64]db.Transfer{
+		21: {ID: 11, FromAccountID: account.ID, ToAccountID: 2, Amount: 100, ToAmount: 100, Fee: 5, CreatedAt: timestamp(sent)},
+		22: {ID: 12, FromAccountID: 3, ToAccountID: account.ID, Amount: 300, ToAmount: 300, CreatedAt: timestamp(received)},
+		23: {ID: 13, FromAccountID: 2, ToAccountID: account.ID, Amount: 40, ToAmount: 40, CreatedAt: timestamp(refunded), ReversalOf: pgtype.Int8{Int64: 11, Valid: true}},
+		// a hold authorized before the range and captured in it keeps the time of its authorization
+		24: {ID: 14, FromAccountID: account.ID, ToAccountID: 3, Amount: 60, ToAmount: 60, CreatedAt: timestamp(start.AddDate(0, -1, 0))},
+	}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		StatementTx(gomock.Any(), gomock.Eq(db.StatementTxParams{AccountID: account.ID, Start: start, End: end})).
+		Times(1).
+		Return(db.StatementTxResult{
+			Account:        account,
+			OpeningBalance: 565,
+			Entries:        entries,
+			Transfers:      transfers,
+		}, nil)
+
+	statement, err := Generate(context.Background(), store, account.ID, start, end)
+	require.NoError(t, err)
+	return statement
+}
+
+func TestGenerate(t *testing.T) {
+	statement := testStatement(t)
+
+	// the closing balance adds the entries of the range to the opening balance
+	require.Equal(t, int64(565), statement.OpeningBalance)
+	require.Equal(t, int64(740), statement.ClosingBalance)
+
+	require.Len(t, statement.Lines, 5)
+	expected := []struct {
+		entryID     int64
+		description string
+		balance     int64
+	}{
+		{2, "transfer 11 to account 2", 465},
+		{3, "fee of transfer 11", 460},
+		{4, "transfer 12 from account 3", 760},
+		{5, "refund 13 of transfer 11 from account 2", 800},
+		{6, "transfer 14 to account 3", 740},
+	}
+	for i, line := range statement.Lines {
+		require.Equal(t, expected[i].entryID, line.EntryID)
+		require.Equal(t, expected[i].description, line.Description)
+		require.Equal(t, expected[i].balance, line.Balance)
+	}
+}
+
+func TestDescribeWithoutTransfer(t *testing.T) {
+	describer := newDescriber(1, nil)
+	require.Equal(t, "credit", describer.describe(db.Entry{Amount: 10}))
+	require.Equal(t, "debit", describer.describe(db.Entry{Amount: -10}))
+}
+
+func TestWriteCSV(t *testing.T) {
+	statement := testStatement(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, statement.WriteCSV(&buf))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, len(statement.Lines)+3)
+	require.Equal(t, []string{"date", "entry_id", "description", "amount", "balance"}, rows[0])
+	require.Equal(t, []string{"2026-09-01T00:00:00Z", "", "opening balance", "", "565"}, rows[1])
+	require.Equal(t, []string{"2026-09-01T01:00:00Z", "2", "transfer 11 to account 2", "-100", "465"}, rows[2])
+	require.Equal(t, []string{"2026-10-01T00:00:00Z", "", "closing balance", "", "740"}, rows[len(rows)-1])
+}
+
+func TestWritePDF(t *testing.T) {
+	statement := testStatement(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, statement.WritePDF(&buf))
+	document := buf.String()
+	require.True(t, strings.HasPrefix(document, "%PDF-1.4\n"))
+	require.True(t, strings.HasSuffix(document, "%%EOF\n"))
+	require.Contains(t, document, "Opening balance")
+	require.Contains(t, document, "/Count 1")
+	requireValidXref(t, document)
+}
+
+func TestWritePDFPages(t *testing.T) {
+	lines := make([]string, 2*pdfLinesPerPage+1)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line (%d)", i)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writePDF(&buf, lines))
+	document := buf.String()
+	require.Contains(t, document, "/Kids [4 0 R 6 0 R 8 0 R] /Count 3")
+	require.Contains(t, document, `(line \(0\)) Tj`)
+	requireValidXref(t, document)
+}
+
+// requireValidXref checks that every offset of the cross-reference table points at its object
+func requireValidXref(t *testing.T, document string) {
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(document)
+	require.NotNil(t, startxref)
+	xref, err := strconv.Atoi(startxref[1])
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(document[xref:], "xref\n"))
+
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(document[xref:], -1)
+	require.NotEmpty(t, offsets)
+	for i, match := range offsets {
+		offset, err := strconv.Atoi(match[1])
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(document[offset:], fmt.Sprintf("%d 0 obj\n", i+1)))
+	}
+}
@@ -0,0 +1,62 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// JSONWebKey is the public part of a signing key, as described in RFC 7517
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JSONWebKeySet is the document served on the JWKS endpoint
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// newJSONWebKey describes a public key. PASETO has no JWA algorithm name, so its algorithm is left empty.
+func newJSONWebKey(keyID string, algorithm string, publicKey crypto.PublicKey) (JSONWebKey, error) {
+	key := JSONWebKey{
+		KeyID:     keyID,
+		Use:       "sig",
+		Algorithm: algorithm,
+	}
+	switch publicKey := publicKey.(type) {
+	case ed25519.PublicKey:
+		key.KeyType = "OKP"
+		key.Curve = "Ed25519"
+		key.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	case *rsa.PublicKey:
+		key.KeyType = "RSA"
+		key.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		key.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	default:
+		return key, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+	return key, nil
+}
+
+// newJSONWebKeySet describes every key of a keyring, so tokens of scheduled keys verify as soon as they are issued
+func newJSONWebKeySet(keyring *Keyring[crypto.Signer], algorithm string) JSONWebKeySet {
+	jwks := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range keyring.Keys() {
+		jwk, err := newJSONWebKey(key.ID, algorithm, key.Material.Public())
+		if err != nil {
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
@@ -0,0 +1,137 @@
+package token
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	JWTAlgorithmEdDSA = "EdDSA"
+	JWTAlgorithmRS256 = "RS256"
+)
+
+// jwtClaims maps the token payload to registered JWT claims
+type jwtClaims struct {
+	Type      TokenType `json:"token_type"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	SessionID string    `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// JWTMaker is a JSON Web Token maker signed with Ed25519 or RSA keys
+type JWTMaker struct {
+	method  jwt.SigningMethod
+	keyring *Keyring[crypto.Signer]
+}
+
+// NewJWTMaker creates a new JWTMaker for the EdDSA or RS256 algorithm with a single key
+func NewJWTMaker(algorithm string, privateKey crypto.Signer, keyID string) (PublicKeyMaker, error) {
+	keyring, err := NewKeyring(Key[crypto.Signer]{ID: keyID, Material: privateKey})
+	if err != nil {
+		return nil, err
+	}
+	return NewJWTKeyringMaker(algorithm, keyring)
+}
+
+// NewJWTKeyringMaker creates a new JWTMaker that signs with the current key of the keyring
+func NewJWTKeyringMaker(algorithm string, keyring *Keyring[crypto.Signer]) (PublicKeyMaker, error) {
+	var method jwt.SigningMethod
+	switch algorithm {
+	case JWTAlgorithmEdDSA:
+		method = jwt.SigningMethodEdDSA
+	case JWTAlgorithmRS256:
+		method = jwt.SigningMethodRS256
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", algorithm)
+	}
+	// Signing once checks that every key matches the algorithm
+	for _, key := range keyring.Keys() {
+		if _, err := method.Sign("", key.Material); err != nil {
+			return nil, fmt.Errorf("invalid %s key %q: %w", algorithm, key.ID, err)
+		}
+	}
+	maker := &JWTMaker{
+		method:  method,
+		keyring: keyring,
+	}
+	return maker, nil
+}
+
+func (maker *JWTMaker) CreateToken(username string, role string, sessionID uuid.UUID, duration time.Duration, tokenType TokenType) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, sessionID, duration, tokenType)
+	if err != nil {
+		return "", payload, err
+	}
+	claims := jwtClaims{
+		Type:     payload.Type,
+		Username: payload.Username,
+		Role:     payload.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        payload.ID.String(),
+			Subject:   payload.Username,
+			IssuedAt:  jwt.NewNumericDate(payload.IssuedAt),
+			ExpiresAt: jwt.NewNumericDate(payload.ExpiredAt),
+		},
+	}
+	if payload.SessionID != uuid.Nil {
+		claims.SessionID = payload.SessionID.String()
+	}
+	key := maker.keyring.SigningKey()
+	jwtToken := jwt.NewWithClaims(maker.method, claims)
+	jwtToken.Header["kid"] = key.ID
+	token, err := jwtToken.SignedString(key.Material)
+	return token, payload, err
+}
+
+// VerifyToken checks if the token is valid or not
+func (maker *JWTMaker) VerifyToken(token string, tokenType TokenType) (*Payload, error) {
+	keyFunc := func(jwtToken *jwt.Token) (any, error) {
+		keyID, _ := jwtToken.Header["kid"].(string)
+		key, ok := maker.keyring.Key(keyID)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		return key.Material.Public(), nil
+	}
+	claims := &jwtClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, keyFunc, jwt.WithValidMethods([]string{maker.method.Alg()}))
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	id, err := uuid.Parse(claims.ID)
+	if err != nil || claims.IssuedAt == nil || claims.ExpiresAt == nil || claims.Type != tokenType {
+		return nil, ErrInvalidToken
+	}
+	// Tokens issued before sessions were linked have no sid claim
+	var sessionID uuid.UUID
+	if claims.SessionID != "" {
+		sessionID, err = uuid.Parse(claims.SessionID)
+		if err != nil {
+			return nil, ErrInvalidToken
+		}
+	}
+	payload := &Payload{
+		ID:        id,
+		Type:      claims.Type,
+		SessionID: sessionID,
+		Username:  claims.Username,
+		Role:      claims.Role,
+		IssuedAt:  claims.IssuedAt.Time,
+		ExpiredAt: claims.ExpiresAt.Time,
+	}
+	return payload, nil
+}
+
+// JWKS returns the public keys that verify the tokens of this maker
+func (maker *JWTMaker) JWKS() JSONWebKeySet {
+	return newJSONWebKeySet(maker.keyring, maker.method.Alg())
+}
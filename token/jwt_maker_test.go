@@ -0,0 +1,97 @@
+package token
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/util"
+	"testing"
+	"time"
+)
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return privateKey
+}
+
+func TestJWTMaker(t *testing.T) {
+	testCases := []struct {
+		algorithm  string
+		privateKey crypto.Signer
+		keyType    string
+	}{
+		{algorithm: JWTAlgorithmEdDSA, privateKey: newEd25519Key(t), keyType: "OKP"},
+		{algorithm: JWTAlgorithmRS256, privateKey: newRSAKey(t), keyType: "RSA"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.algorithm, func(t *testing.T) {
+			maker, err := NewJWTMaker(tc.algorithm, tc.privateKey, "key-1")
+			require.NoError(t, err)
+
+			username := util.RandomOwner()
+			role := util.BankerRole
+			sessionID := uuid.New()
+			duration := time.Minute
+
+			issuedAt := time.Now()
+			expiredAt := issuedAt.Add(duration)
+
+			token, created, err := maker.CreateToken(username, role, sessionID, duration, TokenTypeAccessToken)
+			require.NoError(t, err)
+			require.NotEmpty(t, token)
+
+			payload, err := maker.VerifyToken(token, TokenTypeAccessToken)
+			require.NoError(t, err)
+			require.Equal(t, created.ID, payload.ID)
+			require.Equal(t, username, payload.Username)
+			require.Equal(t, role, payload.Role)
+			require.Equal(t, sessionID, payload.SessionID)
+			require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+			require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+
+			jwks := maker.JWKS()
+			require.Len(t, jwks.Keys, 1)
+			require.Equal(t, "key-1", jwks.Keys[0].KeyID)
+			require.Equal(t, tc.algorithm, jwks.Keys[0].Algorithm)
+			require.Equal(t, tc.keyType, jwks.Keys[0].KeyType)
+		})
+	}
+}
+
+func TestExpiredJWTToken(t *testing.T) {
+	maker, err := NewJWTMaker(JWTAlgorithmEdDSA, newEd25519Key(t), "key-1")
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.Nil, -time.Minute, TokenTypeAccessToken)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token, TokenTypeAccessToken)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestInvalidJWTToken(t *testing.T) {
+	maker, err := NewJWTMaker(JWTAlgorithmEdDSA, newEd25519Key(t), "key-1")
+	require.NoError(t, err)
+
+	otherMaker, err := NewJWTMaker(JWTAlgorithmEdDSA, newEd25519Key(t), "key-1")
+	require.NoError(t, err)
+	token, _, err := otherMaker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.Nil, time.Minute, TokenTypeAccessToken)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token, TokenTypeAccessToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestJWTMakerKeyMismatch(t *testing.T) {
+	_, err := NewJWTMaker(JWTAlgorithmRS256, newEd25519Key(t), "key-1")
+	require.Error(t, err)
+
+	_, err = NewJWTMaker("HS256", newEd25519Key(t), "key-1")
+	require.Error(t, err)
+}
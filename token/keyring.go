@@ -0,0 +1,84 @@
+package token
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Key is a key of a keyring. It signs new tokens from ActiveFrom until a newer key becomes active,
+// and keeps verifying tokens for as long as it stays in the keyring.
+type Key[T any] struct {
+	ID         string
+	ActiveFrom time.Time
+	Material   T
+}
+
+// Keyring holds the keys of a maker: the current signing key and every key that still verifies tokens.
+// A key whose ActiveFrom is in the future is a scheduled rotation, it only signs once that time has passed.
+type Keyring[T any] struct {
+	// keys are sorted by ActiveFrom
+	keys []Key[T]
+	now  func() time.Time
+}
+
+// NewKeyring creates a keyring, at least one of the keys must already be active
+func NewKeyring[T any](keys ...Key[T]) (*Keyring[T], error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("keyring needs at least one key")
+	}
+	ids := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, fmt.Errorf("key id is required")
+		}
+		if ids[key.ID] {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		ids[key.ID] = true
+	}
+
+	sorted := make([]Key[T], len(keys))
+	copy(sorted, keys)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ActiveFrom.Before(sorted[j].ActiveFrom)
+	})
+	keyring := &Keyring[T]{
+		keys: sorted,
+		now:  time.Now,
+	}
+	if keyring.now().Before(sorted[0].ActiveFrom) {
+		return nil, fmt.Errorf("no key is active yet")
+	}
+	return keyring, nil
+}
+
+// SigningKey returns the most recently activated key
+func (keyring *Keyring[T]) SigningKey() Key[T] {
+	now := keyring.now()
+	signingKey := keyring.keys[0]
+	for _, key := range keyring.keys[1:] {
+		if now.Before(key.ActiveFrom) {
+			break
+		}
+		signingKey = key
+	}
+	return signingKey
+}
+
+// Key returns the key with the given ID
+func (keyring *Keyring[T]) Key(id string) (Key[T], bool) {
+	for _, key := range keyring.keys {
+		if key.ID == id {
+			return key, true
+		}
+	}
+	return Key[T]{}, false
+}
+
+// Keys returns every key of the keyring, including the scheduled ones
+func (keyring *Keyring[T]) Keys() []Key[T] {
+	keys := make([]Key[T], len(keyring.keys))
+	copy(keys, keyring.keys)
+	return keys
+}
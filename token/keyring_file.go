@@ -0,0 +1,87 @@
+package token
+
+import (
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// keyringFile is the format of TOKEN_KEYRING_FILE:
+//
+//	{
+//	  "keys": [
+//	    {"id": "default", "secret": "...", "active_from": "2024-01-01T00:00:00Z"},
+//	    {"id": "2024-07", "secret": "...", "active_from": "2024-07-01T00:00:00Z"}
+//	  ]
+//	}
+//
+// The paseto maker reads "secret", the public key makers read "private_key_file",
+// which is relative to the keyring file.
+type keyringFile struct {
+	Keys []struct {
+		ID             string    `json:"id"`
+		Secret         string    `json:"secret"`
+		PrivateKeyFile string    `json:"private_key_file"`
+		ActiveFrom     time.Time `json:"active_from"`
+	} `json:"keys"`
+}
+
+func readKeyringFile(path string) (keyringFile, error) {
+	var file keyringFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return file, fmt.Errorf("cannot read keyring file: %w", err)
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return file, fmt.Errorf("cannot parse keyring file: %w", err)
+	}
+	return file, nil
+}
+
+// LoadSymmetricKeyringFile reads the secrets of a keyring file
+func LoadSymmetricKeyringFile(path string) (*Keyring[[]byte], error) {
+	file, err := readKeyringFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]Key[[]byte], len(file.Keys))
+	for i, entry := range file.Keys {
+		if entry.Secret == "" {
+			return nil, fmt.Errorf("key %q has no secret", entry.ID)
+		}
+		keys[i] = Key[[]byte]{
+			ID:         entry.ID,
+			ActiveFrom: entry.ActiveFrom,
+			Material:   []byte(entry.Secret),
+		}
+	}
+	return NewKeyring(keys...)
+}
+
+// LoadPrivateKeyringFile reads the private keys listed in a keyring file
+func LoadPrivateKeyringFile(path string) (*Keyring[crypto.Signer], error) {
+	file, err := readKeyringFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]Key[crypto.Signer], len(file.Keys))
+	for i, entry := range file.Keys {
+		keyFile := entry.PrivateKeyFile
+		if keyFile != "" && !filepath.IsAbs(keyFile) {
+			keyFile = filepath.Join(filepath.Dir(path), keyFile)
+		}
+		privateKey, err := LoadPrivateKeyFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", entry.ID, err)
+		}
+		keys[i] = Key[crypto.Signer]{
+			ID:         entry.ID,
+			ActiveFrom: entry.ActiveFrom,
+			Material:   privateKey,
+		}
+	}
+	return NewKeyring(keys...)
+}
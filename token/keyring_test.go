@@ -0,0 +1,48 @@
+package token
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestKeyring(t *testing.T) {
+	now := time.Now()
+	keyring, err := NewKeyring(
+		Key[string]{ID: "next", ActiveFrom: now.Add(time.Hour), Material: "c"},
+		Key[string]{ID: "current", ActiveFrom: now.Add(-time.Hour), Material: "b"},
+		Key[string]{ID: "previous", ActiveFrom: now.Add(-48 * time.Hour), Material: "a"},
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, "current", keyring.SigningKey().ID)
+
+	keys := keyring.Keys()
+	require.Len(t, keys, 3)
+	require.Equal(t, "previous", keys[0].ID)
+	require.Equal(t, "next", keys[2].ID)
+
+	key, ok := keyring.Key("next")
+	require.True(t, ok)
+	require.Equal(t, "c", key.Material)
+	_, ok = keyring.Key("unknown")
+	require.False(t, ok)
+
+	// The scheduled key takes over once its time comes
+	keyring.now = func() time.Time { return now.Add(2 * time.Hour) }
+	require.Equal(t, "next", keyring.SigningKey().ID)
+}
+
+func TestNewKeyringErrors(t *testing.T) {
+	_, err := NewKeyring[string]()
+	require.Error(t, err)
+
+	_, err = NewKeyring(Key[string]{ID: ""})
+	require.Error(t, err)
+
+	_, err = NewKeyring(Key[string]{ID: "a"}, Key[string]{ID: "a"})
+	require.Error(t, err)
+
+	_, err = NewKeyring(Key[string]{ID: "a", ActiveFrom: time.Now().Add(time.Hour)})
+	require.EqualError(t, err, "no key is active yet")
+}
@@ -0,0 +1,39 @@
+package token
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+// ParsePrivateKeyPEM parses a PKCS #8 PEM encoded Ed25519 or RSA private key,
+// such as the ones written by `openssl genpkey`
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+// LoadPrivateKeyFile reads a private key from a PEM file
+func LoadPrivateKeyFile(path string) (crypto.Signer, error) {
+	if path == "" {
+		return nil, fmt.Errorf("private key file not configured")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read private key file: %w", err)
+	}
+	return ParsePrivateKeyPEM(data)
+}
@@ -1,11 +1,85 @@
 package token
 
-import "time"
+import (
+	"crypto"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	MakerPaseto       = "paseto"
+	MakerPasetoPublic = "paseto_public"
+	MakerJWTEdDSA     = "jwt_eddsa"
+	MakerJWTRS256     = "jwt_rs256"
+)
 
 // Maker is an interface for managing tokens
 type Maker interface {
-	// CreateToken creates a new token for a specific username and duration
-	CreateToken(username string, duration time.Duration) (string, *Payload, error)
-	// VerifyToken checks if the token is valid or not
-	VerifyToken(token string) (*Payload, error)
+	// CreateToken creates a new token of tokenType for a specific username, role, session and duration
+	CreateToken(username string, role string, sessionID uuid.UUID, duration time.Duration, tokenType TokenType) (string, *Payload, error)
+	// VerifyToken checks if the token is valid or not, a token of another type is invalid
+	VerifyToken(token string, tokenType TokenType) (*Payload, error)
+}
+
+// PublicKeyMaker is a Maker that signs with a private key,
+// so other services can verify its tokens with the published public keys
+type PublicKeyMaker interface {
+	Maker
+	// JWKS returns the public keys that verify the tokens of this maker
+	JWKS() JSONWebKeySet
+}
+
+// MakerConfig selects and configures a Maker
+type MakerConfig struct {
+	Kind string
+	// SymmetricKey is the single key of the paseto maker
+	SymmetricKey string
+	// PrivateKeyFile and KeyID are the single key of the public key makers
+	PrivateKeyFile string
+	KeyID          string
+	// KeyringFile lists the keys for rotation, it replaces the single key settings when set
+	KeyringFile string
+}
+
+// NewMaker creates the maker selected in the config
+func NewMaker(config MakerConfig) (Maker, error) {
+	switch config.Kind {
+	case MakerPaseto, "":
+		if config.KeyringFile == "" {
+			return NewPasetoMaker(config.SymmetricKey)
+		}
+		keyring, err := LoadSymmetricKeyringFile(config.KeyringFile)
+		if err != nil {
+			return nil, err
+		}
+		return NewPasetoKeyringMaker(keyring)
+	case MakerPasetoPublic, MakerJWTEdDSA, MakerJWTRS256:
+	default:
+		return nil, fmt.Errorf("unsupported token maker %q", config.Kind)
+	}
+
+	keyring, err := loadPrivateKeyring(config)
+	if err != nil {
+		return nil, err
+	}
+	switch config.Kind {
+	case MakerPasetoPublic:
+		return NewPasetoPublicKeyringMaker(keyring)
+	case MakerJWTEdDSA:
+		return NewJWTKeyringMaker(JWTAlgorithmEdDSA, keyring)
+	default:
+		return NewJWTKeyringMaker(JWTAlgorithmRS256, keyring)
+	}
+}
+
+func loadPrivateKeyring(config MakerConfig) (*Keyring[crypto.Signer], error) {
+	if config.KeyringFile != "" {
+		return LoadPrivateKeyringFile(config.KeyringFile)
+	}
+	privateKey, err := LoadPrivateKeyFile(config.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyring(Key[crypto.Signer]{ID: config.KeyID, Material: privateKey})
 }
@@ -0,0 +1,113 @@
+package token
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/util"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writePrivateKeyFile(t *testing.T, privateKey crypto.Signer) string {
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "token_key.pem")
+	err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	require.NoError(t, err)
+	return path
+}
+
+func TestNewMaker(t *testing.T) {
+	edKeyFile := writePrivateKeyFile(t, newEd25519Key(t))
+	rsaKeyFile := writePrivateKeyFile(t, newRSAKey(t))
+
+	maker, err := NewMaker(MakerConfig{Kind: MakerPaseto, SymmetricKey: util.RandomString(32)})
+	require.NoError(t, err)
+	require.IsType(t, &PasetoMaker{}, maker)
+
+	maker, err = NewMaker(MakerConfig{Kind: MakerPasetoPublic, PrivateKeyFile: edKeyFile, KeyID: "key-1"})
+	require.NoError(t, err)
+	require.IsType(t, &PasetoPublicMaker{}, maker)
+
+	maker, err = NewMaker(MakerConfig{Kind: MakerJWTEdDSA, PrivateKeyFile: edKeyFile, KeyID: "key-1"})
+	require.NoError(t, err)
+	require.IsType(t, &JWTMaker{}, maker)
+
+	maker, err = NewMaker(MakerConfig{Kind: MakerJWTRS256, PrivateKeyFile: rsaKeyFile, KeyID: "key-1"})
+	require.NoError(t, err)
+	require.IsType(t, &JWTMaker{}, maker)
+
+	// An RSA key cannot sign PASETO v4.public tokens
+	_, err = NewMaker(MakerConfig{Kind: MakerPasetoPublic, PrivateKeyFile: rsaKeyFile, KeyID: "key-1"})
+	require.Error(t, err)
+
+	_, err = NewMaker(MakerConfig{Kind: MakerJWTEdDSA, KeyID: "key-1"})
+	require.Error(t, err)
+
+	_, err = NewMaker(MakerConfig{Kind: "unknown", SymmetricKey: util.RandomString(32)})
+	require.Error(t, err)
+}
+
+func TestNewMakerWithKeyringFile(t *testing.T) {
+	dir := t.TempDir()
+	oldKeyFile := writePrivateKeyFile(t, newEd25519Key(t))
+	newKeyFile := writePrivateKeyFile(t, newEd25519Key(t))
+	keyringFile := filepath.Join(dir, "keyring.json")
+	err := os.WriteFile(keyringFile, []byte(`{"keys": [
+		{"id": "old", "private_key_file": "`+oldKeyFile+`", "active_from": "2024-01-01T00:00:00Z"},
+		{"id": "new", "private_key_file": "`+newKeyFile+`", "active_from": "2024-07-01T00:00:00Z"}
+	]}`), 0600)
+	require.NoError(t, err)
+
+	maker, err := NewMaker(MakerConfig{Kind: MakerJWTEdDSA, KeyringFile: keyringFile})
+	require.NoError(t, err)
+	jwks := maker.(PublicKeyMaker).JWKS()
+	require.Len(t, jwks.Keys, 2)
+
+	secretsFile := filepath.Join(dir, "secrets.json")
+	err = os.WriteFile(secretsFile, []byte(`{"keys": [
+		{"id": "default", "secret": "`+util.RandomString(32)+`", "active_from": "2024-01-01T00:00:00Z"},
+		{"id": "short", "secret": "`+util.RandomString(8)+`", "active_from": "2024-07-01T00:00:00Z"}
+	]}`), 0600)
+	require.NoError(t, err)
+
+	// Every key of the keyring must be valid, not only the signing one
+	_, err = NewMaker(MakerConfig{Kind: MakerPaseto, KeyringFile: secretsFile})
+	require.Error(t, err)
+}
+
+func TestTokenType(t *testing.T) {
+	pasetoMaker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+	pasetoPublicMaker, err := NewPasetoPublicMaker(newEd25519Key(t), "key-1")
+	require.NoError(t, err)
+	jwtMaker, err := NewJWTMaker(JWTAlgorithmEdDSA, newEd25519Key(t), "key-1")
+	require.NoError(t, err)
+
+	for name, maker := range map[string]Maker{
+		MakerPaseto:       pasetoMaker,
+		MakerPasetoPublic: pasetoPublicMaker,
+		MakerJWTEdDSA:     jwtMaker,
+	} {
+		t.Run(name, func(t *testing.T) {
+			refreshToken, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.Nil, time.Minute, TokenTypeRefreshToken)
+			require.NoError(t, err)
+
+			// A refresh token is its own session
+			payload, err := maker.VerifyToken(refreshToken, TokenTypeRefreshToken)
+			require.NoError(t, err)
+			require.Equal(t, TokenTypeRefreshToken, payload.Type)
+			require.Equal(t, payload.ID, payload.SessionID)
+
+			// A refresh token never authorizes a request in place of an access token
+			payload, err = maker.VerifyToken(refreshToken, TokenTypeAccessToken)
+			require.ErrorIs(t, err, ErrInvalidToken)
+			require.Nil(t, payload)
+		})
+	}
+}
@@ -2,51 +2,78 @@ package token
 
 import (
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/o1egl/paseto/v2"
 	"golang.org/x/crypto/chacha20poly1305"
 	"time"
 )
 
+// DefaultKeyID is the id of the key given to NewPasetoMaker
+const DefaultKeyID = "default"
+
 // PasetoMaker is a PASETO Token
 type PasetoMaker struct {
-	paseto       *paseto.V2
-	symmetricKey []byte
+	paseto  *paseto.V2
+	keyring *Keyring[[]byte]
 }
 
-// NewPasetoMaker creates a new PasetoMaker
+// NewPasetoMaker creates a new PasetoMaker with a single key
 func NewPasetoMaker(symmetricKey string) (Maker, error) {
-	if len(symmetricKey) != chacha20poly1305.KeySize {
-		return nil, fmt.Errorf("invalid key size: must be exactly %d characters", chacha20poly1305.KeySize)
+	keyring, err := NewKeyring(Key[[]byte]{ID: DefaultKeyID, Material: []byte(symmetricKey)})
+	if err != nil {
+		return nil, err
+	}
+	return NewPasetoKeyringMaker(keyring)
+}
+
+// NewPasetoKeyringMaker creates a new PasetoMaker that signs with the current key of the keyring
+func NewPasetoKeyringMaker(keyring *Keyring[[]byte]) (Maker, error) {
+	for _, key := range keyring.Keys() {
+		if len(key.Material) != chacha20poly1305.KeySize {
+			return nil, fmt.Errorf("invalid key size for key %q: must be exactly %d characters", key.ID, chacha20poly1305.KeySize)
+		}
 	}
 	maker := &PasetoMaker{
-		paseto:       paseto.NewV2(),
-		symmetricKey: []byte(symmetricKey),
+		paseto:  paseto.NewV2(),
+		keyring: keyring,
 	}
 	return maker, nil
 }
 
-func (maker *PasetoMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(username, duration)
+func (maker *PasetoMaker) CreateToken(username string, role string, sessionID uuid.UUID, duration time.Duration, tokenType TokenType) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, sessionID, duration, tokenType)
 	if err != nil {
 		return "", payload, err
 	}
-	token, err := maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
+	key := maker.keyring.SigningKey()
+	token, err := maker.paseto.Encrypt(key.Material, payload, pasetoFooter{KeyID: key.ID})
 	return token, payload, err
 }
 
 // VerifyToken checks if the token is valid or not
-func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
-	payload := &Payload{}
+func (maker *PasetoMaker) VerifyToken(token string, tokenType TokenType) (*Payload, error) {
+	var footer pasetoFooter
+	_ = paseto.ParseFooter(token, &footer)
 
-	err := maker.paseto.Decrypt(token, maker.symmetricKey, payload, nil)
-	if err != nil {
-		return nil, ErrInvalidToken
+	keys := maker.keyring.Keys()
+	if footer.KeyID != "" {
+		key, ok := maker.keyring.Key(footer.KeyID)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		keys = []Key[[]byte]{key}
 	}
-
-	err = payload.Valid()
-	if err != nil {
-		return nil, err
+	// Tokens issued before key ids have no footer, so every key is tried
+	for _, key := range keys {
+		payload := &Payload{}
+		if err := maker.paseto.Decrypt(token, key.Material, payload, nil); err != nil {
+			continue
+		}
+		err := payload.Valid(tokenType)
+		if err != nil {
+			return nil, err
+		}
+		return payload, nil
 	}
-
-	return payload, nil
+	return nil, ErrInvalidToken
 }
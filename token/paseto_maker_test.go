@@ -1,6 +1,8 @@
 package token
 
 import (
+	"github.com/google/uuid"
+	"github.com/o1egl/paseto/v2"
 	"github.com/stretchr/testify/require"
 	"github.com/the-eduardo/Go-Bank/util"
 	"testing"
@@ -12,22 +14,26 @@ func TestPasetoMaker(t *testing.T) {
 	require.NoError(t, err)
 
 	username := util.RandomOwner()
+	role := util.DepositorRole
+	sessionID := uuid.New()
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, payload, err := maker.CreateToken(username, duration)
+	token, payload, err := maker.CreateToken(username, role, sessionID, duration, TokenTypeAccessToken)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
 
-	payload, err = maker.VerifyToken(token)
+	payload, err = maker.VerifyToken(token, TokenTypeAccessToken)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, username, payload.Username)
+	require.Equal(t, role, payload.Role)
+	require.Equal(t, sessionID, payload.SessionID)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
@@ -36,13 +42,70 @@ func TestExpiredPasetoToken(t *testing.T) {
 	maker, err := NewPasetoMaker(util.RandomString(32))
 	require.NoError(t, err)
 
-	token, payload, err := maker.CreateToken(util.RandomOwner(), -time.Minute)
+	token, payload, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.Nil, -time.Minute, TokenTypeAccessToken)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
 
-	payload, err = maker.VerifyToken(token)
+	payload, err = maker.VerifyToken(token, TokenTypeAccessToken)
 	require.Error(t, err)
 	require.EqualError(t, err, ErrExpiredToken.Error())
 	require.Nil(t, payload)
 }
+
+func TestPasetoMakerKeyRotation(t *testing.T) {
+	now := time.Now()
+	oldKey := Key[[]byte]{ID: "old", ActiveFrom: now.Add(-time.Hour), Material: []byte(util.RandomString(32))}
+	newKey := Key[[]byte]{ID: "new", ActiveFrom: now.Add(time.Hour), Material: []byte(util.RandomString(32))}
+
+	keyring, err := NewKeyring(oldKey, newKey)
+	require.NoError(t, err)
+	maker, err := NewPasetoKeyringMaker(keyring)
+	require.NoError(t, err)
+
+	oldToken, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.Nil, time.Minute, TokenTypeAccessToken)
+	require.NoError(t, err)
+
+	// Rotate: the new key signs, the old tokens still verify
+	keyring.now = func() time.Time { return now.Add(2 * time.Hour) }
+	newToken, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.Nil, 3*time.Hour, TokenTypeAccessToken)
+	require.NoError(t, err)
+	require.NotEqual(t, oldToken, newToken)
+
+	onlyOld, err := NewPasetoMaker(string(oldKey.Material))
+	require.NoError(t, err)
+	_, err = onlyOld.VerifyToken(newToken, TokenTypeAccessToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+
+	_, err = maker.VerifyToken(oldToken, TokenTypeAccessToken)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(newToken, TokenTypeAccessToken)
+	require.NoError(t, err)
+
+	// Retiring the old key invalidates its tokens
+	newKey.ActiveFrom = now.Add(-time.Minute)
+	retired, err := NewKeyring(newKey)
+	require.NoError(t, err)
+	retiredMaker, err := NewPasetoKeyringMaker(retired)
+	require.NoError(t, err)
+	_, err = retiredMaker.VerifyToken(oldToken, TokenTypeAccessToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	_, err = retiredMaker.VerifyToken(newToken, TokenTypeAccessToken)
+	require.NoError(t, err)
+}
+
+func TestPasetoMakerLegacyToken(t *testing.T) {
+	symmetricKey := util.RandomString(32)
+	maker, err := NewPasetoMaker(symmetricKey)
+	require.NoError(t, err)
+
+	// Tokens issued before key ids have no footer
+	payload, err := NewPayload(util.RandomOwner(), util.DepositorRole, uuid.Nil, time.Minute, TokenTypeAccessToken)
+	require.NoError(t, err)
+	legacyToken, err := paseto.NewV2().Encrypt([]byte(symmetricKey), payload, nil)
+	require.NoError(t, err)
+
+	verified, err := maker.VerifyToken(legacyToken, TokenTypeAccessToken)
+	require.NoError(t, err)
+	require.Equal(t, payload.ID, verified.ID)
+}
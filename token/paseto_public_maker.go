@@ -0,0 +1,159 @@
+package token
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+// pasetoPublicHeader is the header of PASETO v4.public tokens
+const pasetoPublicHeader = "v4.public."
+
+// pasetoFooter is the unencrypted footer of the tokens, it tells the verifier which key signed the token
+type pasetoFooter struct {
+	KeyID string `json:"kid"`
+}
+
+// PasetoPublicMaker is a PASETO v4.public token maker, signed with Ed25519 keys
+type PasetoPublicMaker struct {
+	keyring *Keyring[crypto.Signer]
+}
+
+// NewPasetoPublicMaker creates a new PasetoPublicMaker with a single key
+func NewPasetoPublicMaker(privateKey crypto.Signer, keyID string) (PublicKeyMaker, error) {
+	keyring, err := NewKeyring(Key[crypto.Signer]{ID: keyID, Material: privateKey})
+	if err != nil {
+		return nil, err
+	}
+	return NewPasetoPublicKeyringMaker(keyring)
+}
+
+// NewPasetoPublicKeyringMaker creates a new PasetoPublicMaker that signs with the current key of the keyring
+func NewPasetoPublicKeyringMaker(keyring *Keyring[crypto.Signer]) (PublicKeyMaker, error) {
+	for _, key := range keyring.Keys() {
+		if _, ok := key.Material.(ed25519.PrivateKey); !ok {
+			return nil, fmt.Errorf("paseto v4.public requires Ed25519 keys, key %q is %T", key.ID, key.Material)
+		}
+	}
+	maker := &PasetoPublicMaker{
+		keyring: keyring,
+	}
+	return maker, nil
+}
+
+func (maker *PasetoPublicMaker) CreateToken(username string, role string, sessionID uuid.UUID, duration time.Duration, tokenType TokenType) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, sessionID, duration, tokenType)
+	if err != nil {
+		return "", payload, err
+	}
+	message, err := json.Marshal(payload)
+	if err != nil {
+		return "", payload, err
+	}
+	key := maker.keyring.SigningKey()
+	footer, err := json.Marshal(pasetoFooter{KeyID: key.ID})
+	if err != nil {
+		return "", payload, err
+	}
+
+	token := signPasetoPublic(key.Material.(ed25519.PrivateKey), message, footer, nil)
+	return token, payload, nil
+}
+
+// VerifyToken checks if the token is valid or not
+func (maker *PasetoPublicMaker) VerifyToken(token string, tokenType TokenType) (*Payload, error) {
+	message, signature, footer, err := parsePasetoPublic(token)
+	if err != nil {
+		return nil, err
+	}
+	var keyInfo pasetoFooter
+	if err := json.Unmarshal(footer, &keyInfo); err != nil {
+		return nil, ErrInvalidToken
+	}
+	key, ok := maker.keyring.Key(keyInfo.KeyID)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	if !verifyPasetoPublic(key.Material.Public().(ed25519.PublicKey), message, signature, footer, nil) {
+		return nil, ErrInvalidToken
+	}
+
+	payload := &Payload{}
+	if err := json.Unmarshal(message, payload); err != nil {
+		return nil, ErrInvalidToken
+	}
+	err = payload.Valid(tokenType)
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// JWKS returns the public keys that verify the tokens of this maker
+func (maker *PasetoPublicMaker) JWKS() JSONWebKeySet {
+	return newJSONWebKeySet(maker.keyring, "")
+}
+
+// signPasetoPublic signs a message into a v4.public token, the footer is appended when it is not empty.
+// The implicit assertion is signed but not part of the token, the verifier has to pass the same one.
+func signPasetoPublic(privateKey ed25519.PrivateKey, message, footer, implicit []byte) string {
+	signature := ed25519.Sign(privateKey, pae([]byte(pasetoPublicHeader), message, footer, implicit))
+	token := pasetoPublicHeader + base64.RawURLEncoding.EncodeToString(append(append([]byte{}, message...), signature...))
+	if len(footer) > 0 {
+		token += "." + base64.RawURLEncoding.EncodeToString(footer)
+	}
+	return token
+}
+
+// parsePasetoPublic splits a v4.public token into its message, signature and footer, without checking the signature
+func parsePasetoPublic(token string) (message, signature, footer []byte, err error) {
+	if !strings.HasPrefix(token, pasetoPublicHeader) {
+		return nil, nil, nil, ErrInvalidToken
+	}
+	parts := strings.Split(strings.TrimPrefix(token, pasetoPublicHeader), ".")
+	if len(parts) > 2 {
+		return nil, nil, nil, ErrInvalidToken
+	}
+	body, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(body) < ed25519.SignatureSize {
+		return nil, nil, nil, ErrInvalidToken
+	}
+	if len(parts) == 2 {
+		footer, err = base64.RawURLEncoding.DecodeString(parts[1])
+		if err != nil || len(footer) == 0 {
+			return nil, nil, nil, ErrInvalidToken
+		}
+	}
+	message = body[:len(body)-ed25519.SignatureSize]
+	signature = body[len(body)-ed25519.SignatureSize:]
+	return message, signature, footer, nil
+}
+
+// verifyPasetoPublic checks the signature of the parts of a v4.public token
+func verifyPasetoPublic(publicKey ed25519.PublicKey, message, signature, footer, implicit []byte) bool {
+	return ed25519.Verify(publicKey, pae([]byte(pasetoPublicHeader), message, footer, implicit), signature)
+}
+
+// pae is the pre-authentication encoding of the PASETO spec, it is the message that gets signed
+func pae(pieces ...[]byte) []byte {
+	var buf bytes.Buffer
+	writeLength := func(n int) {
+		var length [8]byte
+		// The most significant bit must be cleared
+		binary.LittleEndian.PutUint64(length[:], uint64(n)&(1<<63-1))
+		buf.Write(length[:])
+	}
+	writeLength(len(pieces))
+	for _, piece := range pieces {
+		writeLength(len(piece))
+		buf.Write(piece)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,160 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/util"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return privateKey
+}
+
+func TestPasetoPublicMaker(t *testing.T) {
+	maker, err := NewPasetoPublicMaker(newEd25519Key(t), "key-1")
+	require.NoError(t, err)
+
+	username := util.RandomOwner()
+	role := util.DepositorRole
+	sessionID := uuid.New()
+	duration := time.Minute
+
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+
+	token, payload, err := maker.CreateToken(username, role, sessionID, duration, TokenTypeAccessToken)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, pasetoPublicHeader))
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token, TokenTypeAccessToken)
+	require.NoError(t, err)
+	require.NotZero(t, payload.ID)
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, role, payload.Role)
+	require.Equal(t, sessionID, payload.SessionID)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+
+	jwks := maker.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	require.Equal(t, "key-1", jwks.Keys[0].KeyID)
+	require.Equal(t, "OKP", jwks.Keys[0].KeyType)
+	require.Equal(t, "Ed25519", jwks.Keys[0].Curve)
+}
+
+func TestExpiredPasetoPublicToken(t *testing.T) {
+	maker, err := NewPasetoPublicMaker(newEd25519Key(t), "key-1")
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.Nil, -time.Minute, TokenTypeAccessToken)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token, TokenTypeAccessToken)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestInvalidPasetoPublicToken(t *testing.T) {
+	privateKey := newEd25519Key(t)
+	maker, err := NewPasetoPublicMaker(privateKey, "key-1")
+	require.NoError(t, err)
+	token, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.Nil, time.Minute, TokenTypeAccessToken)
+	require.NoError(t, err)
+
+	// Signed by another key
+	otherMaker, err := NewPasetoPublicMaker(newEd25519Key(t), "key-1")
+	require.NoError(t, err)
+	_, err = otherMaker.VerifyToken(token, TokenTypeAccessToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+
+	// Same key under another id
+	renamedMaker, err := NewPasetoPublicMaker(privateKey, "key-2")
+	require.NoError(t, err)
+	_, err = renamedMaker.VerifyToken(token, TokenTypeAccessToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+
+	// Tampered body
+	tampered := []byte(token)
+	tampered[len(pasetoPublicHeader)+5] ^= 1
+	_, err = maker.VerifyToken(string(tampered), TokenTypeAccessToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+
+	// A local token is rejected
+	localMaker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+	localToken, _, err := localMaker.CreateToken(util.RandomOwner(), util.DepositorRole, uuid.Nil, time.Minute, TokenTypeAccessToken)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(localToken, TokenTypeAccessToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+}
+
+func TestPAE(t *testing.T) {
+	require.Equal(t, []byte("\x00\x00\x00\x00\x00\x00\x00\x00"), pae())
+	require.Equal(t, []byte("\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"), pae([]byte("")))
+	require.Equal(t, []byte("\x01\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00test"), pae([]byte("test")))
+}
+
+// TestPasetoPublicVectors checks signing and verifying against the official v4.public test vectors
+// from https://github.com/paseto-standard/test-vectors
+func TestPasetoPublicVectors(t *testing.T) {
+	secretKey, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774" +
+		"1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	require.NoError(t, err)
+	publicKey, err := hex.DecodeString("1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	require.NoError(t, err)
+	require.Equal(t, ed25519.PublicKey(publicKey), ed25519.PrivateKey(secretKey).Public())
+
+	payload := `{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`
+	footer := `{"kid":"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}`
+
+	testCases := []struct {
+		name     string
+		token    string
+		footer   string
+		implicit string
+	}{
+		{
+			name:  "4-S-1",
+			token: "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA",
+		},
+		{
+			name:   "4-S-2",
+			token:  "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9v3Jt8mx_TdM2ceTGoqwrh4yDFn0XsHvvV_D0DtwQxVrJEBMl0F2caAdgnpKlt4p7xBnx1HcO-SPo8FPp214HDw.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+			footer: footer,
+		},
+		{
+			name:     "4-S-3",
+			token:    "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9NPWciuD3d0o5eXJXG5pJy-DiVEoyPYWs1YSTwWHNJq6DZD3je5gf-0M4JR9ipdUSJbIovzmBECeaWmaqcaP0DQ.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+			footer:   footer,
+			implicit: `{"test-vector":"4-S-3"}`,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			// Ed25519 signatures are deterministic, so signing gives the token of the vector
+			token := signPasetoPublic(secretKey, []byte(payload), []byte(tc.footer), []byte(tc.implicit))
+			require.Equal(t, tc.token, token)
+
+			message, signature, tokenFooter, err := parsePasetoPublic(tc.token)
+			require.NoError(t, err)
+			require.Equal(t, payload, string(message))
+			require.Equal(t, tc.footer, string(tokenFooter))
+			require.True(t, verifyPasetoPublic(publicKey, message, signature, tokenFooter, []byte(tc.implicit)))
+
+			// the implicit assertion is part of the signature
+			require.False(t, verifyPasetoPublic(publicKey, message, signature, tokenFooter, []byte(`{"test-vector":"other"}`)))
+		})
+	}
+}
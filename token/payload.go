@@ -11,32 +11,54 @@ var (
 	ErrExpiredToken = errors.New("expired token")
 )
 
+// TokenType tells the access tokens from the refresh tokens, so a refresh token cannot authorize a request
+type TokenType byte
+
+const (
+	TokenTypeAccessToken  TokenType = 1
+	TokenTypeRefreshToken TokenType = 2
+)
+
 // Payload contains the payload data of the token
 type Payload struct {
-	ID        uuid.UUID `json:"id"`
+	ID   uuid.UUID `json:"id"`
+	Type TokenType `json:"token_type"`
+	// SessionID is the login session the token belongs to, a refresh token is its own session
+	SessionID uuid.UUID `json:"session_id"`
 	Username  string    `json:"username"`
+	Role      string    `json:"role"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
-// NewPayload creates a new Payload with specified username and duration
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+// NewPayload creates a new Payload with specified username, role, session, duration and token type.
+// The session of a refresh token is always its own id.
+func NewPayload(username string, role string, sessionID uuid.UUID, duration time.Duration, tokenType TokenType) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
 	}
+	if tokenType == TokenTypeRefreshToken {
+		sessionID = tokenID
+	}
 
 	payload := &Payload{
 		ID:        tokenID,
+		Type:      tokenType,
+		SessionID: sessionID,
 		Username:  username,
+		Role:      role,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
 	return payload, nil
 }
 
-// Valid checks if the token payload is valid or not
-func (payload *Payload) Valid() error {
+// Valid checks if the token payload is valid or not, and that it is a token of tokenType
+func (payload *Payload) Valid(tokenType TokenType) error {
+	if payload.Type != tokenType {
+		return ErrInvalidToken
+	}
 	if time.Now().After(payload.ExpiredAt) {
 		return ErrExpiredToken
 	}
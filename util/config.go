@@ -7,18 +7,50 @@ import (
 
 // Config holds all the configuration for the application using Viper.
 type Config struct {
-	SecretCodeLength     int           `mapstructure:"SECRET_CODE_LENGTH"`
-	EmailSenderName      string        `mapstructure:"EMAIL_SENDER_NAME"`
-	EmailSenderAddress   string        `mapstructure:"EMAIL_SENDER_ADDRESS"`
-	EmailSenderPassword  string        `mapstructure:"EMAIL_SENDER_PASSWORD"`
-	RedisAddress         string        `mapstructure:"REDIS_ADDRESS"`
-	Environment          string        `mapstructure:"ENVIRONMENT"`
-	DBSource             string        `mapstructure:"DB_SOURCE"`
-	HTTPServerAddress    string        `mapstructure:"HTTP_SERVER_ADDRESS"`
-	GRPCServerAddress    string        `mapstructure:"GRPC_SERVER_ADDRESS"`
-	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	SecretCodeLength              int           `mapstructure:"SECRET_CODE_LENGTH"`
+	EmailSenderName               string        `mapstructure:"EMAIL_SENDER_NAME"`
+	EmailSenderAddress            string        `mapstructure:"EMAIL_SENDER_ADDRESS"`
+	EmailSenderPassword           string        `mapstructure:"EMAIL_SENDER_PASSWORD"`
+	RedisAddress                  string        `mapstructure:"REDIS_ADDRESS"`
+	Environment                   string        `mapstructure:"ENVIRONMENT"`
+	DBSource                      string        `mapstructure:"DB_SOURCE"`
+	HTTPServerAddress             string        `mapstructure:"HTTP_SERVER_ADDRESS"`
+	GRPCServerAddress             string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	TokenMaker                    string        `mapstructure:"TOKEN_MAKER"`
+	TokenSymmetricKey             string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	TokenPrivateKeyFile           string        `mapstructure:"TOKEN_PRIVATE_KEY_FILE"`
+	TokenKeyID                    string        `mapstructure:"TOKEN_KEY_ID"`
+	TokenKeyringFile              string        `mapstructure:"TOKEN_KEYRING_FILE"`
+	AccessTokenDuration           time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration          time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	SessionCleanupInterval        time.Duration `mapstructure:"SESSION_CLEANUP_INTERVAL"`
+	ScheduledTransferPollInterval time.Duration `mapstructure:"SCHEDULED_TRANSFER_POLL_INTERVAL"`
+	MFAChallengeDuration          time.Duration `mapstructure:"MFA_CHALLENGE_DURATION"`
+	TOTPIssuer                    string        `mapstructure:"TOTP_ISSUER"`
+	LoginMaxAttempts              int32         `mapstructure:"LOGIN_MAX_ATTEMPTS"`
+	LoginMaxAttemptsPerIP         int32         `mapstructure:"LOGIN_MAX_ATTEMPTS_PER_IP"`
+	LoginLockoutDuration          time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	PasswordResetDuration         time.Duration `mapstructure:"PASSWORD_RESET_DURATION"`
+	RequireVerifiedEmail          bool          `mapstructure:"REQUIRE_VERIFIED_EMAIL"`
+	KYCDailyTransferLimits        []int64       `mapstructure:"KYC_DAILY_TRANSFER_LIMITS"`
+	AccountTransferMaxAmount      int64         `mapstructure:"ACCOUNT_TRANSFER_MAX_AMOUNT"`
+	AccountDailyTransferLimit     int64         `mapstructure:"ACCOUNT_DAILY_TRANSFER_LIMIT"`
+	AccountWeeklyTransferLimit    int64         `mapstructure:"ACCOUNT_WEEKLY_TRANSFER_LIMIT"`
+	AccountHourlyTransferCount    int64         `mapstructure:"ACCOUNT_HOURLY_TRANSFER_COUNT"`
+	UserTransferMaxAmount         int64         `mapstructure:"USER_TRANSFER_MAX_AMOUNT"`
+	UserDailyTransferLimit        int64         `mapstructure:"USER_DAILY_TRANSFER_LIMIT"`
+	UserWeeklyTransferLimit       int64         `mapstructure:"USER_WEEKLY_TRANSFER_LIMIT"`
+	UserHourlyTransferCount       int64         `mapstructure:"USER_HOURLY_TRANSFER_COUNT"`
+	IdempotencyKeyTTL             time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+	FXRateProvider                string        `mapstructure:"FX_RATE_PROVIDER"`
+	FXRatesSource                 string        `mapstructure:"FX_RATES_SOURCE"`
+	FXQuoteDuration               time.Duration `mapstructure:"FX_QUOTE_DURATION"`
+	FeeScheduleFile               string        `mapstructure:"FEE_SCHEDULE_FILE"`
+	TransferHoldDuration          time.Duration `mapstructure:"TRANSFER_HOLD_DURATION"`
+	TransferHoldExpiryInterval    time.Duration `mapstructure:"TRANSFER_HOLD_EXPIRY_INTERVAL"`
+	WebhookDispatchInterval       time.Duration `mapstructure:"WEBHOOK_DISPATCH_INTERVAL"`
+	WebhookTimeout                time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	WebhookAllowPrivateHosts      bool          `mapstructure:"WEBHOOK_ALLOW_PRIVATE_HOSTS"`
 }
 
 // LoadConfig reads the configuration from the file and environment variables.
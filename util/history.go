@@ -0,0 +1,45 @@
+package util
+
+// Directions of the money in the transaction history
+const (
+	DirectionIn  = "in"
+	DirectionOut = "out"
+)
+
+// Orders of the transaction history
+const (
+	SortByCreatedAt = "created_at"
+	SortByAmount    = "amount"
+	SortOrderAsc    = "asc"
+	SortOrderDesc   = "desc"
+)
+
+// MaxHistoryPageSize is the largest page of entries or transfers a client may ask for
+const MaxHistoryPageSize = 100
+
+// IsSupportedDirection checks if the transaction history can be filtered by the direction
+func IsSupportedDirection(direction string) bool {
+	switch direction {
+	case DirectionIn, DirectionOut:
+		return true
+	}
+	return false
+}
+
+// IsSupportedSortBy checks if the transaction history can be sorted by the field
+func IsSupportedSortBy(sortBy string) bool {
+	switch sortBy {
+	case SortByCreatedAt, SortByAmount:
+		return true
+	}
+	return false
+}
+
+// IsSupportedSortOrder checks if the sort order is valid
+func IsSupportedSortOrder(sortOrder string) bool {
+	switch sortOrder {
+	case SortOrderAsc, SortOrderDesc:
+		return true
+	}
+	return false
+}
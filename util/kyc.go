@@ -0,0 +1,17 @@
+package util
+
+// KYC tiers, a user starts without kyc and moves up once a submission is approved
+const (
+	KYCTierNone  = 0
+	KYCTierBasic = 1
+	KYCTierFull  = 2
+)
+
+// IsSupportedKYCTier checks if a user can apply for the tier
+func IsSupportedKYCTier(tier int32) bool {
+	switch tier {
+	case KYCTierBasic, KYCTierFull:
+		return true
+	}
+	return false
+}
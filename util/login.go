@@ -0,0 +1,37 @@
+package util
+
+import (
+	"sync"
+	"time"
+)
+
+// loginBackoffBase is the delay after the second failed login, it doubles with every further failure
+const loginBackoffBase = time.Second
+
+// LoginBackoff returns how long logins are refused after the given number of consecutive failures.
+// The first failure is free, the delay then grows exponentially until maxAttempts locks out for the whole lockout.
+func LoginBackoff(failures int32, maxAttempts int32, lockout time.Duration) time.Duration {
+	if failures >= maxAttempts {
+		return lockout
+	}
+	if failures < 2 {
+		return 0
+	}
+	shift := failures - 2
+	if shift > 30 {
+		return lockout
+	}
+	return min(loginBackoffBase<<shift, lockout)
+}
+
+// missingUserHash is a bcrypt hash no password matches, created on first use
+var missingUserHash = sync.OnceValue(func() string {
+	hash, _ := HashPassword(RandomString(32))
+	return hash
+})
+
+// CheckPasswordOfMissingUser spends the time of a password check when the username does not exist,
+// so that the response time does not reveal which usernames are taken
+func CheckPasswordOfMissingUser(password string) {
+	_ = CheckPassword(missingUserHash(), password)
+}
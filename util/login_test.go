@@ -0,0 +1,23 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestLoginBackoff(t *testing.T) {
+	lockout := 15 * time.Minute
+
+	require.Zero(t, LoginBackoff(0, 5, lockout))
+	require.Zero(t, LoginBackoff(1, 5, lockout))
+	require.Equal(t, time.Second, LoginBackoff(2, 5, lockout))
+	require.Equal(t, 2*time.Second, LoginBackoff(3, 5, lockout))
+	require.Equal(t, 4*time.Second, LoginBackoff(4, 5, lockout))
+	require.Equal(t, lockout, LoginBackoff(5, 5, lockout))
+	require.Equal(t, lockout, LoginBackoff(6, 5, lockout))
+
+	// The delay never exceeds the lockout, even before the last attempt
+	require.Equal(t, lockout, LoginBackoff(15, 100, lockout))
+	require.Equal(t, lockout, LoginBackoff(99, 100, lockout))
+}
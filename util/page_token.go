@@ -0,0 +1,43 @@
+package util
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrInvalidPageToken is returned when a page token cannot be decoded or was issued for another sort order
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// PageToken is the position of the last row of a page, the next page starts right after it.
+// Clients get it encoded as an opaque string, which only continues a list sorted the same way.
+type PageToken struct {
+	SortBy    string    `json:"sort_by,omitempty"`
+	SortOrder string    `json:"sort_order,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Amount    int64     `json:"amount,omitempty"`
+	ID        int64     `json:"id"`
+}
+
+// Encode returns the page token as an opaque string
+func (token PageToken) Encode() string {
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodePageToken decodes a page token and checks it was issued for a list with the same sort order
+func DecodePageToken(value string, sortBy string, sortOrder string) (PageToken, error) {
+	var token PageToken
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return token, ErrInvalidPageToken
+	}
+	if err = json.Unmarshal(data, &token); err != nil || token.ID < 1 {
+		return token, ErrInvalidPageToken
+	}
+	if token.SortBy != sortBy || token.SortOrder != sortOrder {
+		return token, ErrInvalidPageToken
+	}
+	return token, nil
+}
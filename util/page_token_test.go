@@ -0,0 +1,30 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPageToken(t *testing.T) {
+	token := PageToken{
+		SortBy:    SortByAmount,
+		SortOrder: SortOrderDesc,
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		Amount:    RandomMoney(),
+		ID:        RandomInt(1, 1000),
+	}
+
+	decoded, err := DecodePageToken(token.Encode(), SortByAmount, SortOrderDesc)
+	require.NoError(t, err)
+	require.Equal(t, token, decoded)
+
+	_, err = DecodePageToken(token.Encode(), SortByCreatedAt, SortOrderDesc)
+	require.ErrorIs(t, err, ErrInvalidPageToken)
+
+	_, err = DecodePageToken("not a token", "", "")
+	require.ErrorIs(t, err, ErrInvalidPageToken)
+
+	_, err = DecodePageToken(PageToken{}.Encode(), "", "")
+	require.ErrorIs(t, err, ErrInvalidPageToken)
+}
@@ -24,3 +24,11 @@ func TestPassword(t *testing.T) {
 	require.NotEmpty(t, hashedPassword2)
 	require.NotEqual(t, hashedPassword, hashedPassword2)
 }
+
+func TestHashSecretCode(t *testing.T) {
+	code := RandomString(32)
+	hashedCode := HashSecretCode(code)
+	require.Len(t, hashedCode, 64)
+	require.Equal(t, hashedCode, HashSecretCode(code))
+	require.NotEqual(t, hashedCode, HashSecretCode(RandomString(32)))
+}
@@ -0,0 +1,65 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"time"
+)
+
+// MinRecurrenceInterval is the shortest interval between two runs of a scheduled transfer
+const MinRecurrenceInterval = time.Hour
+
+// ErrInvalidRecurrence is returned when a recurrence sets both or neither of a cron expression and an interval
+var ErrInvalidRecurrence = errors.New("recurrence needs either a cron expression or an interval")
+
+// Recurrence repeats a scheduled transfer on a standard cron expression, evaluated in UTC, or at a fixed interval
+type Recurrence struct {
+	CronExpression string
+	Interval       time.Duration
+}
+
+// Validate checks that the recurrence can compute its runs
+func (recurrence Recurrence) Validate() error {
+	if recurrence.CronExpression == "" {
+		if recurrence.Interval == 0 {
+			return ErrInvalidRecurrence
+		}
+		if recurrence.Interval < MinRecurrenceInterval {
+			return fmt.Errorf("interval must be at least %s", MinRecurrenceInterval)
+		}
+		return nil
+	}
+	if recurrence.Interval != 0 {
+		return ErrInvalidRecurrence
+	}
+	_, err := cron.ParseStandard(recurrence.CronExpression)
+	if err != nil {
+		return fmt.Errorf("invalid cron expression: %w", err)
+	}
+	return nil
+}
+
+// Next returns the first run strictly after now that follows the last run.
+// The runs missed between them are skipped, intervals keep counting from the last run.
+func (recurrence Recurrence) Next(last time.Time, now time.Time) (time.Time, error) {
+	if err := recurrence.Validate(); err != nil {
+		return time.Time{}, err
+	}
+	if recurrence.CronExpression == "" {
+		missed := int64(0)
+		if now.After(last) {
+			missed = int64(now.Sub(last) / recurrence.Interval)
+		}
+		return last.Add(time.Duration(missed+1) * recurrence.Interval), nil
+	}
+	schedule, _ := cron.ParseStandard(recurrence.CronExpression)
+	return schedule.Next(later(last, now).UTC()), nil
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,46 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRecurrenceValidate(t *testing.T) {
+	require.NoError(t, Recurrence{CronExpression: "0 9 1 * *"}.Validate())
+	require.NoError(t, Recurrence{CronExpression: "@weekly"}.Validate())
+	require.NoError(t, Recurrence{Interval: 24 * time.Hour}.Validate())
+
+	require.ErrorIs(t, Recurrence{}.Validate(), ErrInvalidRecurrence)
+	require.ErrorIs(t, Recurrence{CronExpression: "@daily", Interval: time.Hour}.Validate(), ErrInvalidRecurrence)
+	require.Error(t, Recurrence{CronExpression: "every monday"}.Validate())
+	require.Error(t, Recurrence{Interval: time.Minute}.Validate())
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	last := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	// the 1st of every month at 9:00
+	monthly := Recurrence{CronExpression: "0 9 1 * *"}
+	next, err := monthly.Next(last, last)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC), next)
+
+	// runs missed while the worker was down are skipped
+	next, err = monthly.Next(last, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC), next)
+
+	daily := Recurrence{Interval: 24 * time.Hour}
+	next, err = daily.Next(last, last.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, last.Add(24*time.Hour), next)
+
+	// intervals keep their time of day
+	next, err = daily.Next(last, last.Add(50*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, last.Add(72*time.Hour), next)
+
+	_, err = Recurrence{}.Next(last, last)
+	require.ErrorIs(t, err, ErrInvalidRecurrence)
+}
@@ -0,0 +1,29 @@
+package util
+
+// All user roles
+const (
+	DepositorRole = "depositor"
+	BankerRole    = "banker"
+	AdminRole     = "admin"
+)
+
+// IsStaffRole checks if the role may view and act on any customer's accounts
+func IsStaffRole(role string) bool {
+	switch role {
+	case BankerRole, AdminRole:
+		return true
+	}
+	return false
+}
+
+// roleRanks orders the roles from the least to the most privileged
+var roleRanks = map[string]int{
+	DepositorRole: 1,
+	BankerRole:    2,
+	AdminRole:     3,
+}
+
+// OutranksRole checks if role is strictly more privileged than other, a user may only manage users below them
+func OutranksRole(role string, other string) bool {
+	return roleRanks[role] > roleRanks[other]
+}
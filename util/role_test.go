@@ -0,0 +1,16 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestOutranksRole(t *testing.T) {
+	require.True(t, OutranksRole(AdminRole, BankerRole))
+	require.True(t, OutranksRole(AdminRole, DepositorRole))
+	require.True(t, OutranksRole(BankerRole, DepositorRole))
+	require.False(t, OutranksRole(AdminRole, AdminRole))
+	require.False(t, OutranksRole(BankerRole, AdminRole))
+	require.False(t, OutranksRole(DepositorRole, DepositorRole))
+	require.False(t, OutranksRole("unknown", DepositorRole))
+}
@@ -0,0 +1,75 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"fmt"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"strings"
+	"time"
+)
+
+// totpPeriod is the lifetime of a TOTP code in seconds, the default of authenticator apps
+const totpPeriod = 30
+
+// totpSkew is how many periods before and after the current one are accepted, for clock drift
+const totpSkew = 1
+
+// NewTOTPKey generates a TOTP secret and the otpauth URI that authenticator apps scan
+func NewTOTPKey(issuer string, accountName string) (secret string, uri string, err error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: accountName,
+		Period:      totpPeriod,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("error generating totp key: %w", err)
+	}
+	return key.Secret(), key.URL(), nil
+}
+
+// ValidateTOTP checks a code against the secret at the given time.
+// It returns the time step the code belongs to, so that a used code can be rejected when replayed.
+func ValidateTOTP(secret string, code string, t time.Time) (step int64, ok bool) {
+	opts := totp.ValidateOpts{
+		Period:    totpPeriod,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	}
+	for skew := -totpSkew; skew <= totpSkew; skew++ {
+		at := t.Add(time.Duration(skew*totpPeriod) * time.Second)
+		expected, err := totp.GenerateCodeCustom(secret, at, opts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return at.Unix() / totpPeriod, true
+		}
+	}
+	return 0, false
+}
+
+// recoveryCodeEncoding is lowercase base32 without the easily confused padding
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// NewRecoveryCodes returns n random one-time codes such as "k3v9q-7hx2m"
+func NewRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+	for i := range codes {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("error generating recovery code: %w", err)
+		}
+		code := recoveryCodeEncoding.EncodeToString(raw)[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode returns the sha256 of a recovery code, ignoring case and the dash
+func HashRecoveryCode(code string) string {
+	normalized := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(code)), "-", "")
+	return HashSecretCode(normalized)
+}
@@ -0,0 +1,58 @@
+package util
+
+import (
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateTOTP(t *testing.T) {
+	secret, uri, err := NewTOTPKey("GoBank", "user@example.com")
+	require.NoError(t, err)
+	require.NotEmpty(t, secret)
+	require.True(t, strings.HasPrefix(uri, "otpauth://totp/GoBank:user@example.com?"))
+
+	now := time.Now()
+	code, err := totp.GenerateCode(secret, now)
+	require.NoError(t, err)
+
+	step, ok := ValidateTOTP(secret, code, now)
+	require.True(t, ok)
+	require.Equal(t, now.Unix()/30, step)
+
+	// The previous code is still accepted for clock drift, with its own step
+	previous, err := totp.GenerateCode(secret, now.Add(-30*time.Second))
+	require.NoError(t, err)
+	if previous != code {
+		step, ok = ValidateTOTP(secret, previous, now)
+		require.True(t, ok)
+		require.Equal(t, now.Unix()/30-1, step)
+	}
+
+	_, ok = ValidateTOTP(secret, code, now.Add(5*time.Minute))
+	require.False(t, ok)
+	_, ok = ValidateTOTP(secret, "abcdef", now)
+	require.False(t, ok)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := NewRecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		require.Len(t, code, 11)
+		require.Equal(t, byte('-'), code[5])
+		require.False(t, seen[code])
+		seen[code] = true
+	}
+
+	hashed := HashRecoveryCode(codes[0])
+	require.Len(t, hashed, 64)
+	require.Equal(t, hashed, HashRecoveryCode(" "+strings.ToUpper(codes[0])))
+	require.Equal(t, hashed, HashRecoveryCode(strings.ReplaceAll(codes[0], "-", "")))
+	require.NotEqual(t, hashed, HashRecoveryCode(codes[1]))
+}
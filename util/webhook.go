@@ -0,0 +1,30 @@
+package util
+
+import "net"
+
+// Webhook event types, a subscription receives the events of the types it lists
+const (
+	WebhookTransferPosted = "transfer.posted"
+	WebhookUserCreated    = "user.created"
+)
+
+// IsSupportedWebhookEvent checks if a subscription can ask for the event type
+func IsSupportedWebhookEvent(eventType string) bool {
+	switch eventType {
+	case WebhookTransferPosted, WebhookUserCreated:
+		return true
+	}
+	return false
+}
+
+// IsPublicIP checks if a webhook may be delivered to ip. Loopback, private, link-local and unspecified addresses
+// reach the network of the bank itself, so a subscription could use the worker to call internal services.
+func IsPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified()
+}
@@ -2,8 +2,14 @@ package val
 
 import (
 	"fmt"
+	"github.com/google/uuid"
+	"github.com/the-eduardo/Go-Bank/util"
+	"net"
 	"net/mail"
+	"net/url"
 	"regexp"
+	"strings"
+	"time"
 )
 
 var isValidUsername = regexp.MustCompile(`^[a-zA-Z0-9_]+$`).MatchString
@@ -51,3 +57,153 @@ func ValidateEmail(value string) error {
 	}
 	return nil
 }
+
+func ValidateID(value int64) error {
+	if value < 1 {
+		return fmt.Errorf("must be a positive integer")
+	}
+	return nil
+}
+
+func ValidateCurrency(value string) error {
+	if !util.IsSupportedCurrency(value) {
+		return fmt.Errorf("unsupported currency")
+	}
+	return nil
+}
+
+func ValidateAmount(value int64) error {
+	if value <= 0 {
+		return fmt.Errorf("amount must be greater than zero")
+	}
+	return nil
+}
+
+func ValidatePageID(value int32) error {
+	if value < 1 {
+		return fmt.Errorf("page id must be at least 1")
+	}
+	return nil
+}
+
+func ValidatePageSize(value int32) error {
+	if value < 5 || value > 10 {
+		return fmt.Errorf("page size must be between 5 and 10")
+	}
+	return nil
+}
+
+func ValidateHistoryPageSize(value int32) error {
+	if value < 5 || value > util.MaxHistoryPageSize {
+		return fmt.Errorf("page size must be between 5 and %d", util.MaxHistoryPageSize)
+	}
+	return nil
+}
+
+func ValidateDirection(value string) error {
+	if !util.IsSupportedDirection(value) {
+		return fmt.Errorf("direction must be %s or %s", util.DirectionIn, util.DirectionOut)
+	}
+	return nil
+}
+
+func ValidateSortBy(value string) error {
+	if !util.IsSupportedSortBy(value) {
+		return fmt.Errorf("can only sort by %s or %s", util.SortByCreatedAt, util.SortByAmount)
+	}
+	return nil
+}
+
+func ValidateSortOrder(value string) error {
+	if !util.IsSupportedSortOrder(value) {
+		return fmt.Errorf("sort order must be %s or %s", util.SortOrderAsc, util.SortOrderDesc)
+	}
+	return nil
+}
+
+func ValidateIdempotencyKey(value string) error {
+	return ValidateString(value, 1, 255)
+}
+
+func ValidateUUID(value string) error {
+	if _, err := uuid.Parse(value); err != nil {
+		return fmt.Errorf("must be a valid uuid")
+	}
+	return nil
+}
+
+var isValidTOTPCode = regexp.MustCompile(`^[0-9]{6}$`).MatchString
+
+func ValidateTOTPCode(value string) error {
+	if !isValidTOTPCode(value) {
+		return fmt.Errorf("code must have 6 digits")
+	}
+	return nil
+}
+
+func ValidateRecoveryCode(value string) error {
+	return ValidateString(value, 10, 11)
+}
+
+func ValidateKYCTier(value int32) error {
+	if !util.IsSupportedKYCTier(value) {
+		return fmt.Errorf("unsupported kyc tier")
+	}
+	return nil
+}
+
+func ValidateTransferLimit(value int64) error {
+	if value < 0 {
+		return fmt.Errorf("limit must not be negative")
+	}
+	return nil
+}
+
+func ValidateRecurrence(cronExpression string, intervalSeconds int64) error {
+	if intervalSeconds < 0 {
+		return fmt.Errorf("interval must not be negative")
+	}
+	return util.Recurrence{
+		CronExpression: cronExpression,
+		Interval:       time.Duration(intervalSeconds) * time.Second,
+	}.Validate()
+}
+
+// ValidateWebhookURL refuses the loopback, private and link-local hosts unless allowPrivateHosts is set
+// for a local receiver. Host names are checked again against the addresses they resolve to when delivering.
+func ValidateWebhookURL(value string, allowPrivateHosts bool) error {
+	if err := ValidateString(value, 10, 2048); err != nil {
+		return err
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("must be an absolute http or https url")
+	}
+	if !allowPrivateHosts && !isPublicHost(u.Hostname()) {
+		return fmt.Errorf("must not point to a loopback, private or link-local host")
+	}
+	return nil
+}
+
+func isPublicHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return util.IsPublicIP(ip)
+	}
+	return true
+}
+
+func ValidateWebhookEventTypes(values []string) error {
+	if len(values) == 0 {
+		return fmt.Errorf("at least one event type is required")
+	}
+	for _, value := range values {
+		if !util.IsSupportedWebhookEvent(value) {
+			return fmt.Errorf("unsupported event type %q", value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/the-eduardo/Go-Bank/util"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Headers sent with every delivery
+const (
+	SignatureHeader = "X-GoBank-Signature"
+	EventHeader     = "X-GoBank-Event"
+	DeliveryHeader  = "X-GoBank-Delivery"
+)
+
+// userAgent identifies the deliveries in the logs of the receivers
+const userAgent = "GoBank-Webhooks/1.0"
+
+// maxErrorBodySize is how much of the response of a failed delivery is kept in its error
+const maxErrorBodySize = 256
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrExpiredSignature = errors.New("webhook signature is too old")
+	ErrPrivateHost      = errors.New("webhook host is not a public address")
+)
+
+// Payload is the JSON body of a delivery, Data depends on the event type
+type Payload struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Message is an event to deliver to the URL of a subscription
+type Message struct {
+	DeliveryID int64
+	URL        string
+	Secret     string
+	Payload    Payload
+}
+
+// Sign returns the signature header of a body sent at timestamp, in the form t=<unix seconds>,v1=<hex>.
+// v1 is the HMAC-SHA256 of "<unix seconds>.<body>" with the secret of the subscription.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", unix, computeSignature(secret, unix, body))
+}
+
+// Verify checks the signature header of a body the way a receiver should,
+// rejecting the signatures made more than tolerance before now so a captured delivery cannot be replayed later.
+func Verify(secret string, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var unix string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	timestamp, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+	expected := computeSignature(secret, unix, body)
+	valid := false
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			valid = true
+		}
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	if now.Sub(time.Unix(timestamp, 0)) > tolerance {
+		return ErrExpiredSignature
+	}
+	return nil
+}
+
+func computeSignature(secret string, unix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sender delivers the messages, it returns the http status of the receiver even when the delivery failed
+type Sender interface {
+	Send(ctx context.Context, message Message) (statusCode int, err error)
+}
+
+type HTTPSender struct {
+	client *http.Client
+}
+
+// NewHTTPSender returns a sender that gives up on a receiver after timeout. Redirects are not followed,
+// the receiver has to answer with a 2xx status at the URL of the subscription.
+// Unless allowPrivateHosts is set for a local receiver, the addresses that are not public are refused
+// when dialing, after the host is resolved, so a host name cannot be pointed at the internal network.
+func NewHTTPSender(timeout time.Duration, allowPrivateHosts bool) Sender {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivateHosts {
+		dialer.Control = refusePrivateHost
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would dial the receiver itself, past the check of the dialer
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &HTTPSender{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func refusePrivateHost(network string, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !util.IsPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateHost, host)
+	}
+	return nil
+}
+
+func (sender *HTTPSender) Send(ctx context.Context, message Message) (int, error) {
+	body, err := json.Marshal(message.Payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, message.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(EventHeader, message.Payload.Type)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(message.DeliveryID, 10))
+	req.Header.Set(SignatureHeader, Sign(message.Secret, time.Now(), body))
+
+	res, err := sender.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return res.StatusCode, nil
+	}
+	answer, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodySize))
+	return res.StatusCode, fmt.Errorf("receiver answered %s: %s", res.Status, bytes.TrimSpace(answer))
+}
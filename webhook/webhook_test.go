@@ -0,0 +1,143 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"github.com/the-eduardo/Go-Bank/util"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret := util.RandomString(32)
+	body := []byte(`{"id":1}`)
+	now := time.Now()
+	header := Sign(secret, now, body)
+
+	require.NoError(t, Verify(secret, header, body, now, time.Minute))
+	require.ErrorIs(t, Verify(util.RandomString(32), header, body, now, time.Minute), ErrInvalidSignature)
+	require.ErrorIs(t, Verify(secret, header, []byte(`{"id":2}`), now, time.Minute), ErrInvalidSignature)
+	require.ErrorIs(t, Verify(secret, "v1=abc", body, now, time.Minute), ErrInvalidSignature)
+	require.ErrorIs(t, Verify(secret, header, body, now.Add(2*time.Minute), time.Minute), ErrExpiredSignature)
+
+	// a receiver keeps accepting the deliveries signed before a new signature version is added
+	require.NoError(t, Verify(secret, header+",v2=unknown", body, now, time.Minute))
+}
+
+func TestSend(t *testing.T) {
+	secret := util.RandomString(32)
+	payload := Payload{
+		ID:        7,
+		Type:      util.WebhookTransferPosted,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		Data:      json.RawMessage(`{"amount":10}`),
+	}
+
+	var received Payload
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.Equal(t, util.WebhookTransferPosted, r.Header.Get(EventHeader))
+		require.Equal(t, "3", r.Header.Get(DeliveryHeader))
+		require.NoError(t, Verify(secret, r.Header.Get(SignatureHeader), body, time.Now(), time.Minute))
+		require.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	sender := NewHTTPSender(time.Second, true)
+	statusCode, err := sender.Send(context.Background(), Message{
+		DeliveryID: 3,
+		URL:        receiver.URL,
+		Secret:     secret,
+		Payload:    payload,
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, statusCode)
+	require.Equal(t, payload.ID, received.ID)
+	require.Equal(t, payload.Type, received.Type)
+	require.True(t, payload.CreatedAt.Equal(received.CreatedAt))
+	require.JSONEq(t, string(payload.Data), string(received.Data))
+}
+
+func TestSendFailure(t *testing.T) {
+	testCases := []struct {
+		name           string
+		handler        http.HandlerFunc
+		wantStatusCode int
+		wantError      string
+	}{
+		{
+			name: "ServerError",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "database is down", http.StatusServiceUnavailable)
+			},
+			wantStatusCode: http.StatusServiceUnavailable,
+			wantError:      "database is down",
+		},
+		{
+			name: "Redirect",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "/elsewhere", http.StatusFound)
+			},
+			wantStatusCode: http.StatusFound,
+			wantError:      "302",
+		},
+		{
+			name: "Timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			},
+			wantError: "Client.Timeout",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			receiver := httptest.NewServer(tc.handler)
+			defer receiver.Close()
+
+			sender := NewHTTPSender(100*time.Millisecond, true)
+			statusCode, err := sender.Send(context.Background(), Message{
+				DeliveryID: 1,
+				URL:        receiver.URL,
+				Secret:     util.RandomString(32),
+				Payload:    Payload{ID: 1, Type: util.WebhookUserCreated, Data: json.RawMessage(`{}`)},
+			})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.wantError)
+			require.Equal(t, tc.wantStatusCode, statusCode)
+		})
+	}
+}
+
+func TestSendPrivateHost(t *testing.T) {
+	called := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer receiver.Close()
+
+	// the receiver listens on the loopback, which is only allowed for local testing
+	sender := NewHTTPSender(time.Second, false)
+	statusCode, err := sender.Send(context.Background(), Message{
+		DeliveryID: 1,
+		URL:        receiver.URL,
+		Secret:     util.RandomString(32),
+		Payload:    Payload{ID: 1, Type: util.WebhookUserCreated, Data: json.RawMessage(`{}`)},
+	})
+	require.ErrorIs(t, err, ErrPrivateHost)
+	require.Zero(t, statusCode)
+	require.False(t, called)
+}
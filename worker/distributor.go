@@ -11,6 +11,41 @@ type TaskDistributor interface {
 		payload *PayloadSendVerifyEmail,
 		opts ...asynq.Option,
 	) error
+	DistributeTaskSendPasswordReset(
+		ctx context.Context,
+		payload *PayloadSendPasswordReset,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskSendLockoutEmail(
+		ctx context.Context,
+		payload *PayloadSendLockoutEmail,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskSendEmailChanged(
+		ctx context.Context,
+		payload *PayloadSendEmailChanged,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskExecuteScheduledTransfer(
+		ctx context.Context,
+		payload *PayloadExecuteScheduledTransfer,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskSendScheduledTransferFailed(
+		ctx context.Context,
+		payload *PayloadSendScheduledTransferFailed,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskSendStatement(
+		ctx context.Context,
+		payload *PayloadSendStatement,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskDeliverWebhook(
+		ctx context.Context,
+		payload *PayloadDeliverWebhook,
+		opts ...asynq.Option,
+	) error
 }
 
 type RedisTaskDistributor struct {
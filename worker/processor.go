@@ -5,8 +5,11 @@ import (
 	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog/log"
 	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/fees"
 	"github.com/the-eduardo/Go-Bank/mail"
+	"github.com/the-eduardo/Go-Bank/policy"
 	"github.com/the-eduardo/Go-Bank/util"
+	"github.com/the-eduardo/Go-Bank/webhook"
 )
 
 const (
@@ -19,12 +22,27 @@ const (
 type TaskProcessor interface {
 	Start() error
 	ProcessTaskSendVerifyEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendPasswordReset(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendLockoutEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendEmailChanged(ctx context.Context, task *asynq.Task) error
+	ProcessTaskDeleteExpiredSessions(ctx context.Context, task *asynq.Task) error
+	ProcessTaskEnqueueDueScheduledTransfers(ctx context.Context, task *asynq.Task) error
+	ProcessTaskExecuteScheduledTransfer(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendScheduledTransferFailed(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendStatement(ctx context.Context, task *asynq.Task) error
+	ProcessTaskEnqueueMonthlyStatements(ctx context.Context, task *asynq.Task) error
+	ProcessTaskDispatchWebhookEvents(ctx context.Context, task *asynq.Task) error
+	ProcessTaskDeliverWebhook(ctx context.Context, task *asynq.Task) error
 }
 type RedisTaskProcessor struct {
-	server *asynq.Server
-	store  db.Store
-	mailer mail.EmailSender
-	config util.Config
+	server        *asynq.Server
+	store         db.Store
+	mailer        mail.EmailSender
+	config        util.Config
+	distributor   TaskDistributor
+	policy        policy.Policy
+	feeSchedule   *fees.Schedule
+	webhookSender webhook.Sender
 }
 
 func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, store db.Store, mailer mail.EmailSender) TaskProcessor {
@@ -45,16 +63,36 @@ func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, store db.Store, mailer
 	if err != nil {
 		log.Fatal().Msgf("cannot load config in redis task processor: %v", err)
 	}
+	feeSchedule, err := fees.LoadSchedule(config.FeeScheduleFile)
+	if err != nil {
+		log.Fatal().Msgf("cannot load fee schedule in redis task processor: %v", err)
+	}
 	return &RedisTaskProcessor{
-		server: server,
-		store:  store,
-		mailer: mailer,
-		config: config,
+		server:        server,
+		store:         store,
+		mailer:        mailer,
+		config:        config,
+		distributor:   NewRedisTaskDistributor(redisOpt),
+		policy:        policy.New(config),
+		feeSchedule:   feeSchedule,
+		webhookSender: webhook.NewHTTPSender(config.WebhookTimeout, config.WebhookAllowPrivateHosts),
 	}
 }
 
 func (processor *RedisTaskProcessor) Start() error {
 	mux := asynq.NewServeMux()
 	mux.HandleFunc(TaskSendVerifyEmail, processor.ProcessTaskSendVerifyEmail)
+	mux.HandleFunc(TaskSendPasswordReset, processor.ProcessTaskSendPasswordReset)
+	mux.HandleFunc(TaskSendLockoutEmail, processor.ProcessTaskSendLockoutEmail)
+	mux.HandleFunc(TaskSendEmailChanged, processor.ProcessTaskSendEmailChanged)
+	mux.HandleFunc(TaskDeleteExpiredSessions, processor.ProcessTaskDeleteExpiredSessions)
+	mux.HandleFunc(TaskEnqueueDueScheduledTransfers, processor.ProcessTaskEnqueueDueScheduledTransfers)
+	mux.HandleFunc(TaskExecuteScheduledTransfer, processor.ProcessTaskExecuteScheduledTransfer)
+	mux.HandleFunc(TaskSendScheduledTransferFailed, processor.ProcessTaskSendScheduledTransferFailed)
+	mux.HandleFunc(TaskExpireTransferHolds, processor.ProcessTaskExpireTransferHolds)
+	mux.HandleFunc(TaskSendStatement, processor.ProcessTaskSendStatement)
+	mux.HandleFunc(TaskEnqueueMonthlyStatements, processor.ProcessTaskEnqueueMonthlyStatements)
+	mux.HandleFunc(TaskDispatchWebhookEvents, processor.ProcessTaskDispatchWebhookEvents)
+	mux.HandleFunc(TaskDeliverWebhook, processor.ProcessTaskDeliverWebhook)
 	return processor.server.Start(mux)
 }
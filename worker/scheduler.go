@@ -0,0 +1,69 @@
+package worker
+
+import (
+	"fmt"
+	"github.com/hibiken/asynq"
+	"time"
+)
+
+// SchedulerIntervals sets how often the scheduler enqueues each maintenance task
+type SchedulerIntervals struct {
+	SessionCleanup        time.Duration
+	ScheduledTransferPoll time.Duration
+	TransferHoldExpiry    time.Duration
+	WebhookDispatch       time.Duration
+}
+
+// NewScheduler returns a scheduler that periodically enqueues the maintenance tasks
+func NewScheduler(redisOpt asynq.RedisClientOpt, intervals SchedulerIntervals) (*asynq.Scheduler, error) {
+	scheduler := asynq.NewScheduler(redisOpt, &asynq.SchedulerOpts{
+		Logger: NewLogger(),
+	})
+	_, err := scheduler.Register(
+		fmt.Sprintf("@every %s", intervals.SessionCleanup),
+		asynq.NewTask(TaskDeleteExpiredSessions, nil),
+		asynq.Queue(QueueLow),
+		asynq.Unique(intervals.SessionCleanup),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register session cleanup task: %w", err)
+	}
+	_, err = scheduler.Register(
+		fmt.Sprintf("@every %s", intervals.ScheduledTransferPoll),
+		asynq.NewTask(TaskEnqueueDueScheduledTransfers, nil),
+		asynq.Queue(QueueCritial),
+		asynq.Unique(intervals.ScheduledTransferPoll),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register scheduled transfer task: %w", err)
+	}
+	_, err = scheduler.Register(
+		fmt.Sprintf("@every %s", intervals.TransferHoldExpiry),
+		asynq.NewTask(TaskExpireTransferHolds, nil),
+		asynq.Queue(QueueCritial),
+		asynq.Unique(intervals.TransferHoldExpiry),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register transfer hold expiry task: %w", err)
+	}
+	_, err = scheduler.Register(
+		fmt.Sprintf("@every %s", intervals.WebhookDispatch),
+		asynq.NewTask(TaskDispatchWebhookEvents, nil),
+		asynq.Queue(QueueDefault),
+		asynq.Unique(intervals.WebhookDispatch),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register webhook dispatch task: %w", err)
+	}
+	// statements cover whole calendar months, so they are enqueued at midnight on the first of the month
+	_, err = scheduler.Register(
+		"@monthly",
+		asynq.NewTask(TaskEnqueueMonthlyStatements, nil),
+		asynq.Queue(QueueLow),
+		asynq.Unique(time.Hour),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register monthly statement task: %w", err)
+	}
+	return scheduler, nil
+}
@@ -0,0 +1,138 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/webhook"
+)
+
+const TaskDeliverWebhook = "task:deliver_webhook"
+
+// webhookMaxRetry is how many times a delivery is retried, the default backoff of asynq
+// spreads the retries over about four hours before the delivery is marked as failed
+const webhookMaxRetry = 10
+
+type PayloadDeliverWebhook struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+// WebhookDeliveryOptions are the options of the task sending a delivery. The task id changes with every
+// round of attempts, so a delivery sent again is not taken for the round that already ended.
+func WebhookDeliveryOptions(delivery db.WebhookDelivery) []asynq.Option {
+	return []asynq.Option{
+		asynq.TaskID(fmt.Sprintf("webhook_delivery:%d:%d", delivery.ID, delivery.Attempts)),
+		asynq.MaxRetry(webhookMaxRetry),
+		asynq.Queue(QueueDefault),
+	}
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskDeliverWebhook(
+	ctx context.Context,
+	payload *PayloadDeliverWebhook,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+	task := asynq.NewTask(TaskDeliverWebhook, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+	log.Info().Str("task_id", info.ID).
+		Str("type", task.Type()).
+		Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("task enqueued")
+	return nil
+}
+
+// ProcessTaskDeliverWebhook posts the signed event to the URL of the subscription and records the attempt.
+// A failed attempt is retried by asynq, the delivery stays pending until the last retry fails.
+func (processor *RedisTaskProcessor) ProcessTaskDeliverWebhook(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadDeliverWebhook
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal task payload: %w", asynq.SkipRetry)
+	}
+	delivery, err := processor.store.GetWebhookDelivery(ctx, payload.DeliveryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// the deliveries are deleted with their subscription
+			return fmt.Errorf("webhook delivery %d not found: %w", payload.DeliveryID, asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if delivery.Status != db.WebhookDeliveryPending {
+		log.Info().
+			Str("type", task.Type()).
+			Int64("delivery_id", delivery.ID).
+			Str("status", delivery.Status).
+			Msg("skipped finished webhook delivery")
+		return nil
+	}
+	message, err := processor.webhookMessage(ctx, delivery)
+	if err != nil {
+		return err
+	}
+
+	statusCode, sendErr := processor.webhookSender.Send(ctx, message)
+	attempt := db.RecordWebhookDeliveryAttemptParams{
+		ID:     delivery.ID,
+		Status: db.WebhookDeliverySucceeded,
+	}
+	if statusCode != 0 {
+		attempt.ResponseStatus = pgtype.Int4{Int32: int32(statusCode), Valid: true}
+	}
+	if sendErr != nil {
+		attempt.Status = db.WebhookDeliveryPending
+		if isLastRetry(ctx) {
+			attempt.Status = db.WebhookDeliveryFailed
+		}
+		attempt.Error = sendErr.Error()
+	}
+	delivery, err = processor.store.RecordWebhookDeliveryAttempt(ctx, attempt)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery attempt: %w", err)
+	}
+	if sendErr != nil {
+		return fmt.Errorf("failed to deliver webhook: %w", sendErr)
+	}
+	log.Info().
+		Str("type", task.Type()).
+		Int64("delivery_id", delivery.ID).
+		Int32("attempts", delivery.Attempts).
+		Msg("processed task")
+	return nil
+}
+
+// webhookMessage builds the message of a delivery from its event and subscription
+func (processor *RedisTaskProcessor) webhookMessage(ctx context.Context, delivery db.WebhookDelivery) (webhook.Message, error) {
+	subscription, err := processor.store.GetWebhookSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return webhook.Message{}, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	event, err := processor.store.GetWebhookEvent(ctx, delivery.EventID)
+	if err != nil {
+		return webhook.Message{}, fmt.Errorf("failed to get webhook event: %w", err)
+	}
+	return webhook.Message{
+		DeliveryID: delivery.ID,
+		URL:        subscription.Url,
+		Secret:     subscription.Secret,
+		Payload: webhook.Payload{
+			ID:        event.ID,
+			Type:      event.EventType,
+			CreatedAt: event.CreatedAt.Time,
+			Data:      event.Payload,
+		},
+	}, nil
+}
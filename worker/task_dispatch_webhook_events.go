@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+)
+
+const TaskDispatchWebhookEvents = "task:dispatch_webhook_events"
+
+// webhookEventBatchSize is how many outbox events are dispatched per transaction
+const webhookEventBatchSize = 100
+
+// ProcessTaskDispatchWebhookEvents creates and enqueues the deliveries of the events written to the outbox.
+// Each delivery is enqueued with its own task id, so a dispatch that is retried does not send it twice.
+func (processor *RedisTaskProcessor) ProcessTaskDispatchWebhookEvents(ctx context.Context, task *asynq.Task) error {
+	dispatched := 0
+	enqueued := 0
+	for {
+		result, err := processor.store.DispatchWebhookEventsTx(ctx, db.DispatchWebhookEventsTxParams{
+			Limit: webhookEventBatchSize,
+			AfterCreate: func(delivery db.WebhookDelivery) error {
+				return processor.enqueueWebhookDelivery(ctx, delivery)
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to dispatch webhook events: %w", err)
+		}
+		dispatched += len(result.Events)
+		enqueued += len(result.Deliveries)
+		if len(result.Events) < webhookEventBatchSize {
+			break
+		}
+	}
+	log.Info().
+		Str("type", task.Type()).
+		Int("dispatched", dispatched).
+		Int("enqueued", enqueued).
+		Msg("processed task")
+	return nil
+}
+
+// enqueueWebhookDelivery enqueues the next round of attempts of a delivery
+func (processor *RedisTaskProcessor) enqueueWebhookDelivery(ctx context.Context, delivery db.WebhookDelivery) error {
+	err := processor.distributor.DistributeTaskDeliverWebhook(ctx, &PayloadDeliverWebhook{DeliveryID: delivery.ID},
+		WebhookDeliveryOptions(delivery)...,
+	)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"time"
+)
+
+const TaskEnqueueDueScheduledTransfers = "task:enqueue_due_scheduled_transfers"
+
+// scheduledTransferBatchSize is how many due transfers are claimed per transaction
+const scheduledTransferBatchSize = 100
+
+// ProcessTaskEnqueueDueScheduledTransfers enqueues a run for every scheduled transfer that is due.
+// Each occurrence is enqueued with its own task id, so a claim that is retried does not run it twice.
+func (processor *RedisTaskProcessor) ProcessTaskEnqueueDueScheduledTransfers(ctx context.Context, task *asynq.Task) error {
+	now := time.Now().UTC()
+	enqueued := 0
+	for {
+		result, err := processor.store.ClaimDueScheduledTransfersTx(ctx, db.ClaimDueScheduledTransfersTxParams{
+			Now:   now,
+			Limit: scheduledTransferBatchSize,
+			AfterClaim: func(scheduledTransfer db.ScheduledTransfer) error {
+				scheduledFor := scheduledTransfer.NextRunAt.Time
+				taskPayload := &PayloadExecuteScheduledTransfer{
+					ScheduledTransferID: scheduledTransfer.ID,
+					ScheduledFor:        scheduledFor,
+				}
+				err := processor.distributor.DistributeTaskExecuteScheduledTransfer(ctx, taskPayload,
+					asynq.TaskID(fmt.Sprintf("scheduled_transfer:%d:%d", scheduledTransfer.ID, scheduledFor.Unix())),
+					asynq.MaxRetry(5),
+					asynq.Queue(QueueCritial),
+				)
+				if errors.Is(err, asynq.ErrTaskIDConflict) {
+					return nil
+				}
+				return err
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to claim due scheduled transfers: %w", err)
+		}
+		enqueued += len(result.Claimed)
+		if len(result.Claimed) < scheduledTransferBatchSize {
+			break
+		}
+	}
+	log.Info().
+		Str("type", task.Type()).
+		Int("enqueued", enqueued).
+		Msg("processed task")
+	return nil
+}
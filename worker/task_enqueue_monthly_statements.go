@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"time"
+)
+
+const TaskEnqueueMonthlyStatements = "task:enqueue_monthly_statements"
+
+// statementAccountBatchSize is how many accounts are read per page
+const statementAccountBatchSize = 100
+
+// ProcessTaskEnqueueMonthlyStatements enqueues the statement of the previous calendar month for every customer account.
+// Each statement is enqueued with its own task id, so a retry does not email it twice.
+func (processor *RedisTaskProcessor) ProcessTaskEnqueueMonthlyStatements(ctx context.Context, task *asynq.Task) error {
+	now := time.Now().UTC()
+	end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, -1, 0)
+
+	enqueued := 0
+	for offset := int64(0); ; offset += statementAccountBatchSize {
+		accounts, err := processor.store.ListAllAccounts(ctx, db.ListAllAccountsParams{
+			Limit:  statementAccountBatchSize,
+			Offset: offset,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list accounts: %w", err)
+		}
+		for _, account := range accounts {
+			if account.IsSystem {
+				continue
+			}
+			taskPayload := &PayloadSendStatement{
+				AccountID: account.ID,
+				Start:     start,
+				End:       end,
+			}
+			err = processor.distributor.DistributeTaskSendStatement(ctx, taskPayload,
+				asynq.TaskID(fmt.Sprintf("statement:%d:%s", account.ID, start.Format("2006-01"))),
+				asynq.MaxRetry(5),
+				asynq.Queue(QueueEmail),
+			)
+			if errors.Is(err, asynq.ErrTaskIDConflict) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to distribute task to send statement: %w", err)
+			}
+			enqueued++
+		}
+		if len(accounts) < statementAccountBatchSize {
+			break
+		}
+	}
+	log.Info().
+		Str("type", task.Type()).
+		Int("enqueued", enqueued).
+		Msg("processed task")
+	return nil
+}
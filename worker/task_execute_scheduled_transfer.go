@@ -0,0 +1,174 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/policy"
+	"time"
+)
+
+const TaskExecuteScheduledTransfer = "task:execute_scheduled_transfer"
+
+type PayloadExecuteScheduledTransfer struct {
+	ScheduledTransferID int64     `json:"scheduled_transfer_id"`
+	ScheduledFor        time.Time `json:"scheduled_for"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskExecuteScheduledTransfer(
+	ctx context.Context,
+	payload *PayloadExecuteScheduledTransfer,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+	task := asynq.NewTask(TaskExecuteScheduledTransfer, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+	log.Info().Str("task_id", info.ID).
+		Str("type", task.Type()).
+		Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("task enqueued")
+	return nil
+}
+
+// ProcessTaskExecuteScheduledTransfer runs one occurrence of a scheduled transfer. The transfer and its run
+// are committed together, so a retried task never transfers twice. Failures that a retry cannot fix,
+// and any failure of the last retry, are recorded as a failed run and emailed to the owner.
+func (processor *RedisTaskProcessor) ProcessTaskExecuteScheduledTransfer(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadExecuteScheduledTransfer
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal task payload: %w", asynq.SkipRetry)
+	}
+	scheduledTransfer, err := processor.store.GetScheduledTransfer(ctx, payload.ScheduledTransferID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("scheduled transfer %d not found: %w", payload.ScheduledTransferID, asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to get scheduled transfer: %w", err)
+	}
+	if !scheduledTransfer.IsActive {
+		log.Info().
+			Str("type", task.Type()).
+			Int64("scheduled_transfer_id", scheduledTransfer.ID).
+			Msg("skipped paused scheduled transfer")
+		return nil
+	}
+
+	result, err := processor.executeScheduledTransfer(ctx, scheduledTransfer, payload.ScheduledFor)
+	if errors.Is(err, db.ErrScheduledTransferAlreadyRun) {
+		log.Info().
+			Str("type", task.Type()).
+			Int64("scheduled_transfer_id", scheduledTransfer.ID).
+			Time("scheduled_for", payload.ScheduledFor).
+			Msg("scheduled transfer already ran")
+		return nil
+	}
+	if err != nil {
+		reason, final := scheduledTransferFailureReason(err)
+		if !final && !isLastRetry(ctx) {
+			return fmt.Errorf("failed to execute scheduled transfer: %w", err)
+		}
+		if failErr := processor.failScheduledTransfer(ctx, scheduledTransfer, payload.ScheduledFor, reason); failErr != nil {
+			return fmt.Errorf("failed to record scheduled transfer failure: %w", failErr)
+		}
+		return fmt.Errorf("scheduled transfer failed: %v: %w", err, asynq.SkipRetry)
+	}
+	log.Info().
+		Str("type", task.Type()).
+		Int64("scheduled_transfer_id", scheduledTransfer.ID).
+		Int64("transfer_id", result.Transfer.ID).
+		Msg("processed task")
+	return nil
+}
+
+// executeScheduledTransfer applies the policy of the owner as it is now, like a transfer they would send themselves
+func (processor *RedisTaskProcessor) executeScheduledTransfer(ctx context.Context, scheduledTransfer db.ScheduledTransfer, scheduledFor time.Time) (db.ExecuteScheduledTransferTxResult, error) {
+	owner, err := processor.store.GetUser(ctx, scheduledTransfer.Owner)
+	if err != nil {
+		return db.ExecuteScheduledTransferTxResult{}, fmt.Errorf("failed to get owner: %w", err)
+	}
+	limits, err := processor.policy.TransferLimits(owner)
+	if err != nil {
+		return db.ExecuteScheduledTransferTxResult{}, err
+	}
+	return processor.store.ExecuteScheduledTransferTx(ctx, db.ExecuteScheduledTransferTxParams{
+		ScheduledTransfer: scheduledTransfer,
+		ScheduledFor:      scheduledFor,
+		Limits:            limits,
+		Fees:              processor.feeSchedule,
+	})
+}
+
+// failScheduledTransfer records the failed run with the reason shown to the owner and emails them,
+// unless the occurrence already has a run
+func (processor *RedisTaskProcessor) failScheduledTransfer(ctx context.Context, scheduledTransfer db.ScheduledTransfer, scheduledFor time.Time, reason string) error {
+	_, err := processor.store.CreateScheduledTransferRun(ctx, db.CreateScheduledTransferRunParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		ScheduledFor:        pgtype.Timestamptz{Time: scheduledFor, Valid: true},
+		Status:              db.ScheduledTransferRunFailed,
+		Error:               reason,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	taskPayload := &PayloadSendScheduledTransferFailed{
+		Username:            scheduledTransfer.Owner,
+		ScheduledTransferID: scheduledTransfer.ID,
+		FromAccountID:       scheduledTransfer.FromAccountID,
+		ToAccountID:         scheduledTransfer.ToAccountID,
+		Amount:              scheduledTransfer.Amount,
+		ScheduledFor:        scheduledFor,
+		Reason:              reason,
+	}
+	// The failed run is already recorded, a lost notification must not run the transfer again
+	err = processor.distributor.DistributeTaskSendScheduledTransferFailed(ctx, taskPayload,
+		asynq.MaxRetry(7),
+		asynq.Timeout(15*time.Second),
+		asynq.Queue(QueueEmail),
+	)
+	if err != nil {
+		log.Error().Err(err).Int64("scheduled_transfer_id", scheduledTransfer.ID).Msg("cannot distribute scheduled transfer failure email")
+	}
+	return nil
+}
+
+// scheduledTransferFailureReason returns the reason shown to the owner, final is true when retrying cannot help
+func scheduledTransferFailureReason(err error) (reason string, final bool) {
+	var limitErr *db.TransferLimitError
+	switch {
+	case errors.Is(err, db.ErrInsufficientFunds):
+		return "the source account has insufficient funds", true
+	case errors.As(err, &limitErr):
+		return limitErr.Error(), true
+	case errors.Is(err, policy.ErrEmailNotVerified), errors.Is(err, policy.ErrKYCRequired):
+		return err.Error(), true
+	}
+	return "an internal error prevented the transfer", false
+}
+
+// isLastRetry tells whether the task will not be retried if it fails
+func isLastRetry(ctx context.Context) bool {
+	retried, ok := asynq.GetRetryCount(ctx)
+	if !ok {
+		return true
+	}
+	maxRetry, ok := asynq.GetMaxRetry(ctx)
+	return !ok || retried >= maxRetry
+}
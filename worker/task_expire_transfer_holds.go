@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"time"
+)
+
+const TaskExpireTransferHolds = "task:expire_transfer_holds"
+
+// transferHoldBatchSize is how many expired holds are released per transaction
+const transferHoldBatchSize = 100
+
+// ProcessTaskExpireTransferHolds releases the holds of the pending transfers that were not captured in time
+func (processor *RedisTaskProcessor) ProcessTaskExpireTransferHolds(ctx context.Context, task *asynq.Task) error {
+	now := time.Now().UTC()
+	expired := 0
+	for {
+		result, err := processor.store.ExpireTransfersTx(ctx, db.ExpireTransfersTxParams{
+			Now:   now,
+			Limit: transferHoldBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to expire transfer holds: %w", err)
+		}
+		expired += len(result.Expired)
+		if len(result.Expired) < transferHoldBatchSize {
+			break
+		}
+	}
+	log.Info().
+		Str("type", task.Type()).
+		Int("expired", expired).
+		Msg("processed task")
+	return nil
+}
@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSendEmailChanged = "task:send_email_changed"
+
+type PayloadSendEmailChanged struct {
+	Username string `json:"username"`
+	OldEmail string `json:"old_email"`
+	NewEmail string `json:"new_email"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendEmailChanged(
+	ctx context.Context,
+	payload *PayloadSendEmailChanged,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+	task := asynq.NewTask(TaskSendEmailChanged, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+	log.Info().Str("task_id", info.ID).
+		Str("type", task.Type()).
+		Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("task enqueued")
+	return nil
+}
+
+// ProcessTaskSendEmailChanged warns the old address, which no longer receives anything else from us
+func (processor *RedisTaskProcessor) ProcessTaskSendEmailChanged(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendEmailChanged
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal task payload: %w", asynq.SkipRetry)
+	}
+
+	to := []string{payload.OldEmail}
+	subject := "The email of your account was changed"
+	content := fmt.Sprintf(`
+<p>Hi %s,</p>
+<p>The email of your account was changed to %s, we will no longer write to this address.</p>
+<p>If this was not you, please contact our support right away.</p>
+`, payload.Username, payload.NewEmail)
+	err := processor.mailer.SendEmail(subject, content, to, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	log.Info().
+		Str("type", task.Type()).
+		Str("username", payload.Username).
+		Str("email", payload.OldEmail).
+		Msg("processed task")
+	return nil
+}
@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+const TaskSendLockoutEmail = "task:send_lockout_email"
+
+type PayloadSendLockoutEmail struct {
+	Username    string    `json:"username"`
+	ClientIP    string    `json:"client_ip"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendLockoutEmail(
+	ctx context.Context,
+	payload *PayloadSendLockoutEmail,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+	task := asynq.NewTask(TaskSendLockoutEmail, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+	log.Info().Str("task_id", info.ID).
+		Str("type", task.Type()).
+		Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("task enqueued")
+	return nil
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskSendLockoutEmail(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendLockoutEmail
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal task payload: %w", asynq.SkipRetry)
+	}
+	user, err := processor.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("user %s not found: %w", payload.Username, asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	to := []string{user.Email}
+	subject := "Your account was temporarily locked"
+	content := fmt.Sprintf(`
+<p>Hi %s,</p>
+<p>We locked the login of your account after too many failed attempts, the last one from %s.</p>
+<p>You can log in again after %s.</p>
+<p>If this was not you, please change your password once you are back in.</p>
+`, user.FullName, payload.ClientIP, payload.LockedUntil.UTC().Format(time.RFC1123))
+	err = processor.mailer.SendEmail(subject, content, to, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	log.Info().
+		Str("type", task.Type()).
+		Str("username", user.Username).
+		Str("email", user.Email).
+		Msg("processed task")
+	return nil
+}
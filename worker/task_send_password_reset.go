@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+	db "github.com/the-eduardo/Go-Bank/db/sqlc"
+	"github.com/the-eduardo/Go-Bank/util"
+	"time"
+)
+
+const TaskSendPasswordReset = "task:send_password_reset"
+
+type PayloadSendPasswordReset struct {
+	Username string `json:"username"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendPasswordReset(
+	ctx context.Context,
+	payload *PayloadSendPasswordReset,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+	task := asynq.NewTask(TaskSendPasswordReset, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+	log.Info().Str("task_id", info.ID).
+		Str("type", task.Type()).
+		Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("task enqueued")
+	return nil
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskSendPasswordReset(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendPasswordReset
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal task payload: %w", asynq.SkipRetry)
+	}
+	user, err := processor.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("user %s not found: %w", payload.Username, asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Only the hash is stored, the code itself is only in the email
+	code := util.RandomString(processor.config.SecretCodeLength)
+	expiresAt := time.Now().Add(processor.config.PasswordResetDuration)
+	_, err = processor.store.CreatePasswordReset(ctx, db.CreatePasswordResetParams{
+		Username:   user.Username,
+		HashedCode: util.HashSecretCode(code),
+		ExpiresAt:  pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create password reset: %w", err)
+	}
+
+	myUrl := "http://localhost:8080"
+	to := []string{user.Email}
+
+	subject := "Reset Your Password"
+	content := fmt.Sprintf(`
+<p>Hi %s,</p>
+<p>We received a request to reset the password of your account. Please choose a new password with the link below:</p>
+<p><a href="%s/reset_password?code=%s">Reset Password</a></p>
+<p>This link will expire in %s. Resetting the password logs you out of every device.</p>
+<p>If you did not request it, you can ignore this email, your password stays the same.</p>
+`, user.FullName, myUrl, code, processor.config.PasswordResetDuration)
+	err = processor.mailer.SendEmail(subject, content, to, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	log.Info().
+		Str("type", task.Type()).
+		Str("username", user.Username).
+		Str("email", user.Email).
+		Msg("processed task")
+	return nil
+}
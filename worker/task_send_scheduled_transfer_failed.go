@@ -0,0 +1,80 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+const TaskSendScheduledTransferFailed = "task:send_scheduled_transfer_failed"
+
+type PayloadSendScheduledTransferFailed struct {
+	Username            string    `json:"username"`
+	ScheduledTransferID int64     `json:"scheduled_transfer_id"`
+	FromAccountID       int64     `json:"from_account_id"`
+	ToAccountID         int64     `json:"to_account_id"`
+	Amount              int64     `json:"amount"`
+	ScheduledFor        time.Time `json:"scheduled_for"`
+	Reason              string    `json:"reason"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendScheduledTransferFailed(
+	ctx context.Context,
+	payload *PayloadSendScheduledTransferFailed,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+	task := asynq.NewTask(TaskSendScheduledTransferFailed, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+	log.Info().Str("task_id", info.ID).
+		Str("type", task.Type()).
+		Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("task enqueued")
+	return nil
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskSendScheduledTransferFailed(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendScheduledTransferFailed
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal task payload: %w", asynq.SkipRetry)
+	}
+	user, err := processor.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("user %s not found: %w", payload.Username, asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	to := []string{user.Email}
+	subject := "Your scheduled transfer failed"
+	content := fmt.Sprintf(`
+<p>Hi %s,</p>
+<p>The transfer of %d from account %d to account %d scheduled for %s did not go through: %s.</p>
+<p>The next runs of scheduled transfer %d stay on schedule.</p>
+`, user.FullName, payload.Amount, payload.FromAccountID, payload.ToAccountID,
+		payload.ScheduledFor.UTC().Format(time.RFC1123), payload.Reason, payload.ScheduledTransferID)
+	err = processor.mailer.SendEmail(subject, content, to, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	log.Info().
+		Str("type", task.Type()).
+		Str("username", user.Username).
+		Str("email", user.Email).
+		Msg("processed task")
+	return nil
+}
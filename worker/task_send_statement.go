@@ -0,0 +1,118 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/the-eduardo/Go-Bank/statement"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const TaskSendStatement = "task:send_statement"
+
+type PayloadSendStatement struct {
+	AccountID int64     `json:"account_id"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendStatement(
+	ctx context.Context,
+	payload *PayloadSendStatement,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+	task := asynq.NewTask(TaskSendStatement, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+	log.Info().Str("task_id", info.ID).
+		Str("type", task.Type()).
+		Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Msg("task enqueued")
+	return nil
+}
+
+// ProcessTaskSendStatement emails the statement of an account to its owner, as a CSV and a PDF attachment
+func (processor *RedisTaskProcessor) ProcessTaskSendStatement(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendStatement
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal task payload: %w", asynq.SkipRetry)
+	}
+	accountStatement, err := statement.Generate(ctx, processor.store, payload.AccountID, payload.Start, payload.End)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("account %d not found: %w", payload.AccountID, asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to generate statement: %w", err)
+	}
+	user, err := processor.store.GetUser(ctx, accountStatement.Account.Owner)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("user %s not found: %w", accountStatement.Account.Owner, asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// the mailer attaches files, so the statement is written to a directory removed once it is sent
+	dir, err := os.MkdirTemp("", "statement")
+	if err != nil {
+		return fmt.Errorf("failed to create statement directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	name := fmt.Sprintf("statement-%d-%s", payload.AccountID, payload.Start.UTC().Format("2006-01-02"))
+	csvFile := filepath.Join(dir, name+".csv")
+	if err = writeStatementFile(csvFile, accountStatement.WriteCSV); err != nil {
+		return err
+	}
+	pdfFile := filepath.Join(dir, name+".pdf")
+	if err = writeStatementFile(pdfFile, accountStatement.WritePDF); err != nil {
+		return err
+	}
+
+	to := []string{user.Email}
+	subject := fmt.Sprintf("Your statement for account %d", payload.AccountID)
+	content := fmt.Sprintf(`
+<p>Hi %s,</p>
+<p>Attached is the statement of account %d from %s to %s.</p>
+<p>Opening balance: %d %s<br/>Closing balance: %d %s</p>
+`, user.FullName, payload.AccountID,
+		payload.Start.UTC().Format(time.RFC1123), payload.End.UTC().Format(time.RFC1123),
+		accountStatement.OpeningBalance, accountStatement.Account.Currency,
+		accountStatement.ClosingBalance, accountStatement.Account.Currency)
+	err = processor.mailer.SendEmail(subject, content, to, nil, nil, []string{csvFile, pdfFile})
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	log.Info().
+		Str("type", task.Type()).
+		Int64("account_id", payload.AccountID).
+		Str("email", user.Email).
+		Msg("processed task")
+	return nil
+}
+
+func writeStatementFile(name string, write func(w io.Writer) error) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to create statement file: %w", err)
+	}
+	if err = write(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write statement file: %w", err)
+	}
+	return file.Close()
+}
@@ -16,6 +16,8 @@ const TaskSendVerifyEmail = "task:send_verify_email"
 
 type PayloadSendVerifyEmail struct {
 	Username string `json:"username"`
+	// Email is the address to verify, the current email of the user when empty
+	Email string `json:"email,omitempty"`
 }
 
 func (distributor *RedisTaskDistributor) DistributeTaskSendVerifyEmail(
@@ -55,9 +57,14 @@ func (processor *RedisTaskProcessor) ProcessTaskSendVerifyEmail(ctx context.Cont
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 
+	email := user.Email
+	if payload.Email != "" {
+		email = payload.Email
+	}
+
 	verifyEmail, err := processor.store.CreateVerifyEmail(ctx, db.CreateVerifyEmailParams{
 		Username:   user.Username,
-		Email:      user.Email,
+		Email:      email,
 		SecretCode: util.RandomString(processor.config.SecretCodeLength),
 	})
 	if err != nil {
@@ -66,7 +73,7 @@ func (processor *RedisTaskProcessor) ProcessTaskSendVerifyEmail(ctx context.Cont
 
 	// send email
 	myUrl := "http://localhost:8080"
-	to := []string{user.Email}
+	to := []string{email}
 
 	subject := "Welcome! Verify Your Email"
 	content := fmt.Sprintf(`
@@ -84,7 +91,7 @@ func (processor *RedisTaskProcessor) ProcessTaskSendVerifyEmail(ctx context.Cont
 		Str("type", task.Type()).
 		Bytes("payload", task.Payload()).
 		Str("username", user.Username).
-		Str("email", user.Email).
+		Str("email", email).
 		Msg("processed task")
 	return nil
 }